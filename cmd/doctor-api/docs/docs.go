@@ -117,6 +117,7 @@ const docTemplate = `{
                         "JWSToken": []
                     }
                 ],
+                "description": "The invoice of the completed appointment is charged automatically once it's issued if the patient opts in to autopay",
                 "tags": [
                     "Appointment"
                 ],
@@ -157,6 +158,132 @@ const docTemplate = `{
                 }
             }
         },
+        "/appointment/heartbeat": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The room is closed by the system when no heartbeat is received for a while",
+                "tags": [
+                    "Appointment"
+                ],
+                "summary": "Keep the room that the doctor is currently in alive",
+                "responses": {
+                    "200": {
+                        "description": "Room is kept alive"
+                    },
+                    "400": {
+                        "description": "Doctor isn't currently in any room",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/appointment/leave": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The appointment stays scheduled, so the doctor can start a new room for it later",
+                "tags": [
+                    "Appointment"
+                ],
+                "summary": "Force-leave the room that the doctor is currently in without finishing the appointment",
+                "responses": {
+                    "201": {
+                        "description": "Room is closed"
+                    },
+                    "400": {
+                        "description": "Doctor isn't currently in any room",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/appointment/waiting": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "Only the scheduled appointments that start within an hour or started less than 3 hours ago are considered",
+                "tags": [
+                    "Appointment"
+                ],
+                "summary": "Get list of the patients who are waiting in the waiting room",
+                "responses": {
+                    "200": {
+                        "description": "List of appointment overview with the presence of the patient",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.WaitingPatient"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/appointment/{appointmentID}": {
             "get": {
                 "security": [
@@ -182,9 +309,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Appointment detail",
+                        "description": "Appointment detail with the live presence of the patient",
                         "schema": {
-                            "$ref": "#/definitions/hospital.DoctorAppointment"
+                            "$ref": "#/definitions/handler.GetDoctorAppointmentDetailResponse"
                         }
                     },
                     "400": {
@@ -341,6 +468,52 @@ const docTemplate = `{
                 }
             }
         },
+        "/auth/refresh": {
+            "post": {
+                "description": "The refresh token is rotated, so the new refresh token must be used next time.\nReusing the rotated refresh token revokes the session since the token must be leaked",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the new token of the session with the refresh token",
+                "parameters": [
+                    {
+                        "description": "Refresh token of the session",
+                        "name": "RefreshTokenRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Token and the next refresh token of the session",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SigninResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh token is invalid, expired or revoked",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/signin": {
             "post": {
                 "tags": [
@@ -360,7 +533,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {
-                        "description": "Token is return when authentication is successes",
+                        "description": "Token and refresh token of the new session are return when authentication is successes",
                         "schema": {
                             "$ref": "#/definitions/handler.SigninResponse"
                         }
@@ -385,79 +558,1154 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.CompleteAppointmentRequest": {
-            "type": "object",
-            "required": [
-                "status"
-            ],
-            "properties": {
-                "status": {
-                    "type": "string",
-                    "enum": [
-                        "CANCELLED",
-                        "COMPLETED"
-                    ]
-                }
-            }
         },
-        "handler.InitAppointmentRoomResponse": {
-            "type": "object",
-            "properties": {
-                "room_id": {
-                    "type": "string"
+        "/auth/signout": {
+            "delete": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The session of the token is revoked, so its token and refresh token can't be used anymore.\nThe device token is kept since it may belong to another device that is still signed in",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Sign out the doctor from the current device",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "handler.ListAppointmentsResponse": {
-            "type": "object",
-            "properties": {
-                "appointments": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/hospital.AppointmentOverview"
+        "/auth/signout/all": {
+            "delete": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "Every session of the doctor is revoked, including the current one.\nThe device token is removed as well, so the push notification is no longer sent",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Sign out the doctor from all devices",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
                     }
-                },
-                "page_number": {
-                    "type": "integer"
-                },
-                "per_page": {
-                    "type": "integer"
-                },
-                "total_item": {
-                    "type": "integer"
-                },
-                "total_page": {
-                    "type": "integer"
                 }
             }
         },
-        "handler.SigninRequest": {
-            "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
+        "/notification/token": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The push notification about the appointments of the doctor is sent to this device until the doctor signs out",
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Save doctor device notification token",
+                "parameters": [
+                    {
+                        "description": "Notification token",
+                        "name": "SetNotificationTokenRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetNotificationTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/{paymentID}/refund": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Get list of the refunds of the payment from oldest to latest",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the payment",
+                        "name": "paymentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of the refunds",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/datastore.Refund"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payment ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Invoice of the payment not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The amount can be less than the remaining amount of the payment for partial refund",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Refund the payment of the doctor's appointment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the payment",
+                        "name": "paymentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount in baht and reason of the refund",
+                        "name": "RefundPaymentRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefundPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created refund with the updated payment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefundPaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Refund amount exceeds the remaining amount of the payment",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Invoice of the payment not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Get weekly slots, exceptions and leave days of the doctor",
+                "responses": {
+                    "200": {
+                        "description": "Schedule of the doctor",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.DoctorSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/exception": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "An available exception adds extra working time, otherwise the time range is blocked",
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Add exception to the weekly schedule on a specific date",
+                "parameters": [
+                    {
+                        "description": "Date, time and availability of the exception",
+                        "name": "ScheduleExceptionRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleExceptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created exception",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.ScheduleException"
+                        }
+                    },
+                    "400": {
+                        "description": "Start time and end time must be in HH:MM format and start time must be before end time",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/exception/{exceptionID}": {
+            "put": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Update exception of the weekly schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the exception",
+                        "name": "exceptionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Date, time and availability of the exception",
+                        "name": "ScheduleExceptionRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleExceptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated exception",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.ScheduleException"
+                        }
+                    },
+                    "400": {
+                        "description": "Start time and end time must be in HH:MM format and start time must be before end time",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The doctor doesn't own the exception",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule exception not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Delete exception of the weekly schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the exception",
+                        "name": "exceptionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exception is deleted"
+                    },
+                    "400": {
+                        "description": "Invalid schedule ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The doctor doesn't own the exception",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule exception not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/free-slot": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "Free time slots are the working time from the schedule that isn't occupied by any scheduled appointment. The date range is inclusive.",
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Get free time slots of the doctor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "2022-10-16",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2022-10-10",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of free time slots",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/datastore.TimeRange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Start date must not be after end date and the range must not exceed 31 days",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/leave": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Add leave day",
+                "parameters": [
+                    {
+                        "description": "Date and reason of the leave",
+                        "name": "LeaveDayRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LeaveDayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created leave day",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.LeaveDay"
+                        }
+                    },
+                    "400": {
+                        "description": "Date must be in YYYY-MM-DD format",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/leave/{leaveID}": {
+            "put": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Update leave day",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the leave day",
+                        "name": "leaveID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Date and reason of the leave",
+                        "name": "LeaveDayRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LeaveDayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated leave day",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.LeaveDay"
+                        }
+                    },
+                    "400": {
+                        "description": "Date must be in YYYY-MM-DD format",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The doctor doesn't own the leave day",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Leave day not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Delete leave day",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the leave day",
+                        "name": "leaveID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Leave day is deleted"
+                    },
+                    "400": {
+                        "description": "Invalid schedule ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The doctor doesn't own the leave day",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Leave day not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/slot": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Add weekly recurring working time slot",
+                "parameters": [
+                    {
+                        "description": "Weekday (0 is Sunday) and time of the slot",
+                        "name": "ScheduleSlotRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleSlotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created slot",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.ScheduleSlot"
+                        }
+                    },
+                    "400": {
+                        "description": "Start time and end time must be in HH:MM format and start time must be before end time",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/slot/{slotID}": {
+            "put": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Update weekly recurring working time slot",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the slot",
+                        "name": "slotID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Weekday (0 is Sunday) and time of the slot",
+                        "name": "ScheduleSlotRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleSlotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated slot",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.ScheduleSlot"
+                        }
+                    },
+                    "400": {
+                        "description": "Start time and end time must be in HH:MM format and start time must be before end time",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The doctor doesn't own the slot",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule slot not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Delete weekly recurring working time slot",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the slot",
+                        "name": "slotID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Slot is deleted"
+                    },
+                    "400": {
+                        "description": "Invalid schedule ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The doctor doesn't own the slot",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule slot not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "datastore.CreditCard": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry_month": {
+                    "type": "integer"
+                },
+                "expiry_year": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "last_4_digits": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "datastore.DoctorSchedule": {
+            "type": "object",
+            "properties": {
+                "exceptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/datastore.ScheduleException"
+                    }
+                },
+                "leave_days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/datastore.LeaveDay"
+                    }
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/datastore.ScheduleSlot"
+                    }
+                }
+            }
+        },
+        "datastore.LeaveDay": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "datastore.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credit_card": {
+                    "$ref": "#/definitions/datastore.CreditCard"
+                },
+                "credit_card_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "refunded_amount": {
+                    "description": "RefundedAmount is the total amount of the refunds of the payment",
+                    "type": "number"
+                },
+                "split_id": {
+                    "description": "SplitID groups the parts of the split payment, which pays the invoice with several credit cards",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "handler.SigninResponse": {
+        "datastore.Refund": {
             "type": "object",
             "properties": {
-                "token": {
+                "amount": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "hospital.AppointmentOverview": {
+        "datastore.ScheduleException": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "datastore.ScheduleSlot": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "weekday": {
+                    "type": "integer"
+                }
+            }
+        },
+        "datastore.TimeRange": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CompleteAppointmentRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "CANCELLED",
+                        "COMPLETED"
+                    ]
+                }
+            }
+        },
+        "handler.GetDoctorAppointmentDetailResponse": {
             "type": "object",
             "properties": {
                 "detail": {
@@ -472,8 +1720,14 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "next_appointment": {
+                    "type": "string"
+                },
                 "patient": {
-                    "$ref": "#/definitions/hospital.PatientOverview"
+                    "$ref": "#/definitions/hospital.DoctorAppointmentPatient"
+                },
+                "patient_presence": {
+                    "$ref": "#/definitions/presence.State"
                 },
                 "start_date_time": {
                     "type": "string"
@@ -483,7 +1737,190 @@ const docTemplate = `{
                 }
             }
         },
-        "hospital.DoctorAppointment": {
+        "handler.InitAppointmentRoomResponse": {
+            "type": "object",
+            "properties": {
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LeaveDayRequest": {
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2022-10-10"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ListAppointmentsResponse": {
+            "type": "object",
+            "properties": {
+                "appointments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital.AppointmentOverview"
+                    }
+                },
+                "page_number": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total_item": {
+                    "type": "integer"
+                },
+                "total_page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RefundPaymentRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RefundPaymentResponse": {
+            "type": "object",
+            "properties": {
+                "payment": {
+                    "$ref": "#/definitions/datastore.Payment"
+                },
+                "refund": {
+                    "$ref": "#/definitions/datastore.Refund"
+                }
+            }
+        },
+        "handler.ScheduleExceptionRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "end_time",
+                "start_time"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2022-10-10"
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "12:00"
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "09:00"
+                }
+            }
+        },
+        "handler.ScheduleSlotRequest": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time",
+                "weekday"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "12:00"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "09:00"
+                },
+                "weekday": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0,
+                    "example": 1
+                }
+            }
+        },
+        "handler.SetNotificationTokenRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SigninRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SigninResponse": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "description": "RefreshToken is used once to get the new token and refresh token of the session",
+                    "type": "string"
+                },
+                "refresh_token_expired_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.WaitingPatient": {
+            "type": "object",
+            "properties": {
+                "appointment": {
+                    "$ref": "#/definitions/hospital.AppointmentOverview"
+                },
+                "presence": {
+                    "$ref": "#/definitions/presence.State"
+                }
+            }
+        },
+        "hospital.AppointmentOverview": {
             "type": "object",
             "properties": {
                 "detail": {
@@ -498,11 +1935,8 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
-                "next_appointment": {
-                    "type": "string"
-                },
                 "patient": {
-                    "$ref": "#/definitions/hospital.DoctorAppointmentPatient"
+                    "$ref": "#/definitions/hospital.PatientOverview"
                 },
                 "start_date_time": {
                     "type": "string"
@@ -569,6 +2003,21 @@ const docTemplate = `{
                 }
             }
         },
+        "presence.State": {
+            "type": "object",
+            "properties": {
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "WAITING",
+                        "ABSENT"
+                    ]
+                }
+            }
+        },
         "server.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -8,9 +8,11 @@ import (
 	"github.com/synthia-telemed/backend-api/pkg/cache"
 	"github.com/synthia-telemed/backend-api/pkg/clock"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/id"
 	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
 	"github.com/synthia-telemed/backend-api/pkg/server"
 	"go.uber.org/zap"
 	"math"
@@ -31,45 +33,61 @@ var (
 	ErrDoctorNotInRoom             = server.NewErrorResponse("You're not currently in any room")
 )
 
+const (
+	// The waiting patients are searched among the appointments that the doctor can join soon or is able to join
+	waitingRoomLookBehind = time.Hour * 3
+	waitingRoomLookAhead  = time.Hour
+	waitingRoomMaxItems   = 100
+)
+
 type AppointmentHandler struct {
 	appointmentDataStore  datastore.AppointmentDataStore
 	patientDataStore      datastore.PatientDataStore
-	doctorDataStore       datastore.DoctorDataStore
 	notificationDataStore datastore.NotificationDataStore
+	roomClosureDataStore  datastore.RoomClosureDataStore
+	autopayDataStore      datastore.AutopayDataStore
 	hospitalClient        hospital.SystemClient
 	cacheClient           cache.Client
 	clock                 clock.Clock
 	idGenerator           id.Generator
 	logger                *zap.SugaredLogger
 	notificationClient    notification.Client
-	server.GinHandler
+	presenceTracker       presence.Tracker
+	eventBroker           event.Broker
+	roomTTL               time.Duration
+	DoctorGinHandler
 }
 
-func NewAppointmentHandler(ads datastore.AppointmentDataStore, pds datastore.PatientDataStore, dds datastore.DoctorDataStore, nds datastore.NotificationDataStore, hos hospital.SystemClient, cache cache.Client, clock clock.Clock, id id.Generator, noti notification.Client, logger *zap.SugaredLogger) *AppointmentHandler {
+func NewAppointmentHandler(ads datastore.AppointmentDataStore, pds datastore.PatientDataStore, dds datastore.DoctorDataStore, nds datastore.NotificationDataStore, rcds datastore.RoomClosureDataStore, aps datastore.AutopayDataStore, hos hospital.SystemClient, cache cache.Client, clock clock.Clock, id id.Generator, noti notification.Client, presenceTracker presence.Tracker, eventBroker event.Broker, roomTTL time.Duration, logger *zap.SugaredLogger) *AppointmentHandler {
 	return &AppointmentHandler{
 		appointmentDataStore:  ads,
 		patientDataStore:      pds,
-		doctorDataStore:       dds,
 		notificationDataStore: nds,
+		roomClosureDataStore:  rcds,
+		autopayDataStore:      aps,
 		hospitalClient:        hos,
 		cacheClient:           cache,
 		clock:                 clock,
 		idGenerator:           id,
 		logger:                logger,
 		notificationClient:    noti,
-		GinHandler: server.GinHandler{
-			Logger: logger,
-		},
+		presenceTracker:       presenceTracker,
+		eventBroker:           eventBroker,
+		roomTTL:               roomTTL,
+		DoctorGinHandler:      NewDoctorGinHandler(dds, logger),
 	}
 }
 
 func (h AppointmentHandler) Register(r *gin.RouterGroup) {
 	g := r.Group("/appointment", h.ParseUserID, h.ParseDoctor)
 	g.GET("", h.ListAppointments)
+	g.GET("/waiting", h.ListWaitingPatients)
 	g.GET("/:appointmentID", h.AuthorizedDoctorToAppointment, h.GetDoctorAppointmentDetail)
 	g.POST("/:appointmentID", h.AuthorizedDoctorToAppointment, h.CanJoinAppointment, h.InitAppointmentRoom, h.SendAppointmentPushNotification)
 	g.GET("/:appointmentID/can-join", h.AuthorizedDoctorToAppointment, h.CanJoinAppointment)
 	g.POST("/complete", h.CompleteAppointment)
+	g.POST("/heartbeat", h.CurrentRoom, h.HeartbeatRoom)
+	g.POST("/leave", h.CurrentRoom, h.LeaveRoom)
 }
 
 type InitAppointmentRoomResponse struct {
@@ -131,11 +149,67 @@ func (h AppointmentHandler) ListAppointments(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+type WaitingPatient struct {
+	Appointment *hospital.AppointmentOverview `json:"appointment"`
+	Presence    *presence.State               `json:"presence"`
+}
+
+// ListWaitingPatients godoc
+// @Summary      Get list of the patients who are waiting in the waiting room
+// @Description  Only the scheduled appointments that start within an hour or started less than 3 hours ago are considered
+// @Tags         Appointment
+// @Success      200  {array}	WaitingPatient "List of appointment overview with the presence of the patient"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/waiting [get]
+func (h AppointmentHandler) ListWaitingPatients(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	now := h.clock.Now()
+	startAfter := now.Add(-waitingRoomLookBehind)
+	startBefore := now.Add(waitingRoomLookAhead)
+	ctx := context.Background()
+	appointments, err := h.hospitalClient.ListAppointmentsWithFilters(ctx, &hospital.ListAppointmentsFilters{
+		DoctorID:    &doctor.RefID,
+		Status:      hospital.AppointmentStatusScheduled,
+		StartAfter:  &startAfter,
+		StartBefore: &startBefore,
+	}, waitingRoomMaxItems, 0)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.ListAppointmentsWithFilters error")
+		return
+	}
+	appointmentIDs := make([]string, len(appointments))
+	for i, a := range appointments {
+		appointmentIDs[i] = a.Id
+	}
+	states, err := h.presenceTracker.GetMany(ctx, appointmentIDs)
+	if err != nil {
+		h.InternalServerError(c, err, "h.presenceTracker.GetMany error")
+		return
+	}
+	waitingPatients := make([]*WaitingPatient, 0)
+	for i, state := range states {
+		if state.Status == presence.StatusWaiting {
+			waitingPatients = append(waitingPatients, &WaitingPatient{Appointment: appointments[i], Presence: state})
+		}
+	}
+	c.JSON(http.StatusOK, waitingPatients)
+}
+
+type GetDoctorAppointmentDetailResponse struct {
+	*hospital.DoctorAppointment
+	PatientPresence *presence.State `json:"patient_presence"`
+}
+
 // GetDoctorAppointmentDetail godoc
 // @Summary      Get appointment detail
 // @Tags         Appointment
 // @Param  		 appointmentID 	path	 integer	true "ID of the appointment"
-// @Success      200  {object}  hospital.DoctorAppointment  "Appointment detail"
+// @Success      200  {object}  GetDoctorAppointmentDetailResponse  "Appointment detail with the live presence of the patient"
 // @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
 // @Failure      400  {object}  server.ErrorResponse   "Appointment ID is missing"
 // @Failure      400  {object}  server.ErrorResponse   "Invalid appointment ID"
@@ -149,7 +223,15 @@ func (h AppointmentHandler) ListAppointments(c *gin.Context) {
 func (h AppointmentHandler) GetDoctorAppointmentDetail(c *gin.Context) {
 	rawApp, _ := c.Get("Appointment")
 	appointment := rawApp.(*hospital.DoctorAppointment)
-	c.JSON(http.StatusOK, appointment)
+	state, err := h.presenceTracker.Get(context.Background(), appointment.Id)
+	if err != nil {
+		h.InternalServerError(c, err, "h.presenceTracker.Get error")
+		return
+	}
+	c.JSON(http.StatusOK, &GetDoctorAppointmentDetailResponse{
+		DoctorAppointment: appointment,
+		PatientPresence:   state,
+	})
 }
 
 // CanJoinAppointment godoc
@@ -234,6 +316,10 @@ func (h AppointmentHandler) InitAppointmentRoom(c *gin.Context) {
 	rawRoomID, exist := c.Get("RoomID")
 	if exist {
 		roomID := rawRoomID.(string)
+		if err := h.refreshRoom(context.Background(), doctor.ID, appointment.Id, roomID); err != nil {
+			h.InternalServerError(c, err, "h.refreshRoom error")
+			return
+		}
 		c.AbortWithStatusJSON(http.StatusCreated, &InitAppointmentRoomResponse{RoomID: roomID})
 		return
 	}
@@ -268,8 +354,13 @@ func (h AppointmentHandler) InitAppointmentRoom(c *gin.Context) {
 		h.InternalServerError(c, err, "h.cacheClient.HashSet error")
 		return
 	}
+	if err := h.refreshRoom(ctx, doctor.ID, appointment.Id, roomID); err != nil {
+		h.InternalServerError(c, err, "h.refreshRoom error")
+		return
+	}
 
 	c.Set("Patient", patient)
+	c.Set("RoomID", roomID)
 	c.JSON(http.StatusCreated, &InitAppointmentRoomResponse{RoomID: roomID})
 }
 
@@ -278,6 +369,13 @@ func (h AppointmentHandler) SendAppointmentPushNotification(c *gin.Context) {
 	patient, _ := rawPatient.(*datastore.Patient)
 	rawApp, _ := c.Get("Appointment")
 	appointment := rawApp.(*hospital.DoctorAppointment)
+	roomID := c.GetString("RoomID")
+
+	// Publish to the patient's live streams
+	roomOpened := &event.RoomOpened{AppointmentID: appointment.Id, RoomID: roomID}
+	if err := h.eventBroker.Publish(context.Background(), patient.ID, event.RoomOpenedType, roomOpened); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.eventBroker.Publish error")
+	}
 
 	// Push notification to patient
 	notiParam := notification.SendParams{
@@ -298,6 +396,7 @@ type CompleteAppointmentRequest struct {
 
 // CompleteAppointment godoc
 // @Summary      Finish the appointment and close the room
+// @Description  The invoice of the completed appointment is charged automatically once it's issued if the patient opts in to autopay
 // @Tags         Appointment
 // @Param 	  	 CompleteAppointmentRequest body CompleteAppointmentRequest true "Status of the appointment"
 // @Success      201  "Appointment status is set"
@@ -355,35 +454,143 @@ func (h AppointmentHandler) CompleteAppointment(c *gin.Context) {
 			RefID:       appointmentID,
 			Duration:    float64(duration),
 			StartedTime: startedTime.UTC(),
+			Outcome:     datastore.CompletedAppointmentOutcome,
 		}
 		if err := h.appointmentDataStore.Create(&appointment); err != nil {
 			h.InternalServerError(c, err, "h.appointmentDataStore.Create error")
 			return
 		}
 	}
-	if err := h.cacheClient.Delete(ctx, cache.CurrentDoctorAppointmentIDKey(doctor.ID), cache.AppointmentRoomIDKey(appointmentID), cache.RoomInfoKey(roomID)); err != nil {
-		h.InternalServerError(c, err, "h.cacheClient.Delete error")
+	if err := h.closeRoom(ctx, doctor.ID, appointmentID, roomID); err != nil {
+		h.InternalServerError(c, err, "h.closeRoom error")
 		return
 	}
 	if err := h.hospitalClient.SetAppointmentStatus(ctx, int(appIDInt), req.Status); err != nil {
 		h.InternalServerError(c, err, "h.hospitalClient.CompleteAppointment error")
 		return
 	}
+	if req.Status == hospital.SettableAppointmentStatusCompleted {
+		// The appointment is already completed, so the patient pays the invoice manually if the autopay can't be requested
+		if err := h.autopayDataStore.Create(appointmentID); err != nil {
+			h.InternalServerErrorWithoutAborting(c, err, "h.autopayDataStore.Create error")
+		}
+	}
 	c.AbortWithStatus(http.StatusCreated)
 }
 
-func (h AppointmentHandler) ParseDoctor(c *gin.Context) {
-	doctorID := h.GetUserID(c)
-	doctor, err := h.doctorDataStore.FindByID(doctorID)
+// CurrentRoom sets the appointment ID and room ID that the doctor is currently in to the context
+func (h AppointmentHandler) CurrentRoom(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+
+	ctx := context.Background()
+	appointmentID, err := h.cacheClient.Get(ctx, cache.CurrentDoctorAppointmentIDKey(doctor.ID), false)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Get error")
+		return
+	}
+	if appointmentID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotInRoom)
+		return
+	}
+	roomID, err := h.cacheClient.Get(ctx, cache.AppointmentRoomIDKey(appointmentID), false)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Get error")
+		return
+	}
+	c.Set("AppointmentID", appointmentID)
+	c.Set("RoomID", roomID)
+}
+
+// HeartbeatRoom godoc
+// @Summary      Keep the room that the doctor is currently in alive
+// @Description  The room is closed by the system when no heartbeat is received for a while
+// @Tags         Appointment
+// @Success      200  "Room is kept alive"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor isn't currently in any room"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/heartbeat [post]
+func (h AppointmentHandler) HeartbeatRoom(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	appointmentID := c.GetString("AppointmentID")
+	roomID := c.GetString("RoomID")
+	if roomID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotInRoom)
+		return
+	}
+
+	if err := h.refreshRoom(context.Background(), doctor.ID, appointmentID, roomID); err != nil {
+		h.InternalServerError(c, err, "h.refreshRoom error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
+
+// LeaveRoom godoc
+// @Summary      Force-leave the room that the doctor is currently in without finishing the appointment
+// @Description  The appointment stays scheduled, so the doctor can start a new room for it later
+// @Tags         Appointment
+// @Success      201  "Room is closed"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor isn't currently in any room"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/leave [post]
+func (h AppointmentHandler) LeaveRoom(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	appointmentID := c.GetString("AppointmentID")
+	roomID := c.GetString("RoomID")
+
+	ctx := context.Background()
+	score, found, err := h.cacheClient.SortedSetScore(ctx, cache.ActiveRoomsKey, roomID)
 	if err != nil {
-		h.InternalServerError(c, err, "h.doctorDataStore.FindByID error")
+		h.InternalServerError(c, err, "h.cacheClient.SortedSetScore error")
+		return
+	}
+	if err := h.closeRoom(ctx, doctor.ID, appointmentID, roomID); err != nil {
+		h.InternalServerError(c, err, "h.closeRoom error")
 		return
 	}
-	if doctor == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotFound)
+	closure := &datastore.RoomClosure{
+		RoomID:        roomID,
+		AppointmentID: appointmentID,
+		DoctorID:      doctor.ID,
+		Reason:        datastore.ForceLeftRoomClosureReason,
+	}
+	if found {
+		lastHeartbeat := time.Unix(int64(score), 0)
+		closure.LastHeartbeat = &lastHeartbeat
+	}
+	if err := h.roomClosureDataStore.Create(closure); err != nil {
+		h.InternalServerError(c, err, "h.roomClosureDataStore.Create error")
 		return
 	}
-	c.Set("Doctor", doctor)
+	c.AbortWithStatus(http.StatusCreated)
+}
+
+// refreshRoom extends the TTL of the room's keys and marks the room as active now
+func (h AppointmentHandler) refreshRoom(ctx context.Context, doctorID uint, appointmentID, roomID string) error {
+	keys := []string{cache.CurrentDoctorAppointmentIDKey(doctorID), cache.AppointmentRoomIDKey(appointmentID), cache.RoomInfoKey(roomID)}
+	if err := h.cacheClient.Expire(ctx, h.roomTTL, keys...); err != nil {
+		return err
+	}
+	return h.cacheClient.SortedSetAdd(ctx, cache.ActiveRoomsKey, roomID, float64(h.clock.Now().Unix()))
+}
+
+func (h AppointmentHandler) closeRoom(ctx context.Context, doctorID uint, appointmentID, roomID string) error {
+	if err := h.cacheClient.Delete(ctx, cache.CurrentDoctorAppointmentIDKey(doctorID), cache.AppointmentRoomIDKey(appointmentID), cache.RoomInfoKey(roomID)); err != nil {
+		return err
+	}
+	_, err := h.cacheClient.SortedSetRemove(ctx, cache.ActiveRoomsKey, roomID)
+	return err
 }
 
 func (h AppointmentHandler) AuthorizedDoctorToAppointment(c *gin.Context) {
@@ -12,15 +12,19 @@ import (
 	"github.com/synthia-telemed/backend-api/cmd/doctor-api/handler"
 	"github.com/synthia-telemed/backend-api/pkg/cache"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
 	testhelper "github.com/synthia-telemed/backend-api/test/helper"
 	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
 	"github.com/synthia-telemed/backend-api/test/mock_clock"
 	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_event"
 	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
 	"github.com/synthia-telemed/backend-api/test/mock_id"
 	"github.com/synthia-telemed/backend-api/test/mock_notification"
+	"github.com/synthia-telemed/backend-api/test/mock_presence"
 	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
@@ -41,14 +45,19 @@ var _ = Describe("Doctor Appointment Handler", func() {
 		mockAppointmentDataStore  *mock_datastore.MockAppointmentDataStore
 		mockPatientDataStore      *mock_datastore.MockPatientDataStore
 		mockNotificationDataStore *mock_datastore.MockNotificationDataStore
+		mockRoomClosureDataStore  *mock_datastore.MockRoomClosureDataStore
+		mockAutopayDataStore      *mock_datastore.MockAutopayDataStore
 		mockHospitalSysClient     *mock_hospital_client.MockSystemClient
 		mockCacheClient           *mock_cache_client.MockClient
 		mockClock                 *mock_clock.MockClock
 		mockIDGenerator           *mock_id.MockGenerator
 		mockNotificationClient    *mock_notification.MockClient
+		mockPresenceTracker       *mock_presence.MockTracker
+		mockEventBroker           *mock_event.MockBroker
 		doctor                    *datastore.Doctor
 		appointment               *hospital.DoctorAppointment
 		appointmentID             int
+		roomTTL                   time.Duration
 	)
 
 	BeforeEach(func() {
@@ -58,11 +67,16 @@ var _ = Describe("Doctor Appointment Handler", func() {
 		mockPatientDataStore = mock_datastore.NewMockPatientDataStore(mockCtrl)
 		mockAppointmentDataStore = mock_datastore.NewMockAppointmentDataStore(mockCtrl)
 		mockNotificationDataStore = mock_datastore.NewMockNotificationDataStore(mockCtrl)
+		mockRoomClosureDataStore = mock_datastore.NewMockRoomClosureDataStore(mockCtrl)
+		mockAutopayDataStore = mock_datastore.NewMockAutopayDataStore(mockCtrl)
 		mockClock = mock_clock.NewMockClock(mockCtrl)
 		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
 		mockIDGenerator = mock_id.NewMockGenerator(mockCtrl)
 		mockNotificationClient = mock_notification.NewMockClient(mockCtrl)
-		h = handler.NewAppointmentHandler(mockAppointmentDataStore, mockPatientDataStore, mockDoctorDataStore, mockNotificationDataStore, mockHospitalSysClient, mockCacheClient, mockClock, mockIDGenerator, mockNotificationClient, zap.NewNop().Sugar())
+		mockPresenceTracker = mock_presence.NewMockTracker(mockCtrl)
+		mockEventBroker = mock_event.NewMockBroker(mockCtrl)
+		roomTTL = time.Minute * 10
+		h = handler.NewAppointmentHandler(mockAppointmentDataStore, mockPatientDataStore, mockDoctorDataStore, mockNotificationDataStore, mockRoomClosureDataStore, mockAutopayDataStore, mockHospitalSysClient, mockCacheClient, mockClock, mockIDGenerator, mockNotificationClient, mockPresenceTracker, mockEventBroker, roomTTL, zap.NewNop().Sugar())
 		doctor = testhelper.GenerateDoctor()
 		appointment, appointmentID = testhelper.GenerateDoctorAppointment("", doctor.RefID, hospital.AppointmentStatusScheduled)
 	})
@@ -75,6 +89,13 @@ var _ = Describe("Doctor Appointment Handler", func() {
 		mockCtrl.Finish()
 	})
 
+	expectRefreshRoom := func(roomID string, err error) {
+		now := time.Now()
+		mockCacheClient.EXPECT().Expire(gomock.Any(), roomTTL, cache.CurrentDoctorAppointmentIDKey(doctor.ID), cache.AppointmentRoomIDKey(appointment.Id), cache.RoomInfoKey(roomID)).Return(nil).Times(1)
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		mockCacheClient.EXPECT().SortedSetAdd(gomock.Any(), cache.ActiveRoomsKey, roomID, float64(now.Unix())).Return(err).Times(1)
+	}
+
 	Context("ParseDoctor", func() {
 		BeforeEach(func() {
 			handlerFunc = h.ParseDoctor
@@ -301,6 +322,7 @@ var _ = Describe("Doctor Appointment Handler", func() {
 			BeforeEach(func() {
 				roomID = uuid.NewString()
 				c.Set("RoomID", roomID)
+				expectRefreshRoom(roomID, nil)
 			})
 			It("should return the roomID", func() {
 				Expect(rec.Code).To(Equal(http.StatusCreated))
@@ -368,6 +390,17 @@ var _ = Describe("Doctor Appointment Handler", func() {
 					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
 				})
 			})
+			When("refresh room error", func() {
+				BeforeEach(func() {
+					mockCacheClient.EXPECT().MultipleSet(gomock.Any(), kv).Return(nil).Times(1)
+					mockPatientDataStore.EXPECT().FindByRefID(appointment.Patient.ID).Return(patient, nil).Times(1)
+					mockCacheClient.EXPECT().HashSet(gomock.Any(), cache.RoomInfoKey(roomID), gomock.Any()).Return(nil).Times(1)
+					expectRefreshRoom(roomID, testhelper.MockError)
+				})
+				It("should return 500", func() {
+					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+				})
+			})
 			When("successfully set room info to cache", func() {
 				BeforeEach(func() {
 					mockCacheClient.EXPECT().MultipleSet(gomock.Any(), kv).Return(nil).Times(1)
@@ -378,6 +411,7 @@ var _ = Describe("Doctor Appointment Handler", func() {
 						"AppointmentID": appointment.Id,
 					}
 					mockCacheClient.EXPECT().HashSet(gomock.Any(), cache.RoomInfoKey(roomID), info).Return(nil).Times(1)
+					expectRefreshRoom(roomID, nil)
 				})
 				It("should return 201 with room ID", func() {
 					Expect(rec.Code).To(Equal(http.StatusCreated))
@@ -462,6 +496,7 @@ var _ = Describe("Doctor Appointment Handler", func() {
 				mockCacheClient.EXPECT().Get(gomock.Any(), getCurrentAppointmentKey, false).Return(appointment.Id, nil).Times(1)
 				mockCacheClient.EXPECT().Get(gomock.Any(), getRoomIDKey, false).Return(roomID, nil).Times(1)
 				mockCacheClient.EXPECT().Delete(gomock.Any(), gomock.InAnyOrder([]string{getRoomInfoKey, getRoomIDKey, getCurrentAppointmentKey})).Return(nil).Times(1)
+				mockCacheClient.EXPECT().SortedSetRemove(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
 				mockHospitalSysClient.EXPECT().SetAppointmentStatus(gomock.Any(), appointmentID, req.Status).Return(nil).Times(1)
 			})
 			It("should delete cache keys, set appointment status to cancelled, and return 201", func() {
@@ -510,6 +545,7 @@ var _ = Describe("Doctor Appointment Handler", func() {
 					RefID:       appointment.Id,
 					Duration:    duration.Seconds(),
 					StartedTime: startedTime.UTC(),
+					Outcome:     datastore.CompletedAppointmentOutcome,
 				}
 			})
 			When("save appointment to db error", func() {
@@ -533,6 +569,7 @@ var _ = Describe("Doctor Appointment Handler", func() {
 				BeforeEach(func() {
 					mockAppointmentDataStore.EXPECT().Create(dbAppointment).Return(nil).Times(1)
 					mockCacheClient.EXPECT().Delete(gomock.Any(), gomock.InAnyOrder([]string{getRoomInfoKey, getRoomIDKey, getCurrentAppointmentKey})).Return(nil).Times(1)
+					mockCacheClient.EXPECT().SortedSetRemove(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
 					mockHospitalSysClient.EXPECT().SetAppointmentStatus(gomock.Any(), appointmentID, req.Status).Return(testhelper.MockError).Times(1)
 				})
 				It("should return 500", func() {
@@ -543,11 +580,177 @@ var _ = Describe("Doctor Appointment Handler", func() {
 				BeforeEach(func() {
 					mockAppointmentDataStore.EXPECT().Create(dbAppointment).Return(nil).Times(1)
 					mockCacheClient.EXPECT().Delete(gomock.Any(), gomock.InAnyOrder([]string{getRoomInfoKey, getRoomIDKey, getCurrentAppointmentKey})).Return(nil).Times(1)
+					mockCacheClient.EXPECT().SortedSetRemove(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
 					mockHospitalSysClient.EXPECT().SetAppointmentStatus(gomock.Any(), appointmentID, req.Status).Return(nil).Times(1)
 				})
-				It("should return 201", func() {
-					Expect(rec.Code).To(Equal(http.StatusCreated))
+				When("request autopay error", func() {
+					BeforeEach(func() {
+						mockAutopayDataStore.EXPECT().Create(appointment.Id).Return(testhelper.MockError).Times(1)
+					})
+					It("should still return 201", func() {
+						Expect(rec.Code).To(Equal(http.StatusCreated))
+					})
 				})
+				When("autopay is requested", func() {
+					BeforeEach(func() {
+						mockAutopayDataStore.EXPECT().Create(appointment.Id).Return(nil).Times(1)
+					})
+					It("should return 201", func() {
+						Expect(rec.Code).To(Equal(http.StatusCreated))
+					})
+				})
+			})
+		})
+	})
+
+	Context("CurrentRoom", func() {
+		BeforeEach(func() {
+			handlerFunc = h.CurrentRoom
+			c.Set("Doctor", doctor)
+		})
+		When("get current appointment ID from cache error", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), false).Return("", testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("doctor is not in any room", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), false).Return("", nil).Times(1)
+			})
+			It("should return 400 with error", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDoctorNotInRoom)
+			})
+		})
+		When("get room ID from cache error", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), false).Return(appointment.Id, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.AppointmentRoomIDKey(appointment.Id), false).Return("", testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("doctor is in a room", func() {
+			var roomID string
+			BeforeEach(func() {
+				roomID = uuid.NewString()
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), false).Return(appointment.Id, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.AppointmentRoomIDKey(appointment.Id), false).Return(roomID, nil).Times(1)
+			})
+			It("should set appointment ID and room ID to context", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+				Expect(c.GetString("AppointmentID")).To(Equal(appointment.Id))
+				Expect(c.GetString("RoomID")).To(Equal(roomID))
+			})
+		})
+	})
+
+	Context("HeartbeatRoom", func() {
+		var roomID string
+		BeforeEach(func() {
+			handlerFunc = h.HeartbeatRoom
+			roomID = uuid.NewString()
+			c.Set("Doctor", doctor)
+			c.Set("AppointmentID", appointment.Id)
+			c.Set("RoomID", roomID)
+		})
+		When("room ID is expired", func() {
+			BeforeEach(func() {
+				c.Set("RoomID", "")
+			})
+			It("should return 400 with error", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDoctorNotInRoom)
+			})
+		})
+		When("refresh room error", func() {
+			BeforeEach(func() {
+				expectRefreshRoom(roomID, testhelper.MockError)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				expectRefreshRoom(roomID, nil)
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("LeaveRoom", func() {
+		var (
+			roomID        string
+			lastHeartbeat time.Time
+		)
+		BeforeEach(func() {
+			handlerFunc = h.LeaveRoom
+			roomID = uuid.NewString()
+			lastHeartbeat = time.Unix(time.Now().Unix(), 0)
+			c.Set("Doctor", doctor)
+			c.Set("AppointmentID", appointment.Id)
+			c.Set("RoomID", roomID)
+		})
+		expectLastHeartbeat := func(found bool) {
+			mockCacheClient.EXPECT().SortedSetScore(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(float64(lastHeartbeat.Unix()), found, nil).Times(1)
+		}
+		When("get last heartbeat of the room error", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().SortedSetScore(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(0.0, false, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("delete room from cache error", func() {
+			BeforeEach(func() {
+				expectLastHeartbeat(true)
+				mockCacheClient.EXPECT().Delete(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), cache.AppointmentRoomIDKey(appointment.Id), cache.RoomInfoKey(roomID)).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("record room closure error", func() {
+			BeforeEach(func() {
+				expectLastHeartbeat(true)
+				mockCacheClient.EXPECT().Delete(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), cache.AppointmentRoomIDKey(appointment.Id), cache.RoomInfoKey(roomID)).Return(nil).Times(1)
+				mockCacheClient.EXPECT().SortedSetRemove(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
+				mockRoomClosureDataStore.EXPECT().Create(gomock.Any()).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				expectLastHeartbeat(true)
+				mockCacheClient.EXPECT().Delete(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), cache.AppointmentRoomIDKey(appointment.Id), cache.RoomInfoKey(roomID)).Return(nil).Times(1)
+				mockCacheClient.EXPECT().SortedSetRemove(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
+				closure := &datastore.RoomClosure{RoomID: roomID, AppointmentID: appointment.Id, DoctorID: doctor.ID, Reason: datastore.ForceLeftRoomClosureReason, LastHeartbeat: &lastHeartbeat}
+				mockRoomClosureDataStore.EXPECT().Create(closure).Return(nil).Times(1)
+			})
+			It("should close the room with its last heartbeat and return 201", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+			})
+		})
+		When("the room has never been active", func() {
+			BeforeEach(func() {
+				expectLastHeartbeat(false)
+				mockCacheClient.EXPECT().Delete(gomock.Any(), cache.CurrentDoctorAppointmentIDKey(doctor.ID), cache.AppointmentRoomIDKey(appointment.Id), cache.RoomInfoKey(roomID)).Return(nil).Times(1)
+				mockCacheClient.EXPECT().SortedSetRemove(gomock.Any(), cache.ActiveRoomsKey, roomID).Return(0, nil).Times(1)
+				closure := &datastore.RoomClosure{RoomID: roomID, AppointmentID: appointment.Id, DoctorID: doctor.ID, Reason: datastore.ForceLeftRoomClosureReason}
+				mockRoomClosureDataStore.EXPECT().Create(closure).Return(nil).Times(1)
+			})
+			It("should close the room without last heartbeat and return 201", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
 			})
 		})
 	})
@@ -661,19 +864,106 @@ var _ = Describe("Doctor Appointment Handler", func() {
 		})
 	})
 
+	Context("ListWaitingPatients", func() {
+		var (
+			now          time.Time
+			appointments []*hospital.AppointmentOverview
+			filters      *hospital.ListAppointmentsFilters
+		)
+		BeforeEach(func() {
+			handlerFunc = h.ListWaitingPatients
+			c.Set("Doctor", doctor)
+			now = time.Now()
+			startAfter := now.Add(-time.Hour * 3)
+			startBefore := now.Add(time.Hour)
+			filters = &hospital.ListAppointmentsFilters{
+				DoctorID:    &doctor.RefID,
+				Status:      hospital.AppointmentStatusScheduled,
+				StartAfter:  &startAfter,
+				StartBefore: &startBefore,
+			}
+			appointments = testhelper.GenerateAppointmentOverviews(hospital.AppointmentStatusScheduled, 3)
+			mockClock.EXPECT().Now().Return(now).Times(1)
+		})
+		When("list appointments error", func() {
+			BeforeEach(func() {
+				mockHospitalSysClient.EXPECT().ListAppointmentsWithFilters(gomock.Any(), filters, gomock.Any(), 0).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("get presence error", func() {
+			BeforeEach(func() {
+				mockHospitalSysClient.EXPECT().ListAppointmentsWithFilters(gomock.Any(), filters, gomock.Any(), 0).Return(appointments, nil).Times(1)
+				mockPresenceTracker.EXPECT().GetMany(gomock.Any(), gomock.Len(3)).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("some patients are waiting", func() {
+			BeforeEach(func() {
+				ids := []string{appointments[0].Id, appointments[1].Id, appointments[2].Id}
+				states := []*presence.State{
+					{Status: presence.StatusAbsent},
+					{Status: presence.StatusWaiting, LastSeenAt: &now},
+					{Status: presence.StatusAbsent},
+				}
+				mockHospitalSysClient.EXPECT().ListAppointmentsWithFilters(gomock.Any(), filters, gomock.Any(), 0).Return(appointments, nil).Times(1)
+				mockPresenceTracker.EXPECT().GetMany(gomock.Any(), ids).Return(states, nil).Times(1)
+			})
+			It("should return 200 with only waiting patients", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res []handler.WaitingPatient
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res).To(HaveLen(1))
+				Expect(res[0].Appointment.Id).To(Equal(appointments[1].Id))
+				Expect(res[0].Presence.Status).To(Equal(presence.StatusWaiting))
+			})
+		})
+		When("no appointment is found", func() {
+			BeforeEach(func() {
+				mockHospitalSysClient.EXPECT().ListAppointmentsWithFilters(gomock.Any(), filters, gomock.Any(), 0).Return([]*hospital.AppointmentOverview{}, nil).Times(1)
+				mockPresenceTracker.EXPECT().GetMany(gomock.Any(), []string{}).Return([]*presence.State{}, nil).Times(1)
+			})
+			It("should return 200 with empty list", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Body.String()).To(Equal("[]"))
+			})
+		})
+	})
+
 	Context("GetDoctorAppointmentDetail", func() {
 		BeforeEach(func() {
 			handlerFunc = h.GetDoctorAppointmentDetail
 			c.Request = httptest.NewRequest("GET", "/", nil)
 			c.Set("Appointment", appointment)
 		})
-		It("should return appointment detail", func() {
-			Expect(rec.Code).To(Equal(http.StatusOK))
-			var res hospital.DoctorAppointment
-			Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
-			Expect(res.Id).To(Equal(appointment.Id))
-			Expect(res.Doctor.ID).To(Equal(appointment.Doctor.ID))
-			Expect(res.Patient.ID).To(Equal(appointment.Patient.ID))
+		When("get patient presence error", func() {
+			BeforeEach(func() {
+				mockPresenceTracker.EXPECT().Get(gomock.Any(), appointment.Id).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("get patient presence success", func() {
+			var lastSeen time.Time
+			BeforeEach(func() {
+				lastSeen = time.Now().UTC().Truncate(time.Second)
+				mockPresenceTracker.EXPECT().Get(gomock.Any(), appointment.Id).Return(&presence.State{Status: presence.StatusWaiting, LastSeenAt: &lastSeen}, nil).Times(1)
+			})
+			It("should return appointment detail with patient presence", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.GetDoctorAppointmentDetailResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Id).To(Equal(appointment.Id))
+				Expect(res.Doctor.ID).To(Equal(appointment.Doctor.ID))
+				Expect(res.Patient.ID).To(Equal(appointment.Patient.ID))
+				Expect(res.PatientPresence.Status).To(Equal(presence.StatusWaiting))
+				Expect(res.PatientPresence.LastSeenAt.Equal(lastSeen)).To(BeTrue())
+			})
 		})
 	})
 
@@ -682,14 +972,18 @@ var _ = Describe("Doctor Appointment Handler", func() {
 			patient    *datastore.Patient
 			notiParams notification.SendParams
 			data       map[string]string
+			roomOpened *event.RoomOpened
 		)
 		BeforeEach(func() {
 			handlerFunc = h.SendAppointmentPushNotification
 			patient = testhelper.GeneratePatient()
 			appointment.Patient.ID = patient.RefID
 			patient.NotificationToken = uuid.NewString()
+			roomID := uuid.NewString()
 			c.Set("Patient", patient)
 			c.Set("Appointment", appointment)
+			c.Set("RoomID", roomID)
+			roomOpened = &event.RoomOpened{AppointmentID: appointment.Id, RoomID: roomID}
 			notiParams = notification.SendParams{
 				ID:    fmt.Sprintf("%d", patient.ID),
 				Title: "Your doctor is ready",
@@ -698,8 +992,18 @@ var _ = Describe("Doctor Appointment Handler", func() {
 			data = map[string]string{"appointmentID": appointment.Id}
 		})
 
+		When("publishing room opened event error", func() {
+			BeforeEach(func() {
+				mockEventBroker.EXPECT().Publish(gomock.Any(), patient.ID, event.RoomOpenedType, roomOpened).Return(testhelper.MockError).Times(1)
+				mockNotificationClient.EXPECT().Send(gomock.Any(), notiParams, data).Return(nil).Times(1)
+			})
+			It("should still send push notification and return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
 		When("sending notification error", func() {
 			BeforeEach(func() {
+				mockEventBroker.EXPECT().Publish(gomock.Any(), patient.ID, event.RoomOpenedType, roomOpened).Return(nil).Times(1)
 				mockNotificationClient.EXPECT().Send(gomock.Any(), notiParams, data).Return(testhelper.MockError).Times(1)
 			})
 			It("should return 200", func() {
@@ -708,6 +1012,7 @@ var _ = Describe("Doctor Appointment Handler", func() {
 		})
 		When("no error sending notification", func() {
 			BeforeEach(func() {
+				mockEventBroker.EXPECT().Publish(gomock.Any(), patient.ID, event.RoomOpenedType, roomOpened).Return(nil).Times(1)
 				mockNotificationClient.EXPECT().Send(gomock.Any(), notiParams, data).Return(nil).Times(1)
 			})
 			It("should return 200", func() {
@@ -6,28 +6,30 @@ import (
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/server"
-	"github.com/synthia-telemed/backend-api/pkg/token"
+	"github.com/synthia-telemed/backend-api/pkg/session"
 	"go.uber.org/zap"
 	"net/http"
+	"time"
 )
 
 var (
-	ErrInvalidRequestBody = server.NewErrorResponse("Invalid request body")
-	ErrInvalidCredential  = server.NewErrorResponse("Invalid credential")
+	ErrInvalidRequestBody  = server.NewErrorResponse("Invalid request body")
+	ErrInvalidCredential   = server.NewErrorResponse("Invalid credential")
+	ErrInvalidRefreshToken = server.NewErrorResponse("Refresh token is invalid, expired or revoked")
 )
 
 type AuthHandler struct {
 	hospitalSysClient hospital.SystemClient
-	tokenService      token.Service
+	sessionManager    session.Manager
 	doctorDataStore   datastore.DoctorDataStore
 	logger            *zap.SugaredLogger
 	server.GinHandler
 }
 
-func NewAuthHandler(h hospital.SystemClient, t token.Service, ds datastore.DoctorDataStore, l *zap.SugaredLogger) *AuthHandler {
+func NewAuthHandler(h hospital.SystemClient, sm session.Manager, ds datastore.DoctorDataStore, l *zap.SugaredLogger) *AuthHandler {
 	return &AuthHandler{
 		hospitalSysClient: h,
-		tokenService:      t,
+		sessionManager:    sm,
 		doctorDataStore:   ds,
 		logger:            l,
 		GinHandler:        server.GinHandler{Logger: l},
@@ -37,6 +39,9 @@ func NewAuthHandler(h hospital.SystemClient, t token.Service, ds datastore.Docto
 func (h AuthHandler) Register(r *gin.RouterGroup) {
 	authGroup := r.Group("/auth")
 	authGroup.POST("/signin", h.Signin)
+	authGroup.POST("/refresh", h.RefreshToken)
+	authGroup.DELETE("/signout", h.ParseUserID, h.SignOut)
+	authGroup.DELETE("/signout/all", h.ParseUserID, h.SignOutAllDevices)
 }
 
 type SigninRequest struct {
@@ -45,14 +50,17 @@ type SigninRequest struct {
 }
 
 type SigninResponse struct {
-	Token string `json:"token"`
+	RefreshTokenExpiredAt time.Time `json:"refresh_token_expired_at"`
+	Token                 string    `json:"token"`
+	// RefreshToken is used once to get the new token and refresh token of the session
+	RefreshToken string `json:"refresh_token"`
 }
 
 // Signin godoc
 // @Summary      Signin doctor with credential
 // @Tags         Auth
 // @Param 	  	 SigninRequest body SigninRequest true "Username and password of the doctor"
-// @Success      201  {object}  SigninResponse 		   "Token is return when authentication is successes"
+// @Success      201  {object}  SigninResponse 		   "Token and refresh token of the new session are return when authentication is successes"
 // @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
 // @Failure      401  {object}  server.ErrorResponse   "Provided credential is not in the hospital system"
 // @Failure      500  {object}  server.ErrorResponse   "Internal server error"
@@ -86,10 +94,87 @@ func (h AuthHandler) Signin(c *gin.Context) {
 		return
 	}
 
-	jws, err := h.tokenService.GenerateToken(uint64(doctor.ID), "Doctor")
+	tokens, err := h.sessionManager.Create(doctor.ID, "Doctor", c.Request.UserAgent())
 	if err != nil {
-		h.InternalServerError(c, err, "h.tokenService.GenerateToken error")
+		h.InternalServerError(c, err, "h.sessionManager.Create error")
 		return
 	}
-	c.JSON(http.StatusCreated, SigninResponse{Token: jws})
+	c.JSON(http.StatusCreated, SigninResponse{Token: tokens.Token, RefreshToken: tokens.RefreshToken, RefreshTokenExpiredAt: tokens.RefreshTokenExpiredAt})
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken godoc
+// @Summary      Get the new token of the session with the refresh token
+// @Description  The refresh token is rotated, so the new refresh token must be used next time.
+// @Description  Reusing the rotated refresh token revokes the session since the token must be leaked
+// @Tags         Auth
+// @Param 	  	 RefreshTokenRequest body RefreshTokenRequest true "Refresh token of the session"
+// @Success      201  {object}  SigninResponse 		   "Token and the next refresh token of the session"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      401  {object}  server.ErrorResponse   "Refresh token is invalid, expired or revoked"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Router       /auth/refresh [post]
+func (h AuthHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	tokens, err := h.sessionManager.Refresh(req.RefreshToken, "Doctor")
+	if err != nil {
+		h.InternalServerError(c, err, "h.sessionManager.Refresh error")
+		return
+	}
+	if tokens == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrInvalidRefreshToken)
+		return
+	}
+	c.JSON(http.StatusCreated, SigninResponse{Token: tokens.Token, RefreshToken: tokens.RefreshToken, RefreshTokenExpiredAt: tokens.RefreshTokenExpiredAt})
+}
+
+// SignOut godoc
+// @Summary      Sign out the doctor from the current device
+// @Description  The session of the token is revoked, so its token and refresh token can't be used anymore.
+// @Description  The device token is kept since it may belong to another device that is still signed in
+// @Tags         Auth
+// @Success      200
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /auth/signout [delete]
+func (h AuthHandler) SignOut(c *gin.Context) {
+	if sessionID := h.GetSessionID(c); sessionID != "" {
+		if err := h.sessionManager.Revoke(sessionID); err != nil {
+			h.InternalServerError(c, err, "h.sessionManager.Revoke error")
+			return
+		}
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
+
+// SignOutAllDevices godoc
+// @Summary      Sign out the doctor from all devices
+// @Description  Every session of the doctor is revoked, including the current one.
+// @Description  The device token is removed as well, so the push notification is no longer sent
+// @Tags         Auth
+// @Success      200
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /auth/signout/all [delete]
+func (h AuthHandler) SignOutAllDevices(c *gin.Context) {
+	if err := h.sessionManager.RevokeAll(h.GetUserID(c), "Doctor"); err != nil {
+		h.InternalServerError(c, err, "h.sessionManager.RevokeAll error")
+		return
+	}
+	if err := h.doctorDataStore.SetNotificationToken(h.GetUserID(c), ""); err != nil {
+		h.InternalServerError(c, err, "h.doctorDataStore.SetNotificationToken error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
 }
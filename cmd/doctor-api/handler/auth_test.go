@@ -12,10 +12,11 @@ import (
 	"github.com/synthia-telemed/backend-api/cmd/doctor-api/handler"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/session"
 	testhelper "github.com/synthia-telemed/backend-api/test/helper"
 	"github.com/synthia-telemed/backend-api/test/mock_datastore"
 	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
-	"github.com/synthia-telemed/backend-api/test/mock_token_service"
+	"github.com/synthia-telemed/backend-api/test/mock_session"
 	"go.uber.org/zap"
 	"math/rand"
 	"net/http"
@@ -33,15 +34,15 @@ var _ = Describe("Doctor Auth Handler", func() {
 
 		mockDoctorDataStore   *mock_datastore.MockDoctorDataStore
 		mockHospitalSysClient *mock_hospital_client.MockSystemClient
-		mockTokenService      *mock_token_service.MockService
+		mockSessionManager    *mock_session.MockManager
 	)
 
 	BeforeEach(func() {
 		mockCtrl, rec, c = testhelper.InitHandlerTest()
 		mockDoctorDataStore = mock_datastore.NewMockDoctorDataStore(mockCtrl)
 		mockHospitalSysClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
-		mockTokenService = mock_token_service.NewMockService(mockCtrl)
-		h = handler.NewAuthHandler(mockHospitalSysClient, mockTokenService, mockDoctorDataStore, zap.NewNop().Sugar())
+		mockSessionManager = mock_session.NewMockManager(mockCtrl)
+		h = handler.NewAuthHandler(mockHospitalSysClient, mockSessionManager, mockDoctorDataStore, zap.NewNop().Sugar())
 	})
 
 	JustBeforeEach(func() {
@@ -105,13 +106,14 @@ var _ = Describe("Doctor Auth Handler", func() {
 				BeforeEach(func() {
 					mockHospitalSysClient.EXPECT().FindDoctorByUsername(gomock.Any(), req.Username).Return(queryDoctor, nil).Times(1)
 					mockDoctorDataStore.EXPECT().FindOrCreate(&datastore.Doctor{RefID: queryDoctor.Id}).Return(nil).Times(1)
-					mockTokenService.EXPECT().GenerateToken(uint64(0), "Doctor").Return(token, nil).Times(1)
+					mockSessionManager.EXPECT().Create(uint(0), "Doctor", gomock.Any()).Return(&session.Tokens{Token: token, RefreshToken: "refresh_token"}, nil).Times(1)
 				})
-				It("should return 201 with token", func() {
+				It("should return 201 with token and refresh token", func() {
 					var res handler.SigninResponse
 					Expect(rec.Code).To(Equal(http.StatusCreated))
 					Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
 					Expect(res.Token).To(Equal(token))
+					Expect(res.RefreshToken).To(Equal("refresh_token"))
 				})
 			})
 
@@ -132,11 +134,11 @@ var _ = Describe("Doctor Auth Handler", func() {
 					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
 				})
 			})
-			When("tokenService.GenerateToken error", func() {
+			When("sessionManager.Create error", func() {
 				BeforeEach(func() {
 					mockHospitalSysClient.EXPECT().FindDoctorByUsername(gomock.Any(), req.Username).Return(queryDoctor, nil).Times(1)
 					mockDoctorDataStore.EXPECT().FindOrCreate(&datastore.Doctor{RefID: queryDoctor.Id}).Return(nil).Times(1)
-					mockTokenService.EXPECT().GenerateToken(uint64(0), "Doctor").Return("", errors.New("err")).Times(1)
+					mockSessionManager.EXPECT().Create(uint(0), "Doctor", gomock.Any()).Return(nil, errors.New("err")).Times(1)
 				})
 				It("should return 500", func() {
 					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
@@ -145,4 +147,85 @@ var _ = Describe("Doctor Auth Handler", func() {
 		})
 	})
 
+	Context("RefreshToken", func() {
+		BeforeEach(func() {
+			handlerFunc = h.RefreshToken
+			c.Request = httptest.NewRequest("post", "/", strings.NewReader(`{"refresh_token": "refresh_token"}`))
+		})
+
+		When("refresh token is invalid", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().Refresh("refresh_token", "Doctor").Return(nil, nil).Times(1)
+			})
+			It("should return 401", func() {
+				Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRefreshToken)
+			})
+		})
+
+		When("refresh token is valid", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().Refresh("refresh_token", "Doctor").Return(&session.Tokens{Token: "token", RefreshToken: "next_refresh_token"}, nil).Times(1)
+			})
+			It("should return 201 with the next refresh token", func() {
+				var res handler.SigninResponse
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Token).To(Equal("token"))
+				Expect(res.RefreshToken).To(Equal("next_refresh_token"))
+			})
+		})
+	})
+
+	Context("SignOut", func() {
+		BeforeEach(func() {
+			handlerFunc = h.SignOut
+			c.Set("UserID", uint(1))
+			c.Set("SessionID", "session-id")
+		})
+
+		When("revoke session error", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().Revoke("session-id").Return(errors.New("err")).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("session is revoked", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().Revoke("session-id").Return(nil).Times(1)
+			})
+			It("should return 200 and keep the device token for the other devices", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("SignOutAllDevices", func() {
+		BeforeEach(func() {
+			handlerFunc = h.SignOutAllDevices
+			c.Set("UserID", uint(1))
+			mockSessionManager.EXPECT().RevokeAll(uint(1), "Doctor").Return(nil).Times(1)
+		})
+
+		When("remove device token error", func() {
+			BeforeEach(func() {
+				mockDoctorDataStore.EXPECT().SetNotificationToken(uint(1), "").Return(errors.New("err")).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockDoctorDataStore.EXPECT().SetNotificationToken(uint(1), "").Return(nil).Times(1)
+			})
+			It("should revoke all sessions of the doctor and remove the device token", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
 })
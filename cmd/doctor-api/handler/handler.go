@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/server"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type DoctorGinHandler struct {
+	doctorDataStore datastore.DoctorDataStore
+	server.GinHandler
+}
+
+func NewDoctorGinHandler(doctorDS datastore.DoctorDataStore, logger *zap.SugaredLogger) DoctorGinHandler {
+	return DoctorGinHandler{
+		doctorDataStore: doctorDS,
+		GinHandler:      server.GinHandler{Logger: logger},
+	}
+}
+
+func (h DoctorGinHandler) ParseDoctor(c *gin.Context) {
+	doctorID := h.GetUserID(c)
+	doctor, err := h.doctorDataStore.FindByID(doctorID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.doctorDataStore.FindByID error")
+		return
+	}
+	if doctor == nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotFound)
+		return
+	}
+	c.Set("Doctor", doctor)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type NotificationHandler struct {
+	doctorDataStore datastore.DoctorDataStore
+	DoctorGinHandler
+}
+
+func NewNotificationHandler(dds datastore.DoctorDataStore, logger *zap.SugaredLogger) *NotificationHandler {
+	return &NotificationHandler{
+		doctorDataStore:  dds,
+		DoctorGinHandler: NewDoctorGinHandler(dds, logger),
+	}
+}
+
+func (h NotificationHandler) Register(r *gin.RouterGroup) {
+	g := r.Group("/notification", h.ParseUserID, h.ParseDoctor)
+	g.POST("/token", h.SetNotificationToken)
+}
+
+type SetNotificationTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SetNotificationToken godoc
+// @Summary      Save doctor device notification token
+// @Description  The push notification about the appointments of the doctor is sent to this device until the doctor signs out
+// @Tags         Notification
+// @Param  		 SetNotificationTokenRequest body SetNotificationTokenRequest true "Notification token"
+// @Success      200
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /notification/token [post]
+func (h NotificationHandler) SetNotificationToken(c *gin.Context) {
+	var req SetNotificationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	if err := h.doctorDataStore.SetNotificationToken(doctor.ID, req.Token); err != nil {
+		h.InternalServerError(c, err, "h.doctorDataStore.SetNotificationToken error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
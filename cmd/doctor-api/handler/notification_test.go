@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/doctor-api/handler"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("Doctor Notification Handler", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		c           *gin.Context
+		rec         *httptest.ResponseRecorder
+		h           *handler.NotificationHandler
+		handlerFunc gin.HandlerFunc
+
+		mockDoctorDataStore *mock_datastore.MockDoctorDataStore
+		doctor              *datastore.Doctor
+	)
+
+	BeforeEach(func() {
+		mockCtrl, rec, c = testhelper.InitHandlerTest()
+		mockDoctorDataStore = mock_datastore.NewMockDoctorDataStore(mockCtrl)
+		h = handler.NewNotificationHandler(mockDoctorDataStore, zap.NewNop().Sugar())
+		doctor = testhelper.GenerateDoctor()
+		c.Set("Doctor", doctor)
+	})
+
+	JustBeforeEach(func() {
+		handlerFunc(c)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("SetNotificationToken", func() {
+		BeforeEach(func() {
+			handlerFunc = h.SetNotificationToken
+			body, _ := json.Marshal(handler.SetNotificationTokenRequest{Token: "device-token"})
+			c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		})
+
+		When("request body is invalid", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{}`)))
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+
+		When("set notification token error", func() {
+			BeforeEach(func() {
+				mockDoctorDataStore.EXPECT().SetNotificationToken(doctor.ID, "device-token").Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockDoctorDataStore.EXPECT().SetNotificationToken(doctor.ID, "device-token").Return(nil).Times(1)
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+})
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/server"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrInvalidPaymentID      = server.NewErrorResponse("Invalid payment ID")
+	ErrPaymentNotFound       = server.NewErrorResponse("Payment not found")
+	ErrPaymentNotRefundable  = server.NewErrorResponse("Only successful payment can be refunded")
+	ErrInvalidRefundAmount   = server.NewErrorResponse("Refund amount must be positive with at most 2 decimal places")
+	ErrRefundAmountExceeded  = server.NewErrorResponse("Refund amount exceeds the remaining amount of the payment")
+	ErrPaymentInvoiceMissing = server.NewErrorResponse("Invoice of the payment not found")
+)
+
+type PaymentHandler struct {
+	paymentDataStore datastore.PaymentDataStore
+	refundDataStore  datastore.RefundDataStore
+	paymentClient    payment.Client
+	hospitalClient   hospital.SystemClient
+	DoctorGinHandler
+}
+
+func NewPaymentHandler(pds datastore.PaymentDataStore, rds datastore.RefundDataStore, dds datastore.DoctorDataStore, paymentClient payment.Client, hos hospital.SystemClient, logger *zap.SugaredLogger) *PaymentHandler {
+	return &PaymentHandler{
+		paymentDataStore: pds,
+		refundDataStore:  rds,
+		paymentClient:    paymentClient,
+		hospitalClient:   hos,
+		DoctorGinHandler: NewDoctorGinHandler(dds, logger),
+	}
+}
+
+func (h PaymentHandler) Register(r *gin.RouterGroup) {
+	g := r.Group("/payment", h.ParseUserID, h.ParseDoctor)
+	g.GET("/:paymentID/refund", h.AuthorizedDoctorToPayment, h.ListRefunds)
+	g.POST("/:paymentID/refund", h.AuthorizedDoctorToPayment, h.RefundPayment)
+}
+
+// ListRefunds godoc
+// @Summary      Get list of the refunds of the payment from oldest to latest
+// @Tags         Payment
+// @Param  		 paymentID 	path	 integer	true "ID of the payment"
+// @Success      200  {array}	datastore.Refund "List of the refunds"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid payment ID"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "Forbidden"
+// @Failure      404  {object}  server.ErrorResponse   "Payment not found"
+// @Failure      404  {object}  server.ErrorResponse   "Invoice of the payment not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/{paymentID}/refund [get]
+func (h PaymentHandler) ListRefunds(c *gin.Context) {
+	rawPayment, _ := c.Get("Payment")
+	p := rawPayment.(*datastore.Payment)
+	refunds, err := h.refundDataStore.ListByPaymentID(p.ID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.refundDataStore.ListByPaymentID error")
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
+
+type RefundPaymentRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+	Reason string  `json:"reason"`
+}
+
+type RefundPaymentResponse struct {
+	Refund  *datastore.Refund  `json:"refund"`
+	Payment *datastore.Payment `json:"payment"`
+}
+
+// RefundPayment godoc
+// @Summary      Refund the payment of the doctor's appointment
+// @Description  The amount can be less than the remaining amount of the payment for partial refund
+// @Tags         Payment
+// @Param  		 paymentID 	path	 integer	true "ID of the payment"
+// @Param 	  	 RefundPaymentRequest body RefundPaymentRequest true "Amount in baht and reason of the refund"
+// @Success      201  {object}	RefundPaymentResponse "Created refund with the updated payment"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid payment ID"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Only successful payment can be refunded"
+// @Failure      400  {object}  server.ErrorResponse   "Refund amount must be positive with at most 2 decimal places"
+// @Failure      400  {object}  server.ErrorResponse   "Refund amount exceeds the remaining amount of the payment"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "Forbidden"
+// @Failure      404  {object}  server.ErrorResponse   "Payment not found"
+// @Failure      404  {object}  server.ErrorResponse   "Invoice of the payment not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/{paymentID}/refund [post]
+func (h PaymentHandler) RefundPayment(c *gin.Context) {
+	rawPayment, _ := c.Get("Payment")
+	p := rawPayment.(*datastore.Payment)
+	var req RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	satang := math.Round(req.Amount * 100)
+	if req.Amount <= 0 || math.Abs(satang-req.Amount*100) > 1e-6 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRefundAmount)
+		return
+	}
+	if p.Status != datastore.SuccessPaymentStatus {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrPaymentNotRefundable)
+		return
+	}
+
+	// Reserve the amount with the pending refund before refunding, so the concurrent refunds can't exceed the payment amount
+	amount := satang / 100
+	refund := &datastore.Refund{PaymentID: p.ID, Amount: amount, Reason: req.Reason}
+	reserved, err := h.paymentDataStore.ReserveRefund(refund)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.ReserveRefund error")
+		return
+	}
+	if !reserved {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrRefundAmountExceeded)
+		return
+	}
+	paymentRefund, err := h.paymentClient.Refund(p.ChargeID, int(satang))
+	if err != nil {
+		if releaseErr := h.paymentDataStore.ReleaseRefund(refund); releaseErr != nil {
+			h.InternalServerErrorWithoutAborting(c, releaseErr, "h.paymentDataStore.ReleaseRefund error")
+		}
+		h.InternalServerError(c, err, "h.paymentClient.Refund error")
+		return
+	}
+	if err := h.refundDataStore.Complete(refund.ID, paymentRefund.ID); err != nil {
+		// The refund stays pending, so its reference ID is logged to complete it manually
+		h.Logger.Errorw("Refund is left pending", "refundID", refund.ID, "refID", paymentRefund.ID)
+		h.InternalServerError(c, err, "h.refundDataStore.Complete error")
+		return
+	}
+	refund.RefID = paymentRefund.ID
+	refund.Status = datastore.SuccessRefundStatus
+	p.RefundedAmount += amount
+	c.JSON(http.StatusCreated, &RefundPaymentResponse{Refund: refund, Payment: p})
+}
+
+// AuthorizedDoctorToPayment allows only the doctor of the paid appointment to access the payment
+func (h PaymentHandler) AuthorizedDoctorToPayment(c *gin.Context) {
+	paymentID, err := strconv.ParseUint(c.Param("paymentID"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidPaymentID)
+		return
+	}
+	p, err := h.paymentDataStore.FindByID(uint(paymentID))
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.FindByID error")
+		return
+	}
+	if p == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrPaymentNotFound)
+		return
+	}
+	ctx := context.Background()
+	invoice, err := h.hospitalClient.FindInvoiceByID(ctx, p.InvoiceID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.FindInvoiceByID error")
+		return
+	}
+	if invoice == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrPaymentInvoiceMissing)
+		return
+	}
+	appointmentID, err := strconv.Atoi(invoice.AppointmentID)
+	if err != nil {
+		h.InternalServerError(c, err, "strconv.Atoi error")
+		return
+	}
+	appointment, err := h.hospitalClient.FindDoctorAppointmentByID(ctx, appointmentID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.FindDoctorAppointmentByID error")
+		return
+	}
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	if appointment == nil || appointment.Doctor.ID != doctor.RefID {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrForbidden)
+		return
+	}
+	c.Set("Payment", p)
+}
@@ -0,0 +1,275 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/doctor-api/handler"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_payment"
+	"go.uber.org/zap"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("Doctor Payment Handler", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		c           *gin.Context
+		rec         *httptest.ResponseRecorder
+		h           *handler.PaymentHandler
+		handlerFunc gin.HandlerFunc
+
+		mockPaymentDataStore  *mock_datastore.MockPaymentDataStore
+		mockRefundDataStore   *mock_datastore.MockRefundDataStore
+		mockDoctorDataStore   *mock_datastore.MockDoctorDataStore
+		mockPaymentClient     *mock_payment.MockClient
+		mockHospitalSysClient *mock_hospital_client.MockSystemClient
+		doctor                *datastore.Doctor
+		p                     *datastore.Payment
+	)
+
+	BeforeEach(func() {
+		mockCtrl, rec, c = testhelper.InitHandlerTest()
+		mockPaymentDataStore = mock_datastore.NewMockPaymentDataStore(mockCtrl)
+		mockRefundDataStore = mock_datastore.NewMockRefundDataStore(mockCtrl)
+		mockDoctorDataStore = mock_datastore.NewMockDoctorDataStore(mockCtrl)
+		mockPaymentClient = mock_payment.NewMockClient(mockCtrl)
+		mockHospitalSysClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
+		h = handler.NewPaymentHandler(mockPaymentDataStore, mockRefundDataStore, mockDoctorDataStore, mockPaymentClient, mockHospitalSysClient, zap.NewNop().Sugar())
+		doctor = testhelper.GenerateDoctor()
+		c.Set("Doctor", doctor)
+		p = &datastore.Payment{
+			ID:       uint(rand.Uint32()),
+			ChargeID: uuid.NewString(),
+			Status:   datastore.SuccessPaymentStatus,
+			Amount:   500,
+		}
+	})
+
+	JustBeforeEach(func() {
+		handlerFunc(c)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("AuthorizedDoctorToPayment", func() {
+		var (
+			invoice     *hospital.InvoiceOverview
+			appointment *hospital.DoctorAppointment
+		)
+		BeforeEach(func() {
+			handlerFunc = h.AuthorizedDoctorToPayment
+			c.Params = []gin.Param{{Key: "paymentID", Value: fmt.Sprintf("%d", p.ID)}}
+			var appointmentID int
+			appointment, appointmentID = testhelper.GenerateDoctorAppointment("", doctor.RefID, hospital.AppointmentStatusCompleted)
+			invoice = testhelper.GenerateHospitalInvoice(true)
+			invoice.AppointmentID = fmt.Sprintf("%d", appointmentID)
+			p.InvoiceID = invoice.Id
+		})
+
+		When("payment ID is invalid", func() {
+			BeforeEach(func() {
+				c.Params = []gin.Param{{Key: "paymentID", Value: "abc"}}
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidPaymentID)
+			})
+		})
+		When("payment is not found", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindByID(p.ID).Return(nil, nil).Times(1)
+			})
+			It("should return 404", func() {
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrPaymentNotFound)
+			})
+		})
+		When("find invoice error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindByID(p.ID).Return(p, nil).Times(1)
+				mockHospitalSysClient.EXPECT().FindInvoiceByID(gomock.Any(), p.InvoiceID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("appointment of the invoice belongs to another doctor", func() {
+			BeforeEach(func() {
+				appointment.Doctor.ID = uuid.NewString()
+				mockPaymentDataStore.EXPECT().FindByID(p.ID).Return(p, nil).Times(1)
+				mockHospitalSysClient.EXPECT().FindInvoiceByID(gomock.Any(), p.InvoiceID).Return(invoice, nil).Times(1)
+				mockHospitalSysClient.EXPECT().FindDoctorAppointmentByID(gomock.Any(), gomock.Any()).Return(appointment, nil).Times(1)
+			})
+			It("should return 403", func() {
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrForbidden)
+			})
+		})
+		When("doctor owns the appointment of the invoice", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindByID(p.ID).Return(p, nil).Times(1)
+				mockHospitalSysClient.EXPECT().FindInvoiceByID(gomock.Any(), p.InvoiceID).Return(invoice, nil).Times(1)
+				mockHospitalSysClient.EXPECT().FindDoctorAppointmentByID(gomock.Any(), gomock.Any()).Return(appointment, nil).Times(1)
+			})
+			It("should set the payment to the context", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				rawPayment, exist := c.Get("Payment")
+				Expect(exist).To(BeTrue())
+				Expect(rawPayment).To(Equal(p))
+			})
+		})
+	})
+
+	Context("ListRefunds", func() {
+		BeforeEach(func() {
+			handlerFunc = h.ListRefunds
+			c.Set("Payment", p)
+		})
+		When("list refunds error", func() {
+			BeforeEach(func() {
+				mockRefundDataStore.EXPECT().ListByPaymentID(p.ID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error", func() {
+			BeforeEach(func() {
+				refunds := []datastore.Refund{{ID: 1, PaymentID: p.ID, Amount: 100}}
+				mockRefundDataStore.EXPECT().ListByPaymentID(p.ID).Return(refunds, nil).Times(1)
+			})
+			It("should return 200 with the refunds", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res []datastore.Refund
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res).To(HaveLen(1))
+			})
+		})
+	})
+
+	Context("RefundPayment", func() {
+		var req *handler.RefundPaymentRequest
+		BeforeEach(func() {
+			handlerFunc = h.RefundPayment
+			c.Set("Payment", p)
+			req = &handler.RefundPaymentRequest{Amount: 120.5, Reason: "Appointment is cancelled"}
+		})
+		setBody := func() {
+			reqBody, err := json.Marshal(req)
+			Expect(err).To(BeNil())
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewReader(reqBody))
+		}
+		pendingRefundID := uint(rand.Uint32())
+		expectReserveRefund := func(reserved bool) {
+			pendingRefund := &datastore.Refund{PaymentID: p.ID, Amount: req.Amount, Reason: req.Reason}
+			mockPaymentDataStore.EXPECT().ReserveRefund(pendingRefund).DoAndReturn(func(refund *datastore.Refund) (bool, error) {
+				if reserved {
+					refund.ID = pendingRefundID
+					refund.Status = datastore.PendingRefundStatus
+				}
+				return reserved, nil
+			}).Times(1)
+		}
+
+		When("amount has more than 2 decimal places", func() {
+			BeforeEach(func() {
+				req.Amount = 10.555
+				setBody()
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRefundAmount)
+			})
+		})
+		When("amount is negative", func() {
+			BeforeEach(func() {
+				req.Amount = -10
+				setBody()
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRefundAmount)
+			})
+		})
+		When("payment is not successful", func() {
+			BeforeEach(func() {
+				p.Status = datastore.FailedPaymentStatus
+				setBody()
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrPaymentNotRefundable)
+			})
+		})
+		When("amount exceeds the remaining amount", func() {
+			BeforeEach(func() {
+				setBody()
+				expectReserveRefund(false)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrRefundAmountExceeded)
+			})
+		})
+		When("refund with payment provider error", func() {
+			BeforeEach(func() {
+				setBody()
+				expectReserveRefund(true)
+				mockPaymentClient.EXPECT().Refund(p.ChargeID, 12050).Return(nil, testhelper.MockError).Times(1)
+				mockPaymentDataStore.EXPECT().ReleaseRefund(gomock.Any()).DoAndReturn(func(refund *datastore.Refund) error {
+					Expect(refund.ID).To(Equal(pendingRefundID))
+					return nil
+				}).Times(1)
+			})
+			It("should release the reservation and return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("complete the pending refund error", func() {
+			BeforeEach(func() {
+				setBody()
+				refundID := uuid.NewString()
+				expectReserveRefund(true)
+				mockPaymentClient.EXPECT().Refund(p.ChargeID, 12050).Return(&payment.Refund{ID: refundID}, nil).Times(1)
+				mockRefundDataStore.EXPECT().Complete(pendingRefundID, refundID).Return(testhelper.MockError).Times(1)
+			})
+			It("should leave the refund pending and return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error", func() {
+			var refundID string
+			BeforeEach(func() {
+				setBody()
+				refundID = uuid.NewString()
+				expectReserveRefund(true)
+				mockPaymentClient.EXPECT().Refund(p.ChargeID, 12050).Return(&payment.Refund{ID: refundID, ChargeID: p.ChargeID, Amount: 12050}, nil).Times(1)
+				mockRefundDataStore.EXPECT().Complete(pendingRefundID, refundID).Return(nil).Times(1)
+			})
+			It("should return 201 with the refund and the updated payment", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				var res handler.RefundPaymentResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Refund.ID).To(Equal(pendingRefundID))
+				Expect(res.Refund.Status).To(Equal(datastore.SuccessRefundStatus))
+				Expect(res.Refund.Amount).To(Equal(req.Amount))
+				Expect(res.Payment.RefundedAmount).To(Equal(req.Amount))
+			})
+		})
+	})
+})
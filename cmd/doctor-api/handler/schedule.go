@@ -0,0 +1,557 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/server"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxFreeSlotQueryDays is the maximum number of days that can be queried for free slots at once
+const MaxFreeSlotQueryDays = 31
+
+var (
+	ErrInvalidTimeOfDay          = server.NewErrorResponse("Start time and end time must be in HH:MM format and start time must be before end time")
+	ErrInvalidDate               = server.NewErrorResponse("Date must be in YYYY-MM-DD format")
+	ErrInvalidDateRange          = server.NewErrorResponse("Start date must not be after end date and the range must not exceed 31 days")
+	ErrScheduleIDInvalid         = server.NewErrorResponse("Invalid schedule ID")
+	ErrScheduleSlotNotFound      = server.NewErrorResponse("Schedule slot not found")
+	ErrScheduleExceptionNotFound = server.NewErrorResponse("Schedule exception not found")
+	ErrLeaveDayNotFound          = server.NewErrorResponse("Leave day not found")
+)
+
+type ScheduleHandler struct {
+	scheduleDataStore datastore.ScheduleDataStore
+	hospitalClient    hospital.SystemClient
+	location          *time.Location
+	DoctorGinHandler
+}
+
+func NewScheduleHandler(sds datastore.ScheduleDataStore, dds datastore.DoctorDataStore, hos hospital.SystemClient, loc *time.Location, logger *zap.SugaredLogger) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleDataStore: sds,
+		hospitalClient:    hos,
+		location:          loc,
+		DoctorGinHandler:  NewDoctorGinHandler(dds, logger),
+	}
+}
+
+func (h ScheduleHandler) Register(r *gin.RouterGroup) {
+	g := r.Group("/schedule", h.ParseUserID, h.ParseDoctor)
+	g.GET("", h.GetSchedule)
+	g.GET("/free-slot", h.ListFreeSlots)
+	g.POST("/slot", h.CreateSlot)
+	g.PUT("/slot/:slotID", h.AuthorizedDoctorToSlot, h.UpdateSlot)
+	g.DELETE("/slot/:slotID", h.AuthorizedDoctorToSlot, h.DeleteSlot)
+	g.POST("/exception", h.CreateException)
+	g.PUT("/exception/:exceptionID", h.AuthorizedDoctorToException, h.UpdateException)
+	g.DELETE("/exception/:exceptionID", h.AuthorizedDoctorToException, h.DeleteException)
+	g.POST("/leave", h.CreateLeaveDay)
+	g.PUT("/leave/:leaveID", h.AuthorizedDoctorToLeaveDay, h.UpdateLeaveDay)
+	g.DELETE("/leave/:leaveID", h.AuthorizedDoctorToLeaveDay, h.DeleteLeaveDay)
+}
+
+// GetSchedule godoc
+// @Summary      Get weekly slots, exceptions and leave days of the doctor
+// @Tags         Schedule
+// @Success      200  {object}	datastore.DoctorSchedule "Schedule of the doctor"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule [get]
+func (h ScheduleHandler) GetSchedule(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	schedule, err := h.scheduleDataStore.FindByDoctorID(doctor.ID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.FindByDoctorID error")
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+type ListFreeSlotsRequest struct {
+	StartDate string `json:"start_date" form:"start_date" binding:"required" example:"2022-10-10"`
+	EndDate   string `json:"end_date" form:"end_date" binding:"required" example:"2022-10-16"`
+}
+
+// ListFreeSlots godoc
+// @Summary      Get free time slots of the doctor
+// @Description  Free time slots are the working time from the schedule that isn't occupied by any scheduled appointment. The date range is inclusive.
+// @Tags         Schedule
+// @Param 	  	 ListFreeSlotsRequest query ListFreeSlotsRequest true "Date range to compute the free slots"
+// @Success      200  {array}	datastore.TimeRange "List of free time slots"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Date must be in YYYY-MM-DD format"
+// @Failure      400  {object}  server.ErrorResponse   "Start date must not be after end date and the range must not exceed 31 days"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/free-slot [get]
+func (h ScheduleHandler) ListFreeSlots(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	var req ListFreeSlotsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	startDate, err := time.ParseInLocation(datastore.DateLayout, req.StartDate, h.location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidDate)
+		return
+	}
+	endDate, err := time.ParseInLocation(datastore.DateLayout, req.EndDate, h.location)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidDate)
+		return
+	}
+	if startDate.After(endDate) || endDate.Sub(startDate) >= MaxFreeSlotQueryDays*24*time.Hour {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidDateRange)
+		return
+	}
+	schedule, err := h.scheduleDataStore.FindByDoctorID(doctor.ID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.FindByDoctorID error")
+		return
+	}
+
+	to := endDate.AddDate(0, 0, 1)
+	appointments, err := h.hospitalClient.ListAppointmentsByDoctorIDInRange(context.Background(), doctor.RefID, startDate, to)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.ListAppointmentsByDoctorIDInRange error")
+		return
+	}
+	busy := make([]datastore.TimeRange, len(appointments))
+	for i, app := range appointments {
+		busy[i] = datastore.TimeRange{Start: app.StartDateTime, End: app.EndDateTime}
+	}
+	c.JSON(http.StatusOK, schedule.FreeSlots(startDate, to, h.location, busy))
+}
+
+type ScheduleSlotRequest struct {
+	Weekday   *time.Weekday `json:"weekday" binding:"required,min=0,max=6" swaggertype:"integer" example:"1"`
+	StartTime string        `json:"start_time" binding:"required" example:"09:00"`
+	EndTime   string        `json:"end_time" binding:"required" example:"12:00"`
+}
+
+// CreateSlot godoc
+// @Summary      Add weekly recurring working time slot
+// @Tags         Schedule
+// @Param 	  	 ScheduleSlotRequest body ScheduleSlotRequest true "Weekday (0 is Sunday) and time of the slot"
+// @Success      201  {object}	datastore.ScheduleSlot "Created slot"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Start time and end time must be in HH:MM format and start time must be before end time"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/slot [post]
+func (h ScheduleHandler) CreateSlot(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	req, ok := h.bindScheduleSlotRequest(c)
+	if !ok {
+		return
+	}
+	slot := &datastore.ScheduleSlot{
+		DoctorID:  doctor.ID,
+		Weekday:   *req.Weekday,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+	if err := h.scheduleDataStore.CreateSlot(slot); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.CreateSlot error")
+		return
+	}
+	c.JSON(http.StatusCreated, slot)
+}
+
+// UpdateSlot godoc
+// @Summary      Update weekly recurring working time slot
+// @Tags         Schedule
+// @Param  		 slotID 	path	 integer	true "ID of the slot"
+// @Param 	  	 ScheduleSlotRequest body ScheduleSlotRequest true "Weekday (0 is Sunday) and time of the slot"
+// @Success      200  {object}	datastore.ScheduleSlot "Updated slot"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid schedule ID"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Start time and end time must be in HH:MM format and start time must be before end time"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "The doctor doesn't own the slot"
+// @Failure      404  {object}  server.ErrorResponse   "Schedule slot not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/slot/{slotID} [put]
+func (h ScheduleHandler) UpdateSlot(c *gin.Context) {
+	rawSlot, _ := c.Get("ScheduleSlot")
+	slot := rawSlot.(*datastore.ScheduleSlot)
+	req, ok := h.bindScheduleSlotRequest(c)
+	if !ok {
+		return
+	}
+	slot.Weekday = *req.Weekday
+	slot.StartTime = req.StartTime
+	slot.EndTime = req.EndTime
+	if err := h.scheduleDataStore.SaveSlot(slot); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.SaveSlot error")
+		return
+	}
+	c.JSON(http.StatusOK, slot)
+}
+
+// DeleteSlot godoc
+// @Summary      Delete weekly recurring working time slot
+// @Tags         Schedule
+// @Param  		 slotID 	path	 integer	true "ID of the slot"
+// @Success      200  "Slot is deleted"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid schedule ID"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "The doctor doesn't own the slot"
+// @Failure      404  {object}  server.ErrorResponse   "Schedule slot not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/slot/{slotID} [delete]
+func (h ScheduleHandler) DeleteSlot(c *gin.Context) {
+	rawSlot, _ := c.Get("ScheduleSlot")
+	slot := rawSlot.(*datastore.ScheduleSlot)
+	if err := h.scheduleDataStore.DeleteSlot(slot.ID); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.DeleteSlot error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
+
+type ScheduleExceptionRequest struct {
+	Date        string `json:"date" binding:"required" example:"2022-10-10"`
+	StartTime   string `json:"start_time" binding:"required" example:"09:00"`
+	EndTime     string `json:"end_time" binding:"required" example:"12:00"`
+	IsAvailable bool   `json:"is_available"`
+}
+
+// CreateException godoc
+// @Summary      Add exception to the weekly schedule on a specific date
+// @Description  An available exception adds extra working time, otherwise the time range is blocked
+// @Tags         Schedule
+// @Param 	  	 ScheduleExceptionRequest body ScheduleExceptionRequest true "Date, time and availability of the exception"
+// @Success      201  {object}	datastore.ScheduleException "Created exception"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Date must be in YYYY-MM-DD format"
+// @Failure      400  {object}  server.ErrorResponse   "Start time and end time must be in HH:MM format and start time must be before end time"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/exception [post]
+func (h ScheduleHandler) CreateException(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	req, date, ok := h.bindScheduleExceptionRequest(c)
+	if !ok {
+		return
+	}
+	exception := &datastore.ScheduleException{
+		DoctorID:    doctor.ID,
+		Date:        date,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		IsAvailable: req.IsAvailable,
+	}
+	if err := h.scheduleDataStore.CreateException(exception); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.CreateException error")
+		return
+	}
+	c.JSON(http.StatusCreated, exception)
+}
+
+// UpdateException godoc
+// @Summary      Update exception of the weekly schedule
+// @Tags         Schedule
+// @Param  		 exceptionID 	path	 integer	true "ID of the exception"
+// @Param 	  	 ScheduleExceptionRequest body ScheduleExceptionRequest true "Date, time and availability of the exception"
+// @Success      200  {object}	datastore.ScheduleException "Updated exception"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid schedule ID"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Date must be in YYYY-MM-DD format"
+// @Failure      400  {object}  server.ErrorResponse   "Start time and end time must be in HH:MM format and start time must be before end time"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "The doctor doesn't own the exception"
+// @Failure      404  {object}  server.ErrorResponse   "Schedule exception not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/exception/{exceptionID} [put]
+func (h ScheduleHandler) UpdateException(c *gin.Context) {
+	rawException, _ := c.Get("ScheduleException")
+	exception := rawException.(*datastore.ScheduleException)
+	req, date, ok := h.bindScheduleExceptionRequest(c)
+	if !ok {
+		return
+	}
+	exception.Date = date
+	exception.StartTime = req.StartTime
+	exception.EndTime = req.EndTime
+	exception.IsAvailable = req.IsAvailable
+	if err := h.scheduleDataStore.SaveException(exception); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.SaveException error")
+		return
+	}
+	c.JSON(http.StatusOK, exception)
+}
+
+// DeleteException godoc
+// @Summary      Delete exception of the weekly schedule
+// @Tags         Schedule
+// @Param  		 exceptionID 	path	 integer	true "ID of the exception"
+// @Success      200  "Exception is deleted"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid schedule ID"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "The doctor doesn't own the exception"
+// @Failure      404  {object}  server.ErrorResponse   "Schedule exception not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/exception/{exceptionID} [delete]
+func (h ScheduleHandler) DeleteException(c *gin.Context) {
+	rawException, _ := c.Get("ScheduleException")
+	exception := rawException.(*datastore.ScheduleException)
+	if err := h.scheduleDataStore.DeleteException(exception.ID); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.DeleteException error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
+
+type LeaveDayRequest struct {
+	Date   string `json:"date" binding:"required" example:"2022-10-10"`
+	Reason string `json:"reason"`
+}
+
+// CreateLeaveDay godoc
+// @Summary      Add leave day
+// @Tags         Schedule
+// @Param 	  	 LeaveDayRequest body LeaveDayRequest true "Date and reason of the leave"
+// @Success      201  {object}	datastore.LeaveDay "Created leave day"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Date must be in YYYY-MM-DD format"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/leave [post]
+func (h ScheduleHandler) CreateLeaveDay(c *gin.Context) {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	req, date, ok := h.bindLeaveDayRequest(c)
+	if !ok {
+		return
+	}
+	leaveDay := &datastore.LeaveDay{
+		DoctorID: doctor.ID,
+		Date:     date,
+		Reason:   req.Reason,
+	}
+	if err := h.scheduleDataStore.CreateLeaveDay(leaveDay); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.CreateLeaveDay error")
+		return
+	}
+	c.JSON(http.StatusCreated, leaveDay)
+}
+
+// UpdateLeaveDay godoc
+// @Summary      Update leave day
+// @Tags         Schedule
+// @Param  		 leaveID 	path	 integer	true "ID of the leave day"
+// @Param 	  	 LeaveDayRequest body LeaveDayRequest true "Date and reason of the leave"
+// @Success      200  {object}	datastore.LeaveDay "Updated leave day"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid schedule ID"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse   "Date must be in YYYY-MM-DD format"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "The doctor doesn't own the leave day"
+// @Failure      404  {object}  server.ErrorResponse   "Leave day not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/leave/{leaveID} [put]
+func (h ScheduleHandler) UpdateLeaveDay(c *gin.Context) {
+	rawLeaveDay, _ := c.Get("LeaveDay")
+	leaveDay := rawLeaveDay.(*datastore.LeaveDay)
+	req, date, ok := h.bindLeaveDayRequest(c)
+	if !ok {
+		return
+	}
+	leaveDay.Date = date
+	leaveDay.Reason = req.Reason
+	if err := h.scheduleDataStore.SaveLeaveDay(leaveDay); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.SaveLeaveDay error")
+		return
+	}
+	c.JSON(http.StatusOK, leaveDay)
+}
+
+// DeleteLeaveDay godoc
+// @Summary      Delete leave day
+// @Tags         Schedule
+// @Param  		 leaveID 	path	 integer	true "ID of the leave day"
+// @Success      200  "Leave day is deleted"
+// @Failure      400  {object}  server.ErrorResponse   "Doctor not found"
+// @Failure      400  {object}  server.ErrorResponse   "Invalid schedule ID"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse   "The doctor doesn't own the leave day"
+// @Failure      404  {object}  server.ErrorResponse   "Leave day not found"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /schedule/leave/{leaveID} [delete]
+func (h ScheduleHandler) DeleteLeaveDay(c *gin.Context) {
+	rawLeaveDay, _ := c.Get("LeaveDay")
+	leaveDay := rawLeaveDay.(*datastore.LeaveDay)
+	if err := h.scheduleDataStore.DeleteLeaveDay(leaveDay.ID); err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.DeleteLeaveDay error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
+
+func (h ScheduleHandler) AuthorizedDoctorToSlot(c *gin.Context) {
+	id, ok := h.parseScheduleID(c, "slotID")
+	if !ok {
+		return
+	}
+	slot, err := h.scheduleDataStore.FindSlotByID(id)
+	if err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.FindSlotByID error")
+		return
+	}
+	if slot == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrScheduleSlotNotFound)
+		return
+	}
+	if !h.isOwnedByDoctor(c, slot.DoctorID) {
+		return
+	}
+	c.Set("ScheduleSlot", slot)
+}
+
+func (h ScheduleHandler) AuthorizedDoctorToException(c *gin.Context) {
+	id, ok := h.parseScheduleID(c, "exceptionID")
+	if !ok {
+		return
+	}
+	exception, err := h.scheduleDataStore.FindExceptionByID(id)
+	if err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.FindExceptionByID error")
+		return
+	}
+	if exception == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrScheduleExceptionNotFound)
+		return
+	}
+	if !h.isOwnedByDoctor(c, exception.DoctorID) {
+		return
+	}
+	c.Set("ScheduleException", exception)
+}
+
+func (h ScheduleHandler) AuthorizedDoctorToLeaveDay(c *gin.Context) {
+	id, ok := h.parseScheduleID(c, "leaveID")
+	if !ok {
+		return
+	}
+	leaveDay, err := h.scheduleDataStore.FindLeaveDayByID(id)
+	if err != nil {
+		h.InternalServerError(c, err, "h.scheduleDataStore.FindLeaveDayByID error")
+		return
+	}
+	if leaveDay == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrLeaveDayNotFound)
+		return
+	}
+	if !h.isOwnedByDoctor(c, leaveDay.DoctorID) {
+		return
+	}
+	c.Set("LeaveDay", leaveDay)
+}
+
+func (h ScheduleHandler) parseScheduleID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrScheduleIDInvalid)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h ScheduleHandler) isOwnedByDoctor(c *gin.Context, doctorID uint) bool {
+	rawDoc, _ := c.Get("Doctor")
+	doctor := rawDoc.(*datastore.Doctor)
+	if doctor.ID != doctorID {
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrForbidden)
+		return false
+	}
+	return true
+}
+
+func (h ScheduleHandler) bindScheduleSlotRequest(c *gin.Context) (*ScheduleSlotRequest, bool) {
+	var req ScheduleSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return nil, false
+	}
+	if err := datastore.ValidateTimeOfDayRange(req.StartTime, req.EndTime); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidTimeOfDay)
+		return nil, false
+	}
+	return &req, true
+}
+
+func (h ScheduleHandler) bindScheduleExceptionRequest(c *gin.Context) (*ScheduleExceptionRequest, time.Time, bool) {
+	var req ScheduleExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return nil, time.Time{}, false
+	}
+	date, err := time.Parse(datastore.DateLayout, req.Date)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidDate)
+		return nil, time.Time{}, false
+	}
+	if err := datastore.ValidateTimeOfDayRange(req.StartTime, req.EndTime); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidTimeOfDay)
+		return nil, time.Time{}, false
+	}
+	return &req, date, true
+}
+
+func (h ScheduleHandler) bindLeaveDayRequest(c *gin.Context) (*LeaveDayRequest, time.Time, bool) {
+	var req LeaveDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return nil, time.Time{}, false
+	}
+	date, err := time.Parse(datastore.DateLayout, req.Date)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidDate)
+		return nil, time.Time{}, false
+	}
+	return &req, date, true
+}
@@ -0,0 +1,596 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/doctor-api/handler"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"go.uber.org/zap"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+var _ = Describe("Doctor Schedule Handler", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		c           *gin.Context
+		rec         *httptest.ResponseRecorder
+		h           *handler.ScheduleHandler
+		handlerFunc gin.HandlerFunc
+
+		mockScheduleDataStore *mock_datastore.MockScheduleDataStore
+		mockDoctorDataStore   *mock_datastore.MockDoctorDataStore
+		mockHospitalSysClient *mock_hospital_client.MockSystemClient
+		loc                   *time.Location
+		doctor                *datastore.Doctor
+	)
+
+	BeforeEach(func() {
+		mockCtrl, rec, c = testhelper.InitHandlerTest()
+		mockScheduleDataStore = mock_datastore.NewMockScheduleDataStore(mockCtrl)
+		mockDoctorDataStore = mock_datastore.NewMockDoctorDataStore(mockCtrl)
+		mockHospitalSysClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
+		loc = time.FixedZone("ICT", 7*60*60)
+		h = handler.NewScheduleHandler(mockScheduleDataStore, mockDoctorDataStore, mockHospitalSysClient, loc, zap.NewNop().Sugar())
+		doctor = testhelper.GenerateDoctor()
+		c.Set("Doctor", doctor)
+	})
+
+	JustBeforeEach(func() {
+		handlerFunc(c)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	setJSONBody := func(body interface{}) {
+		reqBody, err := json.Marshal(body)
+		Expect(err).To(BeNil())
+		c.Request = httptest.NewRequest("POST", "/", bytes.NewReader(reqBody))
+	}
+
+	Context("GetSchedule", func() {
+		BeforeEach(func() {
+			handlerFunc = h.GetSchedule
+		})
+		When("find schedule error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindByDoctorID(doctor.ID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			var schedule *datastore.DoctorSchedule
+			BeforeEach(func() {
+				schedule = &datastore.DoctorSchedule{Slots: []datastore.ScheduleSlot{{ID: 1, DoctorID: doctor.ID, Weekday: time.Monday, StartTime: "09:00", EndTime: "12:00"}}}
+				mockScheduleDataStore.EXPECT().FindByDoctorID(doctor.ID).Return(schedule, nil).Times(1)
+			})
+			It("should return 200 with schedule", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res datastore.DoctorSchedule
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Slots).To(HaveLen(1))
+				Expect(res.Slots[0].StartTime).To(Equal("09:00"))
+			})
+		})
+	})
+
+	Context("ListFreeSlots", func() {
+		var (
+			schedule *datastore.DoctorSchedule
+		)
+		BeforeEach(func() {
+			handlerFunc = h.ListFreeSlots
+			// 2022-10-10 is Monday
+			schedule = &datastore.DoctorSchedule{Slots: []datastore.ScheduleSlot{{Weekday: time.Monday, StartTime: "09:00", EndTime: "12:00"}}}
+			c.Request = httptest.NewRequest("GET", "/?start_date=2022-10-10&end_date=2022-10-11", nil)
+		})
+
+		When("date range is not provided", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/", nil)
+			})
+			It("should return 400 with ErrInvalidRequestBody", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("date is in invalid format", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/?start_date=10-10-2022&end_date=2022-10-11", nil)
+			})
+			It("should return 400 with ErrInvalidDate", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidDate)
+			})
+		})
+		When("start date is after end date", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/?start_date=2022-10-12&end_date=2022-10-11", nil)
+			})
+			It("should return 400 with ErrInvalidDateRange", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidDateRange)
+			})
+		})
+		When("date range is too long", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/?start_date=2022-10-01&end_date=2022-11-01", nil)
+			})
+			It("should return 400 with ErrInvalidDateRange", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidDateRange)
+			})
+		})
+		When("find schedule error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindByDoctorID(doctor.ID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("list appointments error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindByDoctorID(doctor.ID).Return(schedule, nil).Times(1)
+				mockHospitalSysClient.EXPECT().ListAppointmentsByDoctorIDInRange(gomock.Any(), doctor.RefID, gomock.Any(), gomock.Any()).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				scheduled := testhelper.GenerateAppointmentOverview(hospital.AppointmentStatusScheduled)
+				scheduled.StartDateTime = time.Date(2022, 10, 10, 10, 0, 0, 0, loc)
+				scheduled.EndDateTime = time.Date(2022, 10, 10, 10, 30, 0, 0, loc)
+
+				mockScheduleDataStore.EXPECT().FindByDoctorID(doctor.ID).Return(schedule, nil).Times(1)
+				mockHospitalSysClient.EXPECT().ListAppointmentsByDoctorIDInRange(gomock.Any(), doctor.RefID, time.Date(2022, 10, 10, 0, 0, 0, 0, loc), time.Date(2022, 10, 12, 0, 0, 0, 0, loc)).Return([]*hospital.AppointmentOverview{scheduled}, nil).Times(1)
+			})
+			It("should return 200 with free slots excluding the appointments", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res []datastore.TimeRange
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res).To(HaveLen(2))
+				Expect(res[0].Start.Equal(time.Date(2022, 10, 10, 9, 0, 0, 0, loc))).To(BeTrue())
+				Expect(res[0].End.Equal(time.Date(2022, 10, 10, 10, 0, 0, 0, loc))).To(BeTrue())
+				Expect(res[1].Start.Equal(time.Date(2022, 10, 10, 10, 30, 0, 0, loc))).To(BeTrue())
+				Expect(res[1].End.Equal(time.Date(2022, 10, 10, 12, 0, 0, 0, loc))).To(BeTrue())
+			})
+		})
+	})
+
+	Context("CreateSlot", func() {
+		var req *handler.ScheduleSlotRequest
+		BeforeEach(func() {
+			handlerFunc = h.CreateSlot
+			weekday := time.Sunday
+			req = &handler.ScheduleSlotRequest{Weekday: &weekday, StartTime: "09:00", EndTime: "12:00"}
+			setJSONBody(req)
+		})
+
+		When("weekday is missing", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"start_time": "09:00", "end_time": "12:00"}`))
+			})
+			It("should return 400 with ErrInvalidRequestBody", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("weekday is out of range", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"weekday": 7, "start_time": "09:00", "end_time": "12:00"}`))
+			})
+			It("should return 400 with ErrInvalidRequestBody", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("end time is before start time", func() {
+			BeforeEach(func() {
+				req.EndTime = "08:00"
+				setJSONBody(req)
+			})
+			It("should return 400 with ErrInvalidTimeOfDay", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidTimeOfDay)
+			})
+		})
+		When("create slot error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().CreateSlot(gomock.Any()).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().CreateSlot(&datastore.ScheduleSlot{DoctorID: doctor.ID, Weekday: time.Sunday, StartTime: "09:00", EndTime: "12:00"}).Return(nil).Times(1)
+			})
+			It("should return 201 with created slot", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				var res datastore.ScheduleSlot
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Weekday).To(Equal(time.Sunday))
+			})
+		})
+	})
+
+	Context("UpdateSlot", func() {
+		var slot *datastore.ScheduleSlot
+		BeforeEach(func() {
+			handlerFunc = h.UpdateSlot
+			slot = &datastore.ScheduleSlot{ID: uint(rand.Uint32()), DoctorID: doctor.ID, Weekday: time.Monday, StartTime: "09:00", EndTime: "12:00"}
+			c.Set("ScheduleSlot", slot)
+			weekday := time.Friday
+			setJSONBody(&handler.ScheduleSlotRequest{Weekday: &weekday, StartTime: "13:00", EndTime: "16:00"})
+		})
+
+		When("save slot error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().SaveSlot(slot).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().SaveSlot(slot).Return(nil).Times(1)
+			})
+			It("should return 200 with updated slot", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(slot.Weekday).To(Equal(time.Friday))
+				Expect(slot.StartTime).To(Equal("13:00"))
+				Expect(slot.EndTime).To(Equal("16:00"))
+			})
+		})
+	})
+
+	Context("DeleteSlot", func() {
+		var slot *datastore.ScheduleSlot
+		BeforeEach(func() {
+			handlerFunc = h.DeleteSlot
+			slot = &datastore.ScheduleSlot{ID: uint(rand.Uint32()), DoctorID: doctor.ID}
+			c.Set("ScheduleSlot", slot)
+		})
+		When("delete slot error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().DeleteSlot(slot.ID).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().DeleteSlot(slot.ID).Return(nil).Times(1)
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("CreateException", func() {
+		var req *handler.ScheduleExceptionRequest
+		BeforeEach(func() {
+			handlerFunc = h.CreateException
+			req = &handler.ScheduleExceptionRequest{Date: "2022-10-10", StartTime: "18:00", EndTime: "20:00", IsAvailable: true}
+			setJSONBody(req)
+		})
+
+		When("date is in invalid format", func() {
+			BeforeEach(func() {
+				req.Date = "10/10/2022"
+				setJSONBody(req)
+			})
+			It("should return 400 with ErrInvalidDate", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidDate)
+			})
+		})
+		When("time is in invalid format", func() {
+			BeforeEach(func() {
+				req.StartTime = "6pm"
+				setJSONBody(req)
+			})
+			It("should return 400 with ErrInvalidTimeOfDay", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidTimeOfDay)
+			})
+		})
+		When("create exception error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().CreateException(gomock.Any()).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().CreateException(&datastore.ScheduleException{
+					DoctorID:    doctor.ID,
+					Date:        time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC),
+					StartTime:   req.StartTime,
+					EndTime:     req.EndTime,
+					IsAvailable: true,
+				}).Return(nil).Times(1)
+			})
+			It("should return 201 with created exception", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+			})
+		})
+	})
+
+	Context("UpdateException", func() {
+		var exception *datastore.ScheduleException
+		BeforeEach(func() {
+			handlerFunc = h.UpdateException
+			exception = &datastore.ScheduleException{ID: uint(rand.Uint32()), DoctorID: doctor.ID, Date: time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC), StartTime: "18:00", EndTime: "20:00"}
+			c.Set("ScheduleException", exception)
+			setJSONBody(&handler.ScheduleExceptionRequest{Date: "2022-10-11", StartTime: "10:00", EndTime: "11:00", IsAvailable: false})
+		})
+		When("save exception error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().SaveException(exception).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().SaveException(exception).Return(nil).Times(1)
+			})
+			It("should return 200 with updated exception", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(exception.Date).To(Equal(time.Date(2022, 10, 11, 0, 0, 0, 0, time.UTC)))
+				Expect(exception.StartTime).To(Equal("10:00"))
+			})
+		})
+	})
+
+	Context("DeleteException", func() {
+		var exception *datastore.ScheduleException
+		BeforeEach(func() {
+			handlerFunc = h.DeleteException
+			exception = &datastore.ScheduleException{ID: uint(rand.Uint32()), DoctorID: doctor.ID}
+			c.Set("ScheduleException", exception)
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().DeleteException(exception.ID).Return(nil).Times(1)
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("CreateLeaveDay", func() {
+		var req *handler.LeaveDayRequest
+		BeforeEach(func() {
+			handlerFunc = h.CreateLeaveDay
+			req = &handler.LeaveDayRequest{Date: "2022-12-31", Reason: uuid.NewString()}
+			setJSONBody(req)
+		})
+		When("date is missing", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"reason": "vacation"}`))
+			})
+			It("should return 400 with ErrInvalidRequestBody", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("create leave day error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().CreateLeaveDay(gomock.Any()).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().CreateLeaveDay(&datastore.LeaveDay{
+					DoctorID: doctor.ID,
+					Date:     time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+					Reason:   req.Reason,
+				}).Return(nil).Times(1)
+			})
+			It("should return 201 with created leave day", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+			})
+		})
+	})
+
+	Context("UpdateLeaveDay", func() {
+		var leaveDay *datastore.LeaveDay
+		BeforeEach(func() {
+			handlerFunc = h.UpdateLeaveDay
+			leaveDay = &datastore.LeaveDay{ID: uint(rand.Uint32()), DoctorID: doctor.ID, Date: time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)}
+			c.Set("LeaveDay", leaveDay)
+			setJSONBody(&handler.LeaveDayRequest{Date: "2023-01-02", Reason: "Conference"})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().SaveLeaveDay(leaveDay).Return(nil).Times(1)
+			})
+			It("should return 200 with updated leave day", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(leaveDay.Reason).To(Equal("Conference"))
+				Expect(leaveDay.Date).To(Equal(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)))
+			})
+		})
+	})
+
+	Context("DeleteLeaveDay", func() {
+		var leaveDay *datastore.LeaveDay
+		BeforeEach(func() {
+			handlerFunc = h.DeleteLeaveDay
+			leaveDay = &datastore.LeaveDay{ID: uint(rand.Uint32()), DoctorID: doctor.ID}
+			c.Set("LeaveDay", leaveDay)
+		})
+		When("delete leave day error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().DeleteLeaveDay(leaveDay.ID).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Context("AuthorizedDoctorToSlot", func() {
+		var slot *datastore.ScheduleSlot
+		BeforeEach(func() {
+			handlerFunc = h.AuthorizedDoctorToSlot
+			slot = &datastore.ScheduleSlot{ID: uint(rand.Uint32()), DoctorID: doctor.ID}
+			c.Params = []gin.Param{{Key: "slotID", Value: fmt.Sprintf("%d", slot.ID)}}
+		})
+
+		When("slot ID is invalid", func() {
+			BeforeEach(func() {
+				c.Params = []gin.Param{{Key: "slotID", Value: "abc"}}
+			})
+			It("should return 400 with ErrScheduleIDInvalid", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrScheduleIDInvalid)
+			})
+		})
+		When("find slot error", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindSlotByID(slot.ID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("slot is not found", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindSlotByID(slot.ID).Return(nil, nil).Times(1)
+			})
+			It("should return 404 with ErrScheduleSlotNotFound", func() {
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrScheduleSlotNotFound)
+			})
+		})
+		When("slot is owned by another doctor", func() {
+			BeforeEach(func() {
+				slot.DoctorID = doctor.ID + 1
+				mockScheduleDataStore.EXPECT().FindSlotByID(slot.ID).Return(slot, nil).Times(1)
+			})
+			It("should return 403 with ErrForbidden", func() {
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrForbidden)
+			})
+		})
+		When("slot is owned by the doctor", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindSlotByID(slot.ID).Return(slot, nil).Times(1)
+			})
+			It("should set the slot to context", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+				rawSlot, exist := c.Get("ScheduleSlot")
+				Expect(exist).To(BeTrue())
+				Expect(rawSlot).To(Equal(slot))
+			})
+		})
+	})
+
+	Context("AuthorizedDoctorToException", func() {
+		var exception *datastore.ScheduleException
+		BeforeEach(func() {
+			handlerFunc = h.AuthorizedDoctorToException
+			exception = &datastore.ScheduleException{ID: uint(rand.Uint32()), DoctorID: doctor.ID}
+			c.Params = []gin.Param{{Key: "exceptionID", Value: fmt.Sprintf("%d", exception.ID)}}
+		})
+		When("exception is not found", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindExceptionByID(exception.ID).Return(nil, nil).Times(1)
+			})
+			It("should return 404 with ErrScheduleExceptionNotFound", func() {
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrScheduleExceptionNotFound)
+			})
+		})
+		When("exception is owned by another doctor", func() {
+			BeforeEach(func() {
+				exception.DoctorID = doctor.ID + 1
+				mockScheduleDataStore.EXPECT().FindExceptionByID(exception.ID).Return(exception, nil).Times(1)
+			})
+			It("should return 403 with ErrForbidden", func() {
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+			})
+		})
+		When("exception is owned by the doctor", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindExceptionByID(exception.ID).Return(exception, nil).Times(1)
+			})
+			It("should set the exception to context", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+				rawException, _ := c.Get("ScheduleException")
+				Expect(rawException).To(Equal(exception))
+			})
+		})
+	})
+
+	Context("AuthorizedDoctorToLeaveDay", func() {
+		var leaveDay *datastore.LeaveDay
+		BeforeEach(func() {
+			handlerFunc = h.AuthorizedDoctorToLeaveDay
+			leaveDay = &datastore.LeaveDay{ID: uint(rand.Uint32()), DoctorID: doctor.ID}
+			c.Params = []gin.Param{{Key: "leaveID", Value: fmt.Sprintf("%d", leaveDay.ID)}}
+		})
+		When("leave day is not found", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindLeaveDayByID(leaveDay.ID).Return(nil, nil).Times(1)
+			})
+			It("should return 404 with ErrLeaveDayNotFound", func() {
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrLeaveDayNotFound)
+			})
+		})
+		When("leave day is owned by another doctor", func() {
+			BeforeEach(func() {
+				leaveDay.DoctorID = doctor.ID + 1
+				mockScheduleDataStore.EXPECT().FindLeaveDayByID(leaveDay.ID).Return(leaveDay, nil).Times(1)
+			})
+			It("should return 403 with ErrForbidden", func() {
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+			})
+		})
+		When("leave day is owned by the doctor", func() {
+			BeforeEach(func() {
+				mockScheduleDataStore.EXPECT().FindLeaveDayByID(leaveDay.ID).Return(leaveDay, nil).Times(1)
+			})
+			It("should set the leave day to context", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+				rawLeaveDay, _ := c.Get("LeaveDay")
+				Expect(rawLeaveDay).To(Equal(leaveDay))
+			})
+		})
+	})
+})
@@ -10,11 +10,15 @@ import (
 	"github.com/synthia-telemed/backend-api/pkg/clock"
 	"github.com/synthia-telemed/backend-api/pkg/config"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/id"
 	"github.com/synthia-telemed/backend-api/pkg/logger"
 	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
 	"github.com/synthia-telemed/backend-api/pkg/server"
+	"github.com/synthia-telemed/backend-api/pkg/session"
 	"github.com/synthia-telemed/backend-api/pkg/token"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -69,22 +73,56 @@ func main() {
 	server.AssertFatalError(sugaredLogger, err, "Failed to create appointment data store")
 	notificationDataStore, err := datastore.NewGormNotificationDataStore(db)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create notification data store")
+	roomClosureDataStore, err := datastore.NewGormRoomClosureDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create room closure data store")
+	autopayDataStore, err := datastore.NewGormAutopayDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create autopay data store")
+	paymentDataStore, err := datastore.NewGormPaymentDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create payment data store")
+	refundDataStore, err := datastore.NewGormRefundDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create refund data store")
+	scheduleDataStore, err := datastore.NewGormScheduleDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create schedule data store")
+	sessionDataStore, err := datastore.NewGormSessionDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create session data store")
+	scheduleLocation, err := time.LoadLocation(cfg.ScheduleTimezone)
+	server.AssertFatalError(sugaredLogger, err, "Failed to load schedule timezone")
 
 	hospitalSysClient := hospital.NewGraphQLClient(&cfg.HospitalClient)
 	cacheClient := cache.NewRedisClient(&cfg.Cache)
 	realClock := clock.NewRealClock()
+	presenceTracker := presence.NewCacheTracker(&cfg.Presence, cacheClient, realClock)
+	eventBroker := event.NewCacheBroker(cacheClient)
 	idGenerator := id.NewNanoID()
 	tokenService, err := token.NewGRPCTokenService(&cfg.Token)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create token service")
+	sessionManager := session.NewTokenManager(tokenService, sessionDataStore, realClock)
 	notificationClient, err := notification.NewRabbitMQNotificationClient(&cfg.Notification)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create rabbitmq notification client")
+	paymentClient, err := payment.NewOmisePaymentClient(&cfg.Payment)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create payment client")
 
 	// Handlers
-	authHandler := handler.NewAuthHandler(hospitalSysClient, tokenService, doctorDataStore, sugaredLogger)
-	appointmentHandler := handler.NewAppointmentHandler(appointmentDataStore, patientDataStore, doctorDataStore, notificationDataStore, hospitalSysClient, cacheClient, realClock, idGenerator, notificationClient, sugaredLogger)
+	authHandler := handler.NewAuthHandler(hospitalSysClient, sessionManager, doctorDataStore, sugaredLogger)
+	appointmentHandler := handler.NewAppointmentHandler(appointmentDataStore, patientDataStore, doctorDataStore, notificationDataStore, roomClosureDataStore, autopayDataStore, hospitalSysClient, cacheClient, realClock, idGenerator, notificationClient, presenceTracker, eventBroker, cfg.RoomTTL, sugaredLogger)
+	scheduleHandler := handler.NewScheduleHandler(scheduleDataStore, doctorDataStore, hospitalSysClient, scheduleLocation, sugaredLogger)
+	paymentHandler := handler.NewPaymentHandler(paymentDataStore, refundDataStore, doctorDataStore, paymentClient, hospitalSysClient, sugaredLogger)
+	notificationHandler := handler.NewNotificationHandler(doctorDataStore, sugaredLogger)
 
 	ginServer := server.NewGinServer(cfg, sugaredLogger)
-	ginServer.RegisterHandlers("/api", authHandler, appointmentHandler)
+	authMode := server.AuthMode(cfg.AuthMode)
+	if !authMode.IsValid() {
+		sugaredLogger.Fatalw("Invalid auth mode", "mode", cfg.AuthMode)
+	}
+	sessionChecker := session.NewCachedChecker(sessionDataStore, realClock, cfg.SessionCheckCacheTTL)
+	if authMode == server.JWSAuthMode {
+		tokenVerifier, err := token.NewJWSVerifier(&cfg.TokenVerifier, realClock)
+		server.AssertFatalError(sugaredLogger, err, "Failed to create token verifier")
+		ginServer.Use(server.JWSAuthentication(tokenVerifier, sessionChecker, "Doctor", sugaredLogger))
+	} else {
+		ginServer.Use(server.SessionRevocation(sessionChecker, sugaredLogger))
+	}
+	ginServer.RegisterHandlers("/api", authHandler, appointmentHandler, scheduleHandler, paymentHandler, notificationHandler)
 	ginServer.GET("/api/docs/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	ginServer.ListenAndServe()
 	server.AssertFatalError(sugaredLogger, notificationClient.Close(), "Failed to close rabbitmq connection")
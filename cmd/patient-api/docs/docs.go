@@ -62,6 +62,122 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Appointment"
+                ],
+                "summary": "Book an appointment with the doctor",
+                "parameters": [
+                    {
+                        "description": "Doctor ID, time slot and detail of the appointment",
+                        "name": "CreateAppointmentRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateAppointmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created appointment",
+                        "schema": {
+                            "$ref": "#/definitions/hospital.Appointment"
+                        }
+                    },
+                    "400": {
+                        "description": "Patient already has an appointment in the given time slot",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/appointment/doctor/available": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Appointment"
+                ],
+                "summary": "Get list of doctors who are available in the given time slot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "end_date_time",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_date_time",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of available doctors",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital.DoctorOverview"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Start time must be in the future and before end time",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
         "/appointment/next": {
@@ -161,10 +277,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/appointment/{appointmentID}/roomID": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "UserID": []
@@ -176,7 +290,7 @@ const docTemplate = `{
                 "tags": [
                     "Appointment"
                 ],
-                "summary": "Get room ID of the appointment",
+                "summary": "Cancel the scheduled appointment",
                 "parameters": [
                     {
                         "type": "integer",
@@ -188,13 +302,10 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Room ID for the appointment",
-                        "schema": {
-                            "$ref": "#/definitions/handler.GetAppointmentRoomIDResponse"
-                        }
+                        "description": "Appointment is cancelled"
                     },
                     "400": {
-                        "description": "appointmentID is invalid",
+                        "description": "Appointment can't be changed because it is too close to the start time",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -212,7 +323,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "RoomID of the appointment not found",
+                        "description": "Appointment not found",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -226,79 +337,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/signin": {
-            "post": {
-                "description": "Initiate auth process with government credential which will sent OTP to patient's phone number",
+        "/appointment/{appointmentID}/reschedule": {
+            "patch": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The original appointment is cancelled and a new appointment with the same doctor and detail is created",
                 "tags": [
-                    "Auth"
+                    "Appointment"
                 ],
-                "summary": "Start signing-in with government credential",
+                "summary": "Move the scheduled appointment to another time slot",
                 "parameters": [
                     {
-                        "description": "Patient government credential (Passport ID or National ID)",
-                        "name": "SigninRequest",
+                        "type": "integer",
+                        "description": "ID of the appointment",
+                        "name": "appointmentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New time slot of the appointment",
+                        "name": "RescheduleAppointmentRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.SigninRequest"
+                            "$ref": "#/definitions/handler.RescheduleAppointmentRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "OTP is sent to patient's phone number",
+                    "200": {
+                        "description": "The rescheduled appointment",
                         "schema": {
-                            "$ref": "#/definitions/handler.SigninResponse"
+                            "$ref": "#/definitions/hospital.Appointment"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Patient already has an appointment in the given time slot",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Provided credential is not in the hospital system",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "The patient doesn't own the appointment",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/verify": {
-            "post": {
-                "description": "Complete auth process with OTP verification. It will return token if verification success.",
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Verify OTP and get token",
-                "parameters": [
-                    {
-                        "description": "OTP that is sent to patient's phone number",
-                        "name": "VerifyOTPRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.VerifyOTPRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "JWS Token for later use",
-                        "schema": {
-                            "$ref": "#/definitions/handler.VerifyOTPResponse"
-                        }
                     },
-                    "400": {
-                        "description": "OTP is invalid or expired",
+                    "404": {
+                        "description": "Appointment not found",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -312,7 +410,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/info": {
+        "/appointment/{appointmentID}/roomID": {
             "get": {
                 "security": [
                     {
@@ -323,14 +421,29 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "Info"
+                    "Appointment"
+                ],
+                "summary": "Get room ID of the appointment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the appointment",
+                        "name": "appointmentID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get patient information",
                 "responses": {
                     "200": {
-                        "description": "Patient information",
+                        "description": "Room ID for the appointment",
                         "schema": {
-                            "$ref": "#/definitions/hospital.Patient"
+                            "$ref": "#/definitions/handler.GetAppointmentRoomIDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "appointmentID is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
                     "401": {
@@ -339,8 +452,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "The patient doesn't own the appointment",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Patient not found",
+                        "description": "RoomID of the appointment not found",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -354,8 +473,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/info/name": {
-            "get": {
+        "/appointment/{appointmentID}/waiting-room": {
+            "post": {
                 "security": [
                     {
                         "UserID": []
@@ -364,15 +483,31 @@ const docTemplate = `{
                         "JWSToken": []
                     }
                 ],
+                "description": "The check-in expires after a short period, so the client should call this endpoint periodically as a heartbeat while the patient is waiting.\nThe response tells whether the doctor has opened the room, and the room ID if so.",
                 "tags": [
-                    "Info"
+                    "Appointment"
+                ],
+                "summary": "Check in to the waiting room of the appointment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the appointment",
+                        "name": "appointmentID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get patient name",
                 "responses": {
                     "200": {
-                        "description": "Name of the patient in both Thai and English",
+                        "description": "Presence of the patient and readiness of the doctor",
                         "schema": {
-                            "$ref": "#/definitions/handler.GetNameResponse"
+                            "$ref": "#/definitions/handler.WaitingRoomResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Appointment is not scheduled",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
                     "401": {
@@ -381,8 +516,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "The patient doesn't own the appointment",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Patient not found",
+                        "description": "Appointment not found",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -394,10 +535,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/notification": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "UserID": []
@@ -407,17 +546,26 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "Notification"
+                    "Appointment"
                 ],
-                "summary": "Get list of notification from latest to oldest",
-                "responses": {
-                    "200": {
-                        "description": "List of notifications",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/datastore.Notification"
-                            }
+                "summary": "Check out from the waiting room of the appointment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the appointment",
+                        "name": "appointmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patient left the waiting room"
+                    },
+                    "400": {
+                        "description": "appointmentID is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
                     "401": {
@@ -426,6 +574,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "The patient doesn't own the appointment",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Appointment not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -433,8 +593,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/auth/otp-channel": {
+            "put": {
                 "security": [
                     {
                         "UserID": []
@@ -443,14 +605,32 @@ const docTemplate = `{
                         "JWSToken": []
                     }
                 ],
+                "description": "The address of the channel is required except SMS, which is sent to the phone number in the hospital system.\nThe OTP is sent to the other channels if it can't be delivered through the preferred one",
                 "tags": [
-                    "Notification"
+                    "Auth"
+                ],
+                "summary": "Set the preferred channel to receive the OTP",
+                "parameters": [
+                    {
+                        "description": "Preferred channel and the addresses",
+                        "name": "SetOTPChannelRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetOTPChannelRequest"
+                        }
+                    }
                 ],
-                "summary": "Set all notification as read",
                 "responses": {
                     "200": {
                         "description": "OK"
                     },
+                    "400": {
+                        "description": "Address of the OTP channel is required",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
@@ -466,34 +646,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/notification/token": {
+        "/auth/refresh": {
             "post": {
-                "security": [
-                    {
-                        "UserID": []
-                    },
-                    {
-                        "JWSToken": []
-                    }
-                ],
+                "description": "The refresh token is rotated, so the new refresh token must be used next time.\nReusing the rotated refresh token revokes the session since the token must be leaked",
                 "tags": [
-                    "Notification"
+                    "Auth"
                 ],
-                "summary": "Save patient device notification token",
+                "summary": "Get the new token of the session with the refresh token",
                 "parameters": [
                     {
-                        "description": "Notification token",
-                        "name": "SetNotificationTokenRequest",
+                        "description": "Refresh token of the session",
+                        "name": "RefreshTokenRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.SetNotificationTokenRequest"
+                            "$ref": "#/definitions/handler.RefreshTokenRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "201": {
+                        "description": "JWS Token and the next refresh token of the session",
+                        "schema": {
+                            "$ref": "#/definitions/handler.VerifyOTPResponse"
+                        }
                     },
                     "400": {
                         "description": "Invalid request body",
@@ -501,6 +677,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Refresh token is invalid, expired or revoked",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -510,29 +692,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/notification/unread": {
-            "get": {
-                "security": [
-                    {
-                        "UserID": []
-                    },
+        "/auth/signin": {
+            "post": {
+                "description": "Initiate auth process with government credential which will sent OTP to patient's preferred channel, or the other channels if it can't be delivered.\nThe signins are limited by the credential, phone number and IP. Retry-After header tells the seconds to wait when the limit is reached",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Start signing-in with government credential",
+                "parameters": [
                     {
-                        "JWSToken": []
+                        "description": "Patient government credential (Passport ID or National ID)",
+                        "name": "SigninRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SigninRequest"
+                        }
                     }
                 ],
-                "tags": [
-                    "Notification"
-                ],
-                "summary": "Get count of unread notifications",
                 "responses": {
-                    "200": {
-                        "description": "Count of the unread notifications",
+                    "201": {
+                        "description": "OTP is sent to the patient",
                         "schema": {
-                            "$ref": "#/definitions/handler.CountUnReadNotificationResponse"
+                            "$ref": "#/definitions/handler.SigninResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Provided credential is not in the hospital system",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many signin requests, please try again later",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -546,53 +744,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/notification/{notificationID}": {
-            "patch": {
-                "security": [
-                    {
-                        "UserID": []
-                    },
-                    {
-                        "JWSToken": []
-                    }
-                ],
+        "/auth/signin/resend": {
+            "post": {
+                "description": "The new OTP is sent and the previous one can't be used. The attempts of the signin are kept.\nRetry-After header tells the seconds to wait when the OTP has just been sent",
                 "tags": [
-                    "Notification"
+                    "Auth"
                 ],
-                "summary": "Set specific notification to read",
+                "summary": "Resend the OTP of the signin",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID of the notification",
-                        "name": "notificationID",
-                        "in": "path",
-                        "required": true
+                        "description": "Signin ID and the channel to resend the OTP to",
+                        "name": "ResendOTPRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ResendOTPRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Invalid notification id",
-                        "schema": {
-                            "$ref": "#/definitions/server.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    "201": {
+                        "description": "OTP is sent to the patient",
                         "schema": {
-                            "$ref": "#/definitions/server.ErrorResponse"
+                            "$ref": "#/definitions/handler.SigninResponse"
                         }
                     },
-                    "403": {
-                        "description": "Patient doesn't own the notification",
+                    "400": {
+                        "description": "Signin is invalid or expired",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Notification not found",
+                    "429": {
+                        "description": "Too many signin requests, please try again later",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -606,8 +790,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/payment/credit-card": {
-            "get": {
+        "/auth/signout": {
+            "delete": {
                 "security": [
                     {
                         "UserID": []
@@ -616,19 +800,14 @@ const docTemplate = `{
                         "JWSToken": []
                     }
                 ],
+                "description": "The session of the token is revoked, so its token and refresh token can't be used anymore",
                 "tags": [
-                    "Payment"
+                    "Auth"
                 ],
-                "summary": "Get lists of saved credit cards",
+                "summary": "Sign out the patient from the current device",
                 "responses": {
                     "200": {
-                        "description": "List of saved cards",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/datastore.CreditCard"
-                            }
-                        }
+                        "description": "OK"
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -643,8 +822,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/auth/signout/all": {
+            "delete": {
                 "security": [
                     {
                         "UserID": []
@@ -653,33 +834,63 @@ const docTemplate = `{
                         "JWSToken": []
                     }
                 ],
+                "description": "Every session of the patient is revoked, including the current one",
                 "tags": [
-                    "Payment"
+                    "Auth"
                 ],
-                "summary": "Add new credit card",
+                "summary": "Sign out the patient from all devices",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify": {
+            "post": {
+                "description": "Complete auth process with OTP verification. It will return token if verification success.\nThe signin is cancelled after too many incorrect attempts, so the patient has to sign in again",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify OTP and get token",
                 "parameters": [
                     {
-                        "description": "Token from Omise and name of credit card",
-                        "name": "AddCreditCardRequest",
+                        "description": "Signin ID and OTP that is sent to patient's phone number",
+                        "name": "VerifyOTPRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.AddCreditCardRequest"
+                            "$ref": "#/definitions/handler.VerifyOTPRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created"
+                        "description": "JWS Token and refresh token of the new session",
+                        "schema": {
+                            "$ref": "#/definitions/handler.VerifyOTPResponse"
+                        }
                     },
                     "400": {
-                        "description": "Limited number of credit cards is reached",
+                        "description": "OTP is invalid or expired",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "429": {
+                        "description": "Too many incorrect OTP attempts, please sign in again",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -693,8 +904,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/payment/credit-card/{cardID}": {
-            "delete": {
+        "/info": {
+            "get": {
                 "security": [
                     {
                         "UserID": []
@@ -704,26 +915,14 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "Payment"
-                ],
-                "summary": "Delete saved credit card",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID of the credit card to delete",
-                        "name": "cardID",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Info"
                 ],
+                "summary": "Get patient information",
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Invalid credit card ID",
+                        "description": "Patient information",
                         "schema": {
-                            "$ref": "#/definitions/server.ErrorResponse"
+                            "$ref": "#/definitions/hospital.Patient"
                         }
                     },
                     "401": {
@@ -732,14 +931,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Patient doesn't own the specified credit card",
-                        "schema": {
-                            "$ref": "#/definitions/server.ErrorResponse"
-                        }
-                    },
                     "404": {
-                        "description": "Credit card not found",
+                        "description": "Patient not found",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -751,47 +944,144 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "security": [
-                    {
-                        "UserID": []
+            }
+        },
+        "/info/name": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
                     },
                     {
                         "JWSToken": []
                     }
                 ],
                 "tags": [
-                    "Payment"
+                    "Info"
                 ],
-                "summary": "Set isDefault status of credit card",
-                "parameters": [
+                "summary": "Get patient name",
+                "responses": {
+                    "200": {
+                        "description": "Name of the patient in both Thai and English",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GetNameResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notification": {
+            "get": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "ID of the credit card to set isDefault",
-                        "name": "cardID",
-                        "in": "path",
-                        "required": true
+                        "UserID": []
                     },
                     {
-                        "description": "IsDefault status of the credit card",
-                        "name": "SetCreditCardIsDefaultRequest",
-                        "in": "body",
-                        "required": true,
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Get list of notification from latest to oldest",
+                "responses": {
+                    "200": {
+                        "description": "List of notifications",
                         "schema": {
-                            "$ref": "#/definitions/handler.SetCreditCardIsDefaultRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/datastore.Notification"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
                         }
                     }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Notification"
                 ],
+                "summary": "Set all notification as read",
                 "responses": {
                     "200": {
                         "description": "OK"
                     },
-                    "400": {
-                        "description": "Invalid credit card ID",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/notification/stream": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The current unread count is sent first, then the events are pushed as they happen.\nEach event name is its type which is one of notification, unread_count or room_opened, and its data is datastore.Notification, event.UnreadCount or event.RoomOpened respectively",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Stream live events of the patient with Server-Sent Events",
+                "responses": {
+                    "200": {
+                        "description": "Stream of the events",
+                        "schema": {
+                            "$ref": "#/definitions/event.Event"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -799,14 +1089,46 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Patient doesn't own the specified credit card",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/notification/token": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
                     },
-                    "404": {
-                        "description": "Credit card not found",
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Save patient device notification token",
+                "parameters": [
+                    {
+                        "description": "Notification token",
+                        "name": "SetNotificationTokenRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetNotificationTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid request body",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -820,8 +1142,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/payment/pay/{invoiceID}/credit-card/{cardID}": {
-            "post": {
+        "/notification/unread": {
+            "get": {
                 "security": [
                     {
                         "UserID": []
@@ -831,34 +1153,60 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "Payment"
+                    "Notification"
                 ],
-                "summary": "Pay invoice with credit card method",
-                "parameters": [
+                "summary": "Get count of unread notifications",
+                "responses": {
+                    "200": {
+                        "description": "Count of the unread notifications",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CountUnReadNotificationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notification/{notificationID}": {
+            "patch": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "ID of the credit card to be charged",
-                        "name": "cardID",
-                        "in": "path",
-                        "required": true
+                        "UserID": []
                     },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Set specific notification to read",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID of the invoice to pay",
-                        "name": "invoiceID",
+                        "description": "ID of the notification",
+                        "name": "notificationID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Payment information",
-                        "schema": {
-                            "$ref": "#/definitions/handler.PayInvoiceWithCreditCardResponse"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
-                        "description": "Invalid credit card ID or invoice ID",
+                        "description": "Invalid notification id",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -870,13 +1218,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Patient doesn't own the specified credit card or invoice",
+                        "description": "Patient doesn't own the notification",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Credit card or invoice not found",
+                        "description": "Notification not found",
                         "schema": {
                             "$ref": "#/definitions/server.ErrorResponse"
                         }
@@ -889,76 +1237,1372 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "datastore.CreditCard": {
-            "type": "object",
-            "properties": {
-                "brand": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "expiry": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "is_default": {
-                    "type": "boolean"
-                },
-                "last_4_digits": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
+        },
+        "/payment/autopay": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Get the autopay setting of the patient",
+                "responses": {
+                    "200": {
+                        "description": "Autopay setting",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AutopayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The invoice of the completed appointment is charged with the default card once it's issued. The patient has to pay the invoice manually if the charge fails",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Opt in or out of autopay",
+                "parameters": [
+                    {
+                        "description": "Whether autopay is enabled",
+                        "name": "SetAutopayRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetAutopayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Autopay setting",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AutopayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/credit-card": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The expired card should be re-added as it can't be charged",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Get lists of saved credit cards",
+                "responses": {
+                    "200": {
+                        "description": "List of saved cards",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.SavedCreditCard"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Add new credit card",
+                "parameters": [
+                    {
+                        "description": "Token from Omise and name of credit card",
+                        "name": "AddCreditCardRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AddCreditCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Limited number of credit cards is reached",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/credit-card/return": {
+            "get": {
+                "description": "The charge is retrieved from Omise to settle the pending payment. The invoice is marked as paid only if the charge is authorized.\nThe route isn't authenticated, so the payment status isn't returned. The client gets it from /payment/pay/{invoiceID}/status instead",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Finalize the credit card payment after the patient is redirected back from 3-D Secure page",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the paid invoice",
+                        "name": "ref_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Pending payment of the invoice is settled if its charge is no longer pending"
+                    },
+                    "400": {
+                        "description": "Invalid invoice ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/credit-card/{cardID}": {
+            "delete": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Delete saved credit card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the credit card to delete",
+                        "name": "cardID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid credit card ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Patient doesn't own the specified credit card",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Credit card not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Set isDefault status of credit card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the credit card to set isDefault",
+                        "name": "cardID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "IsDefault status of the credit card",
+                        "name": "SetCreditCardIsDefaultRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetCreditCardIsDefaultRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid credit card ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Patient doesn't own the specified credit card",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Credit card not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/history": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The payments are ordered from the latest one. Each payment is joined with the appointment overview of its invoice",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "List the patient's past payments with filter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "credit_card",
+                            "promptpay"
+                        ],
+                        "type": "string",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_number",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failed",
+                            "pending",
+                            "refunded"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of payments with pagination information",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ListPaymentHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/invoice": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The invoices, both paid and unpaid, are ordered from the latest one",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "List all invoices of the patient with the outstanding balance",
+                "responses": {
+                    "200": {
+                        "description": "List of invoices with the outstanding balance",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ListInvoicesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/pay/{invoiceID}/credit-card/{cardID}": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The payment status is pending if the charge isn't settled yet, and it's updated when Omise notifies the result.\nIf authorize_uri is returned, the patient must authorize the charge with 3-D Secure on that page, which then redirects to /payment/credit-card/return",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Pay invoice with credit card method",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the credit card to be charged",
+                        "name": "cardID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the invoice to pay",
+                        "name": "invoiceID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request. The response of the first request which attempts the charge is replayed for the retries with the same key and body",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Payment information",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PayInvoiceWithCreditCardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Idempotency-Key must not be longer than 255 characters",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Patient doesn't own the specified credit card or invoice",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Credit card or invoice not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Invoice has a pending payment",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key has been used with another request",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/pay/{invoiceID}/promptpay": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The payment is pending until the QR code is paid or expired. Poll the payment status until it's settled",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Pay invoice with PromptPay method",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the invoice to pay",
+                        "name": "invoiceID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request. The response of the first request which attempts the charge is replayed for the retries with the same key and body",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Pending payment with the URI of the QR code image",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PayInvoiceWithPromptPayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Idempotency-Key must not be longer than 255 characters",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Patient doesn't own the specified invoice",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Invoice not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Invoice has a pending payment",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key has been used with another request",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/pay/{invoiceID}/split": {
+            "post": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The parts are charged in order, and their amounts must sum to the invoice total. The invoice is marked as paid only if all parts are successful.\nIf a part fails, the successful parts are refunded and the status is failed. 3-D Secure isn't supported, so the part which requires it fails the payment and is refunded if it's authorized later",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Pay invoice with several credit cards",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the invoice to pay",
+                        "name": "invoiceID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request. The response of the first request which attempts the charge is replayed for the retries with the same key and body",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Credit cards and the amounts to be charged",
+                        "name": "PayInvoiceWithSplitPaymentRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PayInvoiceWithSplitPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Status and parts of the split payment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PayInvoiceWithSplitPaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Idempotency-Key must not be longer than 255 characters",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Patient doesn't own the specified credit card or invoice",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Credit card or invoice not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Invoice has a pending payment",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key has been used with another request",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/pay/{invoiceID}/status": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The pending payment is settled with the latest state of its charge, so the result is available even if the webhook is delayed",
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Get the latest payment of the invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the invoice",
+                        "name": "invoiceID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Latest payment of the invoice",
+                        "schema": {
+                            "$ref": "#/definitions/datastore.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid invoice ID",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/receipt/{invoiceID}": {
+            "get": {
+                "security": [
+                    {
+                        "UserID": []
+                    },
+                    {
+                        "JWSToken": []
+                    }
+                ],
+                "description": "The bilingual Thai/English PDF receipt of the successful payment of the invoice for the insurance claim",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Download the receipt of the paid invoice",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the invoice",
+                        "name": "invoiceID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF receipt",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invoice isn't paid yet",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Patient doesn't own the specified invoice",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/webhook/omise": {
+            "post": {
+                "description": "The charge is retrieved from Omise instead of trusting the event data. The event is acknowledged if there is nothing to update",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Receive the event from Omise to settle the pending payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "HMAC-SHA256 signatures of the event",
+                        "name": "Omise-Signature",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timestamp used to sign the event",
+                        "name": "Omise-Signature-Timestamp",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid webhook event",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid webhook signature",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/server.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "datastore.CreditCard": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry_month": {
+                    "type": "integer"
+                },
+                "expiry_year": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "last_4_digits": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "datastore.Notification": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_read": {
+                    "type": "boolean"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "datastore.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credit_card": {
+                    "$ref": "#/definitions/datastore.CreditCard"
+                },
+                "credit_card_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "refunded_amount": {
+                    "description": "RefundedAmount is the total amount of the refunds of the payment",
+                    "type": "number"
+                },
+                "split_id": {
+                    "description": "SplitID groups the parts of the split payment, which pays the invoice with several credit cards",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "event.Event": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "gorm.DeletedAt": {
+            "type": "object",
+            "properties": {
+                "time": {
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "Valid is true if Time is not NULL",
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.AddCreditCardRequest": {
+            "type": "object",
+            "required": [
+                "card_token"
+            ],
+            "properties": {
+                "card_token": {
+                    "type": "string"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AutopayResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.CountUnReadNotificationResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateAppointmentRequest": {
+            "type": "object",
+            "required": [
+                "detail",
+                "doctor_id",
+                "end_date_time",
+                "start_date_time"
+            ],
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "end_date_time": {
+                    "type": "string"
+                },
+                "start_date_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.GetAppointmentResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "doctor": {
+                    "$ref": "#/definitions/hospital.DoctorOverview"
+                },
+                "duration": {
+                    "type": "number"
+                },
+                "end_date_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice": {
+                    "$ref": "#/definitions/hospital.Invoice"
+                },
+                "next_appointment": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "payment": {
+                    "$ref": "#/definitions/datastore.Payment"
+                },
+                "prescriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital.Prescription"
+                    }
+                },
+                "start_date_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.GetAppointmentRoomIDResponse": {
+            "type": "object",
+            "properties": {
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.GetNameResponse": {
+            "type": "object",
+            "properties": {
+                "EN": {
+                    "$ref": "#/definitions/hospital.Name"
+                },
+                "TH": {
+                    "$ref": "#/definitions/hospital.Name"
+                }
+            }
+        },
+        "handler.ListInvoicesResponse": {
+            "type": "object",
+            "properties": {
+                "invoices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital.PatientInvoice"
+                    }
+                },
+                "outstanding_balance": {
+                    "description": "OutstandingBalance is the sum of the total of the unpaid invoices",
+                    "type": "number"
+                }
+            }
+        },
+        "handler.ListPaymentHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "page_number": {
+                    "type": "integer"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.PaymentHistory"
+                    }
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total_item": {
+                    "type": "integer"
+                },
+                "total_page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.PayInvoiceWithCreditCardResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "authorize_uri": {
+                    "description": "AuthorizeURI is the 3-D Secure page to open when the payment is pending",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credit_card": {
+                    "$ref": "#/definitions/datastore.CreditCard"
+                },
+                "credit_card_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "failure_message": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
                 "patient_id": {
                     "type": "integer"
                 },
+                "refunded_amount": {
+                    "description": "RefundedAmount is the total amount of the refunds of the payment",
+                    "type": "number"
+                },
+                "split_id": {
+                    "description": "SplitID groups the parts of the split payment, which pays the invoice with several credit cards",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "datastore.Notification": {
+        "handler.PayInvoiceWithPromptPayResponse": {
             "type": "object",
             "properties": {
-                "body": {
-                    "type": "string"
+                "amount": {
+                    "type": "number"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "deletedAt": {
-                    "$ref": "#/definitions/gorm.DeletedAt"
+                "credit_card": {
+                    "$ref": "#/definitions/datastore.CreditCard"
+                },
+                "credit_card_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "is_read": {
-                    "type": "boolean"
+                "invoice_id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
                 },
                 "patient_id": {
                     "type": "integer"
                 },
-                "title": {
+                "qr_code_uri": {
+                    "type": "string"
+                },
+                "refunded_amount": {
+                    "description": "RefundedAmount is the total amount of the refunds of the payment",
+                    "type": "number"
+                },
+                "split_id": {
+                    "description": "SplitID groups the parts of the split payment, which pays the invoice with several credit cards",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.PayInvoiceWithSplitPaymentRequest": {
+            "type": "object",
+            "required": [
+                "parts"
+            ],
+            "properties": {
+                "parts": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "minItems": 2,
+                    "items": {
+                        "$ref": "#/definitions/handler.SplitPaymentPart"
+                    }
+                }
+            }
+        },
+        "handler.PayInvoiceWithSplitPaymentResponse": {
+            "type": "object",
+            "properties": {
+                "failure_message": {
+                    "type": "string"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Payment"
+                    }
+                },
+                "split_id": {
                     "type": "string"
                 },
-                "updated_at": {
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "datastore.Payment": {
+        "handler.PaymentHistory": {
             "type": "object",
             "properties": {
                 "amount": {
                     "type": "number"
                 },
+                "appointment": {
+                    "description": "Appointment is null if the invoice of the payment isn't found in the hospital system",
+                    "$ref": "#/definitions/hospital.AppointmentOverview"
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -968,6 +2612,9 @@ const docTemplate = `{
                 "credit_card_id": {
                     "type": "integer"
                 },
+                "expires_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -980,6 +2627,17 @@ const docTemplate = `{
                 "paid_at": {
                     "type": "string"
                 },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "refunded_amount": {
+                    "description": "RefundedAmount is the total amount of the refunds of the payment",
+                    "type": "number"
+                },
+                "split_id": {
+                    "description": "SplitID groups the parts of the split payment, which pays the invoice with several credit cards",
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 },
@@ -988,144 +2646,101 @@ const docTemplate = `{
                 }
             }
         },
-        "gorm.DeletedAt": {
-            "type": "object",
-            "properties": {
-                "time": {
-                    "type": "string"
-                },
-                "valid": {
-                    "description": "Valid is true if Time is not NULL",
-                    "type": "boolean"
-                }
-            }
-        },
-        "handler.AddCreditCardRequest": {
+        "handler.RefreshTokenRequest": {
             "type": "object",
             "required": [
-                "card_token"
+                "refresh_token"
             ],
             "properties": {
-                "card_token": {
-                    "type": "string"
-                },
-                "is_default": {
-                    "type": "boolean"
-                },
-                "name": {
+                "refresh_token": {
                     "type": "string"
                 }
             }
         },
-        "handler.CountUnReadNotificationResponse": {
-            "type": "object",
-            "properties": {
-                "count": {
-                    "type": "integer"
-                }
-            }
-        },
-        "handler.GetAppointmentResponse": {
+        "handler.RescheduleAppointmentRequest": {
             "type": "object",
+            "required": [
+                "end_date_time",
+                "start_date_time"
+            ],
             "properties": {
-                "detail": {
-                    "type": "string"
-                },
-                "doctor": {
-                    "$ref": "#/definitions/hospital.DoctorOverview"
-                },
-                "duration": {
-                    "type": "number"
-                },
                 "end_date_time": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
-                },
-                "invoice": {
-                    "$ref": "#/definitions/hospital.Invoice"
-                },
-                "next_appointment": {
-                    "type": "string"
-                },
-                "patient_id": {
-                    "type": "string"
-                },
-                "payment": {
-                    "$ref": "#/definitions/datastore.Payment"
-                },
-                "prescriptions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/hospital.Prescription"
-                    }
-                },
                 "start_date_time": {
                     "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                }
-            }
-        },
-        "handler.GetAppointmentRoomIDResponse": {
-            "type": "object",
-            "properties": {
-                "room_id": {
-                    "type": "string"
                 }
             }
         },
-        "handler.GetNameResponse": {
+        "handler.ResendOTPRequest": {
             "type": "object",
+            "required": [
+                "signin_id"
+            ],
             "properties": {
-                "EN": {
-                    "$ref": "#/definitions/hospital.Name"
+                "channel": {
+                    "description": "Channel is where the OTP is resent to, e.g. email when the SMS is delayed. The preferred channel is used if it's empty",
+                    "type": "string",
+                    "enum": [
+                        "sms",
+                        "email",
+                        "line"
+                    ]
                 },
-                "TH": {
-                    "$ref": "#/definitions/hospital.Name"
+                "signin_id": {
+                    "type": "string"
                 }
             }
         },
-        "handler.PayInvoiceWithCreditCardResponse": {
+        "handler.SavedCreditCard": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number"
+                "brand": {
+                    "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "credit_card": {
-                    "$ref": "#/definitions/datastore.CreditCard"
-                },
-                "credit_card_id": {
+                "expiry_month": {
                     "type": "integer"
                 },
-                "failure_message": {
-                    "type": "string"
+                "expiry_year": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "invoice_id": {
-                    "type": "integer"
+                "is_default": {
+                    "type": "boolean"
                 },
-                "method": {
-                    "type": "string"
+                "is_expired": {
+                    "type": "boolean"
                 },
-                "paid_at": {
+                "last_4_digits": {
                     "type": "string"
                 },
-                "status": {
+                "name": {
                     "type": "string"
                 },
+                "patient_id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "handler.SetAutopayRequest": {
+            "type": "object",
+            "required": [
+                "enabled"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
         "handler.SetCreditCardIsDefaultRequest": {
             "type": "object",
             "properties": {
@@ -1145,6 +2760,29 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.SetOTPChannelRequest": {
+            "type": "object",
+            "required": [
+                "channel"
+            ],
+            "properties": {
+                "channel": {
+                    "description": "Channel is where the OTP is resent to, e.g. email when the SMS is delayed. The preferred channel is used if it's empty",
+                    "type": "string",
+                    "enum": [
+                        "sms",
+                        "email",
+                        "line"
+                    ]
+                },
+                "email": {
+                    "type": "string"
+                },
+                "line_user_id": {
+                    "type": "string"
+                }
+            }
+        },
         "handler.SigninRequest": {
             "type": "object",
             "required": [
@@ -1159,33 +2797,122 @@ const docTemplate = `{
         "handler.SigninResponse": {
             "type": "object",
             "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "destination": {
+                    "description": "Destination is the censored address that the OTP is sent to. It's empty for LINE",
+                    "type": "string"
+                },
                 "expired_at": {
                     "type": "string"
                 },
                 "phone_number": {
                     "type": "string"
+                },
+                "signin_id": {
+                    "description": "SigninID is the opaque ID of the signin which must be sent with the OTP",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SplitPaymentPart": {
+            "type": "object",
+            "required": [
+                "amount",
+                "credit_card_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "credit_card_id": {
+                    "type": "integer"
                 }
             }
         },
         "handler.VerifyOTPRequest": {
             "type": "object",
             "required": [
-                "otp"
+                "otp",
+                "signin_id"
             ],
             "properties": {
                 "otp": {
                     "type": "string"
+                },
+                "signin_id": {
+                    "type": "string"
                 }
             }
         },
         "handler.VerifyOTPResponse": {
             "type": "object",
             "properties": {
+                "refresh_token": {
+                    "description": "RefreshToken is used once to get the new token and refresh token of the session",
+                    "type": "string"
+                },
+                "refresh_token_expired_at": {
+                    "type": "string"
+                },
                 "token": {
                     "type": "string"
                 }
             }
         },
+        "handler.WaitingRoomResponse": {
+            "type": "object",
+            "properties": {
+                "is_doctor_ready": {
+                    "type": "boolean"
+                },
+                "presence": {
+                    "$ref": "#/definitions/presence.State"
+                },
+                "room_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital.Appointment": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "doctor": {
+                    "$ref": "#/definitions/hospital.DoctorOverview"
+                },
+                "end_date_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice": {
+                    "$ref": "#/definitions/hospital.Invoice"
+                },
+                "next_appointment": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "prescriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital.Prescription"
+                    }
+                },
+                "start_date_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "hospital.AppointmentOverview": {
             "type": "object",
             "properties": {
@@ -1227,6 +2954,12 @@ const docTemplate = `{
                         "$ref": "#/definitions/hospital.AppointmentOverview"
                     }
                 },
+                "no_show": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital.AppointmentOverview"
+                    }
+                },
                 "scheduled": {
                     "type": "array",
                     "items": {
@@ -1361,6 +3094,38 @@ const docTemplate = `{
                 }
             }
         },
+        "hospital.PatientInvoice": {
+            "type": "object",
+            "properties": {
+                "appointment_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital.InvoiceDiscount"
+                    }
+                },
+                "invoice_items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital.InvoiceItem"
+                    }
+                },
+                "paid": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
         "hospital.PatientOverview": {
             "type": "object",
             "properties": {
@@ -1392,6 +3157,21 @@ const docTemplate = `{
                 }
             }
         },
+        "presence.State": {
+            "type": "object",
+            "properties": {
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "WAITING",
+                        "ABSENT"
+                    ]
+                }
+            }
+        },
         "server.ErrorResponse": {
             "type": "object",
             "properties": {
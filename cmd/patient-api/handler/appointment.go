@@ -3,11 +3,14 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/synthia-telemed/backend-api/pkg/cache"
 	"github.com/synthia-telemed/backend-api/pkg/clock"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
 	"github.com/synthia-telemed/backend-api/pkg/server"
 	"go.uber.org/zap"
 	"net/http"
@@ -16,31 +19,51 @@ import (
 )
 
 var (
-	ErrAppointmentIDMissing = server.NewErrorResponse("Appointment ID is missing")
-	ErrAppointmentIDInvalid = server.NewErrorResponse("Invalid appointment ID")
-	ErrAppointmentNotFound  = server.NewErrorResponse("Appointment not found")
-	ErrForbidden            = server.NewErrorResponse("Forbidden")
-	ErrRoomIDNotFound       = server.NewErrorResponse("RoomID of the appointment not found")
+	ErrAppointmentIDMissing    = server.NewErrorResponse("Appointment ID is missing")
+	ErrAppointmentIDInvalid    = server.NewErrorResponse("Invalid appointment ID")
+	ErrAppointmentNotFound     = server.NewErrorResponse("Appointment not found")
+	ErrForbidden               = server.NewErrorResponse("Forbidden")
+	ErrRoomIDNotFound          = server.NewErrorResponse("RoomID of the appointment not found")
+	ErrInvalidAppointmentTime  = server.NewErrorResponse("Start time must be in the future and before end time")
+	ErrDoctorNotFound          = server.NewErrorResponse("Doctor not found")
+	ErrInvalidDoctorID         = server.NewErrorResponse("Invalid doctor ID")
+	ErrDoctorNotAvailable      = server.NewErrorResponse("Doctor is not available in the given time slot")
+	ErrPatientNotAvailable     = server.NewErrorResponse("Patient already has an appointment in the given time slot")
+	ErrAppointmentNotScheduled = server.NewErrorResponse("Only scheduled appointment can be changed")
+	ErrAppointmentChangeCutoff = server.NewErrorResponse("Appointment can't be changed because it is too close to the start time")
+	ErrWaitingRoomNotScheduled = server.NewErrorResponse("Appointment is not scheduled")
 )
 
 type AppointmentHandler struct {
 	patientDataStore     datastore.PatientDataStore
 	paymentDataStore     datastore.PaymentDataStore
 	appointmentDataStore datastore.AppointmentDataStore
+	doctorDataStore      datastore.DoctorDataStore
+	scheduleDataStore    datastore.ScheduleDataStore
 	hospitalClient       hospital.SystemClient
 	cacheClient          cache.Client
+	notificationClient   notification.Client
+	presenceTracker      presence.Tracker
 	clock                clock.Clock
+	changeCutoff         time.Duration
+	location             *time.Location
 	PatientGinHandler
 }
 
-func NewAppointmentHandler(patientDS datastore.PatientDataStore, paymentDS datastore.PaymentDataStore, appsDS datastore.AppointmentDataStore, hos hospital.SystemClient, cacheClient cache.Client, c clock.Clock, logger *zap.SugaredLogger) *AppointmentHandler {
+func NewAppointmentHandler(patientDS datastore.PatientDataStore, paymentDS datastore.PaymentDataStore, appsDS datastore.AppointmentDataStore, doctorDS datastore.DoctorDataStore, scheduleDS datastore.ScheduleDataStore, hos hospital.SystemClient, cacheClient cache.Client, noti notification.Client, presenceTracker presence.Tracker, c clock.Clock, changeCutoff time.Duration, loc *time.Location, logger *zap.SugaredLogger) *AppointmentHandler {
 	return &AppointmentHandler{
 		patientDataStore:     patientDS,
 		hospitalClient:       hos,
 		paymentDataStore:     paymentDS,
 		appointmentDataStore: appsDS,
+		doctorDataStore:      doctorDS,
+		scheduleDataStore:    scheduleDS,
 		cacheClient:          cacheClient,
+		notificationClient:   noti,
+		presenceTracker:      presenceTracker,
 		clock:                c,
+		changeCutoff:         changeCutoff,
+		location:             loc,
 		PatientGinHandler:    NewPatientGinHandler(patientDS, logger),
 	}
 }
@@ -48,9 +71,15 @@ func NewAppointmentHandler(patientDS datastore.PatientDataStore, paymentDS datas
 func (h AppointmentHandler) Register(r *gin.RouterGroup) {
 	g := r.Group("/appointment", h.ParseUserID, h.ParsePatient)
 	g.GET("", h.ListAppointments)
+	g.POST("", h.CreateAppointment)
+	g.GET("/doctor/available", h.ListAvailableDoctors)
 	g.GET("/next", h.GetNextScheduledAppointment)
 	g.GET("/:appointmentID", h.AuthorizedPatientToAppointment, h.GetAppointment)
 	g.GET("/:appointmentID/roomID", h.AuthorizedPatientToAppointment, h.GetAppointmentRoomID)
+	g.POST("/:appointmentID/waiting-room", h.AuthorizedPatientToAppointment, h.CheckInWaitingRoom)
+	g.DELETE("/:appointmentID/waiting-room", h.AuthorizedPatientToAppointment, h.CheckOutWaitingRoom)
+	g.DELETE("/:appointmentID", h.AuthorizedPatientToAppointment, h.CanChangeAppointment, h.CancelAppointment)
+	g.PATCH("/:appointmentID/reschedule", h.AuthorizedPatientToAppointment, h.CanChangeAppointment, h.RescheduleAppointment)
 }
 
 // GetNextScheduledAppointment godoc
@@ -115,7 +144,174 @@ func (h AppointmentHandler) ListAppointments(c *gin.Context) {
 		h.InternalServerError(c, err, "h.hospitalClient.ListAppointmentsByPatientID error")
 		return
 	}
-	c.JSON(http.StatusOK, h.hospitalClient.CategorizeAppointmentByStatus(apps))
+	categorized := h.hospitalClient.CategorizeAppointmentByStatus(apps)
+	cancelledIDs := make([]string, len(categorized.Cancelled))
+	for i, a := range categorized.Cancelled {
+		cancelledIDs[i] = a.Id
+	}
+	noShowIDs, err := h.appointmentDataStore.ListRefIDsByOutcome(cancelledIDs, datastore.NoShowAppointmentOutcome)
+	if err != nil {
+		h.InternalServerError(c, err, "h.appointmentDataStore.ListRefIDsByOutcome error")
+		return
+	}
+	categorized.SeparateNoShow(noShowIDs)
+	c.JSON(http.StatusOK, categorized)
+}
+
+type ListAvailableDoctorsRequest struct {
+	StartDateTime time.Time `json:"start_date_time" form:"start_date_time" binding:"required"`
+	EndDateTime   time.Time `json:"end_date_time" form:"end_date_time" binding:"required"`
+}
+
+// ListAvailableDoctors godoc
+// @Summary      Get list of doctors who are available in the given time slot
+// @Tags         Appointment
+// @Param 	  	 ListAvailableDoctorsRequest query ListAvailableDoctorsRequest true "Time slot to search for available doctors"
+// @Success      200  {array}	hospital.DoctorOverview "List of available doctors"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse "Start time must be in the future and before end time"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/doctor/available [get]
+func (h AppointmentHandler) ListAvailableDoctors(c *gin.Context) {
+	var req ListAvailableDoctorsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	if !h.isValidAppointmentTime(req.StartDateTime, req.EndDateTime) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidAppointmentTime)
+		return
+	}
+	doctors, err := h.hospitalClient.ListAvailableDoctors(context.Background(), req.StartDateTime, req.EndDateTime)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.ListAvailableDoctors error")
+		return
+	}
+	available := make([]*hospital.DoctorOverview, 0, len(doctors))
+	for _, doctor := range doctors {
+		inSchedule, err := h.isInDoctorSchedule(doctor.ID, req.StartDateTime, req.EndDateTime)
+		if err != nil {
+			h.InternalServerError(c, err, "h.isInDoctorSchedule error")
+			return
+		}
+		if inSchedule {
+			available = append(available, doctor)
+		}
+	}
+	c.JSON(http.StatusOK, available)
+}
+
+type CreateAppointmentRequest struct {
+	StartDateTime time.Time `json:"start_date_time" binding:"required"`
+	EndDateTime   time.Time `json:"end_date_time" binding:"required"`
+	DoctorID      string    `json:"doctor_id" binding:"required"`
+	Detail        string    `json:"detail" binding:"required"`
+}
+
+// CreateAppointment godoc
+// @Summary      Book an appointment with the doctor
+// @Tags         Appointment
+// @Param 	  	 CreateAppointmentRequest body CreateAppointmentRequest true "Doctor ID, time slot and detail of the appointment"
+// @Success      201  {object}	hospital.Appointment "Created appointment"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse "Invalid doctor ID"
+// @Failure      400  {object}  server.ErrorResponse "Start time must be in the future and before end time"
+// @Failure      400  {object}  server.ErrorResponse "Doctor is not available in the given time slot"
+// @Failure      400  {object}  server.ErrorResponse "Patient already has an appointment in the given time slot"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      404  {object}  server.ErrorResponse "Doctor not found"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment [post]
+func (h AppointmentHandler) CreateAppointment(c *gin.Context) {
+	rawPatient, _ := c.Get("Patient")
+	patient := rawPatient.(*datastore.Patient)
+	var req CreateAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	if _, err := strconv.ParseInt(req.DoctorID, 10, 32); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidDoctorID)
+		return
+	}
+	if !h.isValidAppointmentTime(req.StartDateTime, req.EndDateTime) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidAppointmentTime)
+		return
+	}
+	ctx := context.Background()
+	doctor, err := h.hospitalClient.FindDoctorByID(ctx, req.DoctorID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.FindDoctorByID error")
+		return
+	}
+	if doctor == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrDoctorNotFound)
+		return
+	}
+	inSchedule, err := h.isInDoctorSchedule(req.DoctorID, req.StartDateTime, req.EndDateTime)
+	if err != nil {
+		h.InternalServerError(c, err, "h.isInDoctorSchedule error")
+		return
+	}
+	if !inSchedule {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotAvailable)
+		return
+	}
+	isDoctorAvailable, err := h.hospitalClient.IsDoctorAvailable(ctx, req.DoctorID, req.StartDateTime, req.EndDateTime)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.IsDoctorAvailable error")
+		return
+	}
+	if !isDoctorAvailable {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotAvailable)
+		return
+	}
+	isPatientAvailable, err := h.hospitalClient.IsPatientAvailable(ctx, patient.RefID, req.StartDateTime, req.EndDateTime)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.IsPatientAvailable error")
+		return
+	}
+	if !isPatientAvailable {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrPatientNotAvailable)
+		return
+	}
+	appointment, err := h.hospitalClient.CreateAppointment(ctx, &hospital.CreateAppointmentParams{
+		PatientID:     patient.RefID,
+		DoctorID:      req.DoctorID,
+		StartDateTime: req.StartDateTime,
+		EndDateTime:   req.EndDateTime,
+		Detail:        req.Detail,
+	})
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.CreateAppointment error")
+		return
+	}
+	c.JSON(http.StatusCreated, appointment)
+}
+
+func (h AppointmentHandler) isValidAppointmentTime(start, end time.Time) bool {
+	return start.After(h.clock.Now()) && end.After(start)
+}
+
+// isInDoctorSchedule checks the time range against the working schedule of the doctor.
+// The doctor who has never signed in has no schedule, so only the hospital availability applies.
+func (h AppointmentHandler) isInDoctorSchedule(doctorRefID string, start, end time.Time) (bool, error) {
+	doctor, err := h.doctorDataStore.FindByRefID(doctorRefID)
+	if err != nil || doctor == nil {
+		return err == nil, err
+	}
+	schedule, err := h.scheduleDataStore.FindByDoctorID(doctor.ID)
+	if err != nil {
+		return false, err
+	}
+	return schedule.IsAvailable(start, end, h.location), nil
 }
 
 type GetAppointmentResponse struct {
@@ -200,6 +396,213 @@ func (h AppointmentHandler) GetAppointmentRoomID(c *gin.Context) {
 	c.JSON(http.StatusOK, &GetAppointmentRoomIDResponse{RoomID: roomID})
 }
 
+type WaitingRoomResponse struct {
+	Presence      *presence.State `json:"presence"`
+	IsDoctorReady bool            `json:"is_doctor_ready"`
+	RoomID        string          `json:"room_id,omitempty"`
+}
+
+// CheckInWaitingRoom godoc
+// @Summary      Check in to the waiting room of the appointment
+// @Description  The check-in expires after a short period, so the client should call this endpoint periodically as a heartbeat while the patient is waiting.
+// @Description  The response tells whether the doctor has opened the room, and the room ID if so.
+// @Tags         Appointment
+// @Param  		 appointmentID 	path	 integer 	true "ID of the appointment"
+// @Success      200  {object}	WaitingRoomResponse "Presence of the patient and readiness of the doctor"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is not provided"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is invalid"
+// @Failure      400  {object}  server.ErrorResponse "Appointment is not scheduled"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse "The patient doesn't own the appointment"
+// @Failure      404  {object}  server.ErrorResponse "Appointment not found"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/{appointmentID}/waiting-room [post]
+func (h AppointmentHandler) CheckInWaitingRoom(c *gin.Context) {
+	rawAppointment, _ := c.Get("Appointment")
+	appointment, _ := rawAppointment.(*hospital.Appointment)
+	if appointment.Status != hospital.AppointmentStatusScheduled {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrWaitingRoomNotScheduled)
+		return
+	}
+	ctx := context.Background()
+	state, err := h.presenceTracker.CheckIn(ctx, appointment.Id)
+	if err != nil {
+		h.InternalServerError(c, err, "h.presenceTracker.CheckIn error")
+		return
+	}
+	roomID, err := h.cacheClient.Get(ctx, cache.AppointmentRoomIDKey(appointment.Id), false)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Get error")
+		return
+	}
+	c.JSON(http.StatusOK, &WaitingRoomResponse{
+		Presence:      state,
+		IsDoctorReady: roomID != "",
+		RoomID:        roomID,
+	})
+}
+
+// CheckOutWaitingRoom godoc
+// @Summary      Check out from the waiting room of the appointment
+// @Tags         Appointment
+// @Param  		 appointmentID 	path	 integer 	true "ID of the appointment"
+// @Success      200  "Patient left the waiting room"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is not provided"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is invalid"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse "The patient doesn't own the appointment"
+// @Failure      404  {object}  server.ErrorResponse "Appointment not found"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/{appointmentID}/waiting-room [delete]
+func (h AppointmentHandler) CheckOutWaitingRoom(c *gin.Context) {
+	rawAppointment, _ := c.Get("Appointment")
+	appointment, _ := rawAppointment.(*hospital.Appointment)
+	if err := h.presenceTracker.CheckOut(context.Background(), appointment.Id); err != nil {
+		h.InternalServerError(c, err, "h.presenceTracker.CheckOut error")
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// CancelAppointment godoc
+// @Summary      Cancel the scheduled appointment
+// @Tags         Appointment
+// @Param  		 appointmentID 	path	 integer 	true "ID of the appointment"
+// @Success      200  "Appointment is cancelled"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is not provided"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is invalid"
+// @Failure      400  {object}  server.ErrorResponse "Only scheduled appointment can be changed"
+// @Failure      400  {object}  server.ErrorResponse "Appointment can't be changed because it is too close to the start time"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse "The patient doesn't own the appointment"
+// @Failure      404  {object}  server.ErrorResponse "Appointment not found"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/{appointmentID} [delete]
+func (h AppointmentHandler) CancelAppointment(c *gin.Context) {
+	rawAppointment, _ := c.Get("Appointment")
+	appointment, _ := rawAppointment.(*hospital.Appointment)
+	appointmentID, _ := strconv.Atoi(appointment.Id)
+	ctx := context.Background()
+	if err := h.hospitalClient.SetAppointmentStatus(ctx, appointmentID, hospital.SettableAppointmentStatusCancelled); err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.SetAppointmentStatus error")
+		return
+	}
+	c.Status(http.StatusOK)
+
+	body := fmt.Sprintf("The appointment on %s has been cancelled by the patient", appointment.StartDateTime.Format(time.RFC1123))
+	h.notifyDoctor(c, appointment.Doctor.ID, "Appointment cancelled", body, appointment.Id)
+}
+
+type RescheduleAppointmentRequest struct {
+	StartDateTime time.Time `json:"start_date_time" binding:"required"`
+	EndDateTime   time.Time `json:"end_date_time" binding:"required"`
+}
+
+// RescheduleAppointment godoc
+// @Summary      Move the scheduled appointment to another time slot
+// @Description  The original appointment is cancelled and a new appointment with the same doctor and detail is created
+// @Tags         Appointment
+// @Param  		 appointmentID 	path	 integer 	true "ID of the appointment"
+// @Param 	  	 RescheduleAppointmentRequest body RescheduleAppointmentRequest true "New time slot of the appointment"
+// @Success      200  {object}	hospital.Appointment "The rescheduled appointment"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is not provided"
+// @Failure      400  {object}  server.ErrorResponse "appointmentID is invalid"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse "Only scheduled appointment can be changed"
+// @Failure      400  {object}  server.ErrorResponse "Appointment can't be changed because it is too close to the start time"
+// @Failure      400  {object}  server.ErrorResponse "Start time must be in the future and before end time"
+// @Failure      400  {object}  server.ErrorResponse "Doctor is not available in the given time slot"
+// @Failure      400  {object}  server.ErrorResponse "Patient already has an appointment in the given time slot"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse "The patient doesn't own the appointment"
+// @Failure      404  {object}  server.ErrorResponse "Appointment not found"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /appointment/{appointmentID}/reschedule [patch]
+func (h AppointmentHandler) RescheduleAppointment(c *gin.Context) {
+	rawAppointment, _ := c.Get("Appointment")
+	appointment, _ := rawAppointment.(*hospital.Appointment)
+	var req RescheduleAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	if !h.isValidAppointmentTime(req.StartDateTime, req.EndDateTime) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidAppointmentTime)
+		return
+	}
+	inSchedule, err := h.isInDoctorSchedule(appointment.Doctor.ID, req.StartDateTime, req.EndDateTime)
+	if err != nil {
+		h.InternalServerError(c, err, "h.isInDoctorSchedule error")
+		return
+	}
+	if !inSchedule {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotAvailable)
+		return
+	}
+	appointmentID, _ := strconv.Atoi(appointment.Id)
+	ctx := context.Background()
+	isDoctorAvailable, err := h.hospitalClient.IsDoctorAvailable(ctx, appointment.Doctor.ID, req.StartDateTime, req.EndDateTime, appointmentID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.IsDoctorAvailable error")
+		return
+	}
+	if !isDoctorAvailable {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrDoctorNotAvailable)
+		return
+	}
+	isPatientAvailable, err := h.hospitalClient.IsPatientAvailable(ctx, appointment.PatientID, req.StartDateTime, req.EndDateTime, appointmentID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.IsPatientAvailable error")
+		return
+	}
+	if !isPatientAvailable {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrPatientNotAvailable)
+		return
+	}
+	newAppointment, err := h.hospitalClient.RescheduleAppointment(ctx, appointment, req.StartDateTime, req.EndDateTime)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalClient.RescheduleAppointment error")
+		return
+	}
+	c.JSON(http.StatusOK, newAppointment)
+
+	body := fmt.Sprintf("The appointment on %s has been rescheduled by the patient to %s", appointment.StartDateTime.Format(time.RFC1123), newAppointment.StartDateTime.Format(time.RFC1123))
+	h.notifyDoctor(c, appointment.Doctor.ID, "Appointment rescheduled", body, newAppointment.Id)
+}
+
+// notifyDoctor sends push notification to the doctor. The response is already written, so the error is only logged
+func (h AppointmentHandler) notifyDoctor(c *gin.Context, doctorRefID, title, body, appointmentID string) {
+	data := map[string]string{"appointmentID": appointmentID}
+	if err := notification.NotifyDoctor(context.Background(), h.notificationClient, h.doctorDataStore, doctorRefID, title, body, data); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "notification.NotifyDoctor error")
+	}
+}
+
+func (h AppointmentHandler) CanChangeAppointment(c *gin.Context) {
+	rawAppointment, _ := c.Get("Appointment")
+	appointment, _ := rawAppointment.(*hospital.Appointment)
+	if appointment.Status != hospital.AppointmentStatusScheduled {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrAppointmentNotScheduled)
+		return
+	}
+	if h.clock.Now().Add(h.changeCutoff).After(appointment.StartDateTime) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrAppointmentChangeCutoff)
+		return
+	}
+}
+
 func (h AppointmentHandler) AuthorizedPatientToAppointment(c *gin.Context) {
 	rawPatient, exist := c.Get("Patient")
 	if !exist {
@@ -1,6 +1,8 @@
 package handler_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,15 +15,21 @@ import (
 	"github.com/synthia-telemed/backend-api/pkg/cache"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
 	testhelper "github.com/synthia-telemed/backend-api/test/helper"
 	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
 	"github.com/synthia-telemed/backend-api/test/mock_clock"
 	"github.com/synthia-telemed/backend-api/test/mock_datastore"
 	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_notification"
+	"github.com/synthia-telemed/backend-api/test/mock_presence"
 	"go.uber.org/zap"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -36,9 +44,14 @@ var _ = Describe("Appointment Handler", func() {
 		mockPatientDataStore     *mock_datastore.MockPatientDataStore
 		mockPaymentDataStore     *mock_datastore.MockPaymentDataStore
 		mockAppointmentDataStore *mock_datastore.MockAppointmentDataStore
+		mockDoctorDataStore      *mock_datastore.MockDoctorDataStore
+		mockScheduleDataStore    *mock_datastore.MockScheduleDataStore
 		mockHospitalSysClient    *mock_hospital_client.MockSystemClient
 		mockCacheClient          *mock_cache_client.MockClient
+		mockNotificationClient   *mock_notification.MockClient
+		mockPresenceTracker      *mock_presence.MockTracker
 		mockClock                *mock_clock.MockClock
+		changeCutoff             time.Duration
 
 		patient *datastore.Patient
 	)
@@ -48,10 +61,15 @@ var _ = Describe("Appointment Handler", func() {
 		mockPatientDataStore = mock_datastore.NewMockPatientDataStore(mockCtrl)
 		mockPaymentDataStore = mock_datastore.NewMockPaymentDataStore(mockCtrl)
 		mockAppointmentDataStore = mock_datastore.NewMockAppointmentDataStore(mockCtrl)
+		mockDoctorDataStore = mock_datastore.NewMockDoctorDataStore(mockCtrl)
+		mockScheduleDataStore = mock_datastore.NewMockScheduleDataStore(mockCtrl)
 		mockHospitalSysClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
 		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
+		mockNotificationClient = mock_notification.NewMockClient(mockCtrl)
+		mockPresenceTracker = mock_presence.NewMockTracker(mockCtrl)
 		mockClock = mock_clock.NewMockClock(mockCtrl)
-		h = handler.NewAppointmentHandler(mockPatientDataStore, mockPaymentDataStore, mockAppointmentDataStore, mockHospitalSysClient, mockCacheClient, mockClock, zap.NewNop().Sugar())
+		changeCutoff = time.Hour * 24
+		h = handler.NewAppointmentHandler(mockPatientDataStore, mockPaymentDataStore, mockAppointmentDataStore, mockDoctorDataStore, mockScheduleDataStore, mockHospitalSysClient, mockCacheClient, mockNotificationClient, mockPresenceTracker, mockClock, changeCutoff, time.UTC, zap.NewNop().Sugar())
 		patient = testhelper.GeneratePatient()
 		c.Set("Patient", patient)
 	})
@@ -122,13 +140,41 @@ var _ = Describe("Appointment Handler", func() {
 				}
 				mockHospitalSysClient.EXPECT().CategorizeAppointmentByStatus(appointments).Return(categorized)
 			})
-			It("should return 200 with list of appointments group by status", func() {
-				Expect(rec.Code).To(Equal(http.StatusOK))
-				var res hospital.CategorizedAppointment
-				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
-				Expect(res.Completed).To(HaveLen(n))
-				Expect(res.Cancelled).To(HaveLen(n))
-				Expect(res.Scheduled).To(HaveLen(n))
+			When("list no-show appointments error", func() {
+				BeforeEach(func() {
+					mockAppointmentDataStore.EXPECT().ListRefIDsByOutcome(gomock.Any(), datastore.NoShowAppointmentOutcome).Return(nil, testhelper.MockError).Times(1)
+				})
+				It("should return 500", func() {
+					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+				})
+			})
+			When("no appointment is no-show", func() {
+				BeforeEach(func() {
+					mockAppointmentDataStore.EXPECT().ListRefIDsByOutcome(gomock.Any(), datastore.NoShowAppointmentOutcome).Return([]string{}, nil).Times(1)
+				})
+				It("should return 200 with list of appointments group by status", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+					var res hospital.CategorizedAppointment
+					Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+					Expect(res.Completed).To(HaveLen(n))
+					Expect(res.Cancelled).To(HaveLen(n))
+					Expect(res.Scheduled).To(HaveLen(n))
+					Expect(res.NoShow).To(BeEmpty())
+				})
+			})
+			When("some cancelled appointments are no-show", func() {
+				BeforeEach(func() {
+					cancelledIDs := []string{cancelled[0].Id, cancelled[1].Id, cancelled[2].Id}
+					mockAppointmentDataStore.EXPECT().ListRefIDsByOutcome(cancelledIDs, datastore.NoShowAppointmentOutcome).Return([]string{cancelled[0].Id}, nil).Times(1)
+				})
+				It("should return 200 with no-show appointments separated from cancelled", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+					var res hospital.CategorizedAppointment
+					Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+					Expect(res.Cancelled).To(HaveLen(n - 1))
+					Expect(res.NoShow).To(HaveLen(1))
+					Expect(res.NoShow[0].Id).To(Equal(cancelled[0].Id))
+				})
 			})
 		})
 	})
@@ -351,6 +397,95 @@ var _ = Describe("Appointment Handler", func() {
 		})
 	})
 
+	Context("CheckInWaitingRoom", func() {
+		var appointment *hospital.Appointment
+		BeforeEach(func() {
+			handlerFunc = h.CheckInWaitingRoom
+			appointment, _ = testhelper.GenerateAppointment(patient.RefID, "", hospital.AppointmentStatusScheduled, false)
+			c.Set("Appointment", appointment)
+		})
+		When("appointment is not scheduled", func() {
+			BeforeEach(func() {
+				appointment.Status = hospital.AppointmentStatusCompleted
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrWaitingRoomNotScheduled)
+			})
+		})
+		When("check in error", func() {
+			BeforeEach(func() {
+				mockPresenceTracker.EXPECT().CheckIn(gomock.Any(), appointment.Id).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("get roomID from cache error", func() {
+			BeforeEach(func() {
+				mockPresenceTracker.EXPECT().CheckIn(gomock.Any(), appointment.Id).Return(&presence.State{Status: presence.StatusWaiting}, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.AppointmentRoomIDKey(appointment.Id), false).Return("", testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("doctor hasn't opened the room", func() {
+			BeforeEach(func() {
+				mockPresenceTracker.EXPECT().CheckIn(gomock.Any(), appointment.Id).Return(&presence.State{Status: presence.StatusWaiting}, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.AppointmentRoomIDKey(appointment.Id), false).Return("", nil).Times(1)
+			})
+			It("should return 200 with doctor not ready", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.WaitingRoomResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Presence.Status).To(Equal(presence.StatusWaiting))
+				Expect(res.IsDoctorReady).To(BeFalse())
+				Expect(res.RoomID).To(BeEmpty())
+			})
+		})
+		When("doctor has opened the room", func() {
+			var roomID string
+			BeforeEach(func() {
+				roomID = uuid.NewString()
+				mockPresenceTracker.EXPECT().CheckIn(gomock.Any(), appointment.Id).Return(&presence.State{Status: presence.StatusWaiting}, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cache.AppointmentRoomIDKey(appointment.Id), false).Return(roomID, nil).Times(1)
+			})
+			It("should return 200 with doctor ready and roomID", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.WaitingRoomResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.IsDoctorReady).To(BeTrue())
+				Expect(res.RoomID).To(Equal(roomID))
+			})
+		})
+	})
+
+	Context("CheckOutWaitingRoom", func() {
+		var appointment *hospital.Appointment
+		BeforeEach(func() {
+			handlerFunc = h.CheckOutWaitingRoom
+			appointment, _ = testhelper.GenerateAppointment(patient.RefID, "", hospital.AppointmentStatusScheduled, false)
+			c.Set("Appointment", appointment)
+		})
+		When("check out error", func() {
+			BeforeEach(func() {
+				mockPresenceTracker.EXPECT().CheckOut(gomock.Any(), appointment.Id).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("check out success", func() {
+			BeforeEach(func() {
+				mockPresenceTracker.EXPECT().CheckOut(gomock.Any(), appointment.Id).Return(nil).Times(1)
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
 	Context("GetNextScheduledAppointment", func() {
 		var (
 			appointment *hospital.AppointmentOverview
@@ -412,4 +547,525 @@ var _ = Describe("Appointment Handler", func() {
 		})
 	})
 
+	Context("ListAvailableDoctors", func() {
+		var (
+			now     time.Time
+			start   time.Time
+			end     time.Time
+			doctors []*hospital.DoctorOverview
+		)
+		BeforeEach(func() {
+			handlerFunc = h.ListAvailableDoctors
+			now = time.Now().UTC()
+			start = now.Add(time.Hour).Truncate(time.Second)
+			end = start.Add(time.Minute * 30)
+			doctors = []*hospital.DoctorOverview{{ID: "1", FullName: uuid.NewString()}, {ID: "2", FullName: uuid.NewString()}}
+			c.Request = httptest.NewRequest("GET", fmt.Sprintf("/?start_date_time=%s&end_date_time=%s", url.QueryEscape(start.Format(time.RFC3339)), url.QueryEscape(end.Format(time.RFC3339))), nil)
+		})
+
+		When("time slot is not provided", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/", nil)
+			})
+			It("should return 400 with ErrInvalidRequestBody", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("start time is in the past", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(start.Add(time.Minute)).Times(1)
+			})
+			It("should return 400 with ErrInvalidAppointmentTime", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidAppointmentTime)
+			})
+		})
+		When("list available doctors error", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockHospitalSysClient.EXPECT().ListAvailableDoctors(gomock.Any(), start, end).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("check doctor schedule error", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockHospitalSysClient.EXPECT().ListAvailableDoctors(gomock.Any(), start, end).Return(doctors, nil).Times(1)
+				mockDoctorDataStore.EXPECT().FindByRefID(doctors[0].ID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				doctorOnLeave := testhelper.GenerateDoctor()
+				doctorOnLeave.RefID = doctors[1].ID
+				schedule := &datastore.DoctorSchedule{LeaveDays: []datastore.LeaveDay{{Date: start}}}
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockHospitalSysClient.EXPECT().ListAvailableDoctors(gomock.Any(), start, end).Return(doctors, nil).Times(1)
+				mockDoctorDataStore.EXPECT().FindByRefID(doctors[0].ID).Return(nil, nil).Times(1)
+				mockDoctorDataStore.EXPECT().FindByRefID(doctors[1].ID).Return(doctorOnLeave, nil).Times(1)
+				mockScheduleDataStore.EXPECT().FindByDoctorID(doctorOnLeave.ID).Return(schedule, nil).Times(1)
+			})
+			It("should return 200 with list of available doctors in their schedule", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res []*hospital.DoctorOverview
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res).To(Equal(doctors[:1]))
+			})
+		})
+	})
+
+	Context("CreateAppointment", func() {
+		var (
+			now             time.Time
+			req             *handler.CreateAppointmentRequest
+			appointment     *hospital.Appointment
+			scheduledDoctor *datastore.Doctor
+		)
+		BeforeEach(func() {
+			handlerFunc = h.CreateAppointment
+			now = time.Now().UTC()
+			req = &handler.CreateAppointmentRequest{
+				DoctorID:      fmt.Sprintf("%d", rand.Int31()),
+				StartDateTime: now.Add(time.Hour).Truncate(time.Second),
+				EndDateTime:   now.Add(time.Hour + time.Minute*30).Truncate(time.Second),
+				Detail:        uuid.NewString(),
+			}
+			appointment, _ = testhelper.GenerateAppointment(patient.RefID, req.DoctorID, hospital.AppointmentStatusScheduled, false)
+			scheduledDoctor = testhelper.GenerateDoctor()
+			scheduledDoctor.RefID = req.DoctorID
+			reqBody, err := json.Marshal(req)
+			Expect(err).To(BeNil())
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewReader(reqBody))
+		})
+
+		When("request body is invalid", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"doctor_id": "1"}`))
+			})
+			It("should return 400 with ErrInvalidRequestBody", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("doctor ID isn't numeric", func() {
+			BeforeEach(func() {
+				req.DoctorID = "abc"
+				reqBody, err := json.Marshal(req)
+				Expect(err).To(BeNil())
+				c.Request = httptest.NewRequest("POST", "/", bytes.NewReader(reqBody))
+			})
+			It("should return 400 with ErrInvalidDoctorID", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidDoctorID)
+			})
+		})
+		When("end time is before start time", func() {
+			BeforeEach(func() {
+				req.EndDateTime = req.StartDateTime.Add(-time.Minute)
+				reqBody, err := json.Marshal(req)
+				Expect(err).To(BeNil())
+				c.Request = httptest.NewRequest("POST", "/", bytes.NewReader(reqBody))
+				mockClock.EXPECT().Now().Return(now).Times(1)
+			})
+			It("should return 400 with ErrInvalidAppointmentTime", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidAppointmentTime)
+			})
+		})
+		When("find doctor by ID error", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockHospitalSysClient.EXPECT().FindDoctorByID(gomock.Any(), req.DoctorID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("doctor is not found", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockHospitalSysClient.EXPECT().FindDoctorByID(gomock.Any(), req.DoctorID).Return(nil, nil).Times(1)
+			})
+			It("should return 404 with ErrDoctorNotFound", func() {
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDoctorNotFound)
+			})
+		})
+
+		When("doctor is found", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockHospitalSysClient.EXPECT().FindDoctorByID(gomock.Any(), req.DoctorID).Return(&hospital.Doctor{Id: req.DoctorID}, nil).Times(1)
+			})
+
+			When("check doctor schedule error", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(req.DoctorID).Return(nil, testhelper.MockError).Times(1)
+				})
+				It("should return 500", func() {
+					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+				})
+			})
+			When("time slot is outside the doctor's schedule", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(req.DoctorID).Return(scheduledDoctor, nil).Times(1)
+					mockScheduleDataStore.EXPECT().FindByDoctorID(scheduledDoctor.ID).Return(&datastore.DoctorSchedule{LeaveDays: []datastore.LeaveDay{{Date: req.StartDateTime}}}, nil).Times(1)
+				})
+				It("should return 400 with ErrDoctorNotAvailable", func() {
+					Expect(rec.Code).To(Equal(http.StatusBadRequest))
+					testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDoctorNotAvailable)
+				})
+			})
+
+			When("time slot is in the doctor's schedule", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(req.DoctorID).Return(scheduledDoctor, nil).Times(1)
+					mockScheduleDataStore.EXPECT().FindByDoctorID(scheduledDoctor.ID).Return(&datastore.DoctorSchedule{}, nil).Times(1)
+				})
+
+				When("check doctor availability error", func() {
+					BeforeEach(func() {
+						mockHospitalSysClient.EXPECT().IsDoctorAvailable(gomock.Any(), req.DoctorID, req.StartDateTime, req.EndDateTime).Return(false, testhelper.MockError).Times(1)
+					})
+					It("should return 500", func() {
+						Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+					})
+				})
+				When("doctor is not available", func() {
+					BeforeEach(func() {
+						mockHospitalSysClient.EXPECT().IsDoctorAvailable(gomock.Any(), req.DoctorID, req.StartDateTime, req.EndDateTime).Return(false, nil).Times(1)
+					})
+					It("should return 400 with ErrDoctorNotAvailable", func() {
+						Expect(rec.Code).To(Equal(http.StatusBadRequest))
+						testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDoctorNotAvailable)
+					})
+				})
+
+				When("doctor is available", func() {
+					BeforeEach(func() {
+						mockHospitalSysClient.EXPECT().IsDoctorAvailable(gomock.Any(), req.DoctorID, req.StartDateTime, req.EndDateTime).Return(true, nil).Times(1)
+					})
+
+					When("check patient availability error", func() {
+						BeforeEach(func() {
+							mockHospitalSysClient.EXPECT().IsPatientAvailable(gomock.Any(), patient.RefID, req.StartDateTime, req.EndDateTime).Return(false, testhelper.MockError).Times(1)
+						})
+						It("should return 500", func() {
+							Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+						})
+					})
+					When("patient is not available", func() {
+						BeforeEach(func() {
+							mockHospitalSysClient.EXPECT().IsPatientAvailable(gomock.Any(), patient.RefID, req.StartDateTime, req.EndDateTime).Return(false, nil).Times(1)
+						})
+						It("should return 400 with ErrPatientNotAvailable", func() {
+							Expect(rec.Code).To(Equal(http.StatusBadRequest))
+							testhelper.AssertErrorResponseBody(rec.Body, handler.ErrPatientNotAvailable)
+						})
+					})
+					When("create appointment error", func() {
+						BeforeEach(func() {
+							mockHospitalSysClient.EXPECT().IsPatientAvailable(gomock.Any(), patient.RefID, req.StartDateTime, req.EndDateTime).Return(true, nil).Times(1)
+							mockHospitalSysClient.EXPECT().CreateAppointment(gomock.Any(), gomock.Any()).Return(nil, testhelper.MockError).Times(1)
+						})
+						It("should return 500", func() {
+							Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+						})
+					})
+					When("no error occurred", func() {
+						BeforeEach(func() {
+							params := &hospital.CreateAppointmentParams{
+								PatientID:     patient.RefID,
+								DoctorID:      req.DoctorID,
+								StartDateTime: req.StartDateTime,
+								EndDateTime:   req.EndDateTime,
+								Detail:        req.Detail,
+							}
+							mockHospitalSysClient.EXPECT().IsPatientAvailable(gomock.Any(), patient.RefID, req.StartDateTime, req.EndDateTime).Return(true, nil).Times(1)
+							mockHospitalSysClient.EXPECT().CreateAppointment(gomock.Any(), params).Return(appointment, nil).Times(1)
+						})
+						It("should return 201 with created appointment", func() {
+							Expect(rec.Code).To(Equal(http.StatusCreated))
+							var res hospital.Appointment
+							Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+							Expect(res.Id).To(Equal(appointment.Id))
+						})
+					})
+				})
+			})
+		})
+	})
+
+	Context("CanChangeAppointment", func() {
+		var (
+			now         time.Time
+			appointment *hospital.Appointment
+		)
+		BeforeEach(func() {
+			handlerFunc = h.CanChangeAppointment
+			now = time.Now()
+			appointment, _ = testhelper.GenerateAppointment(patient.RefID, uuid.NewString(), hospital.AppointmentStatusScheduled, false)
+			appointment.StartDateTime = now.Add(changeCutoff + time.Hour)
+			c.Set("Appointment", appointment)
+		})
+
+		When("appointment is not scheduled", func() {
+			BeforeEach(func() {
+				appointment.Status = hospital.AppointmentStatusCompleted
+			})
+			It("should return 400 with ErrAppointmentNotScheduled", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrAppointmentNotScheduled)
+			})
+		})
+		When("appointment starts within the cutoff", func() {
+			BeforeEach(func() {
+				appointment.StartDateTime = now.Add(changeCutoff - time.Minute)
+				mockClock.EXPECT().Now().Return(now).Times(1)
+			})
+			It("should return 400 with ErrAppointmentChangeCutoff", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrAppointmentChangeCutoff)
+			})
+		})
+		When("appointment starts after the cutoff", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+			})
+			It("should not abort", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+			})
+		})
+	})
+
+	Context("CancelAppointment", func() {
+		var (
+			appointment   *hospital.Appointment
+			appointmentID int
+			doctor        *datastore.Doctor
+		)
+		BeforeEach(func() {
+			handlerFunc = h.CancelAppointment
+			doctor = testhelper.GenerateDoctor()
+			doctor.NotificationToken = "doctor-device-token"
+			appointment, appointmentID = testhelper.GenerateAppointment(patient.RefID, doctor.RefID, hospital.AppointmentStatusScheduled, false)
+			c.Set("Appointment", appointment)
+		})
+
+		When("set appointment status error", func() {
+			BeforeEach(func() {
+				mockHospitalSysClient.EXPECT().SetAppointmentStatus(gomock.Any(), appointmentID, hospital.SettableAppointmentStatusCancelled).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("appointment is cancelled", func() {
+			BeforeEach(func() {
+				mockHospitalSysClient.EXPECT().SetAppointmentStatus(gomock.Any(), appointmentID, hospital.SettableAppointmentStatusCancelled).Return(nil).Times(1)
+			})
+
+			When("find doctor by ref ID error", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(nil, testhelper.MockError).Times(1)
+				})
+				It("should return 200", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+				})
+			})
+			When("doctor has never signed in", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(nil, nil).Times(1)
+				})
+				It("should return 200 without sending notification", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+				})
+			})
+			When("doctor has no device token", func() {
+				BeforeEach(func() {
+					doctor.NotificationToken = ""
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(doctor, nil).Times(1)
+				})
+				It("should return 200 without sending notification", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+				})
+			})
+			When("send notification error", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(doctor, nil).Times(1)
+					mockNotificationClient.EXPECT().SendToDoctor(gomock.Any(), doctor.NotificationToken, gomock.Any(), gomock.Any()).Return(testhelper.MockError).Times(1)
+				})
+				It("should return 200", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+				})
+			})
+			When("no error occurred", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(doctor, nil).Times(1)
+					mockNotificationClient.EXPECT().SendToDoctor(gomock.Any(), doctor.NotificationToken, gomock.Any(), map[string]string{"appointmentID": appointment.Id}).DoAndReturn(func(_ context.Context, _ string, params notification.SendParams, _ map[string]string) error {
+						Expect(params.ID).To(Equal(fmt.Sprintf("%d", doctor.ID)))
+						return nil
+					}).Times(1)
+				})
+				It("should return 200 and notify the doctor", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+				})
+			})
+		})
+	})
+
+	Context("RescheduleAppointment", func() {
+		var (
+			now            time.Time
+			req            *handler.RescheduleAppointmentRequest
+			appointment    *hospital.Appointment
+			appointmentID  int
+			newAppointment *hospital.Appointment
+			doctor         *datastore.Doctor
+		)
+		BeforeEach(func() {
+			handlerFunc = h.RescheduleAppointment
+			now = time.Now().UTC()
+			doctor = testhelper.GenerateDoctor()
+			doctor.NotificationToken = "doctor-device-token"
+			appointment, appointmentID = testhelper.GenerateAppointment(patient.RefID, doctor.RefID, hospital.AppointmentStatusScheduled, false)
+			newAppointment, _ = testhelper.GenerateAppointment(patient.RefID, doctor.RefID, hospital.AppointmentStatusScheduled, false)
+			c.Set("Appointment", appointment)
+			req = &handler.RescheduleAppointmentRequest{
+				StartDateTime: now.Add(time.Hour * 48).Truncate(time.Second),
+				EndDateTime:   now.Add(time.Hour*48 + time.Minute*30).Truncate(time.Second),
+			}
+			reqBody, err := json.Marshal(req)
+			Expect(err).To(BeNil())
+			c.Request = httptest.NewRequest("PATCH", "/", bytes.NewReader(reqBody))
+		})
+
+		When("request body is invalid", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("PATCH", "/", nil)
+			})
+			It("should return 400 with ErrInvalidRequestBody", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("new start time is in the past", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(req.EndDateTime).Times(1)
+			})
+			It("should return 400 with ErrInvalidAppointmentTime", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidAppointmentTime)
+			})
+		})
+
+		When("new time slot is valid", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+			})
+
+			When("check doctor schedule error", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(nil, testhelper.MockError).Times(1)
+				})
+				It("should return 500", func() {
+					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+				})
+			})
+			When("new time slot is outside the doctor's schedule", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(doctor, nil).Times(1)
+					mockScheduleDataStore.EXPECT().FindByDoctorID(doctor.ID).Return(&datastore.DoctorSchedule{LeaveDays: []datastore.LeaveDay{{Date: req.StartDateTime}}}, nil).Times(1)
+				})
+				It("should return 400 with ErrDoctorNotAvailable", func() {
+					Expect(rec.Code).To(Equal(http.StatusBadRequest))
+					testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDoctorNotAvailable)
+				})
+			})
+
+			When("new time slot is in the doctor's schedule", func() {
+				BeforeEach(func() {
+					mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(doctor, nil).Times(1)
+					mockScheduleDataStore.EXPECT().FindByDoctorID(doctor.ID).Return(&datastore.DoctorSchedule{}, nil).Times(1)
+				})
+
+				When("check doctor availability error", func() {
+					BeforeEach(func() {
+						mockHospitalSysClient.EXPECT().IsDoctorAvailable(gomock.Any(), doctor.RefID, req.StartDateTime, req.EndDateTime, appointmentID).Return(false, testhelper.MockError).Times(1)
+					})
+					It("should return 500", func() {
+						Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+					})
+				})
+				When("doctor is not available", func() {
+					BeforeEach(func() {
+						mockHospitalSysClient.EXPECT().IsDoctorAvailable(gomock.Any(), doctor.RefID, req.StartDateTime, req.EndDateTime, appointmentID).Return(false, nil).Times(1)
+					})
+					It("should return 400 with ErrDoctorNotAvailable", func() {
+						Expect(rec.Code).To(Equal(http.StatusBadRequest))
+						testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDoctorNotAvailable)
+					})
+				})
+
+				When("doctor is available", func() {
+					BeforeEach(func() {
+						mockHospitalSysClient.EXPECT().IsDoctorAvailable(gomock.Any(), doctor.RefID, req.StartDateTime, req.EndDateTime, appointmentID).Return(true, nil).Times(1)
+					})
+
+					When("check patient availability error", func() {
+						BeforeEach(func() {
+							mockHospitalSysClient.EXPECT().IsPatientAvailable(gomock.Any(), patient.RefID, req.StartDateTime, req.EndDateTime, appointmentID).Return(false, testhelper.MockError).Times(1)
+						})
+						It("should return 500", func() {
+							Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+						})
+					})
+					When("patient is not available", func() {
+						BeforeEach(func() {
+							mockHospitalSysClient.EXPECT().IsPatientAvailable(gomock.Any(), patient.RefID, req.StartDateTime, req.EndDateTime, appointmentID).Return(false, nil).Times(1)
+						})
+						It("should return 400 with ErrPatientNotAvailable", func() {
+							Expect(rec.Code).To(Equal(http.StatusBadRequest))
+							testhelper.AssertErrorResponseBody(rec.Body, handler.ErrPatientNotAvailable)
+						})
+					})
+
+					When("patient is available", func() {
+						BeforeEach(func() {
+							mockHospitalSysClient.EXPECT().IsPatientAvailable(gomock.Any(), patient.RefID, req.StartDateTime, req.EndDateTime, appointmentID).Return(true, nil).Times(1)
+						})
+
+						When("reschedule appointment error", func() {
+							BeforeEach(func() {
+								mockHospitalSysClient.EXPECT().RescheduleAppointment(gomock.Any(), appointment, req.StartDateTime, req.EndDateTime).Return(nil, testhelper.MockError).Times(1)
+							})
+							It("should return 500", func() {
+								Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+							})
+						})
+						When("no error occurred", func() {
+							BeforeEach(func() {
+								mockHospitalSysClient.EXPECT().RescheduleAppointment(gomock.Any(), appointment, req.StartDateTime, req.EndDateTime).Return(newAppointment, nil).Times(1)
+								mockDoctorDataStore.EXPECT().FindByRefID(doctor.RefID).Return(doctor, nil).Times(1)
+								mockNotificationClient.EXPECT().SendToDoctor(gomock.Any(), doctor.NotificationToken, gomock.Any(), map[string]string{"appointmentID": newAppointment.Id}).Return(nil).Times(1)
+							})
+							It("should return 200 with the new appointment and notify the doctor", func() {
+								Expect(rec.Code).To(Equal(http.StatusOK))
+								var res hospital.Appointment
+								Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+								Expect(res.Id).To(Equal(newAppointment.Id))
+							})
+						})
+					})
+				})
+			})
+		})
+	})
 })
@@ -2,45 +2,84 @@ package handler
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	gonanoid "github.com/matoous/go-nanoid"
 	"github.com/synthia-telemed/backend-api/pkg/cache"
 	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/config"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/server"
+	"github.com/synthia-telemed/backend-api/pkg/session"
 	"github.com/synthia-telemed/backend-api/pkg/sms"
-	"github.com/synthia-telemed/backend-api/pkg/token"
 	"go.uber.org/zap"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 var (
-	ErrInvalidRequestBody = server.NewErrorResponse("Invalid request body")
-	ErrPatientNotFound    = server.NewErrorResponse("Patient not found")
-	ErrInvalidOTP         = server.NewErrorResponse("OTP is invalid or expired")
+	ErrInvalidRequestBody  = server.NewErrorResponse("Invalid request body")
+	ErrPatientNotFound     = server.NewErrorResponse("Patient not found")
+	ErrInvalidOTP          = server.NewErrorResponse("OTP is invalid or expired")
+	ErrTooManySignins      = server.NewErrorResponse("Too many signin requests, please try again later")
+	ErrTooManyOTPAttempts  = server.NewErrorResponse("Too many incorrect OTP attempts, please sign in again")
+	ErrInvalidSignin       = server.NewErrorResponse("Signin is invalid or expired")
+	ErrOTPResendCooldown   = server.NewErrorResponse("OTP has just been sent, please wait before resending")
+	ErrOTPChannelAddress   = server.NewErrorResponse("Address of the OTP channel is required")
+	ErrInvalidRefreshToken = server.NewErrorResponse("Refresh token is invalid, expired or revoked")
 )
 
+// signinSession is the pending signin of the patient, which is completed by verifying the OTP sent to the patient.
+// Only the hash of the OTP is kept, so the OTP can't be read from the cache
+type signinSession struct {
+	RefID     string        `json:"ref_id"`
+	OTPHash   string        `json:"otp_hash"`
+	Recipient sms.Recipient `json:"recipient"`
+}
+
+// signinIDLength makes the signin ID unguessable, so the OTP can only be verified by the device which starts the signin
+const signinIDLength = 32
+
+// hashOTP hashes the OTP with the signin ID, so the same OTP of the concurrent signins has the different hashes
+func hashOTP(signinID, otp string) string {
+	hash := sha256.Sum256([]byte(signinID + ":" + otp))
+	return hex.EncodeToString(hash[:])
+}
+
 type AuthHandler struct {
 	patientDataStore  datastore.PatientDataStore
 	hospitalSysClient hospital.SystemClient
-	smsClient         sms.Client
+	sender            sms.Sender
 	cacheClient       cache.Client
-	tokenService      token.Service
+	sessionManager    session.Manager
 	clock             clock.Clock
+	rateLimit         *config.SigninRateLimit
+	otpTTL            time.Duration
+	otpMaxAttempts    int
+	resendCooldown    time.Duration
 	PatientGinHandler
 }
 
-func NewAuthHandler(patientDataStore datastore.PatientDataStore, hosClient hospital.SystemClient, sms sms.Client, cache cache.Client, tokenService token.Service, clock clock.Clock, logger *zap.SugaredLogger) *AuthHandler {
+func NewAuthHandler(patientDataStore datastore.PatientDataStore, hosClient hospital.SystemClient, sender sms.Sender, cache cache.Client, sessionManager session.Manager, clock clock.Clock, rateLimit *config.SigninRateLimit, otpTTL time.Duration, otpMaxAttempts int, resendCooldown time.Duration, logger *zap.SugaredLogger) *AuthHandler {
 	return &AuthHandler{
 		patientDataStore:  patientDataStore,
 		hospitalSysClient: hosClient,
-		smsClient:         sms,
+		sender:            sender,
 		cacheClient:       cache,
-		tokenService:      tokenService,
+		sessionManager:    sessionManager,
 		clock:             clock,
+		rateLimit:         rateLimit,
+		otpTTL:            otpTTL,
+		otpMaxAttempts:    otpMaxAttempts,
+		resendCooldown:    resendCooldown,
 		PatientGinHandler: NewPatientGinHandler(patientDataStore, logger),
 	}
 }
@@ -48,8 +87,12 @@ func NewAuthHandler(patientDataStore datastore.PatientDataStore, hosClient hospi
 func (h AuthHandler) Register(r *gin.RouterGroup) {
 	authGroup := r.Group("/auth")
 	authGroup.POST("/signin", h.Signin)
+	authGroup.POST("/signin/resend", h.ResendOTP)
 	authGroup.POST("/verify", h.VerifyOTP)
+	authGroup.POST("/refresh", h.RefreshToken)
+	authGroup.PUT("/otp-channel", h.ParseUserID, h.ParsePatient, h.SetOTPChannel)
 	authGroup.DELETE("/signout", h.ParseUserID, h.ParsePatient, h.SignOut)
+	authGroup.DELETE("/signout/all", h.ParseUserID, h.ParsePatient, h.SignOutAllDevices)
 }
 
 type SigninRequest struct {
@@ -57,18 +100,25 @@ type SigninRequest struct {
 }
 
 type SigninResponse struct {
-	ExpiredAt   time.Time `json:"expired_at"`
-	PhoneNumber string    `json:"phone_number"`
+	ExpiredAt time.Time `json:"expired_at"`
+	// SigninID is the opaque ID of the signin which must be sent with the OTP
+	SigninID    string      `json:"signin_id"`
+	PhoneNumber string      `json:"phone_number"`
+	Channel     sms.Channel `json:"channel"`
+	// Destination is the censored address that the OTP is sent to. It's empty for LINE
+	Destination string `json:"destination"`
 }
 
 // Signin godoc
 // @Summary      Start signing-in with government credential
-// @Description  Initiate auth process with government credential which will sent OTP to patient's phone number
+// @Description  Initiate auth process with government credential which will sent OTP to patient's preferred channel, or the other channels if it can't be delivered.
+// @Description  The signins are limited by the credential, phone number and IP. Retry-After header tells the seconds to wait when the limit is reached
 // @Tags         Auth
 // @Param 	  	 SigninRequest body SigninRequest true "Patient government credential (Passport ID or National ID)"
-// @Success      201  {object}  SigninResponse "OTP is sent to patient's phone number"
+// @Success      201  {object}  SigninResponse "OTP is sent to the patient"
 // @Failure      400  {object}  server.ErrorResponse "Invalid request body"
 // @Failure      404  {object}  server.ErrorResponse "Provided credential is not in the hospital system"
+// @Failure      429  {object}  server.ErrorResponse "Too many signin requests, please try again later"
 // @Failure      500  {object}  server.ErrorResponse "Internal server error"
 // @Router       /auth/signin [post]
 func (h AuthHandler) Signin(c *gin.Context) {
@@ -77,6 +127,9 @@ func (h AuthHandler) Signin(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
 		return
 	}
+	if !h.allowSignin(c, "ip", c.ClientIP(), h.rateLimit.PerIP) || !h.allowSignin(c, "credential", req.Credential, h.rateLimit.PerCredential) {
+		return
+	}
 
 	patientInfo, err := h.hospitalSysClient.FindPatientByGovCredential(context.Background(), req.Credential)
 	if err != nil {
@@ -87,45 +140,171 @@ func (h AuthHandler) Signin(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusNotFound, ErrPatientNotFound)
 		return
 	}
-
-	otp, err := gonanoid.Generate("1234567890", 6)
+	// The patient might have several credentials, e.g. national ID and passport ID, which share the same phone number
+	if !h.allowSignin(c, "phone", patientInfo.PhoneNumber, h.rateLimit.PerPhone) {
+		return
+	}
+	// The patient who has never signed in doesn't have the preference, so the OTP is sent by SMS
+	patient, err := h.patientDataStore.FindByRefID(patientInfo.Id)
 	if err != nil {
-		h.InternalServerError(c, err, "gonanoid.Generate error")
+		h.InternalServerError(c, err, "h.patientDataStore.FindByRefID error")
 		return
 	}
+	recipient := sms.Recipient{PhoneNumber: patientInfo.PhoneNumber}
+	if patient != nil {
+		recipient.Email, recipient.LINEUserID, recipient.Preferred = patient.Email, patient.LINEUserID, sms.Channel(patient.OTPChannel)
+	}
 
-	expiredIn := time.Minute * 10
-	if err := h.cacheClient.Set(context.Background(), otp, patientInfo.Id, expiredIn); err != nil {
+	signinID, err := gonanoid.ID(signinIDLength)
+	if err != nil {
+		h.InternalServerError(c, err, "gonanoid.ID error")
+		return
+	}
+	res, ok := h.sendOTP(c, signinID, &signinSession{RefID: patientInfo.Id, Recipient: recipient})
+	if !ok {
+		return
+	}
+	if err := h.cacheClient.Set(context.Background(), cache.SigninResendCooldownKey(signinID), "1", h.resendCooldown); err != nil {
 		h.InternalServerError(c, err, "h.cacheClient.Set error")
 		return
 	}
-	if err := h.smsClient.Send(patientInfo.PhoneNumber, fmt.Sprintf("Your OTP is %s", otp)); err != nil {
-		h.InternalServerError(c, err, "h.smsClient.Send error")
+	c.JSON(http.StatusCreated, res)
+}
+
+type ResendOTPRequest struct {
+	SigninID string `json:"signin_id" binding:"required,len=32"`
+	// Channel is where the OTP is resent to, e.g. email when the SMS is delayed. The preferred channel is used if it's empty
+	Channel sms.Channel `json:"channel" binding:"omitempty,enum" enums:"sms,email,line"`
+}
+
+// ResendOTP godoc
+// @Summary      Resend the OTP of the signin
+// @Description  The new OTP is sent and the previous one can't be used. The attempts of the signin are kept.
+// @Description  Retry-After header tells the seconds to wait when the OTP has just been sent
+// @Tags         Auth
+// @Param 	  	 ResendOTPRequest body ResendOTPRequest true "Signin ID and the channel to resend the OTP to"
+// @Success      201  {object}  SigninResponse "OTP is sent to the patient"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse "Signin is invalid or expired"
+// @Failure      429  {object}  server.ErrorResponse "OTP has just been sent, please wait before resending"
+// @Failure      429  {object}  server.ErrorResponse "Too many signin requests, please try again later"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Router       /auth/signin/resend [post]
+func (h AuthHandler) ResendOTP(c *gin.Context) {
+	var req ResendOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
 		return
 	}
 
-	c.JSON(http.StatusCreated, &SigninResponse{
-		PhoneNumber: h.censorPhoneNumber(patientInfo.PhoneNumber),
-		ExpiredAt:   h.clock.Now().Add(expiredIn),
-	})
+	ctx := context.Background()
+	sent, ttl, err := h.cacheClient.Increment(ctx, cache.SigninResendCooldownKey(req.SigninID), h.resendCooldown)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Increment error")
+		return
+	}
+	if sent > 1 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(ttl.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrOTPResendCooldown)
+		return
+	}
+	rawSession, err := h.cacheClient.Get(ctx, cache.SigninSessionKey(req.SigninID), false)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Get error")
+		return
+	}
+	if len(rawSession) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidSignin)
+		return
+	}
+	var session signinSession
+	if err := json.Unmarshal([]byte(rawSession), &session); err != nil {
+		h.InternalServerError(c, err, "json.Unmarshal error")
+		return
+	}
+	// The resent OTP costs the same as the signin, so it shares the limit of the phone number
+	if !h.allowSignin(c, "phone", session.Recipient.PhoneNumber, h.rateLimit.PerPhone) {
+		return
+	}
+	if req.Channel != "" {
+		session.Recipient.Preferred = req.Channel
+	}
+	res, ok := h.sendOTP(c, req.SigninID, &session)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// sendOTP issues the new OTP of the signin and sends it to the recipient of the session. It reports false if the error response is written
+func (h AuthHandler) sendOTP(c *gin.Context, signinID string, session *signinSession) (*SigninResponse, bool) {
+	otp, err := gonanoid.Generate("1234567890", 6)
+	if err != nil {
+		h.InternalServerError(c, err, "gonanoid.Generate error")
+		return nil, false
+	}
+	session.OTPHash = hashOTP(signinID, otp)
+	rawSession, err := json.Marshal(session)
+	if err != nil {
+		h.InternalServerError(c, err, "json.Marshal error")
+		return nil, false
+	}
+	if err := h.cacheClient.Set(context.Background(), cache.SigninSessionKey(signinID), string(rawSession), h.otpTTL); err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Set error")
+		return nil, false
+	}
+	channel, err := h.sender.Send(&session.Recipient, fmt.Sprintf("Your OTP is %s", otp))
+	if err != nil {
+		h.InternalServerError(c, err, "h.sender.Send error")
+		return nil, false
+	}
+	return &SigninResponse{
+		SigninID:    signinID,
+		PhoneNumber: h.censorPhoneNumber(session.Recipient.PhoneNumber),
+		Channel:     channel,
+		Destination: h.censorAddress(channel, session.Recipient.Address(channel)),
+		ExpiredAt:   h.clock.Now().Add(h.otpTTL),
+	}, true
+}
+
+// allowSignin counts the signin of the subject within the window. It reports false and responds 429 with Retry-After if the limit is exceeded
+func (h AuthHandler) allowSignin(c *gin.Context, subject, value string, limit int) bool {
+	hash := sha256.Sum256([]byte(value))
+	count, ttl, err := h.cacheClient.Increment(context.Background(), cache.SigninRateLimitKey(subject, hex.EncodeToString(hash[:])), h.rateLimit.Window)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Increment error")
+		return false
+	}
+	if count > limit {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(ttl.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrTooManySignins)
+		return false
+	}
+	return true
 }
 
 type VerifyOTPRequest struct {
-	OTP string `json:"otp" binding:"required"`
+	SigninID string `json:"signin_id" binding:"required,len=32"`
+	OTP      string `json:"otp" binding:"required"`
 }
 
 type VerifyOTPResponse struct {
-	Token string `json:"token"`
+	RefreshTokenExpiredAt time.Time `json:"refresh_token_expired_at"`
+	Token                 string    `json:"token"`
+	// RefreshToken is used once to get the new token and refresh token of the session
+	RefreshToken string `json:"refresh_token"`
 }
 
 // VerifyOTP godoc
 // @Summary      Verify OTP and get token
 // @Description  Complete auth process with OTP verification. It will return token if verification success.
+// @Description  The signin is cancelled after too many incorrect attempts, so the patient has to sign in again
 // @Tags         Auth
-// @Param 	  	 VerifyOTPRequest body VerifyOTPRequest true "OTP that is sent to patient's phone number"
-// @Success      201  {object}  VerifyOTPResponse "JWS Token for later use"
+// @Param 	  	 VerifyOTPRequest body VerifyOTPRequest true "Signin ID and OTP that is sent to patient's phone number"
+// @Success      201  {object}  VerifyOTPResponse "JWS Token and refresh token of the new session"
 // @Failure      400  {object}  server.ErrorResponse "Invalid request body"
 // @Failure      400  {object}  server.ErrorResponse "OTP is invalid or expired"
+// @Failure      429  {object}  server.ErrorResponse "Too many incorrect OTP attempts, please sign in again"
 // @Failure      500  {object}  server.ErrorResponse "Internal server error"
 // @Router       /auth/verify [post]
 func (h AuthHandler) VerifyOTP(c *gin.Context) {
@@ -135,38 +314,178 @@ func (h AuthHandler) VerifyOTP(c *gin.Context) {
 		return
 	}
 
-	refID, err := h.cacheClient.Get(context.Background(), req.OTP, true)
+	ctx := context.Background()
+	sessionKey, attemptsKey := cache.SigninSessionKey(req.SigninID), cache.SigninAttemptsKey(req.SigninID)
+	attempts, _, err := h.cacheClient.Increment(ctx, attemptsKey, h.otpTTL)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Increment error")
+		return
+	}
+	if attempts > h.otpMaxAttempts {
+		if err := h.cacheClient.Delete(ctx, sessionKey); err != nil {
+			h.InternalServerError(c, err, "h.cacheClient.Delete error")
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrTooManyOTPAttempts)
+		return
+	}
+	rawSession, err := h.cacheClient.Get(ctx, sessionKey, false)
 	if err != nil {
 		h.InternalServerError(c, err, "h.cacheClient.Get error")
 		return
 	}
-	if len(refID) == 0 {
+	if len(rawSession) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidOTP)
+		return
+	}
+	var session signinSession
+	if err := json.Unmarshal([]byte(rawSession), &session); err != nil {
+		h.InternalServerError(c, err, "json.Unmarshal error")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(session.OTPHash), []byte(hashOTP(req.SigninID, req.OTP))) != 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidOTP)
+		return
+	}
+	// The OTP is used only once, so only the request which deletes the session signs in
+	consumed, err := h.cacheClient.DeleteIfEquals(ctx, sessionKey, rawSession)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.DeleteIfEquals error")
+		return
+	}
+	if !consumed {
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidOTP)
 		return
 	}
 
-	patient := &datastore.Patient{RefID: refID}
+	patient := &datastore.Patient{RefID: session.RefID}
 	if err := h.patientDataStore.FindOrCreate(patient); err != nil {
 		h.InternalServerError(c, err, "h.patientDataStore.FindByRefID error")
 		return
 	}
 
-	jws, err := h.tokenService.GenerateToken(uint64(patient.ID), "Patient")
+	tokens, err := h.sessionManager.Create(patient.ID, "Patient", c.Request.UserAgent())
+	if err != nil {
+		h.InternalServerError(c, err, "h.sessionManager.Create error")
+		return
+	}
+	c.JSON(http.StatusCreated, VerifyOTPResponse{Token: tokens.Token, RefreshToken: tokens.RefreshToken, RefreshTokenExpiredAt: tokens.RefreshTokenExpiredAt})
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken godoc
+// @Summary      Get the new token of the session with the refresh token
+// @Description  The refresh token is rotated, so the new refresh token must be used next time.
+// @Description  Reusing the rotated refresh token revokes the session since the token must be leaked
+// @Tags         Auth
+// @Param 	  	 RefreshTokenRequest body RefreshTokenRequest true "Refresh token of the session"
+// @Success      201  {object}  VerifyOTPResponse "JWS Token and the next refresh token of the session"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      401  {object}  server.ErrorResponse "Refresh token is invalid, expired or revoked"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Router       /auth/refresh [post]
+func (h AuthHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	tokens, err := h.sessionManager.Refresh(req.RefreshToken, "Patient")
 	if err != nil {
-		h.InternalServerError(c, err, "h.tokenService.GenerateToken error")
+		h.InternalServerError(c, err, "h.sessionManager.Refresh error")
 		return
 	}
-	c.JSON(http.StatusCreated, VerifyOTPResponse{Token: jws})
+	if tokens == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrInvalidRefreshToken)
+		return
+	}
+	c.JSON(http.StatusCreated, VerifyOTPResponse{Token: tokens.Token, RefreshToken: tokens.RefreshToken, RefreshTokenExpiredAt: tokens.RefreshTokenExpiredAt})
 }
 
 func (h AuthHandler) censorPhoneNumber(number string) string {
 	return number[:3] + "***" + number[len(number)-4:]
 }
 
+func (h AuthHandler) censorAddress(channel sms.Channel, address string) string {
+	switch channel {
+	case sms.SMSChannel:
+		return h.censorPhoneNumber(address)
+	case sms.EmailChannel:
+		at := strings.LastIndex(address, "@")
+		if at < 1 {
+			return "***"
+		}
+		return address[:1] + "***" + address[at:]
+	default:
+		// LINE user ID means nothing to the patient
+		return ""
+	}
+}
+
+type SetOTPChannelRequest struct {
+	Channel    sms.Channel `json:"channel" binding:"required,enum" enums:"sms,email,line"`
+	Email      string      `json:"email" binding:"omitempty,email"`
+	LINEUserID string      `json:"line_user_id"`
+}
+
+// SetOTPChannel godoc
+// @Summary      Set the preferred channel to receive the OTP
+// @Description  The address of the channel is required except SMS, which is sent to the phone number in the hospital system.
+// @Description  The OTP is sent to the other channels if it can't be delivered through the preferred one
+// @Tags         Auth
+// @Param 	  	 SetOTPChannelRequest body SetOTPChannelRequest true "Preferred channel and the addresses"
+// @Success      200
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse "Address of the OTP channel is required"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /auth/otp-channel [put]
+func (h AuthHandler) SetOTPChannel(c *gin.Context) {
+	var req SetOTPChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	recipient := sms.Recipient{Email: req.Email, LINEUserID: req.LINEUserID}
+	if req.Channel != sms.SMSChannel && recipient.Address(req.Channel) == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrOTPChannelAddress)
+		return
+	}
+	patientRaw, _ := c.Get("Patient")
+	patient := patientRaw.(*datastore.Patient)
+	patient.OTPChannel, patient.Email, patient.LINEUserID = string(req.Channel), req.Email, req.LINEUserID
+	if err := h.patientDataStore.Save(patient); err != nil {
+		h.InternalServerError(c, err, "h.patientDataStore.Save error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
+
+// SignOut godoc
+// @Summary      Sign out the patient from the current device
+// @Description  The session of the token is revoked, so its token and refresh token can't be used anymore
+// @Tags         Auth
+// @Success      200
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /auth/signout [delete]
 func (h AuthHandler) SignOut(c *gin.Context) {
 	patientRaw, _ := c.Get("Patient")
 	patient := patientRaw.(*datastore.Patient)
 
+	if sessionID := h.GetSessionID(c); sessionID != "" {
+		if err := h.sessionManager.Revoke(sessionID); err != nil {
+			h.InternalServerError(c, err, "h.sessionManager.Revoke error")
+			return
+		}
+	}
 	patient.NotificationToken = ""
 	if err := h.patientDataStore.Save(patient); err != nil {
 		h.InternalServerError(c, err, "h.patientDataStore.Save")
@@ -174,3 +493,29 @@ func (h AuthHandler) SignOut(c *gin.Context) {
 	}
 	c.AbortWithStatus(http.StatusOK)
 }
+
+// SignOutAllDevices godoc
+// @Summary      Sign out the patient from all devices
+// @Description  Every session of the patient is revoked, including the current one
+// @Tags         Auth
+// @Success      200
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /auth/signout/all [delete]
+func (h AuthHandler) SignOutAllDevices(c *gin.Context) {
+	patientRaw, _ := c.Get("Patient")
+	patient := patientRaw.(*datastore.Patient)
+
+	if err := h.sessionManager.RevokeAll(patient.ID, "Patient"); err != nil {
+		h.InternalServerError(c, err, "h.sessionManager.RevokeAll error")
+		return
+	}
+	patient.NotificationToken = ""
+	if err := h.patientDataStore.Save(patient); err != nil {
+		h.InternalServerError(c, err, "h.patientDataStore.Save error")
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
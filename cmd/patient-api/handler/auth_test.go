@@ -2,21 +2,29 @@ package handler_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/synthia-telemed/backend-api/cmd/patient-api/handler"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/config"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/session"
+	"github.com/synthia-telemed/backend-api/pkg/sms"
 	testhelper "github.com/synthia-telemed/backend-api/test/helper"
 	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
 	"github.com/synthia-telemed/backend-api/test/mock_clock"
 	"github.com/synthia-telemed/backend-api/test/mock_datastore"
 	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_session"
 	"github.com/synthia-telemed/backend-api/test/mock_sms_client"
-	"github.com/synthia-telemed/backend-api/test/mock_token_service"
 	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
@@ -34,22 +42,38 @@ var _ = Describe("Auth Handler", func() {
 
 		mockPatientDataStore  *mock_datastore.MockPatientDataStore
 		mockHospitalSysClient *mock_hospital_client.MockSystemClient
-		mockSmsClient         *mock_sms_client.MockClient
+		mockSender            *mock_sms_client.MockSender
 		mockCacheClient       *mock_cache_client.MockClient
-		mockTokenService      *mock_token_service.MockService
+		mockSessionManager    *mock_session.MockManager
 		mockClock             *mock_clock.MockClock
+
+		rateLimit      *config.SigninRateLimit
+		otpTTL         time.Duration
+		otpMaxAttempts int
+		resendCooldown time.Duration
 	)
 
+	otpHash := func(signinID, otp string) string {
+		hash := sha256.Sum256([]byte(signinID + ":" + otp))
+		return hex.EncodeToString(hash[:])
+	}
+	rateLimitKey := func(subject, value string) string {
+		hash := sha256.Sum256([]byte(value))
+		return cache.SigninRateLimitKey(subject, hex.EncodeToString(hash[:]))
+	}
+
 	BeforeEach(func() {
 		mockCtrl, rec, c = testhelper.InitHandlerTest()
 
 		mockPatientDataStore = mock_datastore.NewMockPatientDataStore(mockCtrl)
 		mockHospitalSysClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
-		mockSmsClient = mock_sms_client.NewMockClient(mockCtrl)
+		mockSender = mock_sms_client.NewMockSender(mockCtrl)
 		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
-		mockTokenService = mock_token_service.NewMockService(mockCtrl)
+		mockSessionManager = mock_session.NewMockManager(mockCtrl)
 		mockClock = mock_clock.NewMockClock(mockCtrl)
-		h = handler.NewAuthHandler(mockPatientDataStore, mockHospitalSysClient, mockSmsClient, mockCacheClient, mockTokenService, mockClock, zap.NewNop().Sugar())
+		rateLimit = &config.SigninRateLimit{Window: time.Hour, PerCredential: 5, PerPhone: 5, PerIP: 20}
+		otpTTL, otpMaxAttempts, resendCooldown = 10*time.Minute, 5, time.Minute
+		h = handler.NewAuthHandler(mockPatientDataStore, mockHospitalSysClient, mockSender, mockCacheClient, mockSessionManager, mockClock, rateLimit, otpTTL, otpMaxAttempts, resendCooldown, zap.NewNop().Sugar())
 	})
 
 	JustBeforeEach(func() {
@@ -61,8 +85,23 @@ var _ = Describe("Auth Handler", func() {
 	})
 
 	Context("Signin", func() {
+		var (
+			p         *hospital.Patient
+			ipKey     string
+			credKey   string
+			phoneKey  string
+			otpPrefix string
+		)
 		BeforeEach(func() {
 			handlerFunc = h.Signin
+			reqBody := strings.NewReader(`{"credential": "1234567890"}`)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
+			c.Request.RemoteAddr = "10.0.0.1:12345"
+			p = &hospital.Patient{Id: "HN-1234", PhoneNumber: "0812223330"}
+			ipKey = rateLimitKey("ip", "10.0.0.1")
+			credKey = rateLimitKey("credential", "1234567890")
+			phoneKey = rateLimitKey("phone", p.PhoneNumber)
+			otpPrefix = "Your OTP is "
 		})
 
 		When("request body is valid", func() {
@@ -75,10 +114,32 @@ var _ = Describe("Auth Handler", func() {
 			})
 		})
 
+		When("IP reaches the limit", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), ipKey, rateLimit.Window).Return(rateLimit.PerIP+1, 90*time.Second+time.Millisecond, nil).Times(1)
+			})
+			It("should return 429 with Retry-After", func() {
+				Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+				Expect(rec.Header().Get("Retry-After")).To(Equal("91"))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrTooManySignins)
+			})
+		})
+
+		When("credential reaches the limit", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), ipKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), credKey, rateLimit.Window).Return(rateLimit.PerCredential+1, time.Minute, nil).Times(1)
+			})
+			It("should return 429 without looking up the patient", func() {
+				Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+				Expect(rec.Header().Get("Retry-After")).To(Equal("60"))
+			})
+		})
+
 		When("patient is not found", func() {
 			BeforeEach(func() {
-				reqBody := strings.NewReader(`{"credential": "1234567890"}`)
-				c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), ipKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), credKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
 				mockHospitalSysClient.EXPECT().FindPatientByGovCredential(context.Background(), "1234567890").Return(nil, nil).Times(1)
 			})
 			It("should return 404", func() {
@@ -86,39 +147,263 @@ var _ = Describe("Auth Handler", func() {
 			})
 		})
 
+		When("phone number reaches the limit", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), ipKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), credKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+				mockHospitalSysClient.EXPECT().FindPatientByGovCredential(context.Background(), "1234567890").Return(p, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), phoneKey, rateLimit.Window).Return(rateLimit.PerPhone+1, time.Minute, nil).Times(1)
+			})
+			It("should return 429 without sending the SMS", func() {
+				Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+			})
+		})
+
 		When("patient is found", func() {
-			var otpExpiredTime time.Time
+			var (
+				patient        *datastore.Patient
+				otpExpiredTime time.Time
+				sessionKey     string
+				session        string
+				recipient      *sms.Recipient
+				message        string
+				sentChannel    sms.Channel
+			)
 			BeforeEach(func() {
-				reqBody := strings.NewReader(`{"credential": "1234567890"}`)
-				c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
-				p := &hospital.Patient{Id: "HN-1234", PhoneNumber: "0812223330"}
+				patient = nil
+				sentChannel = sms.SMSChannel
+				mockCacheClient.EXPECT().Increment(gomock.Any(), ipKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), credKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
 				mockHospitalSysClient.EXPECT().FindPatientByGovCredential(context.Background(), "1234567890").Return(p, nil).Times(1)
-				mockCacheClient.EXPECT().Set(gomock.Any(), gomock.Any(), p.Id, time.Minute*10).Return(nil).Times(1)
-				mockSmsClient.EXPECT().Send(p.PhoneNumber, gomock.Any()).Return(nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), phoneKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+			})
+
+			setupSend := func() {
+				mockPatientDataStore.EXPECT().FindByRefID(p.Id).DoAndReturn(func(string) (*datastore.Patient, error) {
+					return patient, nil
+				}).Times(1)
+				mockCacheClient.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), otpTTL).DoAndReturn(func(_ context.Context, key, value string, _ time.Duration) error {
+					sessionKey, session = key, value
+					return nil
+				}).Times(1)
+				mockSender.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(r *sms.Recipient, body string) (sms.Channel, error) {
+					recipient, message = r, body
+					return sentChannel, nil
+				}).Times(1)
+				mockCacheClient.EXPECT().Set(gomock.Any(), gomock.Any(), "1", resendCooldown).Return(nil).Times(1)
 				now := time.Now()
 				mockClock.EXPECT().Now().Return(now).Times(1)
-				otpExpiredTime = now.Add(10 * time.Minute)
+				otpExpiredTime = now.Add(otpTTL)
+			}
+
+			When("patient has never signed in", func() {
+				BeforeEach(setupSend)
+
+				It("should send the OTP by SMS and return 201 with signin ID and phone number", func() {
+					Expect(rec.Code).To(Equal(http.StatusCreated))
+					var res handler.SigninResponse
+					Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+					Expect(res.PhoneNumber).To(Equal("081***3330"))
+					Expect(res.Channel).To(Equal(sms.SMSChannel))
+					Expect(res.Destination).To(Equal("081***3330"))
+					Expect(res.ExpiredAt.Equal(otpExpiredTime)).To(BeTrue())
+					Expect(res.SigninID).To(HaveLen(32))
+					Expect(recipient).To(Equal(&sms.Recipient{PhoneNumber: p.PhoneNumber}))
+					Expect(sessionKey).To(Equal(cache.SigninSessionKey(res.SigninID)))
+					otp := strings.TrimPrefix(message, otpPrefix)
+					Expect(session).To(MatchJSON(fmt.Sprintf(`{"ref_id":"%s","otp_hash":"%s","recipient":{"phone_number":"%s","email":"","line_user_id":"","preferred":""}}`, p.Id, otpHash(res.SigninID, otp), p.PhoneNumber)))
+				})
 			})
 
-			It("should return 201 with phone number", func() {
+			When("patient prefers email", func() {
+				BeforeEach(func() {
+					patient = &datastore.Patient{RefID: p.Id, OTPChannel: "email", Email: "somchai@example.com"}
+					sentChannel = sms.EmailChannel
+					setupSend()
+				})
+
+				It("should send the OTP to the preferred channel and return the censored email", func() {
+					Expect(rec.Code).To(Equal(http.StatusCreated))
+					var res handler.SigninResponse
+					Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+					Expect(res.Channel).To(Equal(sms.EmailChannel))
+					Expect(res.Destination).To(Equal("s***@example.com"))
+					Expect(recipient).To(Equal(&sms.Recipient{PhoneNumber: p.PhoneNumber, Email: patient.Email, Preferred: sms.EmailChannel}))
+				})
+			})
+		})
+	})
+
+	Context("Resend OTP", func() {
+		var (
+			signinID    string
+			sessionKey  string
+			cooldownKey string
+			phoneKey    string
+			session     string
+		)
+		BeforeEach(func() {
+			handlerFunc = h.ResendOTP
+			signinID = strings.ReplaceAll(uuid.NewString(), "-", "")
+			sessionKey, cooldownKey = cache.SigninSessionKey(signinID), cache.SigninResendCooldownKey(signinID)
+			phoneKey = rateLimitKey("phone", "0812223330")
+			session = fmt.Sprintf(`{"ref_id":"HN-1234","otp_hash":"%s","recipient":{"phone_number":"0812223330","email":"somchai@example.com","preferred":"sms"}}`, otpHash(signinID, "123456"))
+			reqBody := strings.NewReader(fmt.Sprintf(`{"signin_id": "%s", "channel": "email"}`, signinID))
+			c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
+		})
+
+		When("channel is not supported", func() {
+			BeforeEach(func() {
+				reqBody := strings.NewReader(fmt.Sprintf(`{"signin_id": "%s", "channel": "pigeon"}`, signinID))
+				c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+
+		When("OTP has just been sent", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), cooldownKey, resendCooldown).Return(2, 30*time.Second, nil).Times(1)
+			})
+			It("should return 429 with Retry-After", func() {
+				Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+				Expect(rec.Header().Get("Retry-After")).To(Equal("30"))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrOTPResendCooldown)
+			})
+		})
+
+		When("signin is expired", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), cooldownKey, resendCooldown).Return(1, resendCooldown, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return("", nil).Times(1)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidSignin)
+			})
+		})
+
+		When("phone number reaches the limit", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), cooldownKey, resendCooldown).Return(1, resendCooldown, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return(session, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), phoneKey, rateLimit.Window).Return(rateLimit.PerPhone+1, time.Minute, nil).Times(1)
+			})
+			It("should return 429", func() {
+				Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrTooManySignins)
+			})
+		})
+
+		When("sending the OTP error", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), cooldownKey, resendCooldown).Return(1, resendCooldown, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return(session, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), phoneKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+				mockCacheClient.EXPECT().Set(gomock.Any(), sessionKey, gomock.Any(), otpTTL).Return(nil).Times(1)
+				mockSender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(sms.Channel(""), sms.ErrNoAvailableChannel).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("OTP is resent", func() {
+			var (
+				newSession string
+				recipient  *sms.Recipient
+				message    string
+			)
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), cooldownKey, resendCooldown).Return(1, resendCooldown, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return(session, nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), phoneKey, rateLimit.Window).Return(1, rateLimit.Window, nil).Times(1)
+				mockCacheClient.EXPECT().Set(gomock.Any(), sessionKey, gomock.Any(), otpTTL).DoAndReturn(func(_ context.Context, _, value string, _ time.Duration) error {
+					newSession = value
+					return nil
+				}).Times(1)
+				mockSender.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(r *sms.Recipient, body string) (sms.Channel, error) {
+					recipient, message = r, body
+					return r.Preferred, nil
+				}).Times(1)
+				mockClock.EXPECT().Now().Return(time.Now()).Times(1)
+			})
+			It("should send the new OTP to the requested channel and replace the OTP of the signin", func() {
 				Expect(rec.Code).To(Equal(http.StatusCreated))
 				var res handler.SigninResponse
 				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
-				Expect(res.PhoneNumber).To(Equal("081***3330"))
-				Expect(res.ExpiredAt.Equal(otpExpiredTime)).To(BeTrue())
+				Expect(res.SigninID).To(Equal(signinID))
+				Expect(res.Channel).To(Equal(sms.EmailChannel))
+				Expect(res.Destination).To(Equal("s***@example.com"))
+				Expect(recipient.Preferred).To(Equal(sms.EmailChannel))
+				otp := strings.TrimPrefix(message, "Your OTP is ")
+				Expect(newSession).To(ContainSubstring(otpHash(signinID, otp)))
 			})
 		})
+	})
 
+	Context("Set OTP channel", func() {
+		var patient *datastore.Patient
+		BeforeEach(func() {
+			handlerFunc = h.SetOTPChannel
+			patient = testhelper.GeneratePatient()
+			c.Set("Patient", patient)
+		})
+
+		When("address of the channel is missing", func() {
+			BeforeEach(func() {
+				c.Request, _ = http.NewRequest(http.MethodPut, "/", strings.NewReader(`{"channel": "line"}`))
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrOTPChannelAddress)
+			})
+		})
+
+		When("email is invalid", func() {
+			BeforeEach(func() {
+				c.Request, _ = http.NewRequest(http.MethodPut, "/", strings.NewReader(`{"channel": "email", "email": "somchai"}`))
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+
+		When("channel is valid", func() {
+			BeforeEach(func() {
+				c.Request, _ = http.NewRequest(http.MethodPut, "/", strings.NewReader(`{"channel": "email", "email": "somchai@example.com"}`))
+				p := *patient
+				p.OTPChannel, p.Email = "email", "somchai@example.com"
+				mockPatientDataStore.EXPECT().Save(&p).Return(nil).Times(1)
+			})
+			It("should save the preference and return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
 	})
 
 	Context("OTP Verification", func() {
+		var (
+			signinID    string
+			sessionKey  string
+			attemptsKey string
+			rawSession  string
+		)
 		BeforeEach(func() {
 			handlerFunc = h.VerifyOTP
+			signinID = strings.ReplaceAll(uuid.NewString(), "-", "")
+			sessionKey, attemptsKey = cache.SigninSessionKey(signinID), cache.SigninAttemptsKey(signinID)
+			rawSession = fmt.Sprintf(`{"ref_id":"HN-1234","otp_hash":"%s"}`, otpHash(signinID, "123456"))
+			reqBody := strings.NewReader(fmt.Sprintf(`{"signin_id": "%s", "otp": "123456"}`, signinID))
+			c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
 		})
 
-		When("request body is valid", func() {
+		When("signin ID is not present in request body", func() {
 			BeforeEach(func() {
-				reqBody := strings.NewReader(`{"not-otp": "123456"}`)
+				reqBody := strings.NewReader(`{"otp": "123456"}`)
 				c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
 			})
 
@@ -127,11 +412,73 @@ var _ = Describe("Auth Handler", func() {
 			})
 		})
 
-		When("OTP is invalid or expired", func() {
+		When("signin ID isn't issued by signin", func() {
 			BeforeEach(func() {
-				reqBody := strings.NewReader(`{"otp": "123456"}`)
+				reqBody := strings.NewReader(`{"signin_id": "HN-1234", "otp": "123456"}`)
 				c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
-				mockCacheClient.EXPECT().Get(gomock.Any(), "123456", true).Return("", nil).Times(1)
+			})
+
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+
+		When("OTP of the other signin is sent", func() {
+			BeforeEach(func() {
+				rawSession = fmt.Sprintf(`{"ref_id":"HN-1234","otp_hash":"%s"}`, otpHash(strings.ReplaceAll(uuid.NewString(), "-", ""), "123456"))
+				mockCacheClient.EXPECT().Increment(gomock.Any(), attemptsKey, otpTTL).Return(1, otpTTL, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return(rawSession, nil).Times(1)
+			})
+
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidOTP)
+			})
+		})
+
+		When("attempts exceed the limit", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), attemptsKey, otpTTL).Return(otpMaxAttempts+1, time.Minute, nil).Times(1)
+				mockCacheClient.EXPECT().Delete(gomock.Any(), sessionKey).Return(nil).Times(1)
+			})
+
+			It("should cancel the signin and return 429", func() {
+				Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrTooManyOTPAttempts)
+			})
+		})
+
+		When("signin is expired", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), attemptsKey, otpTTL).Return(1, otpTTL, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return("", nil).Times(1)
+			})
+
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidOTP)
+			})
+		})
+
+		When("OTP is incorrect", func() {
+			BeforeEach(func() {
+				rawSession = fmt.Sprintf(`{"ref_id":"HN-1234","otp_hash":"%s"}`, otpHash(signinID, "654321"))
+				mockCacheClient.EXPECT().Increment(gomock.Any(), attemptsKey, otpTTL).Return(1, otpTTL, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return(rawSession, nil).Times(1)
+			})
+
+			It("should return 400 and keep the signin for the next attempt", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidOTP)
+			})
+		})
+
+		When("OTP is used by the concurrent request", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().Increment(gomock.Any(), attemptsKey, otpTTL).Return(1, otpTTL, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return(rawSession, nil).Times(1)
+				mockCacheClient.EXPECT().DeleteIfEquals(gomock.Any(), sessionKey, rawSession).Return(false, nil).Times(1)
 			})
 
 			It("should return 400", func() {
@@ -141,18 +488,20 @@ var _ = Describe("Auth Handler", func() {
 
 		When("OTP is valid", func() {
 			BeforeEach(func() {
-				reqBody := strings.NewReader(`{"otp": "123456"}`)
-				c.Request, _ = http.NewRequest(http.MethodPost, "/", reqBody)
-				mockCacheClient.EXPECT().Get(gomock.Any(), "123456", true).Return("HN-1234", nil).Times(1)
-				mockPatientDataStore.EXPECT().FindOrCreate(gomock.Any()).Return(nil).Times(1)
-				mockTokenService.EXPECT().GenerateToken(uint64(0), "Patient").Return("token", nil).Times(1)
+				mockCacheClient.EXPECT().Increment(gomock.Any(), attemptsKey, otpTTL).Return(2, otpTTL, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), sessionKey, false).Return(rawSession, nil).Times(1)
+				mockCacheClient.EXPECT().DeleteIfEquals(gomock.Any(), sessionKey, rawSession).Return(true, nil).Times(1)
+				mockPatientDataStore.EXPECT().FindOrCreate(&datastore.Patient{RefID: "HN-1234"}).Return(nil).Times(1)
+				mockSessionManager.EXPECT().Create(uint(0), "Patient", "okhttp/4.9").Return(&session.Tokens{Token: "token", RefreshToken: "refresh_token"}, nil).Times(1)
+				c.Request.Header.Set("User-Agent", "okhttp/4.9")
 			})
 
-			It("should return 201 with token", func() {
+			It("should return 201 with token and refresh token of the new session", func() {
 				Expect(rec.Code).To(Equal(http.StatusCreated))
 				var res handler.VerifyOTPResponse
 				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
 				Expect(res.Token).To(Equal("token"))
+				Expect(res.RefreshToken).To(Equal("refresh_token"))
 			})
 		})
 	})
@@ -189,5 +538,108 @@ var _ = Describe("Auth Handler", func() {
 			})
 		})
 
+		When("token carries the session ID", func() {
+			BeforeEach(func() {
+				c.Set("SessionID", "session-id")
+			})
+
+			When("revoke session error", func() {
+				BeforeEach(func() {
+					mockSessionManager.EXPECT().Revoke("session-id").Return(testhelper.MockError).Times(1)
+				})
+				It("should return 500", func() {
+					Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+				})
+			})
+
+			When("session is revoked", func() {
+				BeforeEach(func() {
+					mockSessionManager.EXPECT().Revoke("session-id").Return(nil).Times(1)
+					mockPatientDataStore.EXPECT().Save(updatedPatient).Return(nil).Times(1)
+				})
+				It("should return 200", func() {
+					Expect(rec.Code).To(Equal(http.StatusOK))
+				})
+			})
+		})
+	})
+
+	Context("SignOutAllDevices", func() {
+		var patient *datastore.Patient
+		BeforeEach(func() {
+			handlerFunc = h.SignOutAllDevices
+			patient = testhelper.GeneratePatient()
+			c.Set("Patient", patient)
+		})
+
+		When("revoke sessions error", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().RevokeAll(patient.ID, "Patient").Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("sessions are revoked", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().RevokeAll(patient.ID, "Patient").Return(nil).Times(1)
+				mockPatientDataStore.EXPECT().Save(gomock.Any()).DoAndReturn(func(p *datastore.Patient) error {
+					Expect(p.NotificationToken).To(BeEmpty())
+					return nil
+				}).Times(1)
+			})
+			It("should clear the notification token and return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("RefreshToken", func() {
+		BeforeEach(func() {
+			handlerFunc = h.RefreshToken
+			c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"refresh_token": "refresh_token"}`))
+		})
+
+		When("refresh token is missing", func() {
+			BeforeEach(func() {
+				c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		When("refresh token is invalid", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().Refresh("refresh_token", "Patient").Return(nil, nil).Times(1)
+			})
+			It("should return 401", func() {
+				Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRefreshToken)
+			})
+		})
+
+		When("refresh session error", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().Refresh("refresh_token", "Patient").Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		When("refresh token is valid", func() {
+			BeforeEach(func() {
+				mockSessionManager.EXPECT().Refresh("refresh_token", "Patient").Return(&session.Tokens{Token: "token", RefreshToken: "next_refresh_token"}, nil).Times(1)
+			})
+			It("should return 201 with the next refresh token", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				var res handler.VerifyOTPResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Token).To(Equal("token"))
+				Expect(res.RefreshToken).To(Equal("next_refresh_token"))
+			})
+		})
 	})
 })
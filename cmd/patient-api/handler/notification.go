@@ -1,15 +1,21 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	"github.com/synthia-telemed/backend-api/pkg/server"
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
+	"time"
 )
 
+// streamKeepAliveInterval is how often the comment is sent to keep the idle stream from being closed by proxies
+const streamKeepAliveInterval = time.Second * 15
+
 var (
 	ErrInvalidNotificationID = server.NewErrorResponse("Invalid notification id")
 	ErrNotificationNotFound  = server.NewErrorResponse("Notification not found")
@@ -17,12 +23,14 @@ var (
 
 type NotificationHandler struct {
 	notificationDataStore datastore.NotificationDataStore
+	eventBroker           event.Broker
 	PatientGinHandler
 }
 
-func NewNotificationHandler(notificationDataStore datastore.NotificationDataStore, patientDataStore datastore.PatientDataStore, logger *zap.SugaredLogger) *NotificationHandler {
+func NewNotificationHandler(notificationDataStore datastore.NotificationDataStore, patientDataStore datastore.PatientDataStore, eventBroker event.Broker, logger *zap.SugaredLogger) *NotificationHandler {
 	return &NotificationHandler{
 		notificationDataStore: notificationDataStore,
+		eventBroker:           eventBroker,
 		PatientGinHandler:     NewPatientGinHandler(patientDataStore, logger),
 	}
 }
@@ -33,6 +41,7 @@ func (h NotificationHandler) Register(r *gin.RouterGroup) {
 	g.PATCH("", h.ReadAll)
 	g.POST("/token", h.ParsePatient, h.SetNotificationToken)
 	g.GET("/unread", h.CountUnRead)
+	g.GET("/stream", h.Stream)
 	g.PATCH("/:id", h.AuthorizedPatientToNotification, h.Read)
 }
 
@@ -94,6 +103,7 @@ func (h NotificationHandler) ReadAll(c *gin.Context) {
 		return
 	}
 	c.AbortWithStatus(http.StatusOK)
+	h.publishUnreadCount(c, patientID)
 }
 
 func (h NotificationHandler) AuthorizedPatientToNotification(c *gin.Context) {
@@ -146,6 +156,75 @@ func (h NotificationHandler) Read(c *gin.Context) {
 		return
 	}
 	c.AbortWithStatus(http.StatusOK)
+	h.publishUnreadCount(c, notification.PatientID)
+}
+
+// publishUnreadCount notifies the patient's streams about the new unread count. The response is already written, so the error is only logged
+func (h NotificationHandler) publishUnreadCount(c *gin.Context, patientID uint) {
+	count, err := h.notificationDataStore.CountUnRead(patientID)
+	if err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.notificationDataStore.CountUnRead error")
+		return
+	}
+	if err := h.eventBroker.Publish(context.Background(), patientID, event.UnreadCountType, &event.UnreadCount{Count: count}); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.eventBroker.Publish error")
+	}
+}
+
+// Stream godoc
+// @Summary      Stream live events of the patient with Server-Sent Events
+// @Description  The current unread count is sent first, then the events are pushed as they happen.
+// @Description  Each event name is its type which is one of notification, unread_count or room_opened, and its data is datastore.Notification, event.UnreadCount or event.RoomOpened respectively
+// @Tags         Notification
+// @Produce      text/event-stream
+// @Success      200  {object}	event.Event "Stream of the events"
+// @Failure      401  {object}  server.ErrorResponse   "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse   "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /notification/stream [get]
+func (h NotificationHandler) Stream(c *gin.Context) {
+	patientID := h.GetUserID(c)
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	events, err := h.eventBroker.Subscribe(ctx, patientID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.eventBroker.Subscribe error")
+		return
+	}
+	count, err := h.notificationDataStore.CountUnRead(patientID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.notificationDataStore.CountUnRead error")
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.SSEvent(string(event.UnreadCountType), &event.UnreadCount{Count: count})
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(string(e.Type), e.Data)
+			c.Writer.Flush()
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
 }
 
 type SetNotificationTokenRequest struct {
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -11,8 +12,10 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/synthia-telemed/backend-api/cmd/patient-api/handler"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	testhelper "github.com/synthia-telemed/backend-api/test/helper"
 	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_event"
 	"go.uber.org/zap"
 	"math/rand"
 	"net/http"
@@ -31,13 +34,15 @@ var _ = Describe("Notification Handler", func() {
 
 		mockNotificationDataStore *mock_datastore.MockNotificationDataStore
 		mockPatientDataStore      *mock_datastore.MockPatientDataStore
+		mockEventBroker           *mock_event.MockBroker
 	)
 
 	BeforeEach(func() {
 		mockCtrl, rec, c = testhelper.InitHandlerTest()
 		mockNotificationDataStore = mock_datastore.NewMockNotificationDataStore(mockCtrl)
 		mockPatientDataStore = mock_datastore.NewMockPatientDataStore(mockCtrl)
-		h = handler.NewNotificationHandler(mockNotificationDataStore, mockPatientDataStore, zap.NewNop().Sugar())
+		mockEventBroker = mock_event.NewMockBroker(mockCtrl)
+		h = handler.NewNotificationHandler(mockNotificationDataStore, mockPatientDataStore, mockEventBroker, zap.NewNop().Sugar())
 		patientID = uint(rand.Uint32())
 		c.Set("UserID", patientID)
 	})
@@ -199,11 +204,23 @@ var _ = Describe("Notification Handler", func() {
 		When("no error", func() {
 			BeforeEach(func() {
 				mockNotificationDataStore.EXPECT().SetAsRead(notification.ID).Return(nil).Times(1)
+				mockNotificationDataStore.EXPECT().CountUnRead(patientID).Return(2, nil).Times(1)
+				mockEventBroker.EXPECT().Publish(gomock.Any(), patientID, event.UnreadCountType, &event.UnreadCount{Count: 2}).Return(nil).Times(1)
 			})
 			It("should return 200", func() {
 				Expect(rec.Code).To(Equal(http.StatusOK))
 			})
 		})
+		When("publish unread count error", func() {
+			BeforeEach(func() {
+				mockNotificationDataStore.EXPECT().SetAsRead(notification.ID).Return(nil).Times(1)
+				mockNotificationDataStore.EXPECT().CountUnRead(patientID).Return(2, nil).Times(1)
+				mockEventBroker.EXPECT().Publish(gomock.Any(), patientID, event.UnreadCountType, gomock.Any()).Return(testhelper.MockError).Times(1)
+			})
+			It("should still return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
 	})
 
 	Context("Read all notifications", func() {
@@ -222,6 +239,8 @@ var _ = Describe("Notification Handler", func() {
 		When("no error", func() {
 			BeforeEach(func() {
 				mockNotificationDataStore.EXPECT().SetAllAsRead(patientID).Return(nil).Times(1)
+				mockNotificationDataStore.EXPECT().CountUnRead(patientID).Return(0, nil).Times(1)
+				mockEventBroker.EXPECT().Publish(gomock.Any(), patientID, event.UnreadCountType, &event.UnreadCount{Count: 0}).Return(nil).Times(1)
 			})
 			It("should return 200", func() {
 				Expect(rec.Code).To(Equal(http.StatusOK))
@@ -229,6 +248,54 @@ var _ = Describe("Notification Handler", func() {
 		})
 	})
 
+	Context("Stream", func() {
+		var cancel context.CancelFunc
+		BeforeEach(func() {
+			handlerFunc = h.Stream
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			c.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		})
+		AfterEach(func() {
+			cancel()
+		})
+
+		When("subscribe error", func() {
+			BeforeEach(func() {
+				mockEventBroker.EXPECT().Subscribe(gomock.Any(), patientID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("count unread notification error", func() {
+			BeforeEach(func() {
+				mockEventBroker.EXPECT().Subscribe(gomock.Any(), patientID).Return(make(chan *event.Event), nil).Times(1)
+				mockNotificationDataStore.EXPECT().CountUnRead(patientID).Return(0, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("events are published", func() {
+			BeforeEach(func() {
+				events := make(chan *event.Event, 2)
+				events <- &event.Event{Type: event.RoomOpenedType, Data: []byte(`{"appointment_id":"1","room_id":"room"}`)}
+				events <- &event.Event{Type: event.UnreadCountType, Data: []byte(`{"count":4}`)}
+				close(events)
+				mockEventBroker.EXPECT().Subscribe(gomock.Any(), patientID).Return((<-chan *event.Event)(events), nil).Times(1)
+				mockNotificationDataStore.EXPECT().CountUnRead(patientID).Return(3, nil).Times(1)
+			})
+			It("should stream the unread count then the events", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Header().Get("Content-Type")).To(Equal("text/event-stream"))
+				Expect(rec.Body.String()).To(Equal("event:unread_count\ndata:{\"count\":3}\n\n" +
+					"event:room_opened\ndata:{\"appointment_id\":\"1\",\"room_id\":\"room\"}\n\n" +
+					"event:unread_count\ndata:{\"count\":4}\n\n"))
+			})
+		})
+	})
+
 	Context("Set notification token", func() {
 		var (
 			req     *handler.SetNotificationTokenRequest
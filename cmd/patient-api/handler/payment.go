@@ -1,18 +1,30 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
 	"github.com/synthia-telemed/backend-api/pkg/clock"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
 	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/receipt"
 	"github.com/synthia-telemed/backend-api/pkg/server"
 	"go.uber.org/zap"
+	"io"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var (
@@ -21,41 +33,89 @@ var (
 	ErrInvalidCreditCardID            = server.NewErrorResponse("Invalid credit card ID")
 	ErrCreditCardOwnership            = server.NewErrorResponse("Patient doesn't own the specified credit card")
 	ErrCreditCardNotFound             = server.NewErrorResponse("Credit card not found")
+	ErrCreditCardExpired              = server.NewErrorResponse("Credit card is expired")
 	ErrInvalidInvoiceID               = server.NewErrorResponse("Invalid invoice ID")
 	ErrInvoiceNotFound                = server.NewErrorResponse("Invoice not found")
 	ErrInvoiceOwnership               = server.NewErrorResponse("Patient doesn't down the specified invoice")
 	ErrInvoicePaid                    = server.NewErrorResponse("Invoice is already paid")
+	ErrInvoiceNotPaid                 = server.NewErrorResponse("Invoice isn't paid yet")
+	ErrInvalidWebhookSignature        = server.NewErrorResponse("Invalid webhook signature")
+	ErrInvalidWebhookEvent            = server.NewErrorResponse("Invalid webhook event")
+	ErrPaymentNotFound                = server.NewErrorResponse("Payment not found")
+	ErrInvalidIdempotencyKey          = server.NewErrorResponse("Idempotency-Key must not be longer than 255 characters")
+	ErrIdempotencyKeyReused           = server.NewErrorResponse("Idempotency-Key has been used with another request")
+	ErrRequestInProgress              = server.NewErrorResponse("Request with the same Idempotency-Key is in progress")
+	ErrPaymentResultUnknown           = server.NewErrorResponse("Result of the payment is unknown, please check the payment status of the invoice")
+	ErrInvoicePaymentInProgress       = server.NewErrorResponse("Another payment of the invoice is in progress")
+	ErrInvoicePaymentPending          = server.NewErrorResponse("Invoice has a pending payment")
+	ErrInvalidSplitAmount             = server.NewErrorResponse("Amount of each part must be positive with at most 2 decimal places")
+	ErrSplitTotalMismatch             = server.NewErrorResponse("Sum of the parts must equal the invoice total")
+	ErrDuplicateSplitCreditCard       = server.NewErrorResponse("Each credit card can be charged only once in the split payment")
 )
 
+// splitRefundReason is the reason of the refund of the part when the split payment fails
+const splitRefundReason = "Split payment failed"
+
 type PaymentHandler struct {
-	paymentClient       payment.Client
-	patientDataStore    datastore.PatientDataStore
-	creditCardDataStore datastore.CreditCardDataStore
-	hospitalSysClient   hospital.SystemClient
-	paymentDataStore    datastore.PaymentDataStore
-	clock               clock.Clock
+	paymentClient         payment.Client
+	patientDataStore      datastore.PatientDataStore
+	creditCardDataStore   datastore.CreditCardDataStore
+	hospitalSysClient     hospital.SystemClient
+	paymentDataStore      datastore.PaymentDataStore
+	outboxDataStore       datastore.PaidInvoiceOutboxDataStore
+	refundDataStore       datastore.RefundDataStore
+	notificationDataStore datastore.NotificationDataStore
+	notificationClient    notification.Client
+	eventBroker           event.Broker
+	cacheClient           cache.Client
+	receiptGenerator      receipt.Generator
+	clock                 clock.Clock
+	lockTTL               time.Duration
+	idempotencyKeyTTL     time.Duration
 	PatientGinHandler
 }
 
-func NewPaymentHandler(paymentClient payment.Client, pds datastore.PatientDataStore, cds datastore.CreditCardDataStore, hsc hospital.SystemClient, pay datastore.PaymentDataStore, clock clock.Clock, logger *zap.SugaredLogger) *PaymentHandler {
+func NewPaymentHandler(paymentClient payment.Client, pds datastore.PatientDataStore, cds datastore.CreditCardDataStore, hsc hospital.SystemClient, pay datastore.PaymentDataStore, outbox datastore.PaidInvoiceOutboxDataStore, rds datastore.RefundDataStore, nds datastore.NotificationDataStore, noti notification.Client, eventBroker event.Broker, cacheClient cache.Client, receiptGenerator receipt.Generator, clock clock.Clock, lockTTL, idempotencyKeyTTL time.Duration, logger *zap.SugaredLogger) *PaymentHandler {
 	return &PaymentHandler{
-		paymentClient:       paymentClient,
-		patientDataStore:    pds,
-		creditCardDataStore: cds,
-		hospitalSysClient:   hsc,
-		paymentDataStore:    pay,
-		clock:               clock,
-		PatientGinHandler:   NewPatientGinHandler(pds, logger),
+		paymentClient:         paymentClient,
+		patientDataStore:      pds,
+		creditCardDataStore:   cds,
+		hospitalSysClient:     hsc,
+		paymentDataStore:      pay,
+		outboxDataStore:       outbox,
+		refundDataStore:       rds,
+		notificationDataStore: nds,
+		notificationClient:    noti,
+		eventBroker:           eventBroker,
+		cacheClient:           cacheClient,
+		receiptGenerator:      receiptGenerator,
+		clock:                 clock,
+		lockTTL:               lockTTL,
+		idempotencyKeyTTL:     idempotencyKeyTTL,
+		PatientGinHandler:     NewPatientGinHandler(pds, logger),
 	}
 }
 
 func (h PaymentHandler) Register(r *gin.RouterGroup) {
+	// Webhook is called by Omise, so there is no user to parse
+	r.POST("/payment/webhook/omise", h.HandleOmiseWebhook)
+	// Patient is redirected back from 3-D Secure page by the browser, so there is no user to parse
+	r.GET("/payment/credit-card/return", h.HandleCreditCardReturn)
 	paymentGroup := r.Group("/payment", h.ParseUserID)
 	paymentGroup.POST("/credit-card", h.CreateOrParseCustomer, h.AddCreditCard)
 	paymentGroup.GET("/credit-card", h.GetCreditCards)
 	paymentGroup.PATCH("/credit-card/:cardID", h.VerifyCreditCardOwnership, h.SetCreditCardIsDefault)
 	paymentGroup.DELETE("/credit-card/:cardID", h.CreateOrParseCustomer, h.VerifyCreditCardOwnership, h.DeleteCreditCard)
-	paymentGroup.POST("/pay/:invoiceID/credit-card/:cardID", h.ParseAndVerifyUnpaidInvoiceOwnership, h.CreateOrParseCustomer, h.VerifyCreditCardOwnership, h.PayInvoiceWithCreditCard)
+	payGroup := paymentGroup.Group("/pay/:invoiceID", h.ReplayIdempotentRequest, h.LockInvoicePayment)
+	payGroup.POST("/credit-card/:cardID", h.ParseAndVerifyUnpaidInvoiceOwnership, h.VerifyNoPendingPayment, h.CreateOrParseCustomer, h.VerifyCreditCardOwnership, h.VerifyCreditCardNotExpired, h.PayInvoiceWithCreditCard)
+	payGroup.POST("/split", h.ParseAndVerifyUnpaidInvoiceOwnership, h.VerifyNoPendingPayment, h.CreateOrParseCustomer, h.PayInvoiceWithSplitPayment)
+	payGroup.POST("/promptpay", h.ParseAndVerifyUnpaidInvoiceOwnership, h.VerifyNoPendingPayment, h.PayInvoiceWithPromptPay)
+	paymentGroup.GET("/pay/:invoiceID/status", h.GetInvoicePaymentStatus)
+	paymentGroup.GET("/receipt/:invoiceID", h.ParseAndVerifyPaidInvoiceOwnership, h.GetReceipt)
+	paymentGroup.GET("/history", h.ListPaymentHistory)
+	paymentGroup.GET("/invoice", h.ParsePatient, h.ListInvoices)
+	paymentGroup.GET("/autopay", h.ParsePatient, h.GetAutopay)
+	paymentGroup.PUT("/autopay", h.ParsePatient, h.SetAutopay)
 }
 
 type AddCreditCardRequest struct {
@@ -110,7 +170,8 @@ func (h PaymentHandler) AddCreditCard(c *gin.Context) {
 		CardID:      card.ID,
 		Name:        req.Name,
 		IsDefault:   cardCount == 0 || req.IsDefault,
-		Expiry:      card.Expiry,
+		ExpiryMonth: card.ExpiryMonth,
+		ExpiryYear:  card.ExpiryYear,
 	}
 	if cardCount > 0 && req.IsDefault {
 		if err := h.creditCardDataStore.SetAllToNonDefault(patientID); err != nil {
@@ -125,10 +186,16 @@ func (h PaymentHandler) AddCreditCard(c *gin.Context) {
 	c.AbortWithStatus(http.StatusCreated)
 }
 
+type SavedCreditCard struct {
+	datastore.CreditCard
+	IsExpired bool `json:"is_expired"`
+}
+
 // GetCreditCards godoc
 // @Summary      Get lists of saved credit cards
+// @Description  The expired card should be re-added as it can't be charged
 // @Tags         Payment
-// @Success      200  {array}   datastore.CreditCard "List of saved cards"
+// @Success      200  {array}   SavedCreditCard "List of saved cards"
 // @Failure      401  {object}  server.ErrorResponse "Unauthorized"
 // @Failure      500  {object}  server.ErrorResponse "Internal server error"
 // @Security     UserID
@@ -141,7 +208,12 @@ func (h PaymentHandler) GetCreditCards(c *gin.Context) {
 		h.InternalServerError(c, err, "h.paymentClient.ListCards error")
 		return
 	}
-	c.JSON(http.StatusOK, cards)
+	now := h.clock.Now()
+	savedCards := make([]SavedCreditCard, len(cards))
+	for i, card := range cards {
+		savedCards[i] = SavedCreditCard{CreditCard: card, IsExpired: card.IsExpired(now)}
+	}
+	c.JSON(http.StatusOK, savedCards)
 }
 
 type SetCreditCardIsDefaultRequest struct {
@@ -233,18 +305,29 @@ func (h PaymentHandler) DeleteCreditCard(c *gin.Context) {
 type PayInvoiceWithCreditCardResponse struct {
 	*datastore.Payment
 	FailureMessage *string `json:"failure_message"`
+	// AuthorizeURI is the 3-D Secure page to open when the payment is pending
+	AuthorizeURI string `json:"authorize_uri,omitempty"`
 }
 
 // PayInvoiceWithCreditCard godoc
 // @Summary      Pay invoice with credit card method
+// @Description  The payment status is pending if the charge isn't settled yet, and it's updated when Omise notifies the result.
+// @Description  If authorize_uri is returned, the patient must authorize the charge with 3-D Secure on that page, which then redirects to /payment/credit-card/return
 // @Tags         Payment
 // @Param  		 cardID 	path	 integer 	true "ID of the credit card to be charged"
 // @Param  		 invoiceID 	path	 integer 	true "ID of the invoice to pay"
+// @Param        Idempotency-Key header string false "Unique key of the request. The response of the first request which attempts the charge is replayed for the retries with the same key and body"
 // @Success      201  {object}	PayInvoiceWithCreditCardResponse "Payment information"
 // @Failure      400  {object}  server.ErrorResponse "Invalid credit card ID or invoice ID"
+// @Failure      400  {object}  server.ErrorResponse "Credit card is expired"
+// @Failure      400  {object}  server.ErrorResponse "Idempotency-Key must not be longer than 255 characters"
 // @Failure      401  {object}  server.ErrorResponse "Unauthorized"
 // @Failure      403  {object}  server.ErrorResponse "Patient doesn't own the specified credit card or invoice"
 // @Failure      404  {object}  server.ErrorResponse "Credit card or invoice not found"
+// @Failure      409  {object}  server.ErrorResponse "Request with the same Idempotency-Key is in progress"
+// @Failure      409  {object}  server.ErrorResponse "Another payment of the invoice is in progress"
+// @Failure      409  {object}  server.ErrorResponse "Invoice has a pending payment"
+// @Failure      422  {object}  server.ErrorResponse "Idempotency-Key has been used with another request"
 // @Failure      500  {object}  server.ErrorResponse "Internal server error"
 // @Security     UserID
 // @Security     JWSToken
@@ -256,19 +339,21 @@ func (h PaymentHandler) PayInvoiceWithCreditCard(c *gin.Context) {
 	rawInvoice, _ := c.Get("Invoice")
 	invoice, _ := rawInvoice.(*hospital.InvoiceOverview)
 
-	paymentCharge, err := h.paymentClient.PayWithCreditCard(customerID, creditCard.CardID, fmt.Sprintf("%d", invoice.Id), int(invoice.Total*100))
+	markChargeAttempted(c)
+	paymentCharge, err := h.paymentClient.PayWithCreditCard(customerID, creditCard.CardID, fmt.Sprintf("%d", invoice.Id), payment.ToSatang(invoice.Total))
 	if err != nil {
 		h.InternalServerError(c, err, "h.paymentClient.PayWithCreditCard error")
 		return
 	}
 	status := datastore.FailedPaymentStatus
 	paidAt := h.clock.NowPointer()
-	if paymentCharge.Success {
+	switch {
+	case paymentCharge.Success:
 		status = datastore.SuccessPaymentStatus
-		if err := h.hospitalSysClient.PaidInvoice(context.Background(), invoice.Id); err != nil {
-			h.InternalServerError(c, err, "h.hospitalSysClient.PaidInvoice error")
-			return
-		}
+	case paymentCharge.Pending:
+		// The charge is settled later, e.g. after 3-D Secure, and the webhook updates the payment
+		status = datastore.PendingPaymentStatus
+		paidAt = nil
 	}
 	p := &datastore.Payment{
 		Method:       datastore.CreditCardPaymentMethod,
@@ -276,6 +361,7 @@ func (h PaymentHandler) PayInvoiceWithCreditCard(c *gin.Context) {
 		PaidAt:       paidAt,
 		ChargeID:     paymentCharge.ID,
 		InvoiceID:    invoice.Id,
+		PatientID:    h.GetUserID(c),
 		Status:       status,
 		CreditCard:   creditCard,
 		CreditCardID: &creditCard.ID,
@@ -284,10 +370,736 @@ func (h PaymentHandler) PayInvoiceWithCreditCard(c *gin.Context) {
 		h.InternalServerError(c, err, "h.paymentDataStore.Create error")
 		return
 	}
+	if status == datastore.SuccessPaymentStatus {
+		h.markInvoicePaid(c, p)
+	}
 	res := &PayInvoiceWithCreditCardResponse{Payment: p, FailureMessage: paymentCharge.FailureMessage}
+	if paymentCharge.Pending {
+		res.AuthorizeURI = paymentCharge.AuthorizeURI
+	}
 	c.JSON(http.StatusCreated, res)
 }
 
+type SplitPaymentPart struct {
+	CreditCardID uint    `json:"credit_card_id" binding:"required"`
+	Amount       float64 `json:"amount" binding:"required"`
+}
+
+type PayInvoiceWithSplitPaymentRequest struct {
+	Parts []SplitPaymentPart `json:"parts" binding:"required,min=2,max=5,dive"`
+}
+
+type PayInvoiceWithSplitPaymentResponse struct {
+	FailureMessage *string                 `json:"failure_message"`
+	SplitID        string                  `json:"split_id"`
+	Status         datastore.PaymentStatus `json:"status"`
+	Parts          []*datastore.Payment    `json:"parts"`
+}
+
+// PayInvoiceWithSplitPayment godoc
+// @Summary      Pay invoice with several credit cards
+// @Description  The parts are charged in order, and their amounts must sum to the invoice total. The invoice is marked as paid only if all parts are successful.
+// @Description  If a part fails, the successful parts are refunded and the status is failed. 3-D Secure isn't supported, so the part which requires it fails the payment and is refunded if it's authorized later
+// @Tags         Payment
+// @Param  		 invoiceID 	path	 integer 	true "ID of the invoice to pay"
+// @Param        Idempotency-Key header string false "Unique key of the request. The response of the first request which attempts the charge is replayed for the retries with the same key and body"
+// @Param 	  	 PayInvoiceWithSplitPaymentRequest body PayInvoiceWithSplitPaymentRequest true "Credit cards and the amounts to be charged"
+// @Success      201  {object}	PayInvoiceWithSplitPaymentResponse "Status and parts of the split payment"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse "Invalid invoice ID"
+// @Failure      400  {object}  server.ErrorResponse "Invoice is already paid"
+// @Failure      400  {object}  server.ErrorResponse "Amount of each part must be positive with at most 2 decimal places"
+// @Failure      400  {object}  server.ErrorResponse "Sum of the parts must equal the invoice total"
+// @Failure      400  {object}  server.ErrorResponse "Each credit card can be charged only once in the split payment"
+// @Failure      400  {object}  server.ErrorResponse "Credit card is expired"
+// @Failure      400  {object}  server.ErrorResponse "Idempotency-Key must not be longer than 255 characters"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse "Patient doesn't own the specified credit card or invoice"
+// @Failure      404  {object}  server.ErrorResponse "Credit card or invoice not found"
+// @Failure      409  {object}  server.ErrorResponse "Request with the same Idempotency-Key is in progress"
+// @Failure      409  {object}  server.ErrorResponse "Another payment of the invoice is in progress"
+// @Failure      409  {object}  server.ErrorResponse "Invoice has a pending payment"
+// @Failure      422  {object}  server.ErrorResponse "Idempotency-Key has been used with another request"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/pay/{invoiceID}/split [post]
+func (h PaymentHandler) PayInvoiceWithSplitPayment(c *gin.Context) {
+	customerID := h.GetCustomerID(c)
+	patientID := h.GetUserID(c)
+	rawInvoice, _ := c.Get("Invoice")
+	invoice, _ := rawInvoice.(*hospital.InvoiceOverview)
+
+	var req PayInvoiceWithSplitPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	var totalSatang float64
+	satangs := make([]float64, len(req.Parts))
+	for i, part := range req.Parts {
+		satangs[i] = math.Round(part.Amount * 100)
+		if part.Amount <= 0 || math.Abs(satangs[i]-part.Amount*100) > 1e-6 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidSplitAmount)
+			return
+		}
+		totalSatang += satangs[i]
+	}
+	if int(totalSatang) != payment.ToSatang(invoice.Total) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrSplitTotalMismatch)
+		return
+	}
+
+	now := h.clock.Now()
+	cards := make([]*datastore.CreditCard, len(req.Parts))
+	seen := make(map[uint]bool, len(req.Parts))
+	for i, part := range req.Parts {
+		if seen[part.CreditCardID] {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrDuplicateSplitCreditCard)
+			return
+		}
+		seen[part.CreditCardID] = true
+		card, err := h.creditCardDataStore.FindByID(part.CreditCardID)
+		if err != nil {
+			h.InternalServerError(c, err, "h.creditCardDataStore.FindByID error")
+			return
+		}
+		if card == nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, ErrCreditCardNotFound)
+			return
+		}
+		if card.PatientID != patientID {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrCreditCardOwnership)
+			return
+		}
+		if card.IsExpired(now) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrCreditCardExpired)
+			return
+		}
+		cards[i] = card
+	}
+
+	splitID := uuid.NewString()
+	res := &PayInvoiceWithSplitPaymentResponse{SplitID: splitID, Status: datastore.SuccessPaymentStatus}
+	markChargeAttempted(c)
+	for i, card := range cards {
+		charge, err := h.paymentClient.PayWithCreditCard(customerID, card.CardID, fmt.Sprintf("%d", invoice.Id), int(satangs[i]))
+		if err != nil {
+			h.refundSplitParts(c, res.Parts)
+			h.InternalServerError(c, err, "h.paymentClient.PayWithCreditCard error")
+			return
+		}
+		status := datastore.FailedPaymentStatus
+		paidAt := h.clock.NowPointer()
+		switch {
+		case charge.Success:
+			status = datastore.SuccessPaymentStatus
+		case charge.Pending:
+			status = datastore.PendingPaymentStatus
+			paidAt = nil
+		}
+		p := &datastore.Payment{
+			Method:       datastore.CreditCardPaymentMethod,
+			Amount:       satangs[i] / 100,
+			PaidAt:       paidAt,
+			ChargeID:     charge.ID,
+			InvoiceID:    invoice.Id,
+			PatientID:    patientID,
+			Status:       status,
+			CreditCard:   card,
+			CreditCardID: &card.ID,
+			SplitID:      &splitID,
+		}
+		create, createName := h.paymentDataStore.Create, "h.paymentDataStore.Create error"
+		if i == len(cards)-1 {
+			create, createName = h.paymentDataStore.CreateLastSplitPart, "h.paymentDataStore.CreateLastSplitPart error"
+		}
+		if err := create(p); err != nil {
+			if status == datastore.SuccessPaymentStatus {
+				h.refundUnrecordedCharge(c, charge.ID, int(satangs[i]))
+			}
+			h.refundSplitParts(c, res.Parts)
+			h.InternalServerError(c, err, createName)
+			return
+		}
+		res.Parts = append(res.Parts, p)
+		if status == datastore.SuccessPaymentStatus {
+			continue
+		}
+
+		// The remaining parts aren't charged, and the pending part is refunded by the settlement if it's authorized later
+		res.Status, res.FailureMessage = datastore.FailedPaymentStatus, charge.FailureMessage
+		if status == datastore.PendingPaymentStatus {
+			msg := "3-D Secure isn't supported in the split payment"
+			res.FailureMessage = &msg
+		}
+		h.refundSplitParts(c, res.Parts)
+		c.JSON(http.StatusCreated, res)
+		return
+	}
+
+	// All parts are successful, so the invoice is marked as paid with the last part, whose outbox is created along with it
+	h.markInvoicePaid(c, res.Parts[len(res.Parts)-1])
+	c.JSON(http.StatusCreated, res)
+}
+
+// refundUnrecordedCharge refunds the successful charge whose payment failed to be created.
+// The charge ID is logged if the refund fails, as there is no payment to refund it from later
+func (h PaymentHandler) refundUnrecordedCharge(c *gin.Context, chargeID string, amount int) {
+	if _, err := h.paymentClient.Refund(chargeID, amount); err != nil {
+		h.Logger.Errorw("Unrecorded charge isn't refunded", "chargeID", chargeID, "amount", amount)
+		h.InternalServerErrorWithoutAborting(c, err, "h.paymentClient.Refund error")
+	}
+}
+
+// refundSplitParts fully refunds the successful parts of the failed split payment and marks them as refunded.
+// The failed refund is logged and left to be refunded manually, as the other parts should still be refunded
+func (h PaymentHandler) refundSplitParts(c *gin.Context, parts []*datastore.Payment) {
+	for _, p := range parts {
+		if p.Status == datastore.SuccessPaymentStatus {
+			h.refundSplitPart(c, p)
+		}
+	}
+}
+
+func (h PaymentHandler) refundSplitPart(c *gin.Context, p *datastore.Payment) {
+	amount := p.Amount - p.RefundedAmount
+	refund := &datastore.Refund{PaymentID: p.ID, Amount: amount, Reason: splitRefundReason}
+	reserved, err := h.paymentDataStore.ReserveRefund(refund)
+	if err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.paymentDataStore.ReserveRefund error")
+		return
+	}
+	if !reserved {
+		return
+	}
+	paymentRefund, err := h.paymentClient.Refund(p.ChargeID, payment.ToSatang(amount))
+	if err != nil {
+		if releaseErr := h.paymentDataStore.ReleaseRefund(refund); releaseErr != nil {
+			h.InternalServerErrorWithoutAborting(c, releaseErr, "h.paymentDataStore.ReleaseRefund error")
+		}
+		h.InternalServerErrorWithoutAborting(c, err, "h.paymentClient.Refund error")
+		return
+	}
+	p.RefundedAmount += amount
+	if err := h.refundDataStore.Complete(refund.ID, paymentRefund.ID); err != nil {
+		// The refund stays pending, so its reference ID is logged to complete it manually
+		h.Logger.Errorw("Refund is left pending", "refundID", refund.ID, "refID", paymentRefund.ID)
+		h.InternalServerErrorWithoutAborting(c, err, "h.refundDataStore.Complete error")
+	}
+	if err := h.paymentDataStore.MarkRefunded(p.ID); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.paymentDataStore.MarkRefunded error")
+		return
+	}
+	p.Status = datastore.RefundedPaymentStatus
+}
+
+type PayInvoiceWithPromptPayResponse struct {
+	*datastore.Payment
+	QRCodeURI string `json:"qr_code_uri"`
+}
+
+// PayInvoiceWithPromptPay godoc
+// @Summary      Pay invoice with PromptPay method
+// @Description  The payment is pending until the QR code is paid or expired. Poll the payment status until it's settled
+// @Tags         Payment
+// @Param  		 invoiceID 	path	 integer 	true "ID of the invoice to pay"
+// @Param        Idempotency-Key header string false "Unique key of the request. The response of the first request which attempts the charge is replayed for the retries with the same key and body"
+// @Success      201  {object}	PayInvoiceWithPromptPayResponse "Pending payment with the URI of the QR code image"
+// @Failure      400  {object}  server.ErrorResponse "Invalid invoice ID"
+// @Failure      400  {object}  server.ErrorResponse "Invoice is already paid"
+// @Failure      400  {object}  server.ErrorResponse "Idempotency-Key must not be longer than 255 characters"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse "Patient doesn't own the specified invoice"
+// @Failure      404  {object}  server.ErrorResponse "Invoice not found"
+// @Failure      409  {object}  server.ErrorResponse "Request with the same Idempotency-Key is in progress"
+// @Failure      409  {object}  server.ErrorResponse "Another payment of the invoice is in progress"
+// @Failure      409  {object}  server.ErrorResponse "Invoice has a pending payment"
+// @Failure      422  {object}  server.ErrorResponse "Idempotency-Key has been used with another request"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/pay/{invoiceID}/promptpay [post]
+func (h PaymentHandler) PayInvoiceWithPromptPay(c *gin.Context) {
+	rawInvoice, _ := c.Get("Invoice")
+	invoice, _ := rawInvoice.(*hospital.InvoiceOverview)
+
+	markChargeAttempted(c)
+	promptPay, err := h.paymentClient.PayWithPromptPay(fmt.Sprintf("%d", invoice.Id), payment.ToSatang(invoice.Total))
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentClient.PayWithPromptPay error")
+		return
+	}
+	p := &datastore.Payment{
+		Method:    datastore.PromptPayPaymentMethod,
+		Amount:    invoice.Total,
+		ExpiresAt: &promptPay.ExpiresAt,
+		ChargeID:  promptPay.ChargeID,
+		InvoiceID: invoice.Id,
+		PatientID: h.GetUserID(c),
+		Status:    datastore.PendingPaymentStatus,
+	}
+	if err := h.paymentDataStore.Create(p); err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.Create error")
+		return
+	}
+	c.JSON(http.StatusCreated, &PayInvoiceWithPromptPayResponse{Payment: p, QRCodeURI: promptPay.QRCodeURI})
+}
+
+// GetInvoicePaymentStatus godoc
+// @Summary      Get the latest payment of the invoice
+// @Description  The pending payment is settled with the latest state of its charge, so the result is available even if the webhook is delayed
+// @Tags         Payment
+// @Param  		 invoiceID 	path	 integer 	true "ID of the invoice"
+// @Success      200  {object}	datastore.Payment "Latest payment of the invoice"
+// @Failure      400  {object}  server.ErrorResponse "Invalid invoice ID"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      404  {object}  server.ErrorResponse "Payment not found"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/pay/{invoiceID}/status [get]
+func (h PaymentHandler) GetInvoicePaymentStatus(c *gin.Context) {
+	invoiceID, err := strconv.ParseInt(c.Param("invoiceID"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidInvoiceID)
+		return
+	}
+	p, err := h.paymentDataStore.FindLatestByInvoiceID(int(invoiceID))
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.FindLatestByInvoiceID error")
+		return
+	}
+	if p == nil || p.PatientID != h.GetUserID(c) {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrPaymentNotFound)
+		return
+	}
+	if p.Status == datastore.PendingPaymentStatus {
+		if _, ok := h.settlePendingPayment(c, p); !ok {
+			return
+		}
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// GetReceipt godoc
+// @Summary      Download the receipt of the paid invoice
+// @Description  The bilingual Thai/English PDF receipt of the successful payment of the invoice for the insurance claim
+// @Tags         Payment
+// @Produce      application/pdf
+// @Param  		 invoiceID 	path	 integer 	true "ID of the invoice"
+// @Success      200  {file}    file "PDF receipt"
+// @Failure      400  {object}  server.ErrorResponse "Invalid invoice ID"
+// @Failure      400  {object}  server.ErrorResponse "Invoice isn't paid yet"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  server.ErrorResponse "Patient doesn't own the specified invoice"
+// @Failure      404  {object}  server.ErrorResponse "Invoice not found"
+// @Failure      404  {object}  server.ErrorResponse "Payment not found"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/receipt/{invoiceID} [get]
+func (h PaymentHandler) GetReceipt(c *gin.Context) {
+	rawInvoice, _ := c.Get("Invoice")
+	invoice, _ := rawInvoice.(*hospital.InvoiceOverview)
+	rawPatient, _ := c.Get("Patient")
+	patient, _ := rawPatient.(*datastore.Patient)
+	p, err := h.paymentDataStore.FindLatestByInvoiceIDAndStatus(invoice.Id, datastore.SuccessPaymentStatus)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.FindLatestByInvoiceIDAndStatus error")
+		return
+	}
+	// The invoice might be paid outside the application, e.g. at the hospital counter
+	if p == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrPaymentNotFound)
+		return
+	}
+	appointmentID, err := strconv.Atoi(invoice.AppointmentID)
+	if err != nil {
+		h.InternalServerError(c, err, "strconv.Atoi error")
+		return
+	}
+	appointment, err := h.hospitalSysClient.FindAppointmentByID(context.Background(), appointmentID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalSysClient.FindAppointmentByID error")
+		return
+	}
+	if appointment == nil || appointment.Invoice == nil {
+		h.InternalServerError(c, errors.New("appointment of the invoice not found"), "h.hospitalSysClient.FindAppointmentByID error")
+		return
+	}
+	hosPatient, err := h.hospitalSysClient.FindPatientByID(context.Background(), patient.RefID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalSysClient.FindPatientByID error")
+		return
+	}
+	pdf, err := h.receiptGenerator.Generate(newReceipt(p, appointment.Invoice, hosPatient))
+	if err != nil {
+		h.InternalServerError(c, err, "h.receiptGenerator.Generate error")
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="receipt-%d.pdf"`, invoice.Id))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func newReceipt(p *datastore.Payment, invoice *hospital.Invoice, patient *hospital.Patient) *receipt.Receipt {
+	r := &receipt.Receipt{
+		PaidAt:    p.CreatedAt,
+		ChargeID:  p.ChargeID,
+		InvoiceID: invoice.Id,
+		Amount:    p.Amount,
+	}
+	if p.PaidAt != nil {
+		r.PaidAt = *p.PaidAt
+	}
+	if p.CreditCard != nil {
+		r.Card = &receipt.Card{Brand: p.CreditCard.Brand, Last4Digits: p.CreditCard.Last4Digits}
+	}
+	if patient != nil && patient.NameTH != nil {
+		r.PatientNameTH = patient.NameTH.FullName
+	}
+	if patient != nil && patient.NameEN != nil {
+		r.PatientNameEN = patient.NameEN.FullName
+	}
+	for _, item := range invoice.InvoiceItems {
+		r.Items = append(r.Items, receipt.Item{Name: item.Name, Price: item.Price, Quantity: item.Quantity})
+	}
+	for _, dis := range invoice.InvoiceDiscounts {
+		r.Discounts = append(r.Discounts, receipt.Discount{Name: dis.Name, Amount: dis.Amount})
+	}
+	return r
+}
+
+type ListPaymentHistoryRequest struct {
+	datastore.PaymentFilters
+	PageNumber int `json:"page_number" form:"page_number" binding:"required,min=1"`
+	PerPage    int `json:"per_page" form:"per_page" binding:"required,min=1,max=100"`
+}
+
+type PaymentHistory struct {
+	*datastore.Payment
+	// Appointment is null if the invoice of the payment isn't found in the hospital system
+	Appointment *hospital.AppointmentOverview `json:"appointment"`
+}
+
+type ListPaymentHistoryResponse struct {
+	PageNumber int               `json:"page_number"`
+	PerPage    int               `json:"per_page"`
+	TotalPage  int               `json:"total_page"`
+	TotalItem  int               `json:"total_item"`
+	Payments   []*PaymentHistory `json:"payments"`
+}
+
+// ListPaymentHistory godoc
+// @Summary      List the patient's past payments with filter
+// @Description  The payments are ordered from the latest one. Each payment is joined with the appointment overview of its invoice
+// @Tags         Payment
+// @Param 	  	 ListPaymentHistoryRequest query ListPaymentHistoryRequest true "Filter with pagination options for querying"
+// @Success      200  {object}  ListPaymentHistoryResponse "List of payments with pagination information"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/history [get]
+func (h PaymentHandler) ListPaymentHistory(c *gin.Context) {
+	var req ListPaymentHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	patientID := h.GetUserID(c)
+	skip := (req.PageNumber - 1) * req.PerPage
+	payments, err := h.paymentDataStore.ListByPatientID(patientID, &req.PaymentFilters, req.PerPage, skip)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.ListByPatientID error")
+		return
+	}
+	count, err := h.paymentDataStore.CountByPatientID(patientID, &req.PaymentFilters)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.CountByPatientID error")
+		return
+	}
+	histories := make([]*PaymentHistory, len(payments))
+	if len(payments) > 0 {
+		invoiceIDs := make([]int, len(payments))
+		for i := range payments {
+			invoiceIDs[i] = payments[i].InvoiceID
+		}
+		appointments, err := h.hospitalSysClient.ListAppointmentsByInvoiceIDs(context.Background(), invoiceIDs)
+		if err != nil {
+			h.InternalServerError(c, err, "h.hospitalSysClient.ListAppointmentsByInvoiceIDs error")
+			return
+		}
+		for i := range payments {
+			histories[i] = &PaymentHistory{Payment: &payments[i], Appointment: appointments[payments[i].InvoiceID]}
+		}
+	}
+	c.JSON(http.StatusOK, &ListPaymentHistoryResponse{
+		PageNumber: req.PageNumber,
+		PerPage:    req.PerPage,
+		TotalPage:  int(math.Ceil(float64(count) / float64(req.PerPage))),
+		TotalItem:  count,
+		Payments:   histories,
+	})
+}
+
+type ListInvoicesResponse struct {
+	// OutstandingBalance is the sum of the total of the unpaid invoices
+	OutstandingBalance float64                    `json:"outstanding_balance"`
+	Invoices           []*hospital.PatientInvoice `json:"invoices"`
+}
+
+// ListInvoices godoc
+// @Summary      List all invoices of the patient with the outstanding balance
+// @Description  The invoices, both paid and unpaid, are ordered from the latest one
+// @Tags         Payment
+// @Success      200  {object}  ListInvoicesResponse "List of invoices with the outstanding balance"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/invoice [get]
+func (h PaymentHandler) ListInvoices(c *gin.Context) {
+	rawPatient, _ := c.Get("Patient")
+	patient, _ := rawPatient.(*datastore.Patient)
+	invoices, err := h.hospitalSysClient.ListInvoicesByPatientID(context.Background(), patient.RefID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.hospitalSysClient.ListInvoicesByPatientID error")
+		return
+	}
+	res := &ListInvoicesResponse{Invoices: invoices}
+	for _, invoice := range invoices {
+		if !invoice.Paid {
+			res.OutstandingBalance += invoice.Total
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+type AutopayResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetAutopay godoc
+// @Summary      Get the autopay setting of the patient
+// @Tags         Payment
+// @Success      200  {object}  AutopayResponse "Autopay setting"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/autopay [get]
+func (h PaymentHandler) GetAutopay(c *gin.Context) {
+	rawPatient, _ := c.Get("Patient")
+	patient, _ := rawPatient.(*datastore.Patient)
+	c.JSON(http.StatusOK, &AutopayResponse{Enabled: patient.Autopay})
+}
+
+type SetAutopayRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// SetAutopay godoc
+// @Summary      Opt in or out of autopay
+// @Description  The invoice of the completed appointment is charged with the default card once it's issued. The patient has to pay the invoice manually if the charge fails
+// @Tags         Payment
+// @Param 	  	 SetAutopayRequest body SetAutopayRequest true "Whether autopay is enabled"
+// @Success      200  {object}  AutopayResponse "Autopay setting"
+// @Failure      400  {object}  server.ErrorResponse "Invalid request body"
+// @Failure      400  {object}  server.ErrorResponse "Patient not found"
+// @Failure      401  {object}  server.ErrorResponse "Unauthorized"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Security     UserID
+// @Security     JWSToken
+// @Router       /payment/autopay [put]
+func (h PaymentHandler) SetAutopay(c *gin.Context) {
+	var req SetAutopayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	rawPatient, _ := c.Get("Patient")
+	patient, _ := rawPatient.(*datastore.Patient)
+	patient.Autopay = *req.Enabled
+	if err := h.patientDataStore.Save(patient); err != nil {
+		h.InternalServerError(c, err, "h.patientDataStore.Save error")
+		return
+	}
+	c.JSON(http.StatusOK, &AutopayResponse{Enabled: patient.Autopay})
+}
+
+// HandleOmiseWebhook godoc
+// @Summary      Receive the event from Omise to settle the pending payment
+// @Description  The charge is retrieved from Omise instead of trusting the event data. The event is acknowledged if there is nothing to update
+// @Tags         Payment
+// @Accept       json
+// @Param        Omise-Signature           header  string  false  "HMAC-SHA256 signatures of the event"
+// @Param        Omise-Signature-Timestamp header  string  false  "Timestamp used to sign the event"
+// @Success      200
+// @Failure      400  {object}  server.ErrorResponse "Invalid webhook event"
+// @Failure      401  {object}  server.ErrorResponse "Invalid webhook signature"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Router       /payment/webhook/omise [post]
+func (h PaymentHandler) HandleOmiseWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidWebhookEvent)
+		return
+	}
+	webhookEvent, err := h.paymentClient.ParseWebhookEvent(body, c.GetHeader("Omise-Signature"), c.GetHeader("Omise-Signature-Timestamp"))
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidWebhookSignature) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrInvalidWebhookSignature)
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidWebhookEvent)
+		return
+	}
+	if !strings.HasPrefix(webhookEvent.Key, "charge.") || webhookEvent.ChargeID == "" {
+		c.AbortWithStatus(http.StatusOK)
+		return
+	}
+
+	p, err := h.paymentDataStore.FindByChargeID(webhookEvent.ChargeID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.FindByChargeID error")
+		return
+	}
+	if p == nil || p.Status != datastore.PendingPaymentStatus {
+		c.AbortWithStatus(http.StatusOK)
+		return
+	}
+	if _, ok := h.settlePendingPayment(c, p); !ok {
+		return
+	}
+	c.AbortWithStatus(http.StatusOK)
+}
+
+// HandleCreditCardReturn godoc
+// @Summary      Finalize the credit card payment after the patient is redirected back from 3-D Secure page
+// @Description  The charge is retrieved from Omise to settle the pending payment. The invoice is marked as paid only if the charge is authorized.
+// @Description  The route isn't authenticated, so the payment status isn't returned. The client gets it from /payment/pay/{invoiceID}/status instead
+// @Tags         Payment
+// @Param        ref_id  query  integer  true  "ID of the paid invoice"
+// @Success      204  "Pending payment of the invoice is settled if its charge is no longer pending"
+// @Failure      400  {object}  server.ErrorResponse "Invalid invoice ID"
+// @Failure      500  {object}  server.ErrorResponse "Internal server error"
+// @Router       /payment/credit-card/return [get]
+func (h PaymentHandler) HandleCreditCardReturn(c *gin.Context) {
+	invoiceID, err := strconv.ParseInt(c.Query("ref_id"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidInvoiceID)
+		return
+	}
+	p, err := h.paymentDataStore.FindLatestByInvoiceID(int(invoiceID))
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.FindLatestByInvoiceID error")
+		return
+	}
+	if p != nil && p.Status == datastore.PendingPaymentStatus {
+		if _, ok := h.settlePendingPayment(c, p); !ok {
+			return
+		}
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// settlePendingPayment settles the pending payment with the latest state of its charge and notifies the patient.
+// The status of p is updated if the charge is no longer pending. It reports false if the error response is written
+func (h PaymentHandler) settlePendingPayment(c *gin.Context, p *datastore.Payment) (*payment.Payment, bool) {
+	charge, err := h.paymentClient.GetCharge(p.ChargeID)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentClient.GetCharge error")
+		return nil, false
+	}
+	if charge.Pending {
+		return charge, true
+	}
+
+	status := datastore.FailedPaymentStatus
+	if charge.Success {
+		status = datastore.SuccessPaymentStatus
+	}
+	paidAt := h.clock.NowPointer()
+	settled, err := h.paymentDataStore.SettlePending(p.ID, status, paidAt)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.SettlePending error")
+		return nil, false
+	}
+	// Both the webhook and the return might settle the payment, but only the first one notifies the patient
+	p.Status = status
+	if settled {
+		p.PaidAt = paidAt
+		// The split payment has already failed when its part is settled afterwards, so the authorized part is refunded instead
+		if p.SplitID != nil {
+			if status == datastore.SuccessPaymentStatus {
+				h.refundSplitPart(c, p)
+			}
+			return charge, true
+		}
+		if status == datastore.SuccessPaymentStatus {
+			h.markInvoicePaid(c, p)
+		}
+		h.notifyPaymentResult(c, p, status, charge.FailureMessage)
+	}
+	return charge, true
+}
+
+// markInvoicePaid marks the invoice of the successful payment as paid in the hospital system and completes its outbox.
+// The outbox is left pending if it fails, so the reconciler retries it and the patient still gets the successful result
+func (h PaymentHandler) markInvoicePaid(c *gin.Context, p *datastore.Payment) {
+	if err := h.hospitalSysClient.PaidInvoice(context.Background(), p.InvoiceID); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.hospitalSysClient.PaidInvoice error")
+		if err := h.outboxDataStore.RecordFailure(p.ID, err.Error()); err != nil {
+			h.InternalServerErrorWithoutAborting(c, err, "h.outboxDataStore.RecordFailure error")
+		}
+		return
+	}
+	if err := h.outboxDataStore.MarkDone(p.ID, h.clock.Now()); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.outboxDataStore.MarkDone error")
+	}
+}
+
+// notifyPaymentResult saves the notification, publishes it to the patient's live streams and sends push notification
+func (h PaymentHandler) notifyPaymentResult(c *gin.Context, p *datastore.Payment, status datastore.PaymentStatus, failureMessage *string) {
+	title := "Payment successful"
+	body := fmt.Sprintf("Your payment of %.2f THB for invoice #%d is successful", p.Amount, p.InvoiceID)
+	if status != datastore.SuccessPaymentStatus {
+		title = "Payment failed"
+		body = fmt.Sprintf("Your payment of %.2f THB for invoice #%d has failed", p.Amount, p.InvoiceID)
+		if failureMessage != nil {
+			body = fmt.Sprintf("%s: %s", body, *failureMessage)
+		}
+	}
+	ctx := context.Background()
+	noti := &datastore.Notification{Title: title, Body: body, PatientID: p.PatientID}
+	if err := h.notificationDataStore.Create(noti); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.notificationDataStore.Create error")
+		return
+	}
+	if err := h.eventBroker.Publish(ctx, p.PatientID, event.NotificationType, noti); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.eventBroker.Publish error")
+	}
+	if count, err := h.notificationDataStore.CountUnRead(p.PatientID); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.notificationDataStore.CountUnRead error")
+	} else if err := h.eventBroker.Publish(ctx, p.PatientID, event.UnreadCountType, &event.UnreadCount{Count: count}); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.eventBroker.Publish error")
+	}
+
+	notiParam := notification.SendParams{
+		ID:    fmt.Sprintf("%d", p.PatientID),
+		Title: title,
+		Body:  body,
+	}
+	notiData := map[string]string{"invoiceID": fmt.Sprintf("%d", p.InvoiceID)}
+	if err := h.notificationClient.Send(ctx, notiParam, notiData); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.notificationClient.Send error")
+	}
+}
+
 func (h PaymentHandler) CreateOrParseCustomer(c *gin.Context) {
 	patientID := h.GetUserID(c)
 	patient, err := h.patientDataStore.FindByID(patientID)
@@ -339,7 +1151,24 @@ func (h PaymentHandler) VerifyCreditCardOwnership(c *gin.Context) {
 	c.Set("CreditCard", card)
 }
 
+// VerifyCreditCardNotExpired rejects the expired card before it's charged, so the patient is asked to re-add the card instead of the failed charge
+func (h PaymentHandler) VerifyCreditCardNotExpired(c *gin.Context) {
+	rawCard, _ := c.Get("CreditCard")
+	card, _ := rawCard.(*datastore.CreditCard)
+	if card.IsExpired(h.clock.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrCreditCardExpired)
+	}
+}
+
 func (h PaymentHandler) ParseAndVerifyUnpaidInvoiceOwnership(c *gin.Context) {
+	h.parseAndVerifyInvoiceOwnership(c, false)
+}
+
+func (h PaymentHandler) ParseAndVerifyPaidInvoiceOwnership(c *gin.Context) {
+	h.parseAndVerifyInvoiceOwnership(c, true)
+}
+
+func (h PaymentHandler) parseAndVerifyInvoiceOwnership(c *gin.Context, paid bool) {
 	invoiceID, err := strconv.ParseInt(c.Param("invoiceID"), 10, 32)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidInvoiceID)
@@ -354,8 +1183,12 @@ func (h PaymentHandler) ParseAndVerifyUnpaidInvoiceOwnership(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusNotFound, ErrInvoiceNotFound)
 		return
 	}
-	if invoice.Paid {
-		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvoicePaid)
+	if invoice.Paid != paid {
+		if invoice.Paid {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvoicePaid)
+		} else {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvoiceNotPaid)
+		}
 		return
 	}
 	patient, err := h.patientDataStore.FindByID(h.GetUserID(c))
@@ -368,4 +1201,197 @@ func (h PaymentHandler) ParseAndVerifyUnpaidInvoiceOwnership(c *gin.Context) {
 		return
 	}
 	c.Set("Invoice", invoice)
+	c.Set("Patient", patient)
+}
+
+// VerifyNoPendingPayment rejects the payment if the latest payment of the invoice is still pending, e.g. unpaid PromptPay QR code,
+// so the invoice isn't charged twice. The pending payment is settled first in case the webhook is delayed
+func (h PaymentHandler) VerifyNoPendingPayment(c *gin.Context) {
+	rawInvoice, _ := c.Get("Invoice")
+	invoice, _ := rawInvoice.(*hospital.InvoiceOverview)
+	p, err := h.paymentDataStore.FindLatestByInvoiceID(invoice.Id)
+	if err != nil {
+		h.InternalServerError(c, err, "h.paymentDataStore.FindLatestByInvoiceID error")
+		return
+	}
+	if p == nil || p.Status == datastore.FailedPaymentStatus {
+		return
+	}
+	if p.Status == datastore.PendingPaymentStatus {
+		if _, ok := h.settlePendingPayment(c, p); !ok {
+			return
+		}
+	}
+	switch p.Status {
+	case datastore.PendingPaymentStatus:
+		c.AbortWithStatusJSON(http.StatusConflict, ErrInvoicePaymentPending)
+	case datastore.SuccessPaymentStatus:
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvoicePaid)
+	}
+}
+
+// LockInvoicePayment serializes the payment requests of the invoice across the replicas until the request is finished
+func (h PaymentHandler) LockInvoicePayment(c *gin.Context) {
+	invoiceID, err := strconv.ParseInt(c.Param("invoiceID"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidInvoiceID)
+		return
+	}
+	ctx := context.Background()
+	key, token := cache.InvoicePaymentLockKey(int(invoiceID)), uuid.NewString()
+	locked, err := h.cacheClient.SetIfNotExists(ctx, key, token, h.lockTTL)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.SetIfNotExists error")
+		return
+	}
+	if !locked {
+		c.AbortWithStatusJSON(http.StatusConflict, ErrInvoicePaymentInProgress)
+		return
+	}
+	c.Next()
+	// The lock might be expired and acquired by another request, so only release the lock of this request
+	if _, err := h.cacheClient.DeleteIfEquals(ctx, key, token); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.cacheClient.DeleteIfEquals error")
+	}
+}
+
+// idempotentResponse is the response of the request with Idempotency-Key. It isn't completed while the first request is in progress
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Body        []byte `json:"body"`
+	Status      int    `json:"status"`
+	Completed   bool   `json:"completed"`
+}
+
+// responseRecorder captures the response body while writing it to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ReplayIdempotentRequest stores the response of the request with Idempotency-Key header and replays it for the retries with the same key.
+// Only the response after the charge is attempted is stored, so the retry never charges again. The key of the request which fails
+// before that, e.g. invalid request or locked invoice, is deleted so it can be retried. The request without the header is handled as usual
+func (h PaymentHandler) ReplayIdempotentRequest(c *gin.Context) {
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if idempotencyKey == "" {
+		return
+	}
+	if len(idempotencyKey) > 255 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidIdempotencyKey)
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	ctx := context.Background()
+	key := cache.IdempotencyKey(h.GetUserID(c), idempotencyKey)
+	fingerprint := fmt.Sprintf("%s %s %x", c.Request.Method, c.Request.URL.Path, sha256.Sum256(body))
+	inProgress, err := json.Marshal(&idempotentResponse{Fingerprint: fingerprint})
+	if err != nil {
+		h.InternalServerError(c, err, "json.Marshal error")
+		return
+	}
+	isFirst, err := h.cacheClient.SetIfNotExists(ctx, key, string(inProgress), h.idempotencyKeyTTL)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.SetIfNotExists error")
+		return
+	}
+	if !isFirst {
+		h.replayResponse(c, key, fingerprint)
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		// The panic is written as 500 by the recovery middleware, but the charge might be created before it
+		if c.GetBool("ChargeAttempted") {
+			body, _ := json.Marshal(ErrPaymentResultUnknown)
+			h.storeResponse(c, key, fingerprint, http.StatusInternalServerError, body)
+		} else {
+			h.deleteIdempotencyKey(c, key, string(inProgress))
+		}
+		panic(r)
+	}()
+	c.Next()
+	if !c.GetBool("ChargeAttempted") {
+		h.deleteIdempotencyKey(c, key, string(inProgress))
+		return
+	}
+	h.storeResponse(c, key, fingerprint, recorder.Status(), recorder.body.Bytes())
+}
+
+// markChargeAttempted records that the charge might be created, so the response is stored for the retries with the same Idempotency-Key
+func markChargeAttempted(c *gin.Context) {
+	c.Set("ChargeAttempted", true)
+}
+
+func (h PaymentHandler) storeResponse(c *gin.Context, key, fingerprint string, status int, body []byte) {
+	res, err := json.Marshal(&idempotentResponse{
+		Fingerprint: fingerprint,
+		Body:        body,
+		Status:      status,
+		Completed:   true,
+	})
+	if err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "json.Marshal error")
+		return
+	}
+	if err := h.cacheClient.Set(context.Background(), key, string(res), h.idempotencyKeyTTL); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.cacheClient.Set error")
+	}
+}
+
+// deleteIdempotencyKey deletes the in-progress key of this request, which might be expired and set by another request
+func (h PaymentHandler) deleteIdempotencyKey(c *gin.Context, key, inProgress string) {
+	if _, err := h.cacheClient.DeleteIfEquals(context.Background(), key, inProgress); err != nil {
+		h.InternalServerErrorWithoutAborting(c, err, "h.cacheClient.DeleteIfEquals error")
+	}
+}
+
+func (h PaymentHandler) replayResponse(c *gin.Context, key, fingerprint string) {
+	raw, err := h.cacheClient.Get(context.Background(), key, false)
+	if err != nil {
+		h.InternalServerError(c, err, "h.cacheClient.Get error")
+		return
+	}
+	// The key is expired right after it's checked
+	if raw == "" {
+		c.AbortWithStatusJSON(http.StatusConflict, ErrRequestInProgress)
+		return
+	}
+	var res idempotentResponse
+	if err := json.Unmarshal([]byte(raw), &res); err != nil {
+		h.InternalServerError(c, err, "json.Unmarshal error")
+		return
+	}
+	if res.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, ErrIdempotencyKeyReused)
+		return
+	}
+	if !res.Completed {
+		c.AbortWithStatusJSON(http.StatusConflict, ErrRequestInProgress)
+		return
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(res.Status, "application/json; charset=utf-8", res.Body)
+	c.Abort()
 }
@@ -2,6 +2,8 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,18 +13,27 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/synthia-telemed/backend-api/cmd/patient-api/handler"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/receipt"
 	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
 	"github.com/synthia-telemed/backend-api/test/mock_clock"
 	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_event"
 	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_notification"
 	"github.com/synthia-telemed/backend-api/test/mock_payment"
+	"github.com/synthia-telemed/backend-api/test/mock_receipt"
 	"go.uber.org/zap"
+	"io"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"time"
 )
 
@@ -42,6 +53,13 @@ var _ = Describe("Payment Handler", func() {
 		mockPaymentDataStore    *mock_datastore.MockPaymentDataStore
 		mockhospitalSysClient   *mock_hospital_client.MockSystemClient
 		mockClock               *mock_clock.MockClock
+		mockNotificationDS      *mock_datastore.MockNotificationDataStore
+		mockNotificationClient  *mock_notification.MockClient
+		mockEventBroker         *mock_event.MockBroker
+		mockCacheClient         *mock_cache_client.MockClient
+		mockOutboxDataStore     *mock_datastore.MockPaidInvoiceOutboxDataStore
+		mockRefundDataStore     *mock_datastore.MockRefundDataStore
+		mockReceiptGenerator    *mock_receipt.MockGenerator
 	)
 
 	BeforeEach(func() {
@@ -57,7 +75,14 @@ var _ = Describe("Payment Handler", func() {
 		mockPaymentClient = mock_payment.NewMockClient(mockCtrl)
 		mockhospitalSysClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
 		mockClock = mock_clock.NewMockClock(mockCtrl)
-		h = handler.NewPaymentHandler(mockPaymentClient, mockPatientDataStore, mockCreditCardDataStore, mockhospitalSysClient, mockPaymentDataStore, mockClock, zap.NewNop().Sugar())
+		mockNotificationDS = mock_datastore.NewMockNotificationDataStore(mockCtrl)
+		mockNotificationClient = mock_notification.NewMockClient(mockCtrl)
+		mockEventBroker = mock_event.NewMockBroker(mockCtrl)
+		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
+		mockOutboxDataStore = mock_datastore.NewMockPaidInvoiceOutboxDataStore(mockCtrl)
+		mockRefundDataStore = mock_datastore.NewMockRefundDataStore(mockCtrl)
+		mockReceiptGenerator = mock_receipt.NewMockGenerator(mockCtrl)
+		h = handler.NewPaymentHandler(mockPaymentClient, mockPatientDataStore, mockCreditCardDataStore, mockhospitalSysClient, mockPaymentDataStore, mockOutboxDataStore, mockRefundDataStore, mockNotificationDS, mockNotificationClient, mockEventBroker, mockCacheClient, mockReceiptGenerator, mockClock, time.Minute, time.Hour, zap.NewNop().Sugar())
 	})
 
 	JustBeforeEach(func() {
@@ -68,6 +93,13 @@ var _ = Describe("Payment Handler", func() {
 		mockCtrl.Finish()
 	})
 
+	pendingRefundID := uint(rand.Uint32())
+	reservePendingRefund := func(refund *datastore.Refund) (bool, error) {
+		refund.ID = pendingRefundID
+		refund.Status = datastore.PendingRefundStatus
+		return true, nil
+	}
+
 	Context("Add credit card", func() {
 		var (
 			req *handler.AddCreditCardRequest
@@ -196,6 +228,7 @@ var _ = Describe("Payment Handler", func() {
 		When("patient has no credit cards", func() {
 			BeforeEach(func() {
 				mockCreditCardDataStore.EXPECT().FindByPatientID(patientID).Return([]datastore.CreditCard{}, nil).Times(1)
+				mockClock.EXPECT().Now().Return(time.Now()).Times(1)
 			})
 			It("should return 200 with empty list", func() {
 				Expect(rec.Code).To(Equal(http.StatusOK))
@@ -207,13 +240,19 @@ var _ = Describe("Payment Handler", func() {
 			var cards []datastore.CreditCard
 			BeforeEach(func() {
 				cards = testhelper.GenerateCreditCards(3)
+				cards[1].ExpiryMonth, cards[1].ExpiryYear = 9, 2022
+				cards[2].ExpiryMonth, cards[2].ExpiryYear = 10, 2022
 				mockCreditCardDataStore.EXPECT().FindByPatientID(patientID).Return(cards, nil).Times(1)
+				mockClock.EXPECT().Now().Return(time.Date(2022, 10, 31, 23, 59, 0, 0, time.UTC)).Times(1)
 			})
-			It("should return 200 with list of cards", func() {
+			It("should return 200 with list of cards and their expiry status", func() {
 				Expect(rec.Code).To(Equal(http.StatusOK))
-				var c []datastore.CreditCard
+				var c []handler.SavedCreditCard
 				Expect(json.Unmarshal(rec.Body.Bytes(), &c)).To(Succeed())
 				Expect(c).To(HaveLen(len(cards)))
+				Expect(c[0].IsExpired).To(BeFalse())
+				Expect(c[1].IsExpired).To(BeTrue())
+				Expect(c[2].IsExpired).To(BeFalse())
 			})
 		})
 	})
@@ -330,6 +369,34 @@ var _ = Describe("Payment Handler", func() {
 		})
 	})
 
+	Context("VerifyCreditCardNotExpired", func() {
+		var card *datastore.CreditCard
+		BeforeEach(func() {
+			handlerFunc = h.VerifyCreditCardNotExpired
+			card = testhelper.GenerateCreditCard()
+			card.ExpiryMonth, card.ExpiryYear = 10, 2022
+			c.Set("CreditCard", card)
+		})
+		When("credit card is expired", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)).Times(1)
+			})
+			It("should return 400", func() {
+				Expect(c.IsAborted()).To(BeTrue())
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrCreditCardExpired)
+			})
+		})
+		When("credit card expires at the end of this month", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(time.Date(2022, 10, 31, 23, 59, 0, 0, time.UTC)).Times(1)
+			})
+			It("should pass to the next handler", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+			})
+		})
+	})
+
 	Context("SetCreditCardIsDefault", func() {
 		var (
 			card *datastore.CreditCard
@@ -541,6 +608,382 @@ var _ = Describe("Payment Handler", func() {
 		})
 	})
 
+	Context("ParseAndVerifyPaidInvoiceOwnership", func() {
+		var invoiceID int
+		BeforeEach(func() {
+			handlerFunc = h.ParseAndVerifyPaidInvoiceOwnership
+			invoiceID = int(rand.Int31())
+			c.AddParam("invoiceID", fmt.Sprintf("%d", invoiceID))
+		})
+		When("invoice is not paid", func() {
+			BeforeEach(func() {
+				i := &hospital.InvoiceOverview{PatientID: uuid.New().String(), Paid: false}
+				mockhospitalSysClient.EXPECT().FindInvoiceByID(gomock.Any(), invoiceID).Return(i, nil).Times(1)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvoiceNotPaid)
+			})
+		})
+		When("patient's refID is not equal to patient ID in invoice", func() {
+			BeforeEach(func() {
+				p := &datastore.Patient{ID: patientID, RefID: uuid.New().String()}
+				i := &hospital.InvoiceOverview{PatientID: uuid.New().String(), Paid: true}
+				mockhospitalSysClient.EXPECT().FindInvoiceByID(gomock.Any(), invoiceID).Return(i, nil).Times(1)
+				mockPatientDataStore.EXPECT().FindByID(patientID).Return(p, nil).Times(1)
+			})
+			It("should return 403", func() {
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				p := &datastore.Patient{ID: patientID, RefID: uuid.New().String()}
+				i := &hospital.InvoiceOverview{Id: invoiceID, PatientID: p.RefID, Paid: true}
+				mockhospitalSysClient.EXPECT().FindInvoiceByID(gomock.Any(), invoiceID).Return(i, nil).Times(1)
+				mockPatientDataStore.EXPECT().FindByID(patientID).Return(p, nil).Times(1)
+			})
+			It("set invoice and patient to the context", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				i, ok := c.Get("Invoice")
+				Expect(ok).To(BeTrue())
+				Expect(i.(*hospital.InvoiceOverview).Id).To(Equal(invoiceID))
+				p, ok := c.Get("Patient")
+				Expect(ok).To(BeTrue())
+				Expect(p.(*datastore.Patient).ID).To(Equal(patientID))
+			})
+		})
+	})
+
+	Context("GetReceipt", func() {
+		var (
+			invoice     *hospital.InvoiceOverview
+			patient     *datastore.Patient
+			p           *datastore.Payment
+			appointment *hospital.Appointment
+			hosPatient  *hospital.Patient
+		)
+		BeforeEach(func() {
+			handlerFunc = h.GetReceipt
+			patient = &datastore.Patient{ID: patientID, RefID: uuid.NewString()}
+			invoice = &hospital.InvoiceOverview{Id: int(rand.Int31()), AppointmentID: "12", PatientID: patient.RefID, Paid: true}
+			paidAt := time.Now()
+			p = &datastore.Payment{
+				ID:         uint(rand.Uint32()),
+				ChargeID:   uuid.NewString(),
+				InvoiceID:  invoice.Id,
+				PatientID:  patientID,
+				Method:     datastore.CreditCardPaymentMethod,
+				Amount:     900,
+				Status:     datastore.SuccessPaymentStatus,
+				PaidAt:     &paidAt,
+				CreditCard: &datastore.CreditCard{Brand: "Visa", Last4Digits: "4242"},
+			}
+			appointment = &hospital.Appointment{
+				Id: "12",
+				Invoice: &hospital.Invoice{
+					Id:               invoice.Id,
+					Total:            1000,
+					Paid:             true,
+					InvoiceItems:     []*hospital.InvoiceItem{{Name: "Consultation", Price: 1000, Quantity: 1}},
+					InvoiceDiscounts: []*hospital.InvoiceDiscount{{Name: "Member", Amount: 100}},
+				},
+			}
+			hosPatient = &hospital.Patient{
+				Id:     patient.RefID,
+				NameTH: hospital.NewName("นาย", "สมชาย", "ใจดี"),
+				NameEN: hospital.NewName("Mr.", "Somchai", "Jaidee"),
+			}
+			c.Set("Invoice", invoice)
+			c.Set("Patient", patient)
+		})
+		When("find payment error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceIDAndStatus(invoice.Id, datastore.SuccessPaymentStatus).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("the invoice is paid outside the application", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceIDAndStatus(invoice.Id, datastore.SuccessPaymentStatus).Return(nil, nil).Times(1)
+			})
+			It("should return 404", func() {
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrPaymentNotFound)
+			})
+		})
+		When("find appointment error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceIDAndStatus(invoice.Id, datastore.SuccessPaymentStatus).Return(p, nil).Times(1)
+				mockhospitalSysClient.EXPECT().FindAppointmentByID(gomock.Any(), 12).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("find patient error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceIDAndStatus(invoice.Id, datastore.SuccessPaymentStatus).Return(p, nil).Times(1)
+				mockhospitalSysClient.EXPECT().FindAppointmentByID(gomock.Any(), 12).Return(appointment, nil).Times(1)
+				mockhospitalSysClient.EXPECT().FindPatientByID(gomock.Any(), patient.RefID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("generate receipt error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceIDAndStatus(invoice.Id, datastore.SuccessPaymentStatus).Return(p, nil).Times(1)
+				mockhospitalSysClient.EXPECT().FindAppointmentByID(gomock.Any(), 12).Return(appointment, nil).Times(1)
+				mockhospitalSysClient.EXPECT().FindPatientByID(gomock.Any(), patient.RefID).Return(hosPatient, nil).Times(1)
+				mockReceiptGenerator.EXPECT().Generate(gomock.Any()).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			var pdf []byte
+			BeforeEach(func() {
+				pdf = []byte("%PDF-1.4")
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceIDAndStatus(invoice.Id, datastore.SuccessPaymentStatus).Return(p, nil).Times(1)
+				mockhospitalSysClient.EXPECT().FindAppointmentByID(gomock.Any(), 12).Return(appointment, nil).Times(1)
+				mockhospitalSysClient.EXPECT().FindPatientByID(gomock.Any(), patient.RefID).Return(hosPatient, nil).Times(1)
+				mockReceiptGenerator.EXPECT().Generate(&receipt.Receipt{
+					PaidAt:        *p.PaidAt,
+					PatientNameTH: hosPatient.NameTH.FullName,
+					PatientNameEN: hosPatient.NameEN.FullName,
+					ChargeID:      p.ChargeID,
+					Card:          &receipt.Card{Brand: "Visa", Last4Digits: "4242"},
+					Items:         []receipt.Item{{Name: "Consultation", Price: 1000, Quantity: 1}},
+					Discounts:     []receipt.Discount{{Name: "Member", Amount: 100}},
+					InvoiceID:     invoice.Id,
+					Amount:        900,
+				}).Return(pdf, nil).Times(1)
+			})
+			It("should return 200 with PDF receipt", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(rec.Header().Get("Content-Type")).To(Equal("application/pdf"))
+				Expect(rec.Header().Get("Content-Disposition")).To(Equal(fmt.Sprintf(`attachment; filename="receipt-%d.pdf"`, invoice.Id)))
+				Expect(rec.Body.Bytes()).To(Equal(pdf))
+			})
+		})
+	})
+
+	Context("ListPaymentHistory", func() {
+		var (
+			payments     []datastore.Payment
+			appointments map[int]*hospital.AppointmentOverview
+			filters      *datastore.PaymentFilters
+		)
+		BeforeEach(func() {
+			handlerFunc = h.ListPaymentHistory
+			filters = &datastore.PaymentFilters{Status: datastore.SuccessPaymentStatus}
+			c.Request = httptest.NewRequest("GET", "/?page_number=2&per_page=2&status=success", nil)
+			payments = make([]datastore.Payment, 2)
+			appointments = make(map[int]*hospital.AppointmentOverview)
+			for i := range payments {
+				payments[i] = *testhelper.GenerateDataStorePayment(datastore.CreditCardPaymentMethod, datastore.SuccessPaymentStatus, testhelper.GenerateHospitalInvoice(true), testhelper.GeneratePayment(true), testhelper.GenerateCreditCard())
+			}
+			appointments[payments[0].InvoiceID] = testhelper.GenerateAppointmentOverview(hospital.AppointmentStatusCompleted)
+		})
+		When("query is invalid", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/?page_number=1&per_page=10&method=cash", nil)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("pagination is not provided", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/", nil)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("list payments error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().ListByPatientID(patientID, filters, 2, 2).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("count payments error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().ListByPatientID(patientID, filters, 2, 2).Return(payments, nil).Times(1)
+				mockPaymentDataStore.EXPECT().CountByPatientID(patientID, filters).Return(0, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("list appointments error", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().ListByPatientID(patientID, filters, 2, 2).Return(payments, nil).Times(1)
+				mockPaymentDataStore.EXPECT().CountByPatientID(patientID, filters).Return(3, nil).Times(1)
+				mockhospitalSysClient.EXPECT().ListAppointmentsByInvoiceIDs(gomock.Any(), []int{payments[0].InvoiceID, payments[1].InvoiceID}).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no payment is found", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().ListByPatientID(patientID, filters, 2, 2).Return(nil, nil).Times(1)
+				mockPaymentDataStore.EXPECT().CountByPatientID(patientID, filters).Return(0, nil).Times(1)
+			})
+			It("should return 200 with empty list", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.ListPaymentHistoryResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Payments).To(BeEmpty())
+				Expect(res.TotalItem).To(BeZero())
+				Expect(res.TotalPage).To(BeZero())
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().ListByPatientID(patientID, filters, 2, 2).Return(payments, nil).Times(1)
+				mockPaymentDataStore.EXPECT().CountByPatientID(patientID, filters).Return(3, nil).Times(1)
+				mockhospitalSysClient.EXPECT().ListAppointmentsByInvoiceIDs(gomock.Any(), []int{payments[0].InvoiceID, payments[1].InvoiceID}).Return(appointments, nil).Times(1)
+			})
+			It("should return 200 with payments joined with appointments", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.ListPaymentHistoryResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.PageNumber).To(Equal(2))
+				Expect(res.PerPage).To(Equal(2))
+				Expect(res.TotalItem).To(Equal(3))
+				Expect(res.TotalPage).To(Equal(2))
+				Expect(res.Payments).To(HaveLen(2))
+				Expect(res.Payments[0].InvoiceID).To(Equal(payments[0].InvoiceID))
+				Expect(res.Payments[0].Appointment).ToNot(BeNil())
+				Expect(res.Payments[0].Appointment.Id).To(Equal(appointments[payments[0].InvoiceID].Id))
+				Expect(res.Payments[1].InvoiceID).To(Equal(payments[1].InvoiceID))
+				Expect(res.Payments[1].Appointment).To(BeNil())
+			})
+		})
+	})
+
+	Context("ListInvoices", func() {
+		var (
+			patient  *datastore.Patient
+			invoices []*hospital.PatientInvoice
+		)
+		BeforeEach(func() {
+			handlerFunc = h.ListInvoices
+			patient = testhelper.GeneratePatient()
+			c.Set("Patient", patient)
+			invoices = []*hospital.PatientInvoice{
+				{Invoice: hospital.Invoice{Id: 3, Total: 800, Paid: false}},
+				{Invoice: hospital.Invoice{Id: 2, Total: 500, Paid: true}},
+				{Invoice: hospital.Invoice{Id: 1, Total: 250.5, Paid: false}},
+			}
+		})
+		When("list invoices error", func() {
+			BeforeEach(func() {
+				mockhospitalSysClient.EXPECT().ListInvoicesByPatientID(gomock.Any(), patient.RefID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no invoice is found", func() {
+			BeforeEach(func() {
+				mockhospitalSysClient.EXPECT().ListInvoicesByPatientID(gomock.Any(), patient.RefID).Return([]*hospital.PatientInvoice{}, nil).Times(1)
+			})
+			It("should return 200 with zero outstanding balance", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.ListInvoicesResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Invoices).To(BeEmpty())
+				Expect(res.OutstandingBalance).To(BeZero())
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockhospitalSysClient.EXPECT().ListInvoicesByPatientID(gomock.Any(), patient.RefID).Return(invoices, nil).Times(1)
+			})
+			It("should return 200 with invoices and the outstanding balance of unpaid invoices", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.ListInvoicesResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Invoices).To(HaveLen(3))
+				Expect(res.Invoices[0].Id).To(Equal(3))
+				Expect(res.Invoices[1].Paid).To(BeTrue())
+				Expect(res.OutstandingBalance).To(Equal(1050.5))
+			})
+		})
+	})
+
+	Context("GetAutopay", func() {
+		BeforeEach(func() {
+			handlerFunc = h.GetAutopay
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				p := testhelper.GeneratePatient()
+				p.Autopay = true
+				c.Set("Patient", p)
+			})
+			It("should return 200 with the setting", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res handler.AutopayResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Enabled).To(BeTrue())
+			})
+		})
+	})
+
+	Context("SetAutopay", func() {
+		var p *datastore.Patient
+		BeforeEach(func() {
+			handlerFunc = h.SetAutopay
+			p = testhelper.GeneratePatient()
+			c.Set("Patient", p)
+			enabled := true
+			reqBody, err := json.Marshal(&handler.SetAutopayRequest{Enabled: &enabled})
+			Expect(err).To(BeNil())
+			c.Request = httptest.NewRequest("PUT", "/", bytes.NewReader(reqBody))
+		})
+		When("request body is invalid", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("PUT", "/", bytes.NewReader([]byte(`{}`)))
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("save patient error", func() {
+			BeforeEach(func() {
+				mockPatientDataStore.EXPECT().Save(p).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockPatientDataStore.EXPECT().Save(p).Return(nil).Times(1)
+			})
+			It("should enable autopay and return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(p.Autopay).To(BeTrue())
+				var res handler.AutopayResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Enabled).To(BeTrue())
+			})
+		})
+	})
+
 	Context("PayInvoiceWithCreditCard", func() {
 		var (
 			creditCard   *datastore.CreditCard
@@ -557,7 +1000,7 @@ var _ = Describe("Payment Handler", func() {
 		})
 		When("pay with credit card error", func() {
 			BeforeEach(func() {
-				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, int(invoice.Total*100)).Return(nil, testhelper.MockError).Times(1)
+				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, payment.ToSatang(invoice.Total)).Return(nil, testhelper.MockError).Times(1)
 			})
 			It("should return 500", func() {
 				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
@@ -566,22 +1009,26 @@ var _ = Describe("Payment Handler", func() {
 		When("hospital sys client PaidInvoice error", func() {
 			BeforeEach(func() {
 				p := testhelper.GeneratePayment(true)
-				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, int(invoice.Total*100)).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, payment.ToSatang(invoice.Total)).Return(p, nil).Times(1)
 				now := time.Now()
 				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().Create(gomock.Any()).Return(nil).Times(1)
 				mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), invoice.Id).Return(testhelper.MockError).Times(1)
+				mockOutboxDataStore.EXPECT().RecordFailure(gomock.Any(), testhelper.MockError.Error()).Return(nil).Times(1)
 			})
-			It("should return 500", func() {
-				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			It("should leave the outbox pending and return 201 with success status", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				var res handler.PayInvoiceWithCreditCardResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Status).To(Equal(datastore.SuccessPaymentStatus))
 			})
 		})
 		When("create payment in datastore error", func() {
 			BeforeEach(func() {
 				p := testhelper.GeneratePayment(true)
-				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, int(invoice.Total*100)).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, payment.ToSatang(invoice.Total)).Return(p, nil).Times(1)
 				now := time.Now()
 				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
-				mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), invoice.Id).Return(nil).Times(1)
 				mockPaymentDataStore.EXPECT().Create(gomock.Any()).Return(testhelper.MockError).Times(1)
 			})
 			It("should return 500", func() {
@@ -596,8 +1043,9 @@ var _ = Describe("Payment Handler", func() {
 			When("payment failed", func() {
 				BeforeEach(func() {
 					paymentCharge = testhelper.GeneratePayment(false)
-					mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, int(invoice.Total*100)).Return(paymentCharge, nil).Times(1)
+					mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, payment.ToSatang(invoice.Total)).Return(paymentCharge, nil).Times(1)
 					paymentData = testhelper.GenerateDataStorePayment(datastore.CreditCardPaymentMethod, datastore.FailedPaymentStatus, invoice, paymentCharge, creditCard)
+					paymentData.PatientID = patientID
 					mockClock.EXPECT().NowPointer().Return(paymentData.PaidAt).Times(1)
 					mockPaymentDataStore.EXPECT().Create(paymentData).Return(nil).Times(1)
 				})
@@ -612,11 +1060,14 @@ var _ = Describe("Payment Handler", func() {
 			When("payment success", func() {
 				BeforeEach(func() {
 					paymentCharge = testhelper.GeneratePayment(true)
-					mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, int(invoice.Total*100)).Return(paymentCharge, nil).Times(1)
-					mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), invoice.Id).Return(nil).Times(1)
+					mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, payment.ToSatang(invoice.Total)).Return(paymentCharge, nil).Times(1)
 					paymentData = testhelper.GenerateDataStorePayment(datastore.CreditCardPaymentMethod, datastore.SuccessPaymentStatus, invoice, paymentCharge, creditCard)
+					paymentData.PatientID = patientID
 					mockClock.EXPECT().NowPointer().Return(paymentData.PaidAt).Times(1)
 					mockPaymentDataStore.EXPECT().Create(paymentData).Return(nil).Times(1)
+					mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), invoice.Id).Return(nil).Times(1)
+					mockClock.EXPECT().Now().Return(*paymentData.PaidAt).Times(1)
+					mockOutboxDataStore.EXPECT().MarkDone(paymentData.ID, *paymentData.PaidAt).Return(nil).Times(1)
 				})
 				It("should return 201 with success message", func() {
 					Expect(rec.Code).To(Equal(http.StatusCreated))
@@ -626,6 +1077,929 @@ var _ = Describe("Payment Handler", func() {
 					Expect(res.Status).To(Equal(datastore.SuccessPaymentStatus))
 				})
 			})
+			When("payment is pending", func() {
+				BeforeEach(func() {
+					paymentCharge = testhelper.GeneratePayment(false)
+					paymentCharge.FailureCode, paymentCharge.FailureMessage = nil, nil
+					paymentCharge.Pending = true
+					paymentCharge.AuthorizeURI = "https://api.omise.co/payments/paym_test/authorize"
+					mockPaymentClient.EXPECT().PayWithCreditCard(customerID, creditCard.CardID, invoiceIDStr, payment.ToSatang(invoice.Total)).Return(paymentCharge, nil).Times(1)
+					paymentData = testhelper.GenerateDataStorePayment(datastore.CreditCardPaymentMethod, datastore.PendingPaymentStatus, invoice, paymentCharge, creditCard)
+					paymentData.PatientID = patientID
+					mockClock.EXPECT().NowPointer().Return(paymentData.PaidAt).Times(1)
+					paymentData.PaidAt = nil
+					mockPaymentDataStore.EXPECT().Create(paymentData).Return(nil).Times(1)
+				})
+				It("should return 201 with pending status without paying the invoice", func() {
+					Expect(rec.Code).To(Equal(http.StatusCreated))
+					var res handler.PayInvoiceWithCreditCardResponse
+					Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+					Expect(res.Status).To(Equal(datastore.PendingPaymentStatus))
+					Expect(res.PaidAt).To(BeNil())
+					Expect(res.AuthorizeURI).To(Equal(paymentCharge.AuthorizeURI))
+				})
+			})
+		})
+	})
+
+	Context("PayInvoiceWithSplitPayment", func() {
+		var (
+			cards        []*datastore.CreditCard
+			invoice      *hospital.InvoiceOverview
+			invoiceIDStr string
+			req          handler.PayInvoiceWithSplitPaymentRequest
+			now          time.Time
+		)
+		BeforeEach(func() {
+			handlerFunc = h.PayInvoiceWithSplitPayment
+			invoice = testhelper.GenerateHospitalInvoice(false)
+			invoice.Total = 1000
+			invoiceIDStr = fmt.Sprintf("%d", invoice.Id)
+			c.Set("Invoice", invoice)
+			cards = []*datastore.CreditCard{testhelper.GenerateCreditCard(), testhelper.GenerateCreditCard()}
+			for _, card := range cards {
+				card.PatientID = patientID
+			}
+			req = handler.PayInvoiceWithSplitPaymentRequest{Parts: []handler.SplitPaymentPart{
+				{CreditCardID: cards[0].ID, Amount: 600.5},
+				{CreditCardID: cards[1].ID, Amount: 399.5},
+			}}
+			now = time.Now()
+			mockClock.EXPECT().Now().Return(now).AnyTimes()
+			mockClock.EXPECT().NowPointer().Return(&now).AnyTimes()
+		})
+		setRequest := func() {
+			body, err := json.Marshal(&req)
+			Expect(err).To(BeNil())
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewReader(body))
+		}
+		expectCards := func() {
+			for _, card := range cards {
+				mockCreditCardDataStore.EXPECT().FindByID(card.ID).Return(card, nil).Times(1)
+			}
+		}
+		expectCharge := func(i int, charge *payment.Payment, paymentID uint) {
+			mockPaymentClient.EXPECT().PayWithCreditCard(customerID, cards[i].CardID, invoiceIDStr, int(req.Parts[i].Amount*100)).Return(charge, nil).Times(1)
+			var create *gomock.Call
+			if i == len(req.Parts)-1 {
+				create = mockPaymentDataStore.EXPECT().CreateLastSplitPart(gomock.Any())
+			} else {
+				create = mockPaymentDataStore.EXPECT().Create(gomock.Any())
+			}
+			create.Do(func(p *datastore.Payment) {
+				Expect(p.Amount).To(Equal(req.Parts[i].Amount))
+				Expect(p.SplitID).ToNot(BeNil())
+				Expect(*p.CreditCardID).To(Equal(cards[i].ID))
+				p.ID = paymentID
+			}).Return(nil).Times(1)
+		}
+		parseResponse := func() *handler.PayInvoiceWithSplitPaymentResponse {
+			var res handler.PayInvoiceWithSplitPaymentResponse
+			Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+			return &res
+		}
+
+		When("there is only one part", func() {
+			BeforeEach(func() {
+				req.Parts = req.Parts[:1]
+				setRequest()
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidRequestBody)
+			})
+		})
+		When("amount has more than 2 decimal places", func() {
+			BeforeEach(func() {
+				req.Parts[0].Amount, req.Parts[1].Amount = 600.505, 399.495
+				setRequest()
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidSplitAmount)
+			})
+		})
+		When("sum of the parts doesn't equal the invoice total", func() {
+			BeforeEach(func() {
+				req.Parts[1].Amount = 300
+				setRequest()
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrSplitTotalMismatch)
+			})
+		})
+		When("the same credit card is charged twice", func() {
+			BeforeEach(func() {
+				req.Parts[1].CreditCardID = cards[0].ID
+				setRequest()
+				mockCreditCardDataStore.EXPECT().FindByID(cards[0].ID).Return(cards[0], nil).Times(1)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrDuplicateSplitCreditCard)
+			})
+		})
+		When("credit card is owned by another patient", func() {
+			BeforeEach(func() {
+				cards[1].PatientID = patientID + 1
+				setRequest()
+				expectCards()
+			})
+			It("should return 403", func() {
+				Expect(rec.Code).To(Equal(http.StatusForbidden))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrCreditCardOwnership)
+			})
+		})
+		When("credit card is expired", func() {
+			BeforeEach(func() {
+				cards[1].ExpiryYear = now.Year() - 1
+				setRequest()
+				expectCards()
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrCreditCardExpired)
+			})
+		})
+		When("all parts are successful", func() {
+			BeforeEach(func() {
+				setRequest()
+				expectCards()
+				expectCharge(0, testhelper.GeneratePayment(true), 1)
+				expectCharge(1, testhelper.GeneratePayment(true), 2)
+				mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), invoice.Id).Return(nil).Times(1)
+				mockOutboxDataStore.EXPECT().MarkDone(uint(2), now).Return(nil).Times(1)
+			})
+			It("should mark the invoice as paid and return 201 with success status", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				res := parseResponse()
+				Expect(res.Status).To(Equal(datastore.SuccessPaymentStatus))
+				Expect(res.Parts).To(HaveLen(2))
+				Expect(res.Parts[0].SplitID).To(Equal(&res.SplitID))
+			})
+		})
+		When("a later part is failed", func() {
+			var failedCharge *payment.Payment
+			BeforeEach(func() {
+				setRequest()
+				expectCards()
+				firstCharge := testhelper.GeneratePayment(true)
+				failedCharge = testhelper.GeneratePayment(false)
+				expectCharge(0, firstCharge, 1)
+				expectCharge(1, failedCharge, 2)
+				mockPaymentDataStore.EXPECT().ReserveRefund(&datastore.Refund{PaymentID: 1, Amount: req.Parts[0].Amount, Reason: "Split payment failed"}).DoAndReturn(reservePendingRefund).Times(1)
+				mockPaymentClient.EXPECT().Refund(firstCharge.ID, int(req.Parts[0].Amount*100)).Return(&payment.Refund{ID: "rfnd_test"}, nil).Times(1)
+				mockRefundDataStore.EXPECT().Complete(pendingRefundID, "rfnd_test").Return(nil).Times(1)
+				mockPaymentDataStore.EXPECT().MarkRefunded(uint(1)).Return(nil).Times(1)
+			})
+			It("should refund the successful part and return 201 with failed status", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				res := parseResponse()
+				Expect(res.Status).To(Equal(datastore.FailedPaymentStatus))
+				Expect(res.FailureMessage).To(Equal(failedCharge.FailureMessage))
+				Expect(res.Parts).To(HaveLen(2))
+				Expect(res.Parts[0].RefundedAmount).To(Equal(req.Parts[0].Amount))
+				Expect(res.Parts[0].Status).To(Equal(datastore.RefundedPaymentStatus))
+			})
+		})
+		When("a later part requires 3-D Secure", func() {
+			BeforeEach(func() {
+				setRequest()
+				expectCards()
+				firstCharge := testhelper.GeneratePayment(true)
+				pendingCharge := testhelper.GeneratePayment(false)
+				pendingCharge.FailureCode, pendingCharge.FailureMessage = nil, nil
+				pendingCharge.Pending = true
+				expectCharge(0, firstCharge, 1)
+				expectCharge(1, pendingCharge, 2)
+				mockPaymentDataStore.EXPECT().ReserveRefund(&datastore.Refund{PaymentID: 1, Amount: req.Parts[0].Amount, Reason: "Split payment failed"}).DoAndReturn(reservePendingRefund).Times(1)
+				mockPaymentClient.EXPECT().Refund(firstCharge.ID, int(req.Parts[0].Amount*100)).Return(nil, testhelper.MockError).Times(1)
+				mockPaymentDataStore.EXPECT().ReleaseRefund(&datastore.Refund{ID: pendingRefundID, PaymentID: 1, Amount: req.Parts[0].Amount, Reason: "Split payment failed", Status: datastore.PendingRefundStatus}).Return(nil).Times(1)
+			})
+			It("should fail the payment even if the refund fails", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				res := parseResponse()
+				Expect(res.Status).To(Equal(datastore.FailedPaymentStatus))
+				Expect(res.FailureMessage).ToNot(BeNil())
+				Expect(res.Parts[1].Status).To(Equal(datastore.PendingPaymentStatus))
+			})
+		})
+		When("charge error", func() {
+			BeforeEach(func() {
+				setRequest()
+				expectCards()
+				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, cards[0].CardID, invoiceIDStr, int(req.Parts[0].Amount*100)).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("the last part with the outbox failed to be created", func() {
+			BeforeEach(func() {
+				setRequest()
+				expectCards()
+				firstCharge := testhelper.GeneratePayment(true)
+				expectCharge(0, firstCharge, 1)
+				lastCharge := testhelper.GeneratePayment(true)
+				mockPaymentClient.EXPECT().PayWithCreditCard(customerID, cards[1].CardID, invoiceIDStr, int(req.Parts[1].Amount*100)).Return(lastCharge, nil).Times(1)
+				mockPaymentDataStore.EXPECT().CreateLastSplitPart(gomock.Any()).Return(testhelper.MockError).Times(1)
+				mockPaymentClient.EXPECT().Refund(lastCharge.ID, int(req.Parts[1].Amount*100)).Return(&payment.Refund{ID: "rfnd_last"}, nil).Times(1)
+				mockPaymentDataStore.EXPECT().ReserveRefund(&datastore.Refund{PaymentID: 1, Amount: req.Parts[0].Amount, Reason: "Split payment failed"}).DoAndReturn(reservePendingRefund).Times(1)
+				mockPaymentClient.EXPECT().Refund(firstCharge.ID, int(req.Parts[0].Amount*100)).Return(&payment.Refund{ID: "rfnd_test"}, nil).Times(1)
+				mockRefundDataStore.EXPECT().Complete(pendingRefundID, "rfnd_test").Return(nil).Times(1)
+				mockPaymentDataStore.EXPECT().MarkRefunded(uint(1)).Return(nil).Times(1)
+			})
+			It("should refund the successful parts including the unrecorded one and return 500 without marking the invoice as paid", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Context("HandleOmiseWebhook", func() {
+		var (
+			webhookEvent *payment.WebhookEvent
+			p            *datastore.Payment
+			charge       *payment.Payment
+		)
+		BeforeEach(func() {
+			handlerFunc = h.HandleOmiseWebhook
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte("{}")))
+			c.Request.Header.Set("Omise-Signature", "signature")
+			c.Request.Header.Set("Omise-Signature-Timestamp", "timestamp")
+			webhookEvent = &payment.WebhookEvent{ID: uuid.NewString(), Key: "charge.complete", ChargeID: uuid.NewString()}
+			p = &datastore.Payment{
+				ID:        uint(rand.Uint32()),
+				ChargeID:  webhookEvent.ChargeID,
+				InvoiceID: int(rand.Int31()),
+				PatientID: patientID,
+				Amount:    500,
+				Status:    datastore.PendingPaymentStatus,
+			}
+			charge = testhelper.GeneratePayment(true)
+		})
+		expectEvent := func() {
+			mockPaymentClient.EXPECT().ParseWebhookEvent([]byte("{}"), "signature", "timestamp").Return(webhookEvent, nil).Times(1)
+		}
+		expectPendingPayment := func() {
+			expectEvent()
+			mockPaymentDataStore.EXPECT().FindByChargeID(webhookEvent.ChargeID).Return(p, nil).Times(1)
+			mockPaymentClient.EXPECT().GetCharge(webhookEvent.ChargeID).Return(charge, nil).Times(1)
+		}
+		expectNotification := func(title string) {
+			mockNotificationDS.EXPECT().Create(gomock.Any()).DoAndReturn(func(n *datastore.Notification) error {
+				Expect(n.Title).To(Equal(title))
+				Expect(n.PatientID).To(Equal(patientID))
+				return nil
+			}).Times(1)
+			mockEventBroker.EXPECT().Publish(gomock.Any(), patientID, event.NotificationType, gomock.Any()).Return(nil).Times(1)
+			mockNotificationDS.EXPECT().CountUnRead(patientID).Return(1, nil).Times(1)
+			mockEventBroker.EXPECT().Publish(gomock.Any(), patientID, event.UnreadCountType, &event.UnreadCount{Count: 1}).Return(nil).Times(1)
+			mockNotificationClient.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		}
+
+		When("signature is invalid", func() {
+			BeforeEach(func() {
+				mockPaymentClient.EXPECT().ParseWebhookEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, payment.ErrInvalidWebhookSignature).Times(1)
+			})
+			It("should return 401", func() {
+				Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidWebhookSignature)
+			})
+		})
+		When("event can't be parsed", func() {
+			BeforeEach(func() {
+				mockPaymentClient.EXPECT().ParseWebhookEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidWebhookEvent)
+			})
+		})
+		When("event isn't about a charge", func() {
+			BeforeEach(func() {
+				webhookEvent.Key = "customer.create"
+				webhookEvent.ChargeID = ""
+				expectEvent()
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("payment of the charge is not found", func() {
+			BeforeEach(func() {
+				expectEvent()
+				mockPaymentDataStore.EXPECT().FindByChargeID(webhookEvent.ChargeID).Return(nil, nil).Times(1)
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("payment is already settled", func() {
+			BeforeEach(func() {
+				p.Status = datastore.SuccessPaymentStatus
+				expectEvent()
+				mockPaymentDataStore.EXPECT().FindByChargeID(webhookEvent.ChargeID).Return(p, nil).Times(1)
+			})
+			It("should return 200 without retrieving the charge", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("get charge error", func() {
+			BeforeEach(func() {
+				expectEvent()
+				mockPaymentDataStore.EXPECT().FindByChargeID(webhookEvent.ChargeID).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(webhookEvent.ChargeID).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("charge is still pending", func() {
+			BeforeEach(func() {
+				charge.Success, charge.Pending = false, true
+				expectPendingPayment()
+			})
+			It("should return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("hospital sys client PaidInvoice error", func() {
+			BeforeEach(func() {
+				expectPendingPayment()
+				now := time.Now()
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.SuccessPaymentStatus, &now).Return(true, nil).Times(1)
+				mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), p.InvoiceID).Return(testhelper.MockError).Times(1)
+				mockOutboxDataStore.EXPECT().RecordFailure(p.ID, testhelper.MockError.Error()).Return(nil).Times(1)
+				expectNotification("Payment successful")
+			})
+			It("should leave the outbox pending for the reconciler and return 200", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("charge is successful", func() {
+			BeforeEach(func() {
+				expectPendingPayment()
+				now := time.Now()
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.SuccessPaymentStatus, &now).Return(true, nil).Times(1)
+				mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), p.InvoiceID).Return(nil).Times(1)
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockOutboxDataStore.EXPECT().MarkDone(p.ID, now).Return(nil).Times(1)
+				expectNotification("Payment successful")
+			})
+			It("should settle the payment and notify the patient", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("charge is successful but the payment is settled concurrently", func() {
+			BeforeEach(func() {
+				expectPendingPayment()
+				now := time.Now()
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.SuccessPaymentStatus, &now).Return(false, nil).Times(1)
+			})
+			It("should return 200 without notifying the patient again", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("charge is failed", func() {
+			BeforeEach(func() {
+				charge = testhelper.GeneratePayment(false)
+				expectPendingPayment()
+				now := time.Now()
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.FailedPaymentStatus, &now).Return(true, nil).Times(1)
+				expectNotification("Payment failed")
+			})
+			It("should settle the payment as failed and notify the patient", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+		When("part of the split payment is settled as successful", func() {
+			BeforeEach(func() {
+				splitID := uuid.NewString()
+				p.SplitID = &splitID
+				expectPendingPayment()
+				now := time.Now()
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.SuccessPaymentStatus, &now).Return(true, nil).Times(1)
+				mockPaymentDataStore.EXPECT().ReserveRefund(&datastore.Refund{PaymentID: p.ID, Amount: p.Amount, Reason: "Split payment failed"}).DoAndReturn(reservePendingRefund).Times(1)
+				mockPaymentClient.EXPECT().Refund(p.ChargeID, int(p.Amount*100)).Return(&payment.Refund{ID: "rfnd_test"}, nil).Times(1)
+				mockRefundDataStore.EXPECT().Complete(pendingRefundID, "rfnd_test").Return(nil).Times(1)
+				mockPaymentDataStore.EXPECT().MarkRefunded(p.ID).Return(nil).Times(1)
+			})
+			It("should refund the part without paying the invoice", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("HandleCreditCardReturn", func() {
+		var (
+			p      *datastore.Payment
+			charge *payment.Payment
+		)
+		BeforeEach(func() {
+			handlerFunc = h.HandleCreditCardReturn
+			p = &datastore.Payment{
+				ID:        uint(rand.Uint32()),
+				ChargeID:  uuid.NewString(),
+				InvoiceID: int(rand.Int31()),
+				PatientID: patientID,
+				Amount:    500,
+				Status:    datastore.PendingPaymentStatus,
+			}
+			charge = testhelper.GeneratePayment(true)
+			c.Request = httptest.NewRequest("GET", fmt.Sprintf("/?ref_id=%d", p.InvoiceID), nil)
+		})
+
+		When("ref ID is invalid", func() {
+			BeforeEach(func() {
+				c.Request = httptest.NewRequest("GET", "/?ref_id=abc", nil)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidInvoiceID)
+			})
+		})
+		When("payment is not found", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(nil, nil).Times(1)
+			})
+			It("should return 204 without telling the payment is not found", func() {
+				Expect(rec.Code).To(Equal(http.StatusNoContent))
+			})
+		})
+		When("payment is already settled by the webhook", func() {
+			BeforeEach(func() {
+				p.Status = datastore.SuccessPaymentStatus
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(p, nil).Times(1)
+			})
+			It("should return 204 without the payment status", func() {
+				Expect(rec.Code).To(Equal(http.StatusNoContent))
+				Expect(rec.Body.Len()).To(BeZero())
+			})
+		})
+		When("charge is not authorized yet", func() {
+			BeforeEach(func() {
+				charge.Success, charge.Pending = false, true
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(p.ChargeID).Return(charge, nil).Times(1)
+			})
+			It("should return 204 without paying the invoice", func() {
+				Expect(rec.Code).To(Equal(http.StatusNoContent))
+			})
+		})
+		When("charge is authorized", func() {
+			BeforeEach(func() {
+				now := time.Now()
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(p.ChargeID).Return(charge, nil).Times(1)
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.SuccessPaymentStatus, &now).Return(true, nil).Times(1)
+				mockhospitalSysClient.EXPECT().PaidInvoice(gomock.Any(), p.InvoiceID).Return(nil).Times(1)
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockOutboxDataStore.EXPECT().MarkDone(p.ID, now).Return(nil).Times(1)
+				mockNotificationDS.EXPECT().Create(gomock.Any()).Return(nil).Times(1)
+				mockEventBroker.EXPECT().Publish(gomock.Any(), patientID, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				mockNotificationDS.EXPECT().CountUnRead(patientID).Return(1, nil).Times(1)
+				mockNotificationClient.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			})
+			It("should settle the payment and return 204", func() {
+				Expect(rec.Code).To(Equal(http.StatusNoContent))
+				Expect(p.Status).To(Equal(datastore.SuccessPaymentStatus))
+			})
+		})
+		When("charge is rejected", func() {
+			BeforeEach(func() {
+				now := time.Now()
+				charge = testhelper.GeneratePayment(false)
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(p.ChargeID).Return(charge, nil).Times(1)
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.FailedPaymentStatus, &now).Return(false, nil).Times(1)
+			})
+			It("should return 204 without the failure message", func() {
+				Expect(rec.Code).To(Equal(http.StatusNoContent))
+				Expect(rec.Body.Len()).To(BeZero())
+				Expect(p.Status).To(Equal(datastore.FailedPaymentStatus))
+			})
+		})
+	})
+
+	Context("PayInvoiceWithPromptPay", func() {
+		var (
+			invoice   *hospital.InvoiceOverview
+			promptPay *payment.PromptPay
+		)
+		BeforeEach(func() {
+			handlerFunc = h.PayInvoiceWithPromptPay
+			invoice = testhelper.GenerateHospitalInvoice(false)
+			c.Set("Invoice", invoice)
+			promptPay = &payment.PromptPay{
+				ExpiresAt: time.Now().Add(24 * time.Hour),
+				ChargeID:  uuid.NewString(),
+				QRCodeURI: "https://api.omise.co/charges/chrg_test/documents/docu_test/downloads/qr",
+				Amount:    payment.ToSatang(invoice.Total),
+			}
+		})
+		When("pay with PromptPay error", func() {
+			BeforeEach(func() {
+				mockPaymentClient.EXPECT().PayWithPromptPay(fmt.Sprintf("%d", invoice.Id), payment.ToSatang(invoice.Total)).Return(nil, testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("create payment in datastore error", func() {
+			BeforeEach(func() {
+				mockPaymentClient.EXPECT().PayWithPromptPay(fmt.Sprintf("%d", invoice.Id), payment.ToSatang(invoice.Total)).Return(promptPay, nil).Times(1)
+				mockPaymentDataStore.EXPECT().Create(gomock.Any()).Return(testhelper.MockError).Times(1)
+			})
+			It("should return 500", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("no error occurred", func() {
+			BeforeEach(func() {
+				mockPaymentClient.EXPECT().PayWithPromptPay(fmt.Sprintf("%d", invoice.Id), payment.ToSatang(invoice.Total)).Return(promptPay, nil).Times(1)
+				mockPaymentDataStore.EXPECT().Create(&datastore.Payment{
+					Method:    datastore.PromptPayPaymentMethod,
+					Amount:    invoice.Total,
+					ExpiresAt: &promptPay.ExpiresAt,
+					ChargeID:  promptPay.ChargeID,
+					InvoiceID: invoice.Id,
+					PatientID: patientID,
+					Status:    datastore.PendingPaymentStatus,
+				}).Return(nil).Times(1)
+			})
+			It("should return 201 with pending payment and QR code", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				var res handler.PayInvoiceWithPromptPayResponse
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Status).To(Equal(datastore.PendingPaymentStatus))
+				Expect(res.Method).To(Equal(datastore.PromptPayPaymentMethod))
+				Expect(res.QRCodeURI).To(Equal(promptPay.QRCodeURI))
+				Expect(res.ExpiresAt).ToNot(BeNil())
+			})
+		})
+	})
+
+	Context("GetInvoicePaymentStatus", func() {
+		var p *datastore.Payment
+		BeforeEach(func() {
+			handlerFunc = h.GetInvoicePaymentStatus
+			p = &datastore.Payment{
+				ID:        uint(rand.Uint32()),
+				ChargeID:  uuid.NewString(),
+				InvoiceID: int(rand.Int31()),
+				PatientID: patientID,
+				Method:    datastore.PromptPayPaymentMethod,
+				Amount:    500,
+				Status:    datastore.PendingPaymentStatus,
+			}
+			c.Params = []gin.Param{{Key: "invoiceID", Value: fmt.Sprintf("%d", p.InvoiceID)}}
+		})
+		When("invoice ID is invalid", func() {
+			BeforeEach(func() {
+				c.Params = []gin.Param{{Key: "invoiceID", Value: "abc"}}
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidInvoiceID)
+			})
+		})
+		When("payment belongs to another patient", func() {
+			BeforeEach(func() {
+				p.PatientID = patientID + 1
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(p, nil).Times(1)
+			})
+			It("should return 404", func() {
+				Expect(rec.Code).To(Equal(http.StatusNotFound))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrPaymentNotFound)
+			})
+		})
+		When("QR code is not paid yet", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(p.ChargeID).Return(&payment.Payment{ID: p.ChargeID, Pending: true}, nil).Times(1)
+			})
+			It("should return 200 with pending status", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res datastore.Payment
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Status).To(Equal(datastore.PendingPaymentStatus))
+			})
+		})
+		When("QR code is paid", func() {
+			BeforeEach(func() {
+				now := time.Now()
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(p.InvoiceID).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(p.ChargeID).Return(testhelper.GeneratePayment(true), nil).Times(1)
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.SuccessPaymentStatus, &now).Return(false, nil).Times(1)
+			})
+			It("should return 200 with success status", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				var res datastore.Payment
+				Expect(json.Unmarshal(rec.Body.Bytes(), &res)).To(Succeed())
+				Expect(res.Status).To(Equal(datastore.SuccessPaymentStatus))
+			})
+		})
+	})
+
+	Context("VerifyNoPendingPayment", func() {
+		var (
+			invoice *hospital.InvoiceOverview
+			p       *datastore.Payment
+		)
+		BeforeEach(func() {
+			handlerFunc = h.VerifyNoPendingPayment
+			invoice = testhelper.GenerateHospitalInvoice(false)
+			c.Set("Invoice", invoice)
+			p = &datastore.Payment{
+				ID:        uint(rand.Uint32()),
+				ChargeID:  uuid.NewString(),
+				InvoiceID: invoice.Id,
+				PatientID: patientID,
+				Status:    datastore.PendingPaymentStatus,
+			}
+		})
+		When("invoice has no payment", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(invoice.Id).Return(nil, nil).Times(1)
+			})
+			It("should continue", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+			})
+		})
+		When("latest payment is failed", func() {
+			BeforeEach(func() {
+				p.Status = datastore.FailedPaymentStatus
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(invoice.Id).Return(p, nil).Times(1)
+			})
+			It("should continue", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+			})
+		})
+		When("latest payment is still pending", func() {
+			BeforeEach(func() {
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(invoice.Id).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(p.ChargeID).Return(&payment.Payment{ID: p.ChargeID, Pending: true}, nil).Times(1)
+			})
+			It("should return 409", func() {
+				Expect(rec.Code).To(Equal(http.StatusConflict))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvoicePaymentPending)
+			})
+		})
+		When("latest payment is settled as failed", func() {
+			BeforeEach(func() {
+				now := time.Now()
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(invoice.Id).Return(p, nil).Times(1)
+				mockPaymentClient.EXPECT().GetCharge(p.ChargeID).Return(testhelper.GeneratePayment(false), nil).Times(1)
+				mockClock.EXPECT().NowPointer().Return(&now).Times(1)
+				mockPaymentDataStore.EXPECT().SettlePending(p.ID, datastore.FailedPaymentStatus, &now).Return(false, nil).Times(1)
+			})
+			It("should continue", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+			})
+		})
+		When("latest payment is successful", func() {
+			BeforeEach(func() {
+				p.Status = datastore.SuccessPaymentStatus
+				mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(invoice.Id).Return(p, nil).Times(1)
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvoicePaid)
+			})
+		})
+	})
+
+	Context("LockInvoicePayment", func() {
+		var (
+			invoiceID int
+			lockKey   string
+		)
+		BeforeEach(func() {
+			handlerFunc = h.LockInvoicePayment
+			invoiceID = int(rand.Int31())
+			lockKey = cache.InvoicePaymentLockKey(invoiceID)
+			c.Params = []gin.Param{{Key: "invoiceID", Value: fmt.Sprintf("%d", invoiceID)}}
+		})
+		When("invoice ID is invalid", func() {
+			BeforeEach(func() {
+				c.Params = []gin.Param{{Key: "invoiceID", Value: "abc"}}
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidInvoiceID)
+			})
+		})
+		When("invoice is locked by another request", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), lockKey, gomock.Any(), time.Minute).Return(false, nil).Times(1)
+			})
+			It("should return 409", func() {
+				Expect(rec.Code).To(Equal(http.StatusConflict))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvoicePaymentInProgress)
+			})
+		})
+		When("lock is acquired", func() {
+			BeforeEach(func() {
+				var token string
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), lockKey, gomock.Any(), time.Minute).DoAndReturn(func(_ context.Context, _, value string, _ time.Duration) (bool, error) {
+					token = value
+					return true, nil
+				}).Times(1)
+				mockCacheClient.EXPECT().DeleteIfEquals(gomock.Any(), lockKey, gomock.Any()).DoAndReturn(func(_ context.Context, _, value string) (bool, error) {
+					Expect(value).To(Equal(token))
+					return true, nil
+				}).Times(1)
+			})
+			It("should release the lock after the request", func() {
+				Expect(c.IsAborted()).To(BeFalse())
+			})
+		})
+	})
+
+	Context("ReplayIdempotentRequest", func() {
+		var (
+			idempotencyKey string
+			cacheKey       string
+			fingerprint    string
+			body           string
+			called         int
+			next           gin.HandlerFunc
+		)
+		BeforeEach(func() {
+			idempotencyKey = uuid.NewString()
+			cacheKey = cache.IdempotencyKey(patientID, idempotencyKey)
+			body = `{"amount":100}`
+			fingerprint = fmt.Sprintf("POST /payment/pay/1/promptpay %x", sha256.Sum256([]byte(body)))
+			called = 0
+			next = func(c *gin.Context) {
+				c.Set("ChargeAttempted", true)
+				c.JSON(http.StatusCreated, gin.H{"id": 1})
+			}
+			handlerFunc = func(*gin.Context) {
+				r := gin.New()
+				r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ interface{}) {
+					c.AbortWithStatus(http.StatusInternalServerError)
+				}))
+				r.POST("/payment/pay/1/promptpay", func(c *gin.Context) { c.Set("UserID", patientID) }, h.ReplayIdempotentRequest, func(c *gin.Context) {
+					called++
+					reqBody, err := io.ReadAll(c.Request.Body)
+					Expect(err).To(BeNil())
+					Expect(string(reqBody)).To(Equal(body))
+					next(c)
+				})
+				req := httptest.NewRequest("POST", "/payment/pay/1/promptpay", strings.NewReader(body))
+				if idempotencyKey != "" {
+					req.Header.Set("Idempotency-Key", idempotencyKey)
+				}
+				r.ServeHTTP(rec, req)
+			}
+		})
+		expectStored := func(status int) {
+			mockCacheClient.EXPECT().Set(gomock.Any(), cacheKey, gomock.Any(), time.Hour).DoAndReturn(func(_ context.Context, _, value string, _ time.Duration) error {
+				var res map[string]interface{}
+				Expect(json.Unmarshal([]byte(value), &res)).To(Succeed())
+				Expect(res["completed"]).To(BeTrue())
+				Expect(res["status"]).To(BeEquivalentTo(status))
+				Expect(res["fingerprint"]).To(Equal(fingerprint))
+				return nil
+			}).Times(1)
+		}
+		expectDeleted := func() {
+			mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), cacheKey, gomock.Any(), time.Hour).DoAndReturn(func(_ context.Context, _, value string, _ time.Duration) (bool, error) {
+				mockCacheClient.EXPECT().DeleteIfEquals(gomock.Any(), cacheKey, value).Return(true, nil).Times(1)
+				return true, nil
+			}).Times(1)
+		}
+		storedResponse := func(res map[string]interface{}) string {
+			raw, err := json.Marshal(res)
+			Expect(err).To(BeNil())
+			return string(raw)
+		}
+
+		When("Idempotency-Key is not provided", func() {
+			BeforeEach(func() {
+				idempotencyKey = ""
+			})
+			It("should handle the request as usual", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				Expect(called).To(Equal(1))
+			})
+		})
+		When("Idempotency-Key is too long", func() {
+			BeforeEach(func() {
+				idempotencyKey = string(bytes.Repeat([]byte("a"), 256))
+			})
+			It("should return 400", func() {
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrInvalidIdempotencyKey)
+			})
+		})
+		When("it's the first request with the key", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), cacheKey, gomock.Any(), time.Hour).Return(true, nil).Times(1)
+				expectStored(http.StatusCreated)
+			})
+			It("should handle the request and store the response", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				Expect(called).To(Equal(1))
+			})
+		})
+		When("the first request fails before the charge is attempted", func() {
+			BeforeEach(func() {
+				next = func(c *gin.Context) {
+					c.AbortWithStatusJSON(http.StatusConflict, handler.ErrInvoicePaymentInProgress)
+				}
+				expectDeleted()
+			})
+			It("should delete the key without storing the response", func() {
+				Expect(rec.Code).To(Equal(http.StatusConflict))
+				Expect(called).To(Equal(1))
+			})
+		})
+		When("the first request panics before the charge is attempted", func() {
+			BeforeEach(func() {
+				next = func(c *gin.Context) {
+					panic("unexpected")
+				}
+				expectDeleted()
+			})
+			It("should delete the key", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("the first request panics after the charge is attempted", func() {
+			BeforeEach(func() {
+				next = func(c *gin.Context) {
+					c.Set("ChargeAttempted", true)
+					panic("unexpected")
+				}
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), cacheKey, gomock.Any(), time.Hour).Return(true, nil).Times(1)
+				expectStored(http.StatusInternalServerError)
+			})
+			It("should store the unknown result so the retry doesn't charge again", func() {
+				Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+		When("the first request is in progress", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), cacheKey, gomock.Any(), time.Hour).Return(false, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cacheKey, false).Return(storedResponse(map[string]interface{}{"fingerprint": fingerprint}), nil).Times(1)
+			})
+			It("should return 409", func() {
+				Expect(rec.Code).To(Equal(http.StatusConflict))
+				Expect(called).To(Equal(0))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrRequestInProgress)
+			})
+		})
+		When("the key is used with another request", func() {
+			BeforeEach(func() {
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), cacheKey, gomock.Any(), time.Hour).Return(false, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cacheKey, false).Return(storedResponse(map[string]interface{}{"fingerprint": "POST /payment/pay/2/promptpay", "completed": true}), nil).Times(1)
+			})
+			It("should return 422", func() {
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+				Expect(called).To(Equal(0))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrIdempotencyKeyReused)
+			})
+		})
+		When("the key is used with another request body", func() {
+			BeforeEach(func() {
+				otherFingerprint := fmt.Sprintf("POST /payment/pay/1/promptpay %x", sha256.Sum256([]byte(`{"amount":200}`)))
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), cacheKey, gomock.Any(), time.Hour).Return(false, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cacheKey, false).Return(storedResponse(map[string]interface{}{"fingerprint": otherFingerprint, "completed": true}), nil).Times(1)
+			})
+			It("should return 422", func() {
+				Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+				Expect(called).To(Equal(0))
+				testhelper.AssertErrorResponseBody(rec.Body, handler.ErrIdempotencyKeyReused)
+			})
+		})
+		When("the first request is completed", func() {
+			BeforeEach(func() {
+				stored := storedResponse(map[string]interface{}{
+					"fingerprint": fingerprint,
+					"body":        []byte(`{"id":1}`),
+					"status":      http.StatusCreated,
+					"completed":   true,
+				})
+				mockCacheClient.EXPECT().SetIfNotExists(gomock.Any(), cacheKey, gomock.Any(), time.Hour).Return(false, nil).Times(1)
+				mockCacheClient.EXPECT().Get(gomock.Any(), cacheKey, false).Return(stored, nil).Times(1)
+			})
+			It("should replay the stored response without handling the request", func() {
+				Expect(rec.Code).To(Equal(http.StatusCreated))
+				Expect(called).To(Equal(0))
+				Expect(rec.Body.String()).To(Equal(`{"id":1}`))
+				Expect(rec.Header().Get("Idempotent-Replayed")).To(Equal("true"))
+			})
 		})
 	})
 })
@@ -10,10 +10,15 @@ import (
 	"github.com/synthia-telemed/backend-api/pkg/clock"
 	"github.com/synthia-telemed/backend-api/pkg/config"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/logger"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
 	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
+	"github.com/synthia-telemed/backend-api/pkg/receipt"
 	"github.com/synthia-telemed/backend-api/pkg/server"
+	"github.com/synthia-telemed/backend-api/pkg/session"
 	"github.com/synthia-telemed/backend-api/pkg/sms"
 	"github.com/synthia-telemed/backend-api/pkg/token"
 	"gorm.io/driver/postgres"
@@ -67,29 +72,71 @@ func main() {
 	server.AssertFatalError(sugaredLogger, err, "Failed to create credit card data store")
 	paymentDataStore, err := datastore.NewGormPaymentDataStore(db)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create payment data store")
+	paidInvoiceOutboxDataStore, err := datastore.NewGormPaidInvoiceOutboxDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create paid invoice outbox data store")
+	refundDataStore, err := datastore.NewGormRefundDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create refund data store")
 	appointmentDataStore, err := datastore.NewGormAppointmentDataStore(db)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create appointment data store")
 	notificationDataStore, err := datastore.NewGormNotificationDataStore(db)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create notification data store")
+	doctorDataStore, err := datastore.NewGormDoctorDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create doctor data store")
+	scheduleDataStore, err := datastore.NewGormScheduleDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create schedule data store")
+	sessionDataStore, err := datastore.NewGormSessionDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create session data store")
 
 	hospitalSysClient := hospital.NewGraphQLClient(&cfg.HospitalClient)
-	smsClient := sms.NewTwilioClient(&cfg.SMS)
+	messageClients := map[sms.Channel]sms.Client{sms.SMSChannel: sms.NewTwilioClient(&cfg.SMS)}
+	if cfg.Email.Host != "" {
+		messageClients[sms.EmailChannel] = sms.NewSMTPClient(&cfg.Email)
+	}
+	if cfg.LINE.ChannelAccessToken != "" {
+		messageClients[sms.LINEChannel] = sms.NewLINEClient(&cfg.LINE)
+	}
+	messageRouter := sms.NewRouter(&cfg.MessageRouter, messageClients)
 	cacheClient := cache.NewRedisClient(&cfg.Cache)
 	tokenService, err := token.NewGRPCTokenService(&cfg.Token)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create token service")
+	if cfg.Mode != "development" && cfg.Payment.WebhookSecret == "" {
+		sugaredLogger.Fatal("OMISE_WEBHOOK_SECRET is required outside development mode")
+	}
 	paymentClient, err := payment.NewOmisePaymentClient(&cfg.Payment)
 	server.AssertFatalError(sugaredLogger, err, "Failed to create payment client")
+	notificationClient, err := notification.NewRabbitMQNotificationClient(&cfg.Notification)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create rabbitmq notification client")
 	realClock := clock.NewRealClock()
+	sessionManager := session.NewTokenManager(tokenService, sessionDataStore, realClock)
+	presenceTracker := presence.NewCacheTracker(&cfg.Presence, cacheClient, realClock)
+	eventBroker := event.NewCacheBroker(cacheClient)
+	scheduleLocation, err := time.LoadLocation(cfg.ScheduleTimezone)
+	server.AssertFatalError(sugaredLogger, err, "Failed to load schedule timezone")
+	receiptGenerator, err := receipt.NewPDFGenerator(&cfg.Receipt, scheduleLocation)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create receipt generator")
 
 	// Handler
-	authHandler := handler.NewAuthHandler(patientDataStore, hospitalSysClient, smsClient, cacheClient, tokenService, realClock, sugaredLogger)
-	paymentHandler := handler.NewPaymentHandler(paymentClient, patientDataStore, creditCardDataStore, hospitalSysClient, paymentDataStore, realClock, sugaredLogger)
-	appointmentHandler := handler.NewAppointmentHandler(patientDataStore, paymentDataStore, appointmentDataStore, hospitalSysClient, cacheClient, realClock, sugaredLogger)
+	authHandler := handler.NewAuthHandler(patientDataStore, hospitalSysClient, messageRouter, cacheClient, sessionManager, realClock, &cfg.SigninRateLimit, cfg.OTPTTL, cfg.OTPMaxAttempts, cfg.OTPResendCooldown, sugaredLogger)
+	paymentHandler := handler.NewPaymentHandler(paymentClient, patientDataStore, creditCardDataStore, hospitalSysClient, paymentDataStore, paidInvoiceOutboxDataStore, refundDataStore, notificationDataStore, notificationClient, eventBroker, cacheClient, receiptGenerator, realClock, cfg.PaymentLockTTL, cfg.IdempotencyKeyTTL, sugaredLogger)
+	appointmentHandler := handler.NewAppointmentHandler(patientDataStore, paymentDataStore, appointmentDataStore, doctorDataStore, scheduleDataStore, hospitalSysClient, cacheClient, notificationClient, presenceTracker, realClock, cfg.AppointmentChangeCutoff, scheduleLocation, sugaredLogger)
 	infoHandler := handler.NewInfoHandler(patientDataStore, hospitalSysClient, sugaredLogger)
-	notificationHandler := handler.NewNotificationHandler(notificationDataStore, patientDataStore, sugaredLogger)
+	notificationHandler := handler.NewNotificationHandler(notificationDataStore, patientDataStore, eventBroker, sugaredLogger)
 
 	ginServer := server.NewGinServer(cfg, sugaredLogger)
+	authMode := server.AuthMode(cfg.AuthMode)
+	if !authMode.IsValid() {
+		sugaredLogger.Fatalw("Invalid auth mode", "mode", cfg.AuthMode)
+	}
+	sessionChecker := session.NewCachedChecker(sessionDataStore, realClock, cfg.SessionCheckCacheTTL)
+	if authMode == server.JWSAuthMode {
+		tokenVerifier, err := token.NewJWSVerifier(&cfg.TokenVerifier, realClock)
+		server.AssertFatalError(sugaredLogger, err, "Failed to create token verifier")
+		ginServer.Use(server.JWSAuthentication(tokenVerifier, sessionChecker, "Patient", sugaredLogger))
+	} else {
+		ginServer.Use(server.SessionRevocation(sessionChecker, sugaredLogger))
+	}
 	ginServer.RegisterHandlers("/api", authHandler, paymentHandler, appointmentHandler, infoHandler, notificationHandler)
 	ginServer.GET("/api/docs/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	ginServer.ListenAndServe()
+	server.AssertFatalError(sugaredLogger, notificationClient.Close(), "Failed to close rabbitmq connection")
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"github.com/getsentry/sentry-go"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/config"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/logger"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/server"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln("Failed to parse ENV:", err)
+	}
+
+	zapLogger, err := logger.NewZapLogger(cfg.Mode == "development")
+	if err != nil {
+		log.Fatalln("Failed to initialized Zap:", err)
+	}
+	defer zapLogger.Sync()
+	sugaredLogger := zapLogger.Sugar()
+
+	if err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.SentryDSN,
+		TracesSampleRate: 1.0,
+	}); err != nil {
+		sugaredLogger.Fatalw("Sentry initialization failed", "error", err)
+	}
+	defer sentry.Flush(2 * time.Second)
+
+	db, err := gorm.Open(postgres.Open(cfg.DB.DSN()), &gorm.Config{})
+	server.AssertFatalError(sugaredLogger, err, "Failed to connect to database")
+
+	paymentDataStore, err := datastore.NewGormPaymentDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create payment data store")
+	paidInvoiceOutboxDataStore, err := datastore.NewGormPaidInvoiceOutboxDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create paid invoice outbox data store")
+
+	paymentClient, err := payment.NewOmisePaymentClient(&cfg.Payment)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create payment client")
+	hospitalSysClient := hospital.NewGraphQLClient(&cfg.HospitalClient)
+	realClock := clock.NewRealClock()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	sugaredLogger.Infow("Starting reconciliation", "window", cfg.ReconciliationWindow)
+	reconciliationJob := job.NewReconciliationJob(paymentClient, hospitalSysClient, paymentDataStore, paidInvoiceOutboxDataStore, realClock, cfg.ReconciliationWindow, sugaredLogger)
+	report, err := reconciliationJob.Reconcile(ctx)
+	server.AssertFatalError(sugaredLogger, err, "Reconciliation failed")
+	sugaredLogger.Infow("Reconciliation finished",
+		"mismatches", len(report.Mismatches),
+		"paidInvoiceRetried", report.PaidInvoiceRetried,
+		"paidInvoiceFailed", report.PaidInvoiceFailed,
+	)
+	if len(report.Mismatches) > 0 {
+		sentry.Flush(2 * time.Second)
+		zapLogger.Sync()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,248 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const autopayBatchSize = 50
+
+// AutopayJob charges the invoice of the completed appointment with the default card of the patient who opts in to autopay.
+// The request waits until the invoice is issued, and the patient is notified to pay manually if it can't be charged
+type AutopayJob struct {
+	notifier
+	hospitalClient      hospital.SystemClient
+	paymentClient       payment.Client
+	creditCardDataStore datastore.CreditCardDataStore
+	paymentDataStore    datastore.PaymentDataStore
+	outboxDataStore     datastore.PaidInvoiceOutboxDataStore
+	autopayDataStore    datastore.AutopayDataStore
+	cacheClient         cache.Client
+	clock               clock.Clock
+	invoiceWait         time.Duration
+	lockTTL             time.Duration
+}
+
+func NewAutopayJob(hos hospital.SystemClient, paymentClient payment.Client, pds datastore.PatientDataStore, cds datastore.CreditCardDataStore, pmds datastore.PaymentDataStore, outbox datastore.PaidInvoiceOutboxDataStore, aps datastore.AutopayDataStore, nds datastore.NotificationDataStore, noti notification.Client, eventBroker event.Broker, cacheClient cache.Client, c clock.Clock, invoiceWait, lockTTL time.Duration, logger *zap.SugaredLogger) *AutopayJob {
+	return &AutopayJob{
+		notifier: notifier{
+			patientDataStore:      pds,
+			notificationDataStore: nds,
+			notificationClient:    noti,
+			eventBroker:           eventBroker,
+			logger:                logger,
+		},
+		hospitalClient:      hos,
+		paymentClient:       paymentClient,
+		creditCardDataStore: cds,
+		paymentDataStore:    pmds,
+		outboxDataStore:     outbox,
+		autopayDataStore:    aps,
+		cacheClient:         cacheClient,
+		clock:               c,
+		invoiceWait:         invoiceWait,
+		lockTTL:             lockTTL,
+	}
+}
+
+func (j AutopayJob) Name() string {
+	return "autopay"
+}
+
+// Run pages through all the pending requests, so the old ones waiting for the invoice don't hold back the newer ones
+func (j AutopayJob) Run(ctx context.Context) error {
+	now := j.clock.Now()
+	var after *datastore.AutopayRequest
+	for {
+		requests, err := j.autopayDataStore.ListPending(after, autopayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, r := range requests {
+			j.process(ctx, now, r)
+		}
+		if len(requests) < autopayBatchSize {
+			return nil
+		}
+		after = &requests[len(requests)-1]
+	}
+}
+
+// process leaves the request pending to be retried on the next run if it can't be decided yet, e.g. the invoice isn't issued
+func (j AutopayJob) process(ctx context.Context, now time.Time, r datastore.AutopayRequest) {
+	appointmentID, err := strconv.Atoi(r.AppointmentID)
+	if err != nil {
+		logError(j.logger, err, "strconv.Atoi error", "appointmentID", r.AppointmentID)
+		j.markDone(r, datastore.SkippedAutopayResult, nil, now)
+		return
+	}
+	appointment, err := j.hospitalClient.FindAppointmentByID(ctx, appointmentID)
+	if err != nil {
+		logError(j.logger, err, "j.hospitalClient.FindAppointmentByID error", "appointmentID", r.AppointmentID)
+		return
+	}
+	if appointment == nil {
+		j.markDone(r, datastore.SkippedAutopayResult, nil, now)
+		return
+	}
+	patient, err := j.patientDataStore.FindByRefID(appointment.PatientID)
+	if err != nil {
+		logError(j.logger, err, "j.patientDataStore.FindByRefID error", "appointmentID", r.AppointmentID)
+		return
+	}
+	if patient == nil || !patient.Autopay {
+		j.markDone(r, datastore.SkippedAutopayResult, nil, now)
+		return
+	}
+	invoice := appointment.Invoice
+	if invoice == nil {
+		if now.Sub(r.CreatedAt) >= j.invoiceWait {
+			j.markDone(r, datastore.ExpiredAutopayResult, nil, now)
+		}
+		return
+	}
+	if invoice.Paid {
+		j.markDone(r, datastore.SkippedAutopayResult, nil, now)
+		return
+	}
+
+	// The patient might be paying the invoice manually, so the invoice is locked in the same way as the manual payment
+	key, token := cache.InvoicePaymentLockKey(invoice.Id), uuid.NewString()
+	locked, err := j.cacheClient.SetIfNotExists(ctx, key, token, j.lockTTL)
+	if err != nil {
+		logError(j.logger, err, "j.cacheClient.SetIfNotExists error", "appointmentID", r.AppointmentID)
+		return
+	}
+	if !locked {
+		return
+	}
+	defer func() {
+		if _, err := j.cacheClient.DeleteIfEquals(ctx, key, token); err != nil {
+			logError(j.logger, err, "j.cacheClient.DeleteIfEquals error", "appointmentID", r.AppointmentID)
+		}
+	}()
+	latest, err := j.paymentDataStore.FindLatestByInvoiceID(invoice.Id)
+	if err != nil {
+		logError(j.logger, err, "j.paymentDataStore.FindLatestByInvoiceID error", "appointmentID", r.AppointmentID)
+		return
+	}
+	if latest != nil && latest.Status != datastore.FailedPaymentStatus {
+		j.markDone(r, datastore.SkippedAutopayResult, nil, now)
+		return
+	}
+
+	amount := invoice.Total
+	card, err := j.findDefaultCard(patient.ID)
+	if err != nil {
+		logError(j.logger, err, "j.findDefaultCard error", "appointmentID", r.AppointmentID)
+		return
+	}
+	if card == nil || card.IsExpired(now) || patient.PaymentCustomerID == nil {
+		j.notifyFailure(ctx, patient.ID, invoice.Id, amount, "No valid default credit card")
+		j.markDone(r, datastore.FailedAutopayResult, nil, now)
+		return
+	}
+	charge, err := j.paymentClient.PayWithCreditCard(*patient.PaymentCustomerID, card.CardID, strconv.Itoa(invoice.Id), payment.ToSatang(amount))
+	if err != nil {
+		logError(j.logger, err, "j.paymentClient.PayWithCreditCard error", "appointmentID", r.AppointmentID)
+		j.notifyFailure(ctx, patient.ID, invoice.Id, amount, "")
+		j.markDone(r, datastore.FailedAutopayResult, nil, now)
+		return
+	}
+	status := chargeStatus(charge)
+	p := &datastore.Payment{
+		Method:       datastore.CreditCardPaymentMethod,
+		Amount:       amount,
+		ChargeID:     charge.ID,
+		InvoiceID:    invoice.Id,
+		PatientID:    patient.ID,
+		Status:       status,
+		CreditCardID: &card.ID,
+	}
+	if status != datastore.PendingPaymentStatus {
+		p.PaidAt = &now
+	}
+	// The charge is already made, so the request is done even if the payment isn't saved. The reconciliation reports the charge without payment
+	var paymentID *uint
+	if err := j.paymentDataStore.Create(p); err != nil {
+		logError(j.logger, err, "j.paymentDataStore.Create error", "appointmentID", r.AppointmentID, "chargeID", charge.ID)
+	} else {
+		paymentID = &p.ID
+	}
+
+	switch status {
+	case datastore.SuccessPaymentStatus:
+		if paymentID != nil {
+			j.markInvoicePaid(ctx, p)
+		}
+		body := fmt.Sprintf("Your invoice #%d of %.2f THB is paid automatically with %s card ending in %s", invoice.Id, amount, card.Brand, card.Last4Digits)
+		j.notifyPatientByID(ctx, patient.ID, "Payment successful", body, map[string]string{"invoiceID": strconv.Itoa(invoice.Id)})
+		j.markDone(r, datastore.ChargedAutopayResult, paymentID, now)
+	case datastore.PendingPaymentStatus:
+		// The charge is settled by the webhook once the patient authorizes it with 3-D Secure
+		body := fmt.Sprintf("Please authorize the automatic payment of %.2f THB for invoice #%d", amount, invoice.Id)
+		data := map[string]string{"invoiceID": strconv.Itoa(invoice.Id), "authorizeURI": charge.AuthorizeURI}
+		j.notifyPatientByID(ctx, patient.ID, "Payment requires authorization", body, data)
+		j.markDone(r, datastore.ChargedAutopayResult, paymentID, now)
+	default:
+		var reason string
+		if charge.FailureMessage != nil {
+			reason = *charge.FailureMessage
+		}
+		j.notifyFailure(ctx, patient.ID, invoice.Id, amount, reason)
+		j.markDone(r, datastore.FailedAutopayResult, paymentID, now)
+	}
+}
+
+func (j AutopayJob) findDefaultCard(patientID uint) (*datastore.CreditCard, error) {
+	cards, err := j.creditCardDataStore.FindByPatientID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cards {
+		if cards[i].IsDefault {
+			return &cards[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (j AutopayJob) markInvoicePaid(ctx context.Context, p *datastore.Payment) {
+	if err := j.hospitalClient.PaidInvoice(ctx, p.InvoiceID); err != nil {
+		logError(j.logger, err, "j.hospitalClient.PaidInvoice error", "paymentID", p.ID)
+		if err := j.outboxDataStore.RecordFailure(p.ID, err.Error()); err != nil {
+			logError(j.logger, err, "j.outboxDataStore.RecordFailure error", "paymentID", p.ID)
+		}
+		return
+	}
+	if err := j.outboxDataStore.MarkDone(p.ID, j.clock.Now()); err != nil {
+		logError(j.logger, err, "j.outboxDataStore.MarkDone error", "paymentID", p.ID)
+	}
+}
+
+// notifyFailure asks the patient to pay the invoice manually
+func (j AutopayJob) notifyFailure(ctx context.Context, patientID uint, invoiceID int, amount float64, reason string) {
+	body := fmt.Sprintf("The automatic payment of %.2f THB for invoice #%d has failed", amount, invoiceID)
+	if reason != "" {
+		body = fmt.Sprintf("%s: %s", body, reason)
+	}
+	body += ". Please pay the invoice manually"
+	j.notifyPatientByID(ctx, patientID, "Automatic payment failed", body, map[string]string{"invoiceID": strconv.Itoa(invoiceID)})
+}
+
+func (j AutopayJob) markDone(r datastore.AutopayRequest, result datastore.AutopayResult, paymentID *uint, now time.Time) {
+	if err := j.autopayDataStore.MarkDone(r.ID, result, paymentID, now); err != nil {
+		logError(j.logger, err, "j.autopayDataStore.MarkDone error", "appointmentID", r.AppointmentID)
+	}
+}
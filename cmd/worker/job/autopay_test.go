@@ -0,0 +1,292 @@
+package job_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_event"
+	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_notification"
+	"github.com/synthia-telemed/backend-api/test/mock_payment"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+var _ = Describe("Autopay Job", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		ctx         context.Context
+		j           *job.AutopayJob
+		now         time.Time
+		invoiceWait time.Duration
+		err         error
+
+		mockHospitalClient        *mock_hospital_client.MockSystemClient
+		mockPaymentClient         *mock_payment.MockClient
+		mockPatientDataStore      *mock_datastore.MockPatientDataStore
+		mockCreditCardDataStore   *mock_datastore.MockCreditCardDataStore
+		mockPaymentDataStore      *mock_datastore.MockPaymentDataStore
+		mockOutboxDataStore       *mock_datastore.MockPaidInvoiceOutboxDataStore
+		mockAutopayDataStore      *mock_datastore.MockAutopayDataStore
+		mockNotificationDataStore *mock_datastore.MockNotificationDataStore
+		mockNotificationClient    *mock_notification.MockClient
+		mockEventBroker           *mock_event.MockBroker
+		mockCacheClient           *mock_cache_client.MockClient
+		mockClock                 *mock_clock.MockClock
+
+		request     datastore.AutopayRequest
+		appointment *hospital.Appointment
+		patient     *datastore.Patient
+		card        *datastore.CreditCard
+		amount      float64
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		ctx = context.Background()
+		mockHospitalClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
+		mockPaymentClient = mock_payment.NewMockClient(mockCtrl)
+		mockPatientDataStore = mock_datastore.NewMockPatientDataStore(mockCtrl)
+		mockCreditCardDataStore = mock_datastore.NewMockCreditCardDataStore(mockCtrl)
+		mockPaymentDataStore = mock_datastore.NewMockPaymentDataStore(mockCtrl)
+		mockOutboxDataStore = mock_datastore.NewMockPaidInvoiceOutboxDataStore(mockCtrl)
+		mockAutopayDataStore = mock_datastore.NewMockAutopayDataStore(mockCtrl)
+		mockNotificationDataStore = mock_datastore.NewMockNotificationDataStore(mockCtrl)
+		mockNotificationClient = mock_notification.NewMockClient(mockCtrl)
+		mockEventBroker = mock_event.NewMockBroker(mockCtrl)
+		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		invoiceWait = 72 * time.Hour
+		j = job.NewAutopayJob(mockHospitalClient, mockPaymentClient, mockPatientDataStore, mockCreditCardDataStore, mockPaymentDataStore, mockOutboxDataStore, mockAutopayDataStore, mockNotificationDataStore, mockNotificationClient, mockEventBroker, mockCacheClient, mockClock, invoiceWait, time.Minute, zap.NewNop().Sugar())
+
+		now = time.Date(2022, 10, 18, 9, 0, 0, 0, time.UTC)
+		mockClock.EXPECT().Now().Return(now).AnyTimes()
+		patient = testhelper.GeneratePatient()
+		patient.Autopay = true
+		customerID := "cust_test"
+		patient.PaymentCustomerID = &customerID
+		var appointmentID int
+		appointment, appointmentID = testhelper.GenerateAppointment(patient.RefID, "1", hospital.AppointmentStatusCompleted, false)
+		// The total is charged as in the manual payment, and it's rounded to satang as 19.99*100 isn't exact in float
+		appointment.Invoice.Total = 19.99
+		appointment.Invoice.InvoiceDiscounts = []*hospital.InvoiceDiscount{{Name: "Member", Amount: 5}}
+		amount = 19.99
+		request = datastore.AutopayRequest{ID: 1, AppointmentID: strconv.Itoa(appointmentID), CreatedAt: now.Add(-time.Hour)}
+		card = testhelper.GenerateCreditCard()
+		card.PatientID = patient.ID
+		card.IsDefault = true
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	JustBeforeEach(func() {
+		err = j.Run(ctx)
+	})
+
+	expectListPending := func() {
+		mockAutopayDataStore.EXPECT().ListPending(gomock.Nil(), gomock.Any()).Return([]datastore.AutopayRequest{request}, nil).Times(1)
+	}
+	expectAppointmentAndPatient := func() {
+		mockHospitalClient.EXPECT().FindAppointmentByID(ctx, gomock.Any()).Return(appointment, nil).Times(1)
+		mockPatientDataStore.EXPECT().FindByRefID(patient.RefID).Return(patient, nil).Times(1)
+	}
+	expectLock := func(locked bool) {
+		key := cache.InvoicePaymentLockKey(appointment.Invoice.Id)
+		mockCacheClient.EXPECT().SetIfNotExists(ctx, key, gomock.Any(), time.Minute).Return(locked, nil).Times(1)
+		if locked {
+			mockCacheClient.EXPECT().DeleteIfEquals(ctx, key, gomock.Any()).Return(true, nil).Times(1)
+		}
+	}
+	expectMarkDone := func(result datastore.AutopayResult, paymentID *uint) {
+		mockAutopayDataStore.EXPECT().MarkDone(request.ID, result, paymentID, now).Return(nil).Times(1)
+	}
+	expectNotify := func(title string) {
+		mockNotificationDataStore.EXPECT().Create(gomock.Any()).Do(func(noti *datastore.Notification) {
+			Expect(noti.PatientID).To(Equal(patient.ID))
+			Expect(noti.Title).To(Equal(title))
+		}).Return(nil).Times(1)
+		mockEventBroker.EXPECT().Publish(ctx, patient.ID, event.NotificationType, gomock.Any()).Return(nil).Times(1)
+		mockNotificationDataStore.EXPECT().CountUnRead(patient.ID).Return(1, nil).Times(1)
+		mockEventBroker.EXPECT().Publish(ctx, patient.ID, event.UnreadCountType, &event.UnreadCount{Count: 1}).Return(nil).Times(1)
+		mockNotificationClient.EXPECT().Send(ctx, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	}
+
+	When("list pending requests error", func() {
+		BeforeEach(func() {
+			mockAutopayDataStore.EXPECT().ListPending(gomock.Nil(), gomock.Any()).Return(nil, testhelper.MockError).Times(1)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	When("more than a batch of requests are pending", func() {
+		BeforeEach(func() {
+			appointment.Invoice = nil
+			page := make([]datastore.AutopayRequest, 50)
+			for i := range page {
+				page[i] = datastore.AutopayRequest{ID: uint(i + 1), AppointmentID: request.AppointmentID, CreatedAt: request.CreatedAt}
+			}
+			mockAutopayDataStore.EXPECT().ListPending(gomock.Nil(), len(page)).Return(page, nil).Times(1)
+			mockHospitalClient.EXPECT().FindAppointmentByID(ctx, gomock.Any()).Return(appointment, nil).Times(len(page) + 1)
+			mockPatientDataStore.EXPECT().FindByRefID(patient.RefID).Return(patient, nil).Times(len(page) + 1)
+			mockAutopayDataStore.EXPECT().ListPending(&page[len(page)-1], len(page)).Return([]datastore.AutopayRequest{request}, nil).Times(1)
+		})
+		It("should page through all of them", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("patient doesn't opt in to autopay", func() {
+		BeforeEach(func() {
+			patient.Autopay = false
+			expectListPending()
+			expectAppointmentAndPatient()
+			expectMarkDone(datastore.SkippedAutopayResult, nil)
+		})
+		It("should skip the request", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("invoice isn't issued yet", func() {
+		BeforeEach(func() {
+			appointment.Invoice = nil
+			expectListPending()
+			expectAppointmentAndPatient()
+		})
+		It("should leave the request pending", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("invoice isn't issued in time", func() {
+		BeforeEach(func() {
+			appointment.Invoice = nil
+			request.CreatedAt = now.Add(-invoiceWait)
+			expectListPending()
+			expectAppointmentAndPatient()
+			expectMarkDone(datastore.ExpiredAutopayResult, nil)
+		})
+		It("should expire the request", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("invoice is being paid manually", func() {
+		BeforeEach(func() {
+			expectListPending()
+			expectAppointmentAndPatient()
+			expectLock(false)
+		})
+		It("should leave the request pending", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("invoice has a pending payment", func() {
+		BeforeEach(func() {
+			expectListPending()
+			expectAppointmentAndPatient()
+			expectLock(true)
+			mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(appointment.Invoice.Id).Return(&datastore.Payment{Status: datastore.PendingPaymentStatus}, nil).Times(1)
+			expectMarkDone(datastore.SkippedAutopayResult, nil)
+		})
+		It("should skip the request", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("patient has no valid default card", func() {
+		BeforeEach(func() {
+			card.ExpiryMonth, card.ExpiryYear = 9, 2022
+			expectListPending()
+			expectAppointmentAndPatient()
+			expectLock(true)
+			mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(appointment.Invoice.Id).Return(nil, nil).Times(1)
+			mockCreditCardDataStore.EXPECT().FindByPatientID(patient.ID).Return([]datastore.CreditCard{*card}, nil).Times(1)
+			expectNotify("Automatic payment failed")
+			expectMarkDone(datastore.FailedAutopayResult, nil)
+		})
+		It("should ask the patient to pay manually", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("default card is charged", func() {
+		var (
+			charge    *payment.Payment
+			paymentID uint
+		)
+		BeforeEach(func() {
+			paymentID = 10
+			charge = testhelper.GeneratePayment(true)
+			expectListPending()
+			expectAppointmentAndPatient()
+			expectLock(true)
+			mockPaymentDataStore.EXPECT().FindLatestByInvoiceID(appointment.Invoice.Id).Return(&datastore.Payment{Status: datastore.FailedPaymentStatus}, nil).Times(1)
+			mockCreditCardDataStore.EXPECT().FindByPatientID(patient.ID).Return([]datastore.CreditCard{*testhelper.GenerateCreditCard(), *card}, nil).Times(1)
+		})
+
+		When("charge is successful", func() {
+			BeforeEach(func() {
+				mockPaymentClient.EXPECT().PayWithCreditCard(*patient.PaymentCustomerID, card.CardID, strconv.Itoa(appointment.Invoice.Id), 1999).Return(charge, nil).Times(1)
+				mockPaymentDataStore.EXPECT().Create(gomock.Any()).Do(func(p *datastore.Payment) {
+					Expect(p.Status).To(Equal(datastore.SuccessPaymentStatus))
+					Expect(p.Amount).To(Equal(amount))
+					Expect(p.ChargeID).To(Equal(charge.ID))
+					Expect(*p.CreditCardID).To(Equal(card.ID))
+					p.ID = paymentID
+				}).Return(nil).Times(1)
+				mockHospitalClient.EXPECT().PaidInvoice(ctx, appointment.Invoice.Id).Return(nil).Times(1)
+				mockOutboxDataStore.EXPECT().MarkDone(paymentID, now).Return(nil).Times(1)
+				expectNotify("Payment successful")
+				expectMarkDone(datastore.ChargedAutopayResult, &paymentID)
+			})
+			It("should record the payment and mark the invoice as paid", func() {
+				Expect(err).To(BeNil())
+			})
+		})
+
+		When("charge is failed", func() {
+			BeforeEach(func() {
+				charge = testhelper.GeneratePayment(false)
+				mockPaymentClient.EXPECT().PayWithCreditCard(*patient.PaymentCustomerID, card.CardID, strconv.Itoa(appointment.Invoice.Id), 1999).Return(charge, nil).Times(1)
+				mockPaymentDataStore.EXPECT().Create(gomock.Any()).Do(func(p *datastore.Payment) {
+					Expect(p.Status).To(Equal(datastore.FailedPaymentStatus))
+					p.ID = paymentID
+				}).Return(nil).Times(1)
+				expectNotify("Automatic payment failed")
+				expectMarkDone(datastore.FailedAutopayResult, &paymentID)
+			})
+			It("should record the failed payment and ask the patient to pay manually", func() {
+				Expect(err).To(BeNil())
+			})
+		})
+
+		When("charge error", func() {
+			BeforeEach(func() {
+				mockPaymentClient.EXPECT().PayWithCreditCard(*patient.PaymentCustomerID, card.CardID, strconv.Itoa(appointment.Invoice.Id), 1999).Return(nil, testhelper.MockError).Times(1)
+				expectNotify("Automatic payment failed")
+				expectMarkDone(datastore.FailedAutopayResult, nil)
+			})
+			It("should ask the patient to pay manually", func() {
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+})
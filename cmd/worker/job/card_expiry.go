@@ -0,0 +1,69 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const cardExpiryTitle = "Credit card is expiring"
+
+// CardExpiryJob notifies patients whose default card expires within the notice period, so they can add a new card before it fails to be charged
+type CardExpiryJob struct {
+	notifier
+	creditCardDataStore datastore.CreditCardDataStore
+	clock               clock.Clock
+	location            *time.Location
+	noticePeriod        time.Duration
+}
+
+func NewCardExpiryJob(cds datastore.CreditCardDataStore, nds datastore.NotificationDataStore, noti notification.Client, eventBroker event.Broker, c clock.Clock, noticePeriod time.Duration, loc *time.Location, logger *zap.SugaredLogger) *CardExpiryJob {
+	return &CardExpiryJob{
+		notifier: notifier{
+			notificationDataStore: nds,
+			notificationClient:    noti,
+			eventBroker:           eventBroker,
+			logger:                logger,
+		},
+		creditCardDataStore: cds,
+		clock:               c,
+		location:            loc,
+		noticePeriod:        noticePeriod,
+	}
+}
+
+func (j CardExpiryJob) Name() string {
+	return "card-expiry"
+}
+
+func (j CardExpiryJob) Run(ctx context.Context) error {
+	now := j.clock.Now().In(j.location)
+	cards, err := j.creditCardDataStore.ListExpiringDefault(now, now.Add(j.noticePeriod))
+	if err != nil {
+		return err
+	}
+	for _, card := range cards {
+		j.notify(ctx, now, card)
+	}
+	return nil
+}
+
+// notify claims the notification before sending, so the patient is notified at most once per card even if the job is run by multiple workers
+func (j CardExpiryJob) notify(ctx context.Context, now time.Time, card datastore.CreditCard) {
+	claimed, err := j.creditCardDataStore.ClaimExpiryNotification(card.ID, now)
+	if err != nil {
+		logError(j.logger, err, "j.creditCardDataStore.ClaimExpiryNotification error", "creditCardID", card.ID)
+		return
+	}
+	if !claimed {
+		return
+	}
+	body := fmt.Sprintf("Your default %s card ending in %s expires at the end of %s %d. Please add a new card to pay your invoices", card.Brand, card.Last4Digits, time.Month(card.ExpiryMonth), card.ExpiryYear)
+	j.notifyPatientByID(ctx, card.PatientID, cardExpiryTitle, body, map[string]string{"creditCardID": strconv.FormatUint(uint64(card.ID), 10)})
+}
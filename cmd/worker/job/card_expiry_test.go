@@ -0,0 +1,110 @@
+package job_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_event"
+	"github.com/synthia-telemed/backend-api/test/mock_notification"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ = Describe("Card Expiry Job", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		ctx          context.Context
+		j            *job.CardExpiryJob
+		now          time.Time
+		noticePeriod time.Duration
+		err          error
+
+		mockCreditCardDataStore   *mock_datastore.MockCreditCardDataStore
+		mockNotificationDataStore *mock_datastore.MockNotificationDataStore
+		mockNotificationClient    *mock_notification.MockClient
+		mockEventBroker           *mock_event.MockBroker
+		mockClock                 *mock_clock.MockClock
+
+		cards []datastore.CreditCard
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		ctx = context.Background()
+		mockCreditCardDataStore = mock_datastore.NewMockCreditCardDataStore(mockCtrl)
+		mockNotificationDataStore = mock_datastore.NewMockNotificationDataStore(mockCtrl)
+		mockNotificationClient = mock_notification.NewMockClient(mockCtrl)
+		mockEventBroker = mock_event.NewMockBroker(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		noticePeriod = 30 * 24 * time.Hour
+		j = job.NewCardExpiryJob(mockCreditCardDataStore, mockNotificationDataStore, mockNotificationClient, mockEventBroker, mockClock, noticePeriod, time.UTC, zap.NewNop().Sugar())
+
+		now = time.Date(2022, 10, 18, 9, 0, 0, 0, time.UTC)
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		cards = testhelper.GenerateCreditCards(2)
+		for i := range cards {
+			cards[i].IsDefault = true
+			cards[i].ExpiryMonth, cards[i].ExpiryYear = 10, 2022
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	JustBeforeEach(func() {
+		err = j.Run(ctx)
+	})
+
+	When("list expiring cards error", func() {
+		BeforeEach(func() {
+			mockCreditCardDataStore.EXPECT().ListExpiringDefault(now, now.Add(noticePeriod)).Return(nil, testhelper.MockError).Times(1)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	When("claim notification error", func() {
+		BeforeEach(func() {
+			mockCreditCardDataStore.EXPECT().ListExpiringDefault(now, now.Add(noticePeriod)).Return(cards[:1], nil).Times(1)
+			mockCreditCardDataStore.EXPECT().ClaimExpiryNotification(cards[0].ID, now).Return(false, testhelper.MockError).Times(1)
+		})
+		It("should skip the card", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("no error occurred", func() {
+		BeforeEach(func() {
+			mockCreditCardDataStore.EXPECT().ListExpiringDefault(now, now.Add(noticePeriod)).Return(cards, nil).Times(1)
+			mockCreditCardDataStore.EXPECT().ClaimExpiryNotification(cards[0].ID, now).Return(true, nil).Times(1)
+			mockNotificationDataStore.EXPECT().Create(gomock.Any()).Do(func(noti *datastore.Notification) {
+				Expect(noti.PatientID).To(Equal(cards[0].PatientID))
+				Expect(noti.Body).To(ContainSubstring(cards[0].Last4Digits))
+				Expect(noti.Body).To(ContainSubstring("October 2022"))
+			}).Return(nil).Times(1)
+			mockEventBroker.EXPECT().Publish(ctx, cards[0].PatientID, event.NotificationType, gomock.Any()).Return(nil).Times(1)
+			mockNotificationDataStore.EXPECT().CountUnRead(cards[0].PatientID).Return(1, nil).Times(1)
+			mockEventBroker.EXPECT().Publish(ctx, cards[0].PatientID, event.UnreadCountType, &event.UnreadCount{Count: 1}).Return(nil).Times(1)
+			mockNotificationClient.EXPECT().Send(ctx, gomock.Any(), map[string]string{"creditCardID": fmt.Sprintf("%d", cards[0].ID)}).Do(func(_ context.Context, params notification.SendParams, _ map[string]string) {
+				Expect(params.ID).To(Equal(fmt.Sprintf("%d", cards[0].PatientID)))
+			}).Return(nil).Times(1)
+
+			// The other worker has already notified the patient
+			mockCreditCardDataStore.EXPECT().ClaimExpiryNotification(cards[1].ID, now).Return(false, nil).Times(1)
+		})
+		It("should notify the patients of the claimed cards", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package job
+
+import (
+	"context"
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Job is a unit of work that is run periodically by the worker
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type Runner struct {
+	logger   *zap.SugaredLogger
+	jobs     []Job
+	interval time.Duration
+}
+
+func NewRunner(interval time.Duration, logger *zap.SugaredLogger, jobs ...Job) *Runner {
+	return &Runner{interval: interval, logger: logger, jobs: jobs}
+}
+
+// Start runs every job immediately and then on every interval until ctx is done
+func (r Runner) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.runAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r Runner) runAll(ctx context.Context) {
+	for _, j := range r.jobs {
+		if err := j.Run(ctx); err != nil {
+			logError(r.logger, err, "Job failed", "job", j.Name())
+		}
+	}
+}
+
+func logError(logger *zap.SugaredLogger, err error, msg string, keysAndValues ...interface{}) {
+	logger.Errorw(msg, append(keysAndValues, "error", err.Error())...)
+	sentry.CaptureException(err)
+}
@@ -0,0 +1,146 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	noShowPageSize = 50
+	noShowTitle    = "Appointment missed"
+)
+
+// NoShowJob marks the scheduled appointments that were never started and ended longer than the grace period ago as no-show.
+// The hospital system has no no-show status, so the appointment is cancelled and the outcome is recorded locally.
+type NoShowJob struct {
+	notifier
+	hospitalClient       hospital.SystemClient
+	appointmentDataStore datastore.AppointmentDataStore
+	roomClosureDataStore datastore.RoomClosureDataStore
+	cacheClient          cache.Client
+	clock                clock.Clock
+	location             *time.Location
+	gracePeriod          time.Duration
+}
+
+func NewNoShowJob(hos hospital.SystemClient, ads datastore.AppointmentDataStore, rcds datastore.RoomClosureDataStore, pds datastore.PatientDataStore, dds datastore.DoctorDataStore, nds datastore.NotificationDataStore, cacheClient cache.Client, noti notification.Client, eventBroker event.Broker, c clock.Clock, gracePeriod time.Duration, loc *time.Location, logger *zap.SugaredLogger) *NoShowJob {
+	return &NoShowJob{
+		notifier: notifier{
+			patientDataStore:      pds,
+			doctorDataStore:       dds,
+			notificationDataStore: nds,
+			notificationClient:    noti,
+			eventBroker:           eventBroker,
+			logger:                logger,
+		},
+		hospitalClient:       hos,
+		appointmentDataStore: ads,
+		roomClosureDataStore: rcds,
+		cacheClient:          cacheClient,
+		clock:                c,
+		location:             loc,
+		gracePeriod:          gracePeriod,
+	}
+}
+
+func (j NoShowJob) Name() string {
+	return "no-show"
+}
+
+func (j NoShowJob) Run(ctx context.Context) error {
+	now := j.clock.Now()
+	since := time.Unix(0, 0).UTC()
+	before := now.Add(-j.gracePeriod)
+	filters := &hospital.ListAppointmentsFilters{
+		Status:      hospital.AppointmentStatusScheduled,
+		StartAfter:  &since,
+		StartBefore: &before,
+	}
+	// The appointments that are marked as no-show are no longer scheduled, so only the remaining ones are skipped
+	skip := 0
+	for {
+		appointments, err := j.hospitalClient.ListAppointmentsWithFilters(ctx, filters, noShowPageSize, skip)
+		if err != nil {
+			return err
+		}
+		for _, appointment := range appointments {
+			if !j.markNoShow(ctx, now, appointment) {
+				skip++
+			}
+		}
+		if len(appointments) < noShowPageSize {
+			return nil
+		}
+	}
+}
+
+// markNoShow reports whether the appointment is marked as no-show
+func (j NoShowJob) markNoShow(ctx context.Context, now time.Time, appointment *hospital.AppointmentOverview) bool {
+	if appointment.EndDateTime.Add(j.gracePeriod).After(now) {
+		return false
+	}
+	// The room is still open, so the doctor has started the appointment
+	roomID, err := j.cacheClient.Get(ctx, cache.AppointmentRoomIDKey(appointment.Id), false)
+	if err != nil {
+		logError(j.logger, err, "j.cacheClient.Get error", "appointmentID", appointment.Id)
+		return false
+	}
+	if roomID != "" {
+		return false
+	}
+	// The room was closed without completing the appointment, e.g. swept or force left, so it was started as well
+	isClosed, err := j.roomClosureDataStore.ExistsByAppointmentID(appointment.Id)
+	if err != nil {
+		logError(j.logger, err, "j.roomClosureDataStore.ExistsByAppointmentID error", "appointmentID", appointment.Id)
+		return false
+	}
+	if isClosed {
+		return false
+	}
+	appointmentID, err := strconv.Atoi(appointment.Id)
+	if err != nil {
+		logError(j.logger, err, "strconv.Atoi error", "appointmentID", appointment.Id)
+		return false
+	}
+
+	record, err := j.appointmentDataStore.FindByRefID(appointment.Id)
+	if err != nil {
+		logError(j.logger, err, "j.appointmentDataStore.FindByRefID error", "appointmentID", appointment.Id)
+		return false
+	}
+	if record != nil && record.Outcome != datastore.NoShowAppointmentOutcome {
+		return false
+	}
+	// The record is created first, so only the first attempt notifies and the later ones only retry updating the status
+	isNewRecord := record == nil
+	if isNewRecord {
+		record = &datastore.Appointment{RefID: appointment.Id, Outcome: datastore.NoShowAppointmentOutcome}
+		if err := j.appointmentDataStore.Create(record); err != nil {
+			logError(j.logger, err, "j.appointmentDataStore.Create error", "appointmentID", appointment.Id)
+			return false
+		}
+	}
+	if err := j.hospitalClient.SetAppointmentStatus(ctx, appointmentID, hospital.SettableAppointmentStatusCancelled); err != nil {
+		logError(j.logger, err, "j.hospitalClient.SetAppointmentStatus error", "appointmentID", appointment.Id)
+		return false
+	}
+	if !isNewRecord {
+		return true
+	}
+
+	startTime := appointment.StartDateTime.In(j.location).Format(notificationTimeLayout)
+	patientBody := fmt.Sprintf("Your appointment with %s on %s was not started by the doctor and has been marked as no-show", appointment.Doctor.FullName, startTime)
+	j.notifyPatient(ctx, appointment.Patient.ID, noShowTitle, patientBody, appointment.Id)
+	doctorBody := fmt.Sprintf("Your appointment with %s on %s was not started and has been marked as no-show", appointment.Patient.FullName, startTime)
+	j.notifyDoctor(ctx, appointment.Doctor.ID, noShowTitle, doctorBody, appointment.Id)
+	return true
+}
@@ -0,0 +1,215 @@
+package job_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_event"
+	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_notification"
+	"go.uber.org/zap"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+var _ = Describe("No-show Job", func() {
+	var (
+		mockCtrl *gomock.Controller
+		ctx      context.Context
+		j        *job.NoShowJob
+		now      time.Time
+		err      error
+
+		mockHospitalClient        *mock_hospital_client.MockSystemClient
+		mockAppointmentDataStore  *mock_datastore.MockAppointmentDataStore
+		mockRoomClosureDataStore  *mock_datastore.MockRoomClosureDataStore
+		mockPatientDataStore      *mock_datastore.MockPatientDataStore
+		mockDoctorDataStore       *mock_datastore.MockDoctorDataStore
+		mockNotificationDataStore *mock_datastore.MockNotificationDataStore
+		mockCacheClient           *mock_cache_client.MockClient
+		mockNotificationClient    *mock_notification.MockClient
+		mockEventBroker           *mock_event.MockBroker
+		mockClock                 *mock_clock.MockClock
+
+		appointment   *hospital.AppointmentOverview
+		appointmentID int
+		gracePeriod   time.Duration
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		ctx = context.Background()
+		mockHospitalClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
+		mockAppointmentDataStore = mock_datastore.NewMockAppointmentDataStore(mockCtrl)
+		mockRoomClosureDataStore = mock_datastore.NewMockRoomClosureDataStore(mockCtrl)
+		mockPatientDataStore = mock_datastore.NewMockPatientDataStore(mockCtrl)
+		mockDoctorDataStore = mock_datastore.NewMockDoctorDataStore(mockCtrl)
+		mockNotificationDataStore = mock_datastore.NewMockNotificationDataStore(mockCtrl)
+		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
+		mockNotificationClient = mock_notification.NewMockClient(mockCtrl)
+		mockEventBroker = mock_event.NewMockBroker(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		gracePeriod = 3 * time.Hour
+		j = job.NewNoShowJob(mockHospitalClient, mockAppointmentDataStore, mockRoomClosureDataStore, mockPatientDataStore, mockDoctorDataStore, mockNotificationDataStore, mockCacheClient, mockNotificationClient, mockEventBroker, mockClock, gracePeriod, time.UTC, zap.NewNop().Sugar())
+
+		now = time.Date(2022, 10, 10, 9, 0, 0, 0, time.UTC)
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		appointmentID = int(rand.Int31())
+		appointment = testhelper.GenerateAppointmentOverview(hospital.AppointmentStatusScheduled)
+		appointment.Id = strconv.Itoa(appointmentID)
+		appointment.StartDateTime = now.Add(-4 * time.Hour)
+		appointment.EndDateTime = appointment.StartDateTime.Add(30 * time.Minute)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	JustBeforeEach(func() {
+		err = j.Run(ctx)
+	})
+
+	expectListAppointments := func(apps []*hospital.AppointmentOverview, err error) {
+		mockHospitalClient.EXPECT().ListAppointmentsWithFilters(ctx, gomock.Any(), gomock.Any(), 0).Do(func(_ context.Context, filters *hospital.ListAppointmentsFilters, _, _ int) {
+			Expect(filters.Status).To(Equal(hospital.AppointmentStatusScheduled))
+			Expect(*filters.StartBefore).To(Equal(now.Add(-gracePeriod)))
+		}).Return(apps, err).Times(1)
+	}
+	expectRoomID := func(roomID string) {
+		mockCacheClient.EXPECT().Get(ctx, cache.AppointmentRoomIDKey(appointment.Id), false).Return(roomID, nil).Times(1)
+	}
+	expectRoomClosure := func(isClosed bool, err error) {
+		mockRoomClosureDataStore.EXPECT().ExistsByAppointmentID(appointment.Id).Return(isClosed, err).Times(1)
+	}
+
+	When("list appointments error", func() {
+		BeforeEach(func() {
+			expectListAppointments(nil, testhelper.MockError)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	When("the appointment hasn't passed the grace period after it ends", func() {
+		BeforeEach(func() {
+			appointment.EndDateTime = now.Add(-time.Hour)
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+		})
+		It("should not mark the appointment", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("the room of the appointment is still open", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			expectRoomID("room-id")
+		})
+		It("should not mark the appointment", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("check room closure error", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			expectRoomID("")
+			expectRoomClosure(false, testhelper.MockError)
+		})
+		It("should not mark the appointment", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("the room of the appointment was closed", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			expectRoomID("")
+			expectRoomClosure(true, nil)
+		})
+		It("should not mark the appointment", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("the appointment was completed locally", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			expectRoomID("")
+			expectRoomClosure(false, nil)
+			record := &datastore.Appointment{RefID: appointment.Id, Outcome: datastore.CompletedAppointmentOutcome}
+			mockAppointmentDataStore.EXPECT().FindByRefID(appointment.Id).Return(record, nil).Times(1)
+		})
+		It("should not mark the appointment", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("the appointment was marked as no-show but the status wasn't updated", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			expectRoomID("")
+			expectRoomClosure(false, nil)
+			record := &datastore.Appointment{RefID: appointment.Id, Outcome: datastore.NoShowAppointmentOutcome}
+			mockAppointmentDataStore.EXPECT().FindByRefID(appointment.Id).Return(record, nil).Times(1)
+			mockHospitalClient.EXPECT().SetAppointmentStatus(ctx, appointmentID, hospital.SettableAppointmentStatusCancelled).Return(nil).Times(1)
+		})
+		It("should retry updating the status without notifying again", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("create local record error", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			expectRoomID("")
+			expectRoomClosure(false, nil)
+			mockAppointmentDataStore.EXPECT().FindByRefID(appointment.Id).Return(nil, nil).Times(1)
+			mockAppointmentDataStore.EXPECT().Create(gomock.Any()).Return(testhelper.MockError).Times(1)
+		})
+		It("should not update the status", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("no error occurred", func() {
+		var (
+			patient *datastore.Patient
+			doctor  *datastore.Doctor
+		)
+		BeforeEach(func() {
+			patient = testhelper.GeneratePatient()
+			doctor = testhelper.GenerateDoctor()
+			doctor.NotificationToken = "doctor-device-token"
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			expectRoomID("")
+			expectRoomClosure(false, nil)
+			mockAppointmentDataStore.EXPECT().FindByRefID(appointment.Id).Return(nil, nil).Times(1)
+			mockAppointmentDataStore.EXPECT().Create(&datastore.Appointment{RefID: appointment.Id, Outcome: datastore.NoShowAppointmentOutcome}).Return(nil).Times(1)
+			mockHospitalClient.EXPECT().SetAppointmentStatus(ctx, appointmentID, hospital.SettableAppointmentStatusCancelled).Return(nil).Times(1)
+			mockPatientDataStore.EXPECT().FindByRefID(appointment.Patient.ID).Return(patient, nil).Times(1)
+			mockNotificationDataStore.EXPECT().Create(gomock.Any()).Return(nil).Times(1)
+			mockEventBroker.EXPECT().Publish(ctx, patient.ID, event.NotificationType, gomock.Any()).Return(nil).Times(1)
+			mockNotificationDataStore.EXPECT().CountUnRead(patient.ID).Return(1, nil).Times(1)
+			mockEventBroker.EXPECT().Publish(ctx, patient.ID, event.UnreadCountType, &event.UnreadCount{Count: 1}).Return(nil).Times(1)
+			mockDoctorDataStore.EXPECT().FindByRefID(appointment.Doctor.ID).Return(doctor, nil).Times(1)
+			mockNotificationClient.EXPECT().Send(ctx, gomock.Any(), map[string]string{"appointmentID": appointment.Id}).Return(nil).Times(1)
+			mockNotificationClient.EXPECT().SendToDoctor(ctx, doctor.NotificationToken, gomock.Any(), map[string]string{"appointmentID": appointment.Id}).Return(nil).Times(1)
+		})
+		It("should mark the appointment as no-show and notify both parties", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,80 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"go.uber.org/zap"
+)
+
+const notificationTimeLayout = "2 Jan 2006 15:04"
+
+// notifier sends push notification about the appointment to the patient and the doctor
+type notifier struct {
+	patientDataStore      datastore.PatientDataStore
+	doctorDataStore       datastore.DoctorDataStore
+	notificationDataStore datastore.NotificationDataStore
+	notificationClient    notification.Client
+	eventBroker           event.Broker
+	logger                *zap.SugaredLogger
+}
+
+// notifyPatient saves the notification to the patient's notification list, publishes it to the patient's live streams and sends push notification
+func (n notifier) notifyPatient(ctx context.Context, patientRefID, title, body, appointmentID string) {
+	patient, err := n.patientDataStore.FindByRefID(patientRefID)
+	if err != nil {
+		logError(n.logger, err, "n.patientDataStore.FindByRefID error", "appointmentID", appointmentID)
+		return
+	}
+	// Patient never signed in, so there is no device to notify
+	if patient == nil {
+		return
+	}
+	n.notifyPatientByID(ctx, patient.ID, title, body, map[string]string{"appointmentID": appointmentID})
+}
+
+// notifyPatientByID is notifyPatient of the patient who has signed in. The data is attached to the push notification
+func (n notifier) notifyPatientByID(ctx context.Context, patientID uint, title, body string, data map[string]string) {
+	noti := &datastore.Notification{Title: title, Body: body, PatientID: patientID}
+	if err := n.notificationDataStore.Create(noti); err != nil {
+		logError(n.logger, err, "n.notificationDataStore.Create error", "patientID", patientID, "data", data)
+		return
+	}
+	n.publish(ctx, noti)
+	n.send(ctx, patientID, title, body, data)
+}
+
+func (n notifier) publish(ctx context.Context, noti *datastore.Notification) {
+	if err := n.eventBroker.Publish(ctx, noti.PatientID, event.NotificationType, noti); err != nil {
+		logError(n.logger, err, "n.eventBroker.Publish error", "patientID", noti.PatientID)
+		return
+	}
+	count, err := n.notificationDataStore.CountUnRead(noti.PatientID)
+	if err != nil {
+		logError(n.logger, err, "n.notificationDataStore.CountUnRead error", "patientID", noti.PatientID)
+		return
+	}
+	if err := n.eventBroker.Publish(ctx, noti.PatientID, event.UnreadCountType, &event.UnreadCount{Count: count}); err != nil {
+		logError(n.logger, err, "n.eventBroker.Publish error", "patientID", noti.PatientID)
+	}
+}
+
+// notifyDoctor sends push notification to the doctor's device, which isn't reachable by the patient's notification channel
+func (n notifier) notifyDoctor(ctx context.Context, doctorRefID, title, body, appointmentID string) {
+	if err := notification.NotifyDoctor(ctx, n.notificationClient, n.doctorDataStore, doctorRefID, title, body, map[string]string{"appointmentID": appointmentID}); err != nil {
+		logError(n.logger, err, "notification.NotifyDoctor error", "appointmentID", appointmentID)
+	}
+}
+
+func (n notifier) send(ctx context.Context, id uint, title, body string, data map[string]string) {
+	notiParam := notification.SendParams{
+		ID:    fmt.Sprintf("%d", id),
+		Title: title,
+		Body:  body,
+	}
+	if err := n.notificationClient.Send(ctx, notiParam, data); err != nil {
+		logError(n.logger, err, "n.notificationClient.Send error", "id", id, "data", data)
+	}
+}
@@ -0,0 +1,262 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const outboxBatchSize = 100
+
+var errOutboxInvoiceNotFound = errors.New("invoice of the outbox not found")
+
+type MismatchType string
+
+const (
+	// ChargeWithoutPaymentMismatch is the successful charge that has no payment, e.g. the payment failed to be saved after charging
+	ChargeWithoutPaymentMismatch MismatchType = "charge_without_payment"
+	// PaymentStatusMismatch is the payment whose status is different from its charge
+	PaymentStatusMismatch MismatchType = "payment_status_mismatch"
+	// InvoiceUnpaidMismatch is the successful payment whose invoice is still unpaid in the hospital system
+	InvoiceUnpaidMismatch MismatchType = "invoice_unpaid"
+)
+
+type Mismatch struct {
+	Type          MismatchType
+	ChargeID      string
+	PaymentStatus datastore.PaymentStatus
+	ChargeStatus  datastore.PaymentStatus
+	PaymentID     uint
+	InvoiceID     int
+}
+
+type ReconciliationReport struct {
+	Mismatches []Mismatch
+	// PaidInvoiceRetried and PaidInvoiceFailed are the numbers of the outbox which are done and still failed respectively
+	PaidInvoiceRetried int
+	PaidInvoiceFailed  int
+}
+
+// ReconciliationJob retries marking the invoices of the successful payments as paid from the outbox,
+// then compares the charges, the payments and the invoices created within the window and reports the mismatches
+type ReconciliationJob struct {
+	paymentClient    payment.Client
+	hospitalClient   hospital.SystemClient
+	paymentDataStore datastore.PaymentDataStore
+	outboxDataStore  datastore.PaidInvoiceOutboxDataStore
+	clock            clock.Clock
+	logger           *zap.SugaredLogger
+	window           time.Duration
+}
+
+func NewReconciliationJob(paymentClient payment.Client, hos hospital.SystemClient, pds datastore.PaymentDataStore, outbox datastore.PaidInvoiceOutboxDataStore, c clock.Clock, window time.Duration, logger *zap.SugaredLogger) *ReconciliationJob {
+	return &ReconciliationJob{
+		paymentClient:    paymentClient,
+		hospitalClient:   hos,
+		paymentDataStore: pds,
+		outboxDataStore:  outbox,
+		clock:            c,
+		logger:           logger,
+		window:           window,
+	}
+}
+
+func (j ReconciliationJob) Name() string {
+	return "reconciliation"
+}
+
+func (j ReconciliationJob) Run(ctx context.Context) error {
+	_, err := j.Reconcile(ctx)
+	return err
+}
+
+func (j ReconciliationJob) Reconcile(ctx context.Context) (*ReconciliationReport, error) {
+	report := &ReconciliationReport{}
+	if err := j.retryOutbox(ctx, report); err != nil {
+		return nil, err
+	}
+
+	now := j.clock.Now()
+	from := now.Add(-j.window)
+	charges, err := j.paymentClient.ListCharges(from, now)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := j.paymentDataStore.ListCreatedBetween(from, now)
+	if err != nil {
+		return nil, err
+	}
+	chargeByID := make(map[string]*payment.Payment, len(charges))
+	for _, charge := range charges {
+		chargeByID[charge.ID] = charge
+	}
+	paymentByChargeID := make(map[string]*datastore.Payment, len(payments))
+	for i := range payments {
+		paymentByChargeID[payments[i].ChargeID] = &payments[i]
+	}
+
+	for _, charge := range charges {
+		if !charge.Success || paymentByChargeID[charge.ID] != nil {
+			continue
+		}
+		// The payment might be created right after the window
+		p, err := j.paymentDataStore.FindByChargeID(charge.ID)
+		if err != nil {
+			logError(j.logger, err, "j.paymentDataStore.FindByChargeID error", "chargeID", charge.ID)
+			continue
+		}
+		if p == nil {
+			invoiceID, _ := strconv.Atoi(charge.RefID)
+			report.Mismatches = append(report.Mismatches, Mismatch{
+				Type:         ChargeWithoutPaymentMismatch,
+				ChargeID:     charge.ID,
+				ChargeStatus: chargeStatus(charge),
+				InvoiceID:    invoiceID,
+			})
+		}
+	}
+
+	// The parts of the split payment pay the invoice together, so the invoice is checked once with the last part.
+	// The split with any part that isn't successful, e.g. refunded, doesn't pay the invoice
+	splits := make(map[string]*splitPayment)
+	splitIDs := make([]string, 0)
+	for _, p := range payments {
+		var split *splitPayment
+		if p.SplitID != nil {
+			if split = splits[*p.SplitID]; split == nil {
+				split = &splitPayment{paid: true}
+				splits[*p.SplitID] = split
+				splitIDs = append(splitIDs, *p.SplitID)
+			}
+			split.paid = split.paid && p.Status == datastore.SuccessPaymentStatus
+		}
+		charge, ok := chargeByID[p.ChargeID]
+		if !ok {
+			charge, err = j.paymentClient.GetCharge(p.ChargeID)
+			if err != nil {
+				logError(j.logger, err, "j.paymentClient.GetCharge error", "paymentID", p.ID)
+				if split != nil {
+					split.paid = false
+				}
+				continue
+			}
+		}
+		mismatch := Mismatch{
+			ChargeID:      p.ChargeID,
+			PaymentStatus: p.Status,
+			ChargeStatus:  chargeStatus(charge),
+			PaymentID:     p.ID,
+			InvoiceID:     p.InvoiceID,
+		}
+		if expectedChargeStatus(p.Status) != mismatch.ChargeStatus {
+			mismatch.Type = PaymentStatusMismatch
+			report.Mismatches = append(report.Mismatches, mismatch)
+		}
+		if split != nil {
+			split.last = mismatch
+			continue
+		}
+		if p.Status == datastore.SuccessPaymentStatus {
+			j.checkInvoicePaid(ctx, report, mismatch)
+		}
+	}
+	for _, id := range splitIDs {
+		if split := splits[id]; split.paid {
+			j.checkInvoicePaid(ctx, report, split.last)
+		}
+	}
+
+	for _, m := range report.Mismatches {
+		j.logger.Warnw("Payment mismatch",
+			"type", m.Type,
+			"chargeID", m.ChargeID,
+			"paymentID", m.PaymentID,
+			"invoiceID", m.InvoiceID,
+			"paymentStatus", m.PaymentStatus,
+			"chargeStatus", m.ChargeStatus,
+		)
+	}
+	return report, nil
+}
+
+type splitPayment struct {
+	last Mismatch
+	paid bool
+}
+
+// checkInvoicePaid reports the mismatch if the invoice paid by the payment is still unpaid
+func (j ReconciliationJob) checkInvoicePaid(ctx context.Context, report *ReconciliationReport, mismatch Mismatch) {
+	invoice, err := j.hospitalClient.FindInvoiceByID(ctx, mismatch.InvoiceID)
+	if err != nil {
+		logError(j.logger, err, "j.hospitalClient.FindInvoiceByID error", "paymentID", mismatch.PaymentID)
+		return
+	}
+	if invoice != nil && !invoice.Paid {
+		mismatch.Type = InvoiceUnpaidMismatch
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+}
+
+// retryOutbox marks the invoices of the pending outbox as paid. The invoice that is already paid isn't marked again
+func (j ReconciliationJob) retryOutbox(ctx context.Context, report *ReconciliationReport) error {
+	outbox, err := j.outboxDataStore.ListPending(outboxBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, o := range outbox {
+		if err := j.markInvoicePaid(ctx, o.InvoiceID); err != nil {
+			logError(j.logger, err, "j.markInvoicePaid error", "paymentID", o.PaymentID, "invoiceID", o.InvoiceID)
+			if err := j.outboxDataStore.RecordFailure(o.PaymentID, err.Error()); err != nil {
+				logError(j.logger, err, "j.outboxDataStore.RecordFailure error", "paymentID", o.PaymentID)
+			}
+			report.PaidInvoiceFailed++
+			continue
+		}
+		if err := j.outboxDataStore.MarkDone(o.PaymentID, j.clock.Now()); err != nil {
+			logError(j.logger, err, "j.outboxDataStore.MarkDone error", "paymentID", o.PaymentID)
+			continue
+		}
+		report.PaidInvoiceRetried++
+	}
+	return nil
+}
+
+func (j ReconciliationJob) markInvoicePaid(ctx context.Context, invoiceID int) error {
+	invoice, err := j.hospitalClient.FindInvoiceByID(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+	if invoice == nil {
+		return errOutboxInvoiceNotFound
+	}
+	if invoice.Paid {
+		return nil
+	}
+	return j.hospitalClient.PaidInvoice(ctx, invoiceID)
+}
+
+func chargeStatus(charge *payment.Payment) datastore.PaymentStatus {
+	switch {
+	case charge.Success:
+		return datastore.SuccessPaymentStatus
+	case charge.Pending:
+		return datastore.PendingPaymentStatus
+	default:
+		return datastore.FailedPaymentStatus
+	}
+}
+
+// expectedChargeStatus returns the status of the charge matching the payment status.
+// The charge of the refunded part of the split payment is still successful, as the refund doesn't change it
+func expectedChargeStatus(status datastore.PaymentStatus) datastore.PaymentStatus {
+	if status == datastore.RefundedPaymentStatus {
+		return datastore.SuccessPaymentStatus
+	}
+	return status
+}
@@ -0,0 +1,308 @@
+package job_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_payment"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ = Describe("Reconciliation Job", func() {
+	var (
+		mockCtrl *gomock.Controller
+		ctx      context.Context
+		j        *job.ReconciliationJob
+		now      time.Time
+		window   time.Duration
+		report   *job.ReconciliationReport
+		err      error
+
+		mockPaymentClient    *mock_payment.MockClient
+		mockHospitalClient   *mock_hospital_client.MockSystemClient
+		mockPaymentDataStore *mock_datastore.MockPaymentDataStore
+		mockOutboxDataStore  *mock_datastore.MockPaidInvoiceOutboxDataStore
+		mockClock            *mock_clock.MockClock
+		pendingOutbox        []datastore.PaidInvoiceOutbox
+		charges              []*payment.Payment
+		payments             []datastore.Payment
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		ctx = context.Background()
+		mockPaymentClient = mock_payment.NewMockClient(mockCtrl)
+		mockHospitalClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
+		mockPaymentDataStore = mock_datastore.NewMockPaymentDataStore(mockCtrl)
+		mockOutboxDataStore = mock_datastore.NewMockPaidInvoiceOutboxDataStore(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		window = 72 * time.Hour
+		j = job.NewReconciliationJob(mockPaymentClient, mockHospitalClient, mockPaymentDataStore, mockOutboxDataStore, mockClock, window, zap.NewNop().Sugar())
+
+		now = time.Now()
+		mockClock.EXPECT().Now().Return(now).AnyTimes()
+		pendingOutbox = nil
+		charges = nil
+		payments = nil
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	JustBeforeEach(func() {
+		report, err = j.Reconcile(ctx)
+	})
+
+	expectListPendingOutbox := func(err error) {
+		mockOutboxDataStore.EXPECT().ListPending(gomock.Any()).Return(pendingOutbox, err).Times(1)
+	}
+	expectListCharges := func(err error) {
+		mockPaymentClient.EXPECT().ListCharges(now.Add(-window), now).Return(charges, err).Times(1)
+	}
+	expectListPayments := func(err error) {
+		mockPaymentDataStore.EXPECT().ListCreatedBetween(now.Add(-window), now).Return(payments, err).Times(1)
+	}
+	expectInvoice := func(invoiceID int, paid bool) {
+		mockHospitalClient.EXPECT().FindInvoiceByID(ctx, invoiceID).Return(&hospital.InvoiceOverview{Id: invoiceID, Paid: paid}, nil).Times(1)
+	}
+
+	When("list pending outbox error", func() {
+		BeforeEach(func() {
+			expectListPendingOutbox(testhelper.MockError)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+			Expect(report).To(BeNil())
+		})
+	})
+
+	When("list charges error", func() {
+		BeforeEach(func() {
+			expectListPendingOutbox(nil)
+			expectListCharges(testhelper.MockError)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	When("list payments error", func() {
+		BeforeEach(func() {
+			expectListPendingOutbox(nil)
+			expectListCharges(nil)
+			expectListPayments(testhelper.MockError)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	Context("retry paid invoice outbox", func() {
+		BeforeEach(func() {
+			pendingOutbox = []datastore.PaidInvoiceOutbox{
+				{PaymentID: 1, InvoiceID: 10},
+				{PaymentID: 2, InvoiceID: 20},
+				{PaymentID: 3, InvoiceID: 30},
+			}
+			expectListPendingOutbox(nil)
+			expectListCharges(nil)
+			expectListPayments(nil)
+		})
+
+		When("the invoices are marked as paid or already paid", func() {
+			BeforeEach(func() {
+				expectInvoice(10, false)
+				mockHospitalClient.EXPECT().PaidInvoice(ctx, 10).Return(nil).Times(1)
+				expectInvoice(20, true)
+				expectInvoice(30, false)
+				mockHospitalClient.EXPECT().PaidInvoice(ctx, 30).Return(nil).Times(1)
+				for _, id := range []uint{1, 2, 3} {
+					mockOutboxDataStore.EXPECT().MarkDone(id, now).Return(nil).Times(1)
+				}
+			})
+			It("should mark the outbox as done", func() {
+				Expect(err).To(BeNil())
+				Expect(report.PaidInvoiceRetried).To(Equal(3))
+				Expect(report.PaidInvoiceFailed).To(BeZero())
+			})
+		})
+
+		When("some invoices failed to be marked as paid", func() {
+			BeforeEach(func() {
+				expectInvoice(10, false)
+				mockHospitalClient.EXPECT().PaidInvoice(ctx, 10).Return(testhelper.MockError).Times(1)
+				mockOutboxDataStore.EXPECT().RecordFailure(uint(1), testhelper.MockError.Error()).Return(nil).Times(1)
+				mockHospitalClient.EXPECT().FindInvoiceByID(ctx, 20).Return(nil, nil).Times(1)
+				mockOutboxDataStore.EXPECT().RecordFailure(uint(2), gomock.Any()).Return(nil).Times(1)
+				expectInvoice(30, false)
+				mockHospitalClient.EXPECT().PaidInvoice(ctx, 30).Return(nil).Times(1)
+				mockOutboxDataStore.EXPECT().MarkDone(uint(3), now).Return(nil).Times(1)
+			})
+			It("should record the failures and continue", func() {
+				Expect(err).To(BeNil())
+				Expect(report.PaidInvoiceRetried).To(Equal(1))
+				Expect(report.PaidInvoiceFailed).To(Equal(2))
+			})
+		})
+	})
+
+	Context("compare charges, payments and invoices", func() {
+		BeforeEach(func() {
+			expectListPendingOutbox(nil)
+		})
+
+		When("everything matches", func() {
+			BeforeEach(func() {
+				charges = []*payment.Payment{
+					{ID: "chrg_1", RefID: "10", Success: true, Paid: true},
+					{ID: "chrg_2", RefID: "20"},
+					{ID: "chrg_3", RefID: "30", Success: true, Paid: true},
+				}
+				payments = []datastore.Payment{
+					{ID: 1, ChargeID: "chrg_1", InvoiceID: 10, Status: datastore.SuccessPaymentStatus},
+					{ID: 2, ChargeID: "chrg_2", InvoiceID: 20, Status: datastore.FailedPaymentStatus},
+					{ID: 3, ChargeID: "chrg_3", InvoiceID: 30, Status: datastore.RefundedPaymentStatus},
+				}
+				expectListCharges(nil)
+				expectListPayments(nil)
+				expectInvoice(10, true)
+			})
+			It("should report no mismatch", func() {
+				Expect(err).To(BeNil())
+				Expect(report.Mismatches).To(BeEmpty())
+			})
+		})
+
+		When("the successful charge has no payment", func() {
+			BeforeEach(func() {
+				charges = []*payment.Payment{
+					{ID: "chrg_1", RefID: "10", Success: true, Paid: true},
+					{ID: "chrg_2", RefID: "20", Success: true, Paid: true},
+				}
+				expectListCharges(nil)
+				expectListPayments(nil)
+				mockPaymentDataStore.EXPECT().FindByChargeID("chrg_1").Return(nil, nil).Times(1)
+				mockPaymentDataStore.EXPECT().FindByChargeID("chrg_2").Return(&datastore.Payment{ID: 2, ChargeID: "chrg_2"}, nil).Times(1)
+			})
+			It("should report the charge without payment", func() {
+				Expect(err).To(BeNil())
+				Expect(report.Mismatches).To(ConsistOf(job.Mismatch{
+					Type:         job.ChargeWithoutPaymentMismatch,
+					ChargeID:     "chrg_1",
+					ChargeStatus: datastore.SuccessPaymentStatus,
+					InvoiceID:    10,
+				}))
+			})
+		})
+
+		When("the payment status is different from the charge", func() {
+			BeforeEach(func() {
+				charges = []*payment.Payment{
+					{ID: "chrg_1", RefID: "10", Success: true, Paid: true},
+				}
+				payments = []datastore.Payment{
+					{ID: 1, ChargeID: "chrg_1", InvoiceID: 10, Status: datastore.PendingPaymentStatus},
+					{ID: 2, ChargeID: "chrg_old", InvoiceID: 20, Status: datastore.SuccessPaymentStatus},
+				}
+				expectListCharges(nil)
+				expectListPayments(nil)
+				mockPaymentClient.EXPECT().GetCharge("chrg_old").Return(&payment.Payment{ID: "chrg_old"}, nil).Times(1)
+				expectInvoice(20, true)
+			})
+			It("should report the status mismatches", func() {
+				Expect(err).To(BeNil())
+				Expect(report.Mismatches).To(ConsistOf(
+					job.Mismatch{
+						Type:          job.PaymentStatusMismatch,
+						ChargeID:      "chrg_1",
+						PaymentStatus: datastore.PendingPaymentStatus,
+						ChargeStatus:  datastore.SuccessPaymentStatus,
+						PaymentID:     1,
+						InvoiceID:     10,
+					},
+					job.Mismatch{
+						Type:          job.PaymentStatusMismatch,
+						ChargeID:      "chrg_old",
+						PaymentStatus: datastore.SuccessPaymentStatus,
+						ChargeStatus:  datastore.FailedPaymentStatus,
+						PaymentID:     2,
+						InvoiceID:     20,
+					},
+				))
+			})
+		})
+
+		When("the invoice of the successful payment is unpaid", func() {
+			BeforeEach(func() {
+				charges = []*payment.Payment{
+					{ID: "chrg_1", RefID: "10", Success: true, Paid: true},
+				}
+				payments = []datastore.Payment{
+					{ID: 1, ChargeID: "chrg_1", InvoiceID: 10, Status: datastore.SuccessPaymentStatus},
+				}
+				expectListCharges(nil)
+				expectListPayments(nil)
+				expectInvoice(10, false)
+			})
+			It("should report the unpaid invoice", func() {
+				Expect(err).To(BeNil())
+				Expect(report.Mismatches).To(ConsistOf(job.Mismatch{
+					Type:          job.InvoiceUnpaidMismatch,
+					ChargeID:      "chrg_1",
+					PaymentStatus: datastore.SuccessPaymentStatus,
+					ChargeStatus:  datastore.SuccessPaymentStatus,
+					PaymentID:     1,
+					InvoiceID:     10,
+				}))
+			})
+		})
+
+		When("the invoice is paid with the split payments", func() {
+			BeforeEach(func() {
+				unpaidSplitID, failedSplitID, paidSplitID := "split_1", "split_2", "split_3"
+				charges = []*payment.Payment{
+					{ID: "chrg_1", RefID: "10", Success: true, Paid: true},
+					{ID: "chrg_2", RefID: "10", Success: true, Paid: true},
+					{ID: "chrg_3", RefID: "20", Success: true, Paid: true},
+					{ID: "chrg_4", RefID: "20"},
+					{ID: "chrg_5", RefID: "30", Success: true, Paid: true},
+					{ID: "chrg_6", RefID: "30", Success: true, Paid: true},
+				}
+				payments = []datastore.Payment{
+					{ID: 1, ChargeID: "chrg_1", InvoiceID: 10, Status: datastore.SuccessPaymentStatus, SplitID: &unpaidSplitID},
+					{ID: 2, ChargeID: "chrg_2", InvoiceID: 10, Status: datastore.SuccessPaymentStatus, SplitID: &unpaidSplitID},
+					{ID: 3, ChargeID: "chrg_3", InvoiceID: 20, Status: datastore.RefundedPaymentStatus, SplitID: &failedSplitID},
+					{ID: 4, ChargeID: "chrg_4", InvoiceID: 20, Status: datastore.FailedPaymentStatus, SplitID: &failedSplitID},
+					{ID: 5, ChargeID: "chrg_5", InvoiceID: 30, Status: datastore.SuccessPaymentStatus, SplitID: &paidSplitID},
+					{ID: 6, ChargeID: "chrg_6", InvoiceID: 30, Status: datastore.SuccessPaymentStatus, SplitID: &paidSplitID},
+				}
+				expectListCharges(nil)
+				expectListPayments(nil)
+				expectInvoice(10, false)
+				expectInvoice(30, true)
+			})
+			It("should check the invoice once per split and skip the failed split", func() {
+				Expect(err).To(BeNil())
+				Expect(report.Mismatches).To(ConsistOf(job.Mismatch{
+					Type:          job.InvoiceUnpaidMismatch,
+					ChargeID:      "chrg_2",
+					PaymentStatus: datastore.SuccessPaymentStatus,
+					ChargeStatus:  datastore.SuccessPaymentStatus,
+					PaymentID:     2,
+					InvoiceID:     10,
+				}))
+			})
+		})
+	})
+})
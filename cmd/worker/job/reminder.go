@@ -0,0 +1,125 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/sms"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+const (
+	reminderPageSize = 50
+	reminderTitle    = "Appointment reminder"
+)
+
+// ReminderJob reminds patients of their upcoming appointments at each of the offsets before the appointment starts
+type ReminderJob struct {
+	notifier
+	hospitalClient    hospital.SystemClient
+	reminderDataStore datastore.ReminderDataStore
+	smsClient         sms.Client
+	clock             clock.Clock
+	location          *time.Location
+	offsets           []time.Duration
+}
+
+func NewReminderJob(hos hospital.SystemClient, pds datastore.PatientDataStore, nds datastore.NotificationDataStore, rds datastore.ReminderDataStore, noti notification.Client, eventBroker event.Broker, smsClient sms.Client, c clock.Clock, offsets []time.Duration, loc *time.Location, logger *zap.SugaredLogger) *ReminderJob {
+	sorted := make([]time.Duration, len(offsets))
+	copy(sorted, offsets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &ReminderJob{
+		notifier: notifier{
+			patientDataStore:      pds,
+			notificationDataStore: nds,
+			notificationClient:    noti,
+			eventBroker:           eventBroker,
+			logger:                logger,
+		},
+		hospitalClient:    hos,
+		reminderDataStore: rds,
+		smsClient:         smsClient,
+		clock:             c,
+		location:          loc,
+		offsets:           sorted,
+	}
+}
+
+func (j ReminderJob) Name() string {
+	return "reminder"
+}
+
+func (j ReminderJob) Run(ctx context.Context) error {
+	if len(j.offsets) == 0 {
+		return nil
+	}
+	now := j.clock.Now()
+	until := now.Add(j.offsets[len(j.offsets)-1])
+	filters := &hospital.ListAppointmentsFilters{
+		Status:      hospital.AppointmentStatusScheduled,
+		StartAfter:  &now,
+		StartBefore: &until,
+	}
+	for skip := 0; ; skip += reminderPageSize {
+		appointments, err := j.hospitalClient.ListAppointmentsWithFilters(ctx, filters, reminderPageSize, skip)
+		if err != nil {
+			return err
+		}
+		for _, appointment := range appointments {
+			j.remind(ctx, now, appointment)
+		}
+		if len(appointments) < reminderPageSize {
+			return nil
+		}
+	}
+}
+
+// remind sends the reminder of the smallest offset that the appointment is due for.
+// The reminder is claimed before sending, so it is sent at most once even if the job is run by multiple workers.
+func (j ReminderJob) remind(ctx context.Context, now time.Time, appointment *hospital.AppointmentOverview) {
+	offset, ok := j.dueOffset(appointment.StartDateTime.Sub(now))
+	if !ok {
+		return
+	}
+	claimed, err := j.reminderDataStore.Claim(appointment.Id, offset)
+	if err != nil {
+		logError(j.logger, err, "j.reminderDataStore.Claim error", "appointmentID", appointment.Id)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	body := fmt.Sprintf("You have an appointment with %s on %s", appointment.Doctor.FullName, appointment.StartDateTime.In(j.location).Format(notificationTimeLayout))
+	j.notifyPatient(ctx, appointment.Patient.ID, reminderTitle, body, appointment.Id)
+	j.sendSMS(ctx, appointment, body)
+}
+
+func (j ReminderJob) dueOffset(remaining time.Duration) (time.Duration, bool) {
+	for _, offset := range j.offsets {
+		if remaining <= offset {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+func (j ReminderJob) sendSMS(ctx context.Context, appointment *hospital.AppointmentOverview, body string) {
+	patientInfo, err := j.hospitalClient.FindPatientByID(ctx, appointment.Patient.ID)
+	if err != nil {
+		logError(j.logger, err, "j.hospitalClient.FindPatientByID error", "appointmentID", appointment.Id)
+		return
+	}
+	if patientInfo == nil || patientInfo.PhoneNumber == "" {
+		return
+	}
+	if err := j.smsClient.Send(patientInfo.PhoneNumber, body); err != nil {
+		logError(j.logger, err, "j.smsClient.Send error", "appointmentID", appointment.Id)
+	}
+}
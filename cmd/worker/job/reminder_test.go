@@ -0,0 +1,173 @@
+package job_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_event"
+	"github.com/synthia-telemed/backend-api/test/mock_hospital_client"
+	"github.com/synthia-telemed/backend-api/test/mock_notification"
+	"github.com/synthia-telemed/backend-api/test/mock_sms_client"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ = Describe("Reminder Job", func() {
+	var (
+		mockCtrl *gomock.Controller
+		ctx      context.Context
+		j        *job.ReminderJob
+		now      time.Time
+		err      error
+
+		mockHospitalClient        *mock_hospital_client.MockSystemClient
+		mockPatientDataStore      *mock_datastore.MockPatientDataStore
+		mockNotificationDataStore *mock_datastore.MockNotificationDataStore
+		mockReminderDataStore     *mock_datastore.MockReminderDataStore
+		mockNotificationClient    *mock_notification.MockClient
+		mockEventBroker           *mock_event.MockBroker
+		mockSmsClient             *mock_sms_client.MockClient
+		mockClock                 *mock_clock.MockClock
+
+		appointment *hospital.AppointmentOverview
+		patient     *datastore.Patient
+		patientInfo *hospital.Patient
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		ctx = context.Background()
+		mockHospitalClient = mock_hospital_client.NewMockSystemClient(mockCtrl)
+		mockPatientDataStore = mock_datastore.NewMockPatientDataStore(mockCtrl)
+		mockNotificationDataStore = mock_datastore.NewMockNotificationDataStore(mockCtrl)
+		mockReminderDataStore = mock_datastore.NewMockReminderDataStore(mockCtrl)
+		mockNotificationClient = mock_notification.NewMockClient(mockCtrl)
+		mockEventBroker = mock_event.NewMockBroker(mockCtrl)
+		mockSmsClient = mock_sms_client.NewMockClient(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		offsets := []time.Duration{time.Hour, 24 * time.Hour}
+		j = job.NewReminderJob(mockHospitalClient, mockPatientDataStore, mockNotificationDataStore, mockReminderDataStore, mockNotificationClient, mockEventBroker, mockSmsClient, mockClock, offsets, time.UTC, zap.NewNop().Sugar())
+
+		now = time.Date(2022, 10, 10, 9, 0, 0, 0, time.UTC)
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		appointment = testhelper.GenerateAppointmentOverview(hospital.AppointmentStatusScheduled)
+		appointment.StartDateTime = now.Add(30 * time.Minute)
+		patient = testhelper.GeneratePatient()
+		patientInfo = testhelper.GenerateHospitalPatient()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	JustBeforeEach(func() {
+		err = j.Run(ctx)
+	})
+
+	expectListAppointments := func(apps []*hospital.AppointmentOverview, err error) {
+		until := now.Add(24 * time.Hour)
+		filters := &hospital.ListAppointmentsFilters{Status: hospital.AppointmentStatusScheduled, StartAfter: &now, StartBefore: &until}
+		mockHospitalClient.EXPECT().ListAppointmentsWithFilters(ctx, filters, gomock.Any(), 0).Return(apps, err).Times(1)
+	}
+
+	When("list appointments error", func() {
+		BeforeEach(func() {
+			expectListAppointments(nil, testhelper.MockError)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	When("the reminder has already been sent", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			mockReminderDataStore.EXPECT().Claim(appointment.Id, time.Hour).Return(false, nil).Times(1)
+		})
+		It("should not send the reminder again", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("claim reminder error", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			mockReminderDataStore.EXPECT().Claim(appointment.Id, time.Hour).Return(false, testhelper.MockError).Times(1)
+		})
+		It("should skip the appointment", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("the patient has never signed in", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			mockReminderDataStore.EXPECT().Claim(appointment.Id, time.Hour).Return(true, nil).Times(1)
+			mockPatientDataStore.EXPECT().FindByRefID(appointment.Patient.ID).Return(nil, nil).Times(1)
+			mockHospitalClient.EXPECT().FindPatientByID(ctx, appointment.Patient.ID).Return(patientInfo, nil).Times(1)
+			mockSmsClient.EXPECT().Send(patientInfo.PhoneNumber, gomock.Any()).Return(nil).Times(1)
+		})
+		It("should only send the SMS", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("send push notification and publish event error", func() {
+		BeforeEach(func() {
+			expectListAppointments([]*hospital.AppointmentOverview{appointment}, nil)
+			mockReminderDataStore.EXPECT().Claim(appointment.Id, time.Hour).Return(true, nil).Times(1)
+			mockPatientDataStore.EXPECT().FindByRefID(appointment.Patient.ID).Return(patient, nil).Times(1)
+			mockNotificationDataStore.EXPECT().Create(gomock.Any()).Return(nil).Times(1)
+			mockEventBroker.EXPECT().Publish(ctx, patient.ID, event.NotificationType, gomock.Any()).Return(testhelper.MockError).Times(1)
+			mockNotificationClient.EXPECT().Send(ctx, gomock.Any(), gomock.Any()).Return(testhelper.MockError).Times(1)
+			mockHospitalClient.EXPECT().FindPatientByID(ctx, appointment.Patient.ID).Return(patientInfo, nil).Times(1)
+			mockSmsClient.EXPECT().Send(patientInfo.PhoneNumber, gomock.Any()).Return(nil).Times(1)
+		})
+		It("should still send the SMS", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("no error occurred", func() {
+		var farAppointment *hospital.AppointmentOverview
+		BeforeEach(func() {
+			farAppointment = testhelper.GenerateAppointmentOverview(hospital.AppointmentStatusScheduled)
+			farAppointment.StartDateTime = now.Add(20 * time.Hour)
+			farPatientInfo := testhelper.GenerateHospitalPatient()
+			expectListAppointments([]*hospital.AppointmentOverview{appointment, farAppointment}, nil)
+
+			mockReminderDataStore.EXPECT().Claim(appointment.Id, time.Hour).Return(true, nil).Times(1)
+			mockPatientDataStore.EXPECT().FindByRefID(appointment.Patient.ID).Return(patient, nil).Times(1)
+			mockNotificationDataStore.EXPECT().Create(gomock.Any()).Do(func(noti *datastore.Notification) {
+				Expect(noti.PatientID).To(Equal(patient.ID))
+				Expect(noti.Body).To(ContainSubstring(appointment.Doctor.FullName))
+			}).Return(nil).Times(1)
+			mockEventBroker.EXPECT().Publish(ctx, patient.ID, event.NotificationType, gomock.Any()).Return(nil).Times(1)
+			mockNotificationDataStore.EXPECT().CountUnRead(patient.ID).Return(1, nil).Times(1)
+			mockEventBroker.EXPECT().Publish(ctx, patient.ID, event.UnreadCountType, &event.UnreadCount{Count: 1}).Return(nil).Times(1)
+			mockNotificationClient.EXPECT().Send(ctx, gomock.Any(), map[string]string{"appointmentID": appointment.Id}).Do(func(_ context.Context, params notification.SendParams, _ map[string]string) {
+				Expect(params.ID).To(Equal(fmt.Sprintf("%d", patient.ID)))
+			}).Return(nil).Times(1)
+			mockHospitalClient.EXPECT().FindPatientByID(ctx, appointment.Patient.ID).Return(patientInfo, nil).Times(1)
+			mockSmsClient.EXPECT().Send(patientInfo.PhoneNumber, gomock.Any()).Return(nil).Times(1)
+
+			mockReminderDataStore.EXPECT().Claim(farAppointment.Id, 24*time.Hour).Return(true, nil).Times(1)
+			mockPatientDataStore.EXPECT().FindByRefID(farAppointment.Patient.ID).Return(nil, nil).Times(1)
+			mockHospitalClient.EXPECT().FindPatientByID(ctx, farAppointment.Patient.ID).Return(farPatientInfo, nil).Times(1)
+			mockSmsClient.EXPECT().Send(farPatientInfo.PhoneNumber, gomock.Any()).Return(nil).Times(1)
+		})
+		It("should send the reminder of the smallest due offset", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+})
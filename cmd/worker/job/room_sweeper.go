@@ -0,0 +1,114 @@
+package job
+
+import (
+	"context"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// RoomSweeperJob closes the rooms that haven't received heartbeat within the timeout, e.g. the doctor's client crashed
+type RoomSweeperJob struct {
+	cacheClient          cache.Client
+	roomClosureDataStore datastore.RoomClosureDataStore
+	clock                clock.Clock
+	logger               *zap.SugaredLogger
+	heartbeatTimeout     time.Duration
+}
+
+func NewRoomSweeperJob(cacheClient cache.Client, rcds datastore.RoomClosureDataStore, c clock.Clock, heartbeatTimeout time.Duration, logger *zap.SugaredLogger) *RoomSweeperJob {
+	return &RoomSweeperJob{
+		cacheClient:          cacheClient,
+		roomClosureDataStore: rcds,
+		clock:                c,
+		logger:               logger,
+		heartbeatTimeout:     heartbeatTimeout,
+	}
+}
+
+func (j RoomSweeperJob) Name() string {
+	return "room-sweeper"
+}
+
+func (j RoomSweeperJob) Run(ctx context.Context) error {
+	staleBefore := j.clock.Now().Add(-j.heartbeatTimeout)
+	roomIDs, err := j.cacheClient.SortedSetRangeByMaxScore(ctx, cache.ActiveRoomsKey, float64(staleBefore.Unix()))
+	if err != nil {
+		return err
+	}
+	for _, roomID := range roomIDs {
+		if err := j.closeRoom(ctx, roomID); err != nil {
+			logError(j.logger, err, "j.closeRoom error", "roomID", roomID)
+		}
+	}
+	return nil
+}
+
+// closeRoom removes the room from the active rooms, records the closure and deletes its keys.
+// Removing the room first makes sure that only one worker closes the room.
+// The closure is recorded before the keys are deleted, so the no-show job can tell that the appointment was started.
+func (j RoomSweeperJob) closeRoom(ctx context.Context, roomID string) error {
+	lastHeartbeat, err := lastRoomHeartbeat(ctx, j.cacheClient, roomID)
+	if err != nil {
+		return err
+	}
+	removed, err := j.cacheClient.SortedSetRemove(ctx, cache.ActiveRoomsKey, roomID)
+	if err != nil || removed == 0 {
+		return err
+	}
+	appointmentID, err := j.cacheClient.HashGet(ctx, cache.RoomInfoKey(roomID), "AppointmentID")
+	if err != nil {
+		return err
+	}
+	doctorIDStr, err := j.cacheClient.HashGet(ctx, cache.RoomInfoKey(roomID), "DoctorID")
+	if err != nil {
+		return err
+	}
+	doctorID, _ := strconv.ParseUint(doctorIDStr, 10, 64)
+
+	// Only delete the keys that still point to this room, the doctor might already start a new one
+	keys := []string{cache.RoomInfoKey(roomID)}
+	if appointmentID != "" {
+		appointmentRoomID, err := j.cacheClient.Get(ctx, cache.AppointmentRoomIDKey(appointmentID), false)
+		if err != nil {
+			return err
+		}
+		if appointmentRoomID == roomID {
+			keys = append(keys, cache.AppointmentRoomIDKey(appointmentID))
+		}
+	}
+	if doctorID != 0 {
+		currentAppointmentID, err := j.cacheClient.Get(ctx, cache.CurrentDoctorAppointmentIDKey(uint(doctorID)), false)
+		if err != nil {
+			return err
+		}
+		if currentAppointmentID != "" && currentAppointmentID == appointmentID {
+			keys = append(keys, cache.CurrentDoctorAppointmentIDKey(uint(doctorID)))
+		}
+	}
+
+	closure := &datastore.RoomClosure{
+		RoomID:        roomID,
+		AppointmentID: appointmentID,
+		DoctorID:      uint(doctorID),
+		Reason:        datastore.OrphanedRoomClosureReason,
+		LastHeartbeat: lastHeartbeat,
+	}
+	if err := j.roomClosureDataStore.Create(closure); err != nil {
+		return err
+	}
+	return j.cacheClient.Delete(ctx, keys...)
+}
+
+// lastRoomHeartbeat returns the time of the room's last heartbeat from the active rooms score, or nil if the room isn't active
+func lastRoomHeartbeat(ctx context.Context, cacheClient cache.Client, roomID string) (*time.Time, error) {
+	score, found, err := cacheClient.SortedSetScore(ctx, cache.ActiveRoomsKey, roomID)
+	if err != nil || !found {
+		return nil, err
+	}
+	heartbeat := time.Unix(int64(score), 0)
+	return &heartbeat, nil
+}
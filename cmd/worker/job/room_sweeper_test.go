@@ -0,0 +1,153 @@
+package job_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ = Describe("Room Sweeper Job", func() {
+	var (
+		mockCtrl *gomock.Controller
+		ctx      context.Context
+		j        *job.RoomSweeperJob
+		now      time.Time
+		err      error
+
+		mockCacheClient          *mock_cache_client.MockClient
+		mockRoomClosureDataStore *mock_datastore.MockRoomClosureDataStore
+		mockClock                *mock_clock.MockClock
+
+		heartbeatTimeout time.Duration
+		roomID           string
+		appointmentID    string
+		doctorID         uint
+		lastHeartbeat    time.Time
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		ctx = context.Background()
+		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
+		mockRoomClosureDataStore = mock_datastore.NewMockRoomClosureDataStore(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		heartbeatTimeout = 2 * time.Minute
+		j = job.NewRoomSweeperJob(mockCacheClient, mockRoomClosureDataStore, mockClock, heartbeatTimeout, zap.NewNop().Sugar())
+
+		now = time.Now()
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		roomID = uuid.NewString()
+		appointmentID = uuid.NewString()
+		doctorID = 12
+		lastHeartbeat = time.Unix(now.Add(-heartbeatTimeout).Unix()-30, 0)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	JustBeforeEach(func() {
+		err = j.Run(ctx)
+	})
+
+	expectStaleRooms := func(roomIDs []string, err error) {
+		mockCacheClient.EXPECT().SortedSetRangeByMaxScore(ctx, cache.ActiveRoomsKey, float64(now.Add(-heartbeatTimeout).Unix())).Return(roomIDs, err).Times(1)
+	}
+	expectLastHeartbeat := func() {
+		mockCacheClient.EXPECT().SortedSetScore(ctx, cache.ActiveRoomsKey, roomID).Return(float64(lastHeartbeat.Unix()), true, nil).Times(1)
+	}
+	expectRoomInfo := func() {
+		mockCacheClient.EXPECT().HashGet(ctx, cache.RoomInfoKey(roomID), "AppointmentID").Return(appointmentID, nil).Times(1)
+		mockCacheClient.EXPECT().HashGet(ctx, cache.RoomInfoKey(roomID), "DoctorID").Return(fmt.Sprintf("%d", doctorID), nil).Times(1)
+	}
+
+	When("list stale rooms error", func() {
+		BeforeEach(func() {
+			expectStaleRooms(nil, testhelper.MockError)
+		})
+		It("should return error", func() {
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	When("get last heartbeat of the room error", func() {
+		BeforeEach(func() {
+			expectStaleRooms([]string{roomID}, nil)
+			mockCacheClient.EXPECT().SortedSetScore(ctx, cache.ActiveRoomsKey, roomID).Return(0.0, false, testhelper.MockError).Times(1)
+		})
+		It("should skip the room", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("the room is already closed by another worker", func() {
+		BeforeEach(func() {
+			expectStaleRooms([]string{roomID}, nil)
+			expectLastHeartbeat()
+			mockCacheClient.EXPECT().SortedSetRemove(ctx, cache.ActiveRoomsKey, roomID).Return(0, nil).Times(1)
+		})
+		It("should skip the room", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("the doctor has already started another room", func() {
+		BeforeEach(func() {
+			expectStaleRooms([]string{roomID}, nil)
+			expectLastHeartbeat()
+			mockCacheClient.EXPECT().SortedSetRemove(ctx, cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
+			expectRoomInfo()
+			mockCacheClient.EXPECT().Get(ctx, cache.AppointmentRoomIDKey(appointmentID), false).Return(uuid.NewString(), nil).Times(1)
+			mockCacheClient.EXPECT().Get(ctx, cache.CurrentDoctorAppointmentIDKey(doctorID), false).Return(uuid.NewString(), nil).Times(1)
+			mockCacheClient.EXPECT().Delete(ctx, cache.RoomInfoKey(roomID)).Return(nil).Times(1)
+			mockRoomClosureDataStore.EXPECT().Create(gomock.Any()).Return(nil).Times(1)
+		})
+		It("should only delete the room information", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("record the closure error", func() {
+		BeforeEach(func() {
+			expectStaleRooms([]string{roomID}, nil)
+			expectLastHeartbeat()
+			mockCacheClient.EXPECT().SortedSetRemove(ctx, cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
+			expectRoomInfo()
+			mockCacheClient.EXPECT().Get(ctx, cache.AppointmentRoomIDKey(appointmentID), false).Return(roomID, nil).Times(1)
+			mockCacheClient.EXPECT().Get(ctx, cache.CurrentDoctorAppointmentIDKey(doctorID), false).Return(appointmentID, nil).Times(1)
+			mockRoomClosureDataStore.EXPECT().Create(gomock.Any()).Return(testhelper.MockError).Times(1)
+		})
+		It("should not delete the room keys", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+
+	When("no error occurred", func() {
+		BeforeEach(func() {
+			expectStaleRooms([]string{roomID}, nil)
+			expectLastHeartbeat()
+			mockCacheClient.EXPECT().SortedSetRemove(ctx, cache.ActiveRoomsKey, roomID).Return(1, nil).Times(1)
+			expectRoomInfo()
+			mockCacheClient.EXPECT().Get(ctx, cache.AppointmentRoomIDKey(appointmentID), false).Return(roomID, nil).Times(1)
+			mockCacheClient.EXPECT().Get(ctx, cache.CurrentDoctorAppointmentIDKey(doctorID), false).Return(appointmentID, nil).Times(1)
+			mockCacheClient.EXPECT().Delete(ctx, cache.RoomInfoKey(roomID), cache.AppointmentRoomIDKey(appointmentID), cache.CurrentDoctorAppointmentIDKey(doctorID)).Return(nil).Times(1)
+			closure := &datastore.RoomClosure{RoomID: roomID, AppointmentID: appointmentID, DoctorID: doctorID, Reason: datastore.OrphanedRoomClosureReason, LastHeartbeat: &lastHeartbeat}
+			mockRoomClosureDataStore.EXPECT().Create(closure).Return(nil).Times(1)
+		})
+		It("should delete the room keys and record the closure with the last heartbeat", func() {
+			Expect(err).To(BeNil())
+		})
+	})
+})
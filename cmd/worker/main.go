@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"github.com/getsentry/sentry-go"
+	"github.com/synthia-telemed/backend-api/cmd/worker/job"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/config"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	"github.com/synthia-telemed/backend-api/pkg/hospital"
+	"github.com/synthia-telemed/backend-api/pkg/logger"
+	"github.com/synthia-telemed/backend-api/pkg/notification"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/server"
+	"github.com/synthia-telemed/backend-api/pkg/sms"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln("Failed to parse ENV:", err)
+	}
+
+	zapLogger, err := logger.NewZapLogger(cfg.Mode == "development")
+	if err != nil {
+		log.Fatalln("Failed to initialized Zap:", err)
+	}
+	defer zapLogger.Sync()
+	sugaredLogger := zapLogger.Sugar()
+
+	if err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.SentryDSN,
+		TracesSampleRate: 1.0,
+	}); err != nil {
+		sugaredLogger.Fatalw("Sentry initialization failed", "error", err)
+	}
+	defer sentry.Flush(2 * time.Second)
+
+	db, err := gorm.Open(postgres.Open(cfg.DB.DSN()), &gorm.Config{})
+	server.AssertFatalError(sugaredLogger, err, "Failed to connect to database")
+
+	patientDataStore, err := datastore.NewGormPatientDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create patient data store")
+	doctorDataStore, err := datastore.NewGormDoctorDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create doctor data store")
+	appointmentDataStore, err := datastore.NewGormAppointmentDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create appointment data store")
+	notificationDataStore, err := datastore.NewGormNotificationDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create notification data store")
+	reminderDataStore, err := datastore.NewGormReminderDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create reminder data store")
+	roomClosureDataStore, err := datastore.NewGormRoomClosureDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create room closure data store")
+	creditCardDataStore, err := datastore.NewGormCreditCardDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create credit card data store")
+	paymentDataStore, err := datastore.NewGormPaymentDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create payment data store")
+	paidInvoiceOutboxDataStore, err := datastore.NewGormPaidInvoiceOutboxDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create paid invoice outbox data store")
+	autopayDataStore, err := datastore.NewGormAutopayDataStore(db)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create autopay data store")
+	location, err := time.LoadLocation(cfg.ScheduleTimezone)
+	server.AssertFatalError(sugaredLogger, err, "Failed to load schedule timezone")
+
+	hospitalSysClient := hospital.NewGraphQLClient(&cfg.HospitalClient)
+	smsClient := sms.NewTwilioClient(&cfg.SMS)
+	cacheClient := cache.NewRedisClient(&cfg.Cache)
+	realClock := clock.NewRealClock()
+	eventBroker := event.NewCacheBroker(cacheClient)
+	notificationClient, err := notification.NewRabbitMQNotificationClient(&cfg.Notification)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create rabbitmq notification client")
+	paymentClient, err := payment.NewOmisePaymentClient(&cfg.Payment)
+	server.AssertFatalError(sugaredLogger, err, "Failed to create payment client")
+
+	// Jobs
+	reminderJob := job.NewReminderJob(hospitalSysClient, patientDataStore, notificationDataStore, reminderDataStore, notificationClient, eventBroker, smsClient, realClock, cfg.ReminderOffsets, location, sugaredLogger)
+	noShowJob := job.NewNoShowJob(hospitalSysClient, appointmentDataStore, roomClosureDataStore, patientDataStore, doctorDataStore, notificationDataStore, cacheClient, notificationClient, eventBroker, realClock, cfg.NoShowGracePeriod, location, sugaredLogger)
+	autopayJob := job.NewAutopayJob(hospitalSysClient, paymentClient, patientDataStore, creditCardDataStore, paymentDataStore, paidInvoiceOutboxDataStore, autopayDataStore, notificationDataStore, notificationClient, eventBroker, cacheClient, realClock, cfg.AutopayInvoiceWait, cfg.PaymentLockTTL, sugaredLogger)
+	cardExpiryJob := job.NewCardExpiryJob(creditCardDataStore, notificationDataStore, notificationClient, eventBroker, realClock, cfg.CardExpiryNoticePeriod, location, sugaredLogger)
+
+	jobs := []job.Job{reminderJob, noShowJob, cardExpiryJob, autopayJob}
+	// The sweeper closes every room without heartbeat, so it's enabled only when the doctor clients send the heartbeat
+	if cfg.RoomSweeperEnabled {
+		jobs = append(jobs, job.NewRoomSweeperJob(cacheClient, roomClosureDataStore, realClock, cfg.RoomHeartbeatTimeout, sugaredLogger))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	sugaredLogger.Infow("Starting worker", "interval", cfg.WorkerInterval, "roomSweeperEnabled", cfg.RoomSweeperEnabled)
+	job.NewRunner(cfg.WorkerInterval, sugaredLogger, jobs...).Start(ctx)
+	sugaredLogger.Info("Worker exiting")
+	server.AssertFatalError(sugaredLogger, notificationClient.Close(), "Failed to close rabbitmq connection")
+}
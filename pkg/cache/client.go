@@ -8,9 +8,19 @@ import (
 type Client interface {
 	Get(ctx context.Context, key string, getAndDelete bool) (string, error)
 	Set(ctx context.Context, key string, value string, expiredIn time.Duration) error
+	SetIfNotExists(ctx context.Context, key string, value string, expiredIn time.Duration) (bool, error)
+	DeleteIfEquals(ctx context.Context, key string, value string) (bool, error)
 	Delete(ctx context.Context, keys ...string) error
+	Increment(ctx context.Context, key string, expiredIn time.Duration) (int, time.Duration, error)
 	MultipleGet(ctx context.Context, keys ...string) ([]string, error)
 	MultipleSet(ctx context.Context, kv map[string]string) error
 	HashSet(ctx context.Context, key string, kv map[string]string) error
 	HashGet(ctx context.Context, key, field string) (string, error)
+	Expire(ctx context.Context, expiredIn time.Duration, keys ...string) error
+	SortedSetAdd(ctx context.Context, key, member string, score float64) error
+	SortedSetRangeByMaxScore(ctx context.Context, key string, max float64) ([]string, error)
+	SortedSetScore(ctx context.Context, key, member string) (float64, bool, error)
+	SortedSetRemove(ctx context.Context, key string, members ...string) (int, error)
+	Publish(ctx context.Context, channel, message string) error
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
 }
@@ -2,6 +2,9 @@ package cache
 
 import "fmt"
 
+// ActiveRoomsKey is the sorted set of opened room IDs scored by the unix time of their last heartbeat
+const ActiveRoomsKey = "rooms:active"
+
 func CurrentDoctorAppointmentIDKey(doctorID uint) string {
 	return fmt.Sprintf("doctor:%d:appointment_id", doctorID)
 }
@@ -13,3 +16,37 @@ func AppointmentRoomIDKey(appointmentID string) string {
 func RoomInfoKey(roomID string) string {
 	return fmt.Sprintf("room:%s", roomID)
 }
+
+func WaitingRoomPresenceKey(appointmentID string) string {
+	return fmt.Sprintf("appointment:%s:waiting_room", appointmentID)
+}
+
+func PatientEventChannel(patientID uint) string {
+	return fmt.Sprintf("patient:%d:events", patientID)
+}
+
+func InvoicePaymentLockKey(invoiceID int) string {
+	return fmt.Sprintf("invoice:%d:payment_lock", invoiceID)
+}
+
+func IdempotencyKey(patientID uint, key string) string {
+	return fmt.Sprintf("patient:%d:idempotency:%s", patientID, key)
+}
+
+// SigninSessionKey is the pending signin which is completed by verifying the OTP sent to the patient
+func SigninSessionKey(signinID string) string {
+	return fmt.Sprintf("signin:%s", signinID)
+}
+
+func SigninAttemptsKey(signinID string) string {
+	return fmt.Sprintf("signin:%s:attempts", signinID)
+}
+
+// SigninRateLimitKey counts the signins of the subject, e.g. credential, phone number or IP, which is hashed to keep it out of the cache
+func SigninRateLimitKey(subject, hash string) string {
+	return fmt.Sprintf("signin_rate_limit:%s:%s", subject, hash)
+}
+
+func SigninResendCooldownKey(signinID string) string {
+	return fmt.Sprintf("signin:%s:resend_cooldown", signinID)
+}
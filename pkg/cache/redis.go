@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"github.com/go-redis/redis/v8"
+	"strconv"
 	"time"
 )
 
@@ -53,6 +54,26 @@ func (c RedisClient) Set(ctx context.Context, key string, value string, expiredI
 	return c.client.Set(ctx, key, value, expiredIn).Err()
 }
 
+// SetIfNotExists sets the value only if the key doesn't exist and reports whether it is set
+func (c RedisClient) SetIfNotExists(ctx context.Context, key string, value string, expiredIn time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, expiredIn).Result()
+}
+
+// deleteIfEqualsScript deletes the key atomically only if its value is still the expected one
+var deleteIfEqualsScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// DeleteIfEquals deletes the key only if its value equals to the given value and reports whether it is deleted,
+// e.g. releasing the lock only if it's still held by the same owner
+func (c RedisClient) DeleteIfEquals(ctx context.Context, key string, value string) (bool, error) {
+	deleted, err := deleteIfEqualsScript.Run(ctx, c.client, []string{key}, value).Int()
+	return deleted == 1, err
+}
+
 func (c RedisClient) MultipleSet(ctx context.Context, kv map[string]string) error {
 	return c.client.MSet(ctx, kv).Err()
 }
@@ -91,3 +112,101 @@ func (c RedisClient) HashGet(ctx context.Context, key, field string) (string, er
 func (c RedisClient) Delete(ctx context.Context, keys ...string) error {
 	return c.client.Del(ctx, keys...).Err()
 }
+
+// incrementScript increments the counter and starts its expiry only when it's created, so the window isn't extended by the later increments
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// Increment increments the counter of the key and returns the count with the remaining time until the counter expires,
+// e.g. counting the requests within the fixed window of the rate limit
+func (c RedisClient) Increment(ctx context.Context, key string, expiredIn time.Duration) (int, time.Duration, error) {
+	res, err := incrementScript.Run(ctx, c.client, []string{key}, expiredIn.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(res[0]), time.Duration(res[1]) * time.Millisecond, nil
+}
+
+func (c RedisClient) Expire(ctx context.Context, expiredIn time.Duration, keys ...string) error {
+	pipe := c.client.TxPipeline()
+	for _, key := range keys {
+		pipe.Expire(ctx, key, expiredIn)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c RedisClient) SortedSetAdd(ctx context.Context, key, member string, score float64) error {
+	return c.client.ZAdd(ctx, key, &redis.Z{Score: score, Member: member}).Err()
+}
+
+func (c RedisClient) SortedSetRangeByMaxScore(ctx context.Context, key string, max float64) ([]string, error) {
+	return c.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatFloat(max, 'f', -1, 64),
+	}).Result()
+}
+
+// SortedSetScore returns the score of the member and reports whether the member is in the sorted set
+func (c RedisClient) SortedSetScore(ctx context.Context, key, member string) (float64, bool, error) {
+	score, err := c.client.ZScore(ctx, key, member).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return score, true, nil
+}
+
+// SortedSetRemove removes the members from the sorted set and returns number of the removed members
+func (c RedisClient) SortedSetRemove(ctx context.Context, key string, members ...string) (int, error) {
+	values := make([]interface{}, len(members))
+	for i, m := range members {
+		values[i] = m
+	}
+	removed, err := c.client.ZRem(ctx, key, values...).Result()
+	return int(removed), err
+}
+
+func (c RedisClient) Publish(ctx context.Context, channel, message string) error {
+	return c.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe subscribes to the channel and returns the channel of the received messages.
+// The subscription is closed and the returned channel is closed when the context is done.
+func (c RedisClient) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	pubsub := c.client.Subscribe(ctx, channel)
+	// Wait for the subscription to be confirmed, so the messages published afterward are not missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+	messages := make(chan string)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
@@ -82,6 +82,65 @@ var _ = Describe("Cache Suite", func() {
 		})
 	})
 
+	Context("SetIfNotExists", func() {
+		var key string
+		BeforeEach(func() {
+			key = uuid.NewString()
+		})
+
+		It("set the value only if the key doesn't exist", func() {
+			set, err := client.SetIfNotExists(ctx, key, "first", time.Minute)
+			Expect(err).To(BeNil())
+			Expect(set).To(BeTrue())
+			set, err = client.SetIfNotExists(ctx, key, "second", time.Minute)
+			Expect(err).To(BeNil())
+			Expect(set).To(BeFalse())
+			Expect(redisClient.Get(ctx, key).Val()).To(Equal("first"))
+		})
+	})
+
+	Context("DeleteIfEquals", func() {
+		var key string
+		BeforeEach(func() {
+			key = uuid.NewString()
+			Expect(redisClient.Set(ctx, key, "owner", 0).Err()).To(Succeed())
+		})
+
+		It("not delete the key when the value is different", func() {
+			deleted, err := client.DeleteIfEquals(ctx, key, "another-owner")
+			Expect(err).To(BeNil())
+			Expect(deleted).To(BeFalse())
+			Expect(redisClient.Exists(ctx, key).Val()).To(Equal(int64(1)))
+		})
+
+		It("delete the key when the value equals", func() {
+			deleted, err := client.DeleteIfEquals(ctx, key, "owner")
+			Expect(err).To(BeNil())
+			Expect(deleted).To(BeTrue())
+			Expect(redisClient.Exists(ctx, key).Val()).To(Equal(int64(0)))
+		})
+	})
+
+	Context("Increment", func() {
+		var key string
+		BeforeEach(func() {
+			key = uuid.NewString()
+		})
+
+		It("increment the counter and keep the expiry of the first increment", func() {
+			count, ttl, err := client.Increment(ctx, key, time.Minute)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(1))
+			Expect(ttl).To(BeNumerically("~", time.Minute, time.Second))
+			Expect(redisClient.Expire(ctx, key, 30*time.Second).Err()).To(Succeed())
+
+			count, ttl, err = client.Increment(ctx, key, time.Minute)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(2))
+			Expect(ttl).To(BeNumerically("~", 30*time.Second, time.Second))
+		})
+	})
+
 	Context("MultipleSet", func() {
 		It("set multiple key-value", func() {
 			kv := map[string]string{
@@ -177,4 +236,87 @@ var _ = Describe("Cache Suite", func() {
 			Expect(client.Delete(ctx, uuid.NewString())).To(Succeed())
 		})
 	})
+
+	Context("Expire", func() {
+		It("set expiration time of multiple keys", func() {
+			keys := []string{uuid.NewString(), uuid.NewString()}
+			Expect(redisClient.MSet(ctx, keys[0], uuid.NewString(), keys[1], uuid.NewString()).Err()).To(Succeed())
+			du := time.Millisecond * 10
+			Expect(client.Expire(ctx, du, keys...)).To(Succeed())
+			time.Sleep(du * 10)
+			count, err := redisClient.Exists(ctx, keys...).Result()
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(int64(0)))
+		})
+	})
+
+	Context("Sorted Set", func() {
+		var key string
+		BeforeEach(func() {
+			key = uuid.NewString()
+			Expect(client.SortedSetAdd(ctx, key, "m1", 10)).To(Succeed())
+			Expect(client.SortedSetAdd(ctx, key, "m2", 20)).To(Succeed())
+			Expect(client.SortedSetAdd(ctx, key, "m3", 30)).To(Succeed())
+		})
+
+		It("get members with score not greater than max score in ascending order", func() {
+			members, err := client.SortedSetRangeByMaxScore(ctx, key, 20)
+			Expect(err).To(BeNil())
+			Expect(members).To(Equal([]string{"m1", "m2"}))
+		})
+
+		It("update score of existing member", func() {
+			Expect(client.SortedSetAdd(ctx, key, "m1", 40)).To(Succeed())
+			members, err := client.SortedSetRangeByMaxScore(ctx, key, 20)
+			Expect(err).To(BeNil())
+			Expect(members).To(Equal([]string{"m2"}))
+		})
+
+		It("remove members", func() {
+			removed, err := client.SortedSetRemove(ctx, key, "m1", "m3", "m4")
+			Expect(err).To(BeNil())
+			Expect(removed).To(Equal(2))
+			members, err := client.SortedSetRangeByMaxScore(ctx, key, 100)
+			Expect(err).To(BeNil())
+			Expect(members).To(Equal([]string{"m2"}))
+		})
+
+		It("get score of the member", func() {
+			score, found, err := client.SortedSetScore(ctx, key, "m2")
+			Expect(err).To(BeNil())
+			Expect(found).To(BeTrue())
+			Expect(score).To(Equal(20.0))
+		})
+
+		It("report the member isn't found", func() {
+			score, found, err := client.SortedSetScore(ctx, key, "m4")
+			Expect(err).To(BeNil())
+			Expect(found).To(BeFalse())
+			Expect(score).To(BeZero())
+		})
+	})
+
+	Context("Publish and Subscribe", func() {
+		var channel string
+		BeforeEach(func() {
+			channel = uuid.NewString()
+		})
+
+		It("receive the message published after subscribed", func() {
+			subCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			messages, err := client.Subscribe(subCtx, channel)
+			Expect(err).To(BeNil())
+			Expect(client.Publish(ctx, channel, "hello")).To(Succeed())
+			Eventually(messages).Should(Receive(Equal("hello")))
+		})
+
+		It("close the channel when the context is done", func() {
+			subCtx, cancel := context.WithCancel(ctx)
+			messages, err := client.Subscribe(subCtx, channel)
+			Expect(err).To(BeNil())
+			cancel()
+			Eventually(messages).Should(BeClosed())
+		})
+	})
 })
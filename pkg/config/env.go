@@ -8,23 +8,60 @@ import (
 	"github.com/synthia-telemed/backend-api/pkg/hospital"
 	"github.com/synthia-telemed/backend-api/pkg/notification"
 	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
+	"github.com/synthia-telemed/backend-api/pkg/receipt"
 	"github.com/synthia-telemed/backend-api/pkg/sms"
 	"github.com/synthia-telemed/backend-api/pkg/token"
+	"time"
 )
 
 type Config struct {
-	DB             datastore.Config
-	SMS            sms.Config
-	Payment        payment.Config
-	HospitalClient hospital.Config
-	GinMode        string `env:"GIN_MODE" envDefault:"debug"`
-	SentryDSN      string `env:"SENTRY_DSN" envDefault:""`
-	Mode           string `env:"MODE" envDefault:"development"`
-	Token          token.Config
-	DatabaseDSN    string
-	Cache          cache.Config
-	Port           int `env:"PORT" envDefault:"8080"`
-	Notification   notification.Config
+	DB                      datastore.Config
+	SMS                     sms.Config
+	Email                   sms.EmailConfig
+	LINE                    sms.LINEConfig
+	MessageRouter           sms.RouterConfig
+	Payment                 payment.Config
+	HospitalClient          hospital.Config
+	GinMode                 string `env:"GIN_MODE" envDefault:"debug"`
+	SentryDSN               string `env:"SENTRY_DSN" envDefault:""`
+	Mode                    string `env:"MODE" envDefault:"development"`
+	Token                   token.Config
+	TokenVerifier           token.VerifierConfig
+	AuthMode                string        `env:"AUTH_MODE" envDefault:"header"`
+	SessionCheckCacheTTL    time.Duration `env:"SESSION_CHECK_CACHE_TTL" envDefault:"10s"`
+	DatabaseDSN             string
+	Cache                   cache.Config
+	Port                    int      `env:"PORT" envDefault:"8080"`
+	TrustedProxies          []string `env:"TRUSTED_PROXIES"`
+	Notification            notification.Config
+	Presence                presence.Config
+	Receipt                 receipt.Config
+	AppointmentChangeCutoff time.Duration   `env:"APPOINTMENT_CHANGE_CUTOFF" envDefault:"24h"`
+	ScheduleTimezone        string          `env:"SCHEDULE_TIMEZONE" envDefault:"Asia/Bangkok"`
+	WorkerInterval          time.Duration   `env:"WORKER_INTERVAL" envDefault:"1m"`
+	ReminderOffsets         []time.Duration `env:"REMINDER_OFFSETS" envDefault:"24h,1h"`
+	NoShowGracePeriod       time.Duration   `env:"NO_SHOW_GRACE_PERIOD" envDefault:"3h"`
+	RoomTTL                 time.Duration   `env:"ROOM_TTL" envDefault:"10m"`
+	RoomHeartbeatTimeout    time.Duration   `env:"ROOM_HEARTBEAT_TIMEOUT" envDefault:"2m"`
+	RoomSweeperEnabled      bool            `env:"ROOM_SWEEPER_ENABLED" envDefault:"false"`
+	PaymentLockTTL          time.Duration   `env:"PAYMENT_LOCK_TTL" envDefault:"1m"`
+	IdempotencyKeyTTL       time.Duration   `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	ReconciliationWindow    time.Duration   `env:"RECONCILIATION_WINDOW" envDefault:"72h"`
+	CardExpiryNoticePeriod  time.Duration   `env:"CARD_EXPIRY_NOTICE_PERIOD" envDefault:"720h"`
+	AutopayInvoiceWait      time.Duration   `env:"AUTOPAY_INVOICE_WAIT" envDefault:"72h"`
+	OTPTTL                  time.Duration   `env:"OTP_TTL" envDefault:"10m"`
+	OTPMaxAttempts          int             `env:"OTP_MAX_ATTEMPTS" envDefault:"5"`
+	OTPResendCooldown       time.Duration   `env:"OTP_RESEND_COOLDOWN" envDefault:"1m"`
+	SigninRateLimit         SigninRateLimit
+}
+
+// SigninRateLimit limits the signins, which send the OTP by SMS, of each credential, phone number and IP within the window
+type SigninRateLimit struct {
+	Window        time.Duration `env:"SIGNIN_RATE_LIMIT_WINDOW" envDefault:"1h"`
+	PerCredential int           `env:"SIGNIN_RATE_LIMIT_PER_CREDENTIAL" envDefault:"5"`
+	PerPhone      int           `env:"SIGNIN_RATE_LIMIT_PER_PHONE" envDefault:"5"`
+	PerIP         int           `env:"SIGNIN_RATE_LIMIT_PER_IP" envDefault:"20"`
 }
 
 func Load() (*Config, error) {
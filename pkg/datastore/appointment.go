@@ -6,19 +6,28 @@ import (
 	"time"
 )
 
+type AppointmentOutcome string
+
+const (
+	CompletedAppointmentOutcome AppointmentOutcome = "COMPLETED"
+	NoShowAppointmentOutcome    AppointmentOutcome = "NO_SHOW"
+)
+
 type Appointment struct {
-	StartedTime time.Time      `json:"started_time"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
-	RefID       string         `json:"ref_id" gorm:"unique"`
-	Duration    float64        `json:"duration"`
-	ID          uint           `json:"id" gorm:"autoIncrement,primaryKey"`
+	StartedTime time.Time          `json:"started_time"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt     `json:"-" gorm:"index"`
+	RefID       string             `json:"ref_id" gorm:"unique"`
+	Outcome     AppointmentOutcome `json:"outcome" gorm:"default:COMPLETED;not null"`
+	Duration    float64            `json:"duration"`
+	ID          uint               `json:"id" gorm:"autoIncrement,primaryKey"`
 }
 
 type AppointmentDataStore interface {
 	Create(appointment *Appointment) error
 	FindByRefID(refID string) (*Appointment, error)
+	ListRefIDsByOutcome(refIDs []string, outcome AppointmentOutcome) ([]string, error)
 }
 
 type GormAppointmentDataStore struct {
@@ -44,3 +53,12 @@ func (g GormAppointmentDataStore) FindByRefID(refID string) (*Appointment, error
 	}
 	return &appointment, nil
 }
+
+func (g GormAppointmentDataStore) ListRefIDsByOutcome(refIDs []string, outcome AppointmentOutcome) ([]string, error) {
+	matched := make([]string, 0)
+	if len(refIDs) == 0 {
+		return matched, nil
+	}
+	tx := g.db.Model(&Appointment{}).Where("ref_id IN ? AND outcome = ?", refIDs, outcome).Pluck("ref_id", &matched)
+	return matched, tx.Error
+}
@@ -79,4 +79,23 @@ var _ = Describe("Appointment Datastore", Ordered, func() {
 			})
 		})
 	})
+
+	Context("ListRefIDsByOutcome", func() {
+		var completed, noShow datastore.Appointment
+		BeforeEach(func() {
+			completed = datastore.Appointment{RefID: uuid.NewString(), Outcome: datastore.CompletedAppointmentOutcome}
+			noShow = datastore.Appointment{RefID: uuid.NewString(), Outcome: datastore.NoShowAppointmentOutcome}
+			Expect(db.Create(&[]datastore.Appointment{completed, noShow}).Error).To(Succeed())
+		})
+		It("should return only ref IDs of the appointments with the outcome", func() {
+			refIDs, err := appointmentDataStore.ListRefIDsByOutcome([]string{completed.RefID, noShow.RefID, uuid.NewString()}, datastore.NoShowAppointmentOutcome)
+			Expect(err).To(BeNil())
+			Expect(refIDs).To(Equal([]string{noShow.RefID}))
+		})
+		It("should return empty list when no ref ID is given", func() {
+			refIDs, err := appointmentDataStore.ListRefIDsByOutcome(nil, datastore.NoShowAppointmentOutcome)
+			Expect(err).To(BeNil())
+			Expect(refIDs).To(BeEmpty())
+		})
+	})
 })
@@ -0,0 +1,70 @@
+package datastore
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type AutopayResult string
+
+const (
+	// ChargedAutopayResult is the invoice is paid, or the charge is pending for the patient to authorize it
+	ChargedAutopayResult AutopayResult = "charged"
+	// FailedAutopayResult is the charge isn't successful, so the patient has to pay the invoice manually
+	FailedAutopayResult AutopayResult = "failed"
+	// SkippedAutopayResult is the patient doesn't opt in to autopay, or the invoice is already paid
+	SkippedAutopayResult AutopayResult = "skipped"
+	// ExpiredAutopayResult is the invoice isn't issued in time, so the patient has to pay the invoice manually
+	ExpiredAutopayResult AutopayResult = "expired"
+)
+
+// AutopayRequest is the completed appointment whose invoice is charged with the patient's default card once it's issued
+type AutopayRequest struct {
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	DoneAt        *time.Time    `json:"done_at" gorm:"index"`
+	PaymentID     *uint         `json:"payment_id"`
+	AppointmentID string        `json:"appointment_id" gorm:"uniqueIndex;not null"`
+	Result        AutopayResult `json:"result"`
+	ID            uint          `json:"id" gorm:"autoIncrement,primaryKey"`
+}
+
+type AutopayDataStore interface {
+	// Create requests the autopay of the appointment. The request of the same appointment is ignored
+	Create(appointmentID string) error
+	// ListPending returns the requests that are not done yet from oldest to latest.
+	// The requests are listed after the given request to page through them, or from the oldest one if it's nil
+	ListPending(after *AutopayRequest, limit int) ([]AutopayRequest, error)
+	MarkDone(id uint, result AutopayResult, paymentID *uint, doneAt time.Time) error
+}
+
+type GormAutopayDataStore struct {
+	db *gorm.DB
+}
+
+func NewGormAutopayDataStore(db *gorm.DB) (AutopayDataStore, error) {
+	return &GormAutopayDataStore{db: db}, db.AutoMigrate(&AutopayRequest{})
+}
+
+func (g GormAutopayDataStore) Create(appointmentID string) error {
+	return g.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&AutopayRequest{AppointmentID: appointmentID}).Error
+}
+
+func (g GormAutopayDataStore) ListPending(after *AutopayRequest, limit int) ([]AutopayRequest, error) {
+	var requests []AutopayRequest
+	tx := g.db.Where("done_at IS NULL")
+	if after != nil {
+		tx = tx.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+	if err := tx.Order("created_at, id").Limit(limit).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (g GormAutopayDataStore) MarkDone(id uint, result AutopayResult, paymentID *uint, doneAt time.Time) error {
+	return g.db.Model(&AutopayRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"done_at": doneAt, "result": result, "payment_id": paymentID}).Error
+}
@@ -0,0 +1,92 @@
+package datastore_test
+
+import (
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"math/rand"
+	"time"
+)
+
+var _ = Describe("Autopay Datastore", Ordered, func() {
+	var (
+		db               *gorm.DB
+		autopayDataStore datastore.AutopayDataStore
+		requests         []datastore.AutopayRequest
+	)
+
+	BeforeAll(func() {
+		var err error
+		db, err = gorm.Open(pg.Open(postgres.Config.DSN()), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+	})
+
+	BeforeEach(func() {
+		var err error
+		autopayDataStore, err = datastore.NewGormAutopayDataStore(db)
+		Expect(err).To(BeNil())
+
+		now := time.Now()
+		requests = []datastore.AutopayRequest{
+			{AppointmentID: uuid.NewString(), CreatedAt: now.Add(-time.Hour)},
+			{AppointmentID: uuid.NewString(), CreatedAt: now.Add(-2 * time.Hour)},
+			{AppointmentID: uuid.NewString(), CreatedAt: now, DoneAt: &now, Result: datastore.SkippedAutopayResult},
+		}
+		Expect(db.Create(&requests).Error).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(db.Migrator().DropTable(&datastore.AutopayRequest{})).To(Succeed())
+	})
+
+	Context("Create", func() {
+		It("should create the pending request", func() {
+			appointmentID := uuid.NewString()
+			Expect(autopayDataStore.Create(appointmentID)).To(Succeed())
+			assertRecord(db, &datastore.AutopayRequest{AppointmentID: appointmentID})
+		})
+		It("should ignore the request of the same appointment", func() {
+			Expect(autopayDataStore.Create(requests[2].AppointmentID)).To(Succeed())
+			var r datastore.AutopayRequest
+			Expect(db.Where(&datastore.AutopayRequest{AppointmentID: requests[2].AppointmentID}).First(&r).Error).To(Succeed())
+			Expect(r.DoneAt).ToNot(BeNil())
+		})
+	})
+
+	Context("ListPending", func() {
+		It("should return the pending requests from oldest to latest", func() {
+			pending, err := autopayDataStore.ListPending(nil, 10)
+			Expect(err).To(BeNil())
+			Expect(pending).To(HaveLen(2))
+			Expect(pending[0].AppointmentID).To(Equal(requests[1].AppointmentID))
+			Expect(pending[1].AppointmentID).To(Equal(requests[0].AppointmentID))
+		})
+		It("should return the pending requests after the given request", func() {
+			pending, err := autopayDataStore.ListPending(nil, 1)
+			Expect(err).To(BeNil())
+			Expect(pending).To(HaveLen(1))
+			next, err := autopayDataStore.ListPending(&pending[0], 1)
+			Expect(err).To(BeNil())
+			Expect(next).To(HaveLen(1))
+			Expect(next[0].AppointmentID).To(Equal(requests[0].AppointmentID))
+		})
+	})
+
+	Context("MarkDone", func() {
+		It("should mark the request as done with the result", func() {
+			paymentID := uint(rand.Uint32())
+			Expect(autopayDataStore.MarkDone(requests[0].ID, datastore.ChargedAutopayResult, &paymentID, time.Now())).To(Succeed())
+			var r datastore.AutopayRequest
+			Expect(db.First(&r, requests[0].ID).Error).To(Succeed())
+			Expect(r.DoneAt).ToNot(BeNil())
+			Expect(r.Result).To(Equal(datastore.ChargedAutopayResult))
+			Expect(*r.PaymentID).To(Equal(paymentID))
+		})
+	})
+})
@@ -7,18 +7,30 @@ import (
 )
 
 type CreditCard struct {
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
-	Last4Digits string         `json:"last_4_digits"`
-	Brand       string         `json:"brand"`
-	Name        string         `json:"name"`
-	CardID      string         `json:"-"`
-	Payments    []Payment      `json:"-" gorm:"foreignKey:CreditCardID"`
-	ID          uint           `json:"id" gorm:"autoIncrement,primaryKey"`
-	PatientID   uint           `json:"patient_id" gorm:"not null"`
-	IsDefault   bool           `json:"is_default"`
-	Expiry      string         `json:"expiry"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	// ExpiryNotifiedAt is the time that the patient is notified that the default card is about to expire
+	ExpiryNotifiedAt *time.Time `json:"-"`
+	Last4Digits      string     `json:"last_4_digits"`
+	Brand            string     `json:"brand"`
+	Name             string     `json:"name"`
+	CardID           string     `json:"-"`
+	Payments         []Payment  `json:"-" gorm:"foreignKey:CreditCardID"`
+	ID               uint       `json:"id" gorm:"autoIncrement,primaryKey"`
+	PatientID        uint       `json:"patient_id" gorm:"not null"`
+	ExpiryMonth      int        `json:"expiry_month"`
+	ExpiryYear       int        `json:"expiry_year"`
+	IsDefault        bool       `json:"is_default"`
+}
+
+// ExpiresAt returns the time that the card can't be charged anymore, i.e. the beginning of the month after the expiry month
+func (c CreditCard) ExpiresAt(loc *time.Location) time.Time {
+	return time.Date(c.ExpiryYear, time.Month(c.ExpiryMonth)+1, 1, 0, 0, 0, 0, loc)
+}
+
+func (c CreditCard) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt(now.Location()))
 }
 
 type CreditCardDataStore interface {
@@ -30,6 +42,10 @@ type CreditCardDataStore interface {
 	SetAllToNonDefault(patientID uint) error
 	SetIsDefault(cardID uint, isDefault bool) error
 	Delete(id uint) error
+	// ListExpiringDefault lists the default cards which expire after from but not later than to, and the patient isn't notified yet
+	ListExpiringDefault(from, to time.Time) ([]CreditCard, error)
+	// ClaimExpiryNotification reports whether the caller is the first one to notify the patient about the card expiry
+	ClaimExpiryNotification(cardID uint, now time.Time) (bool, error)
 }
 
 type GormCreditCardDataStore struct {
@@ -37,7 +53,24 @@ type GormCreditCardDataStore struct {
 }
 
 func NewGormCreditCardDataStore(db *gorm.DB) (CreditCardDataStore, error) {
-	return &GormCreditCardDataStore{db: db}, db.AutoMigrate(&CreditCard{})
+	if err := db.AutoMigrate(&CreditCard{}); err != nil {
+		return nil, err
+	}
+	return &GormCreditCardDataStore{db: db}, migrateCreditCardExpiry(db)
+}
+
+// migrateCreditCardExpiry moves the legacy "M/YYYY" expiry string into the expiry month and year columns
+func migrateCreditCardExpiry(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&CreditCard{}, "expiry") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE credit_cards SET expiry_month = split_part(expiry, '/', 1)::int, expiry_year = split_part(expiry, '/', 2)::int WHERE expiry LIKE '%/%'`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&CreditCard{}, "expiry")
+	})
 }
 
 func (g GormCreditCardDataStore) Create(card *CreditCard) error {
@@ -93,3 +126,23 @@ func (g GormCreditCardDataStore) SetAllToNonDefault(patientID uint) error {
 func (g GormCreditCardDataStore) SetIsDefault(cardID uint, isDefault bool) error {
 	return g.db.Model(&CreditCard{}).Where(&CreditCard{ID: cardID}).Update("is_default", isDefault).Error
 }
+
+func (g GormCreditCardDataStore) ListExpiringDefault(from, to time.Time) ([]CreditCard, error) {
+	var cards []CreditCard
+	// The card expires at the end of the expiry month, so it expires in the range if the expiry month is from the month of from
+	// to the month before the month of to
+	tx := g.db.Where(&CreditCard{IsDefault: true}).
+		Where("expiry_notified_at IS NULL").
+		Where("expiry_year * 12 + expiry_month BETWEEN ? AND ?", monthIndex(from), monthIndex(to)-1).
+		Find(&cards)
+	return cards, tx.Error
+}
+
+func (g GormCreditCardDataStore) ClaimExpiryNotification(cardID uint, now time.Time) (bool, error) {
+	tx := g.db.Model(&CreditCard{}).Where("id = ? AND expiry_notified_at IS NULL", cardID).Update("expiry_notified_at", now)
+	return tx.RowsAffected == 1, tx.Error
+}
+
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month())
+}
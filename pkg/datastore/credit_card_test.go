@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"math/rand"
+	"time"
 )
 
 var _ = Describe("Credit Card Datastore", Ordered, func() {
@@ -155,6 +156,58 @@ var _ = Describe("Credit Card Datastore", Ordered, func() {
 		})
 	})
 
+	Context("ListExpiringDefault", func() {
+		var (
+			from, to     time.Time
+			expiringCard *datastore.CreditCard
+		)
+		createCard := func(isDefault bool, month, year int) *datastore.CreditCard {
+			c := generateCreditCard(patient.ID, isDefault)
+			c.ExpiryMonth, c.ExpiryYear = month, year
+			Expect(db.Create(c).Error).To(Succeed())
+			return c
+		}
+		BeforeEach(func() {
+			from = time.Date(2022, 10, 18, 9, 0, 0, 0, time.UTC)
+			to = from.Add(30 * 24 * time.Hour)
+			expiringCard = createCard(true, 10, 2022)
+			createCard(false, 10, 2022)
+			createCard(true, 9, 2022)
+			createCard(true, 11, 2022)
+			notifiedCard := createCard(true, 10, 2022)
+			Expect(db.Model(notifiedCard).Update("expiry_notified_at", from).Error).To(Succeed())
+		})
+		It("should return only the default cards which expire in the range and aren't notified", func() {
+			cards, err := creditCardDataStore.ListExpiringDefault(from, to)
+			Expect(err).To(BeNil())
+			Expect(cards).To(HaveLen(1))
+			Expect(cards[0].ID).To(Equal(expiringCard.ID))
+		})
+	})
+
+	Context("ClaimExpiryNotification", func() {
+		It("should claim the notification only once", func() {
+			now := time.Now()
+			claimed, err := creditCardDataStore.ClaimExpiryNotification(card.ID, now)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeTrue())
+			claimed, err = creditCardDataStore.ClaimExpiryNotification(card.ID, now)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeFalse())
+			var retrievedCard datastore.CreditCard
+			Expect(db.First(&retrievedCard, card.ID).Error).To(Succeed())
+			Expect(retrievedCard.ExpiryNotifiedAt).ToNot(BeNil())
+		})
+	})
+
+	DescribeTable("IsExpired", func(now time.Time, expired bool) {
+		c := datastore.CreditCard{ExpiryMonth: 12, ExpiryYear: 2022}
+		Expect(c.IsExpired(now)).To(Equal(expired))
+	},
+		Entry("Before the expiry month", time.Date(2022, 11, 30, 0, 0, 0, 0, time.UTC), false),
+		Entry("At the end of the expiry month", time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC), false),
+		Entry("After the expiry month", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), true))
+
 	DescribeTable("SetIsDefault", func(initialIsDefault bool) {
 		card := generateCreditCard(patient.ID, initialIsDefault)
 		Expect(db.Create(card).Error).To(BeNil())
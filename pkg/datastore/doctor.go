@@ -12,11 +12,16 @@ type Doctor struct {
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 	RefID     string         `json:"refID" gorm:"unique"`
 	ID        uint           `json:"id" gorm:"autoIncrement,primaryKey"`
+	// NotificationToken is the device token of the doctor, which is sent along with the push notification on the doctor channel
+	NotificationToken string `json:"-"`
 }
 
 type DoctorDataStore interface {
 	FindOrCreate(doctor *Doctor) error
 	FindByID(id uint) (*Doctor, error)
+	FindByRefID(refID string) (*Doctor, error)
+	// SetNotificationToken sets the device token of the doctor. The empty token stops the push notification
+	SetNotificationToken(id uint, token string) error
 }
 
 type GormDoctorDataStore struct {
@@ -41,3 +46,18 @@ func (g GormDoctorDataStore) FindByID(id uint) (*Doctor, error) {
 	}
 	return &doc, nil
 }
+
+func (g GormDoctorDataStore) FindByRefID(refID string) (*Doctor, error) {
+	var doc Doctor
+	if err := g.db.First(&doc, "ref_id = ?", refID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (g GormDoctorDataStore) SetNotificationToken(id uint, token string) error {
+	return g.db.Model(&Doctor{}).Where("id = ?", id).Update("notification_token", token).Error
+}
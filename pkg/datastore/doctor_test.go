@@ -89,4 +89,35 @@ var _ = Describe("Doctor Datastore", Ordered, func() {
 			})
 		})
 	})
+
+	Context("FindByRefID", func() {
+		When("doctor is found", func() {
+			It("should return doctor with no error", func() {
+				d := doctors[5]
+				foundDoc, err := doctorDataStore.FindByRefID(d.RefID)
+				Expect(err).To(BeNil())
+				Expect(foundDoc.ID).To(Equal(d.ID))
+				Expect(foundDoc.RefID).To(Equal(d.RefID))
+			})
+		})
+		When("doctor is not found", func() {
+			It("should return nil with no error", func() {
+				foundDoc, err := doctorDataStore.FindByRefID(uuid.NewString())
+				Expect(err).To(BeNil())
+				Expect(foundDoc).To(BeNil())
+			})
+		})
+	})
+
+	Context("SetNotificationToken", func() {
+		It("should set the device token of the doctor only", func() {
+			d := doctors[2]
+			Expect(doctorDataStore.SetNotificationToken(d.ID, "device-token")).To(Succeed())
+			var found datastore.Doctor
+			Expect(db.First(&found, d.ID).Error).To(Succeed())
+			Expect(found.NotificationToken).To(Equal("device-token"))
+			Expect(db.First(&found, doctors[3].ID).Error).To(Succeed())
+			Expect(found.NotificationToken).To(BeEmpty())
+		})
+	})
 })
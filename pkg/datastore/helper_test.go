@@ -54,7 +54,8 @@ func generateCreditCard(patientID uint, isDefault bool) *datastore.CreditCard {
 		CardID:      uuid.New().String(),
 		Name:        "test_card",
 		IsDefault:   isDefault,
-		Expiry:      fmt.Sprintf("12/%d", time.Now().Year()),
+		ExpiryMonth: 12,
+		ExpiryYear:  time.Now().Year(),
 	}
 }
 
@@ -105,3 +106,30 @@ func generatePatientWithNotifications(n int) ([]*datastore.Patient, []int) {
 	}
 	return patients, readCounts
 }
+
+func generateScheduleSlot(doctorID uint, weekday time.Weekday, start, end string) *datastore.ScheduleSlot {
+	return &datastore.ScheduleSlot{
+		DoctorID:  doctorID,
+		Weekday:   weekday,
+		StartTime: start,
+		EndTime:   end,
+	}
+}
+
+func generateScheduleException(doctorID uint, date time.Time, start, end string, isAvailable bool) *datastore.ScheduleException {
+	return &datastore.ScheduleException{
+		DoctorID:    doctorID,
+		Date:        date,
+		StartTime:   start,
+		EndTime:     end,
+		IsAvailable: isAvailable,
+	}
+}
+
+func generateLeaveDay(doctorID uint, date time.Time) *datastore.LeaveDay {
+	return &datastore.LeaveDay{
+		DoctorID: doctorID,
+		Date:     date,
+		Reason:   uuid.NewString(),
+	}
+}
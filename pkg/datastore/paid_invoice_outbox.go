@@ -0,0 +1,54 @@
+package datastore
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// PaidInvoiceOutbox is the pending update of marking the invoice as paid in the hospital system.
+// It's created in the same transaction as the successful payment, so the update is retried until it's done
+type PaidInvoiceOutbox struct {
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DoneAt    *time.Time `json:"done_at" gorm:"index"`
+	LastError string     `json:"last_error"`
+	ID        uint       `json:"id" gorm:"autoIncrement,primaryKey"`
+	PaymentID uint       `json:"payment_id" gorm:"uniqueIndex;not null"`
+	InvoiceID int        `json:"invoice_id" gorm:"not null"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+}
+
+type PaidInvoiceOutboxDataStore interface {
+	// ListPending returns the updates that are not done yet from oldest to latest
+	ListPending(limit int) ([]PaidInvoiceOutbox, error)
+	MarkDone(paymentID uint, doneAt time.Time) error
+	RecordFailure(paymentID uint, errMsg string) error
+}
+
+type GormPaidInvoiceOutboxDataStore struct {
+	db *gorm.DB
+}
+
+func NewGormPaidInvoiceOutboxDataStore(db *gorm.DB) (PaidInvoiceOutboxDataStore, error) {
+	return &GormPaidInvoiceOutboxDataStore{db: db}, db.AutoMigrate(&PaidInvoiceOutbox{})
+}
+
+func (g GormPaidInvoiceOutboxDataStore) ListPending(limit int) ([]PaidInvoiceOutbox, error) {
+	var outbox []PaidInvoiceOutbox
+	if err := g.db.Where("done_at IS NULL").Order("created_at").Limit(limit).Find(&outbox).Error; err != nil {
+		return nil, err
+	}
+	return outbox, nil
+}
+
+func (g GormPaidInvoiceOutboxDataStore) MarkDone(paymentID uint, doneAt time.Time) error {
+	return g.db.Model(&PaidInvoiceOutbox{}).
+		Where("payment_id = ?", paymentID).
+		Updates(map[string]interface{}{"done_at": doneAt, "attempts": gorm.Expr("attempts + 1")}).Error
+}
+
+func (g GormPaidInvoiceOutboxDataStore) RecordFailure(paymentID uint, errMsg string) error {
+	return g.db.Model(&PaidInvoiceOutbox{}).
+		Where("payment_id = ?", paymentID).
+		Updates(map[string]interface{}{"last_error": errMsg, "attempts": gorm.Expr("attempts + 1")}).Error
+}
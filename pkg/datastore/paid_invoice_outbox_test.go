@@ -0,0 +1,77 @@
+package datastore_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"math/rand"
+	"time"
+)
+
+var _ = Describe("Paid Invoice Outbox Datastore", Ordered, func() {
+	var (
+		db              *gorm.DB
+		outboxDataStore datastore.PaidInvoiceOutboxDataStore
+		outbox          []datastore.PaidInvoiceOutbox
+	)
+
+	BeforeAll(func() {
+		var err error
+		db, err = gorm.Open(pg.Open(postgres.Config.DSN()), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+	})
+
+	BeforeEach(func() {
+		var err error
+		outboxDataStore, err = datastore.NewGormPaidInvoiceOutboxDataStore(db)
+		Expect(err).To(BeNil())
+
+		now := time.Now()
+		outbox = []datastore.PaidInvoiceOutbox{
+			{PaymentID: uint(rand.Uint32()), InvoiceID: int(rand.Int31()), CreatedAt: now.Add(-time.Hour)},
+			{PaymentID: uint(rand.Uint32()), InvoiceID: int(rand.Int31()), CreatedAt: now.Add(-2 * time.Hour)},
+			{PaymentID: uint(rand.Uint32()), InvoiceID: int(rand.Int31()), CreatedAt: now, DoneAt: &now},
+		}
+		Expect(db.Create(&outbox).Error).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(db.Migrator().DropTable(&datastore.PaidInvoiceOutbox{})).To(Succeed())
+	})
+
+	Context("ListPending", func() {
+		It("should return the pending updates from oldest to latest", func() {
+			pending, err := outboxDataStore.ListPending(10)
+			Expect(err).To(BeNil())
+			Expect(pending).To(HaveLen(2))
+			Expect(pending[0].PaymentID).To(Equal(outbox[1].PaymentID))
+			Expect(pending[1].PaymentID).To(Equal(outbox[0].PaymentID))
+		})
+	})
+
+	Context("MarkDone", func() {
+		It("should mark the update as done", func() {
+			Expect(outboxDataStore.MarkDone(outbox[0].PaymentID, time.Now())).To(Succeed())
+			var o datastore.PaidInvoiceOutbox
+			Expect(db.First(&o, outbox[0].ID).Error).To(Succeed())
+			Expect(o.DoneAt).ToNot(BeNil())
+			Expect(o.Attempts).To(Equal(1))
+		})
+	})
+
+	Context("RecordFailure", func() {
+		It("should record the error and keep the update pending", func() {
+			Expect(outboxDataStore.RecordFailure(outbox[0].PaymentID, "hospital is down")).To(Succeed())
+			var o datastore.PaidInvoiceOutbox
+			Expect(db.First(&o, outbox[0].ID).Error).To(Succeed())
+			Expect(o.DoneAt).To(BeNil())
+			Expect(o.LastError).To(Equal("hospital is down"))
+			Expect(o.Attempts).To(Equal(1))
+		})
+	})
+})
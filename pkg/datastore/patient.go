@@ -18,6 +18,12 @@ type Patient struct {
 	ID                uint           `json:"id" gorm:"autoIncrement,primaryKey"`
 	Notification      []Notification `gorm:"foreignKey:PatientID"`
 	NotificationToken string         `json:"-"`
+	// Autopay is whether the invoice of the completed appointment is charged with the default card automatically
+	Autopay bool `json:"autopay" gorm:"not null;default:false"`
+	// OTPChannel is the preferred channel to receive the OTP. The OTP is sent by SMS if it's empty
+	OTPChannel string `json:"otp_channel"`
+	Email      string `json:"email"`
+	LINEUserID string `json:"line_user_id"`
 }
 
 type PatientDataStore interface {
@@ -11,29 +11,88 @@ type PaymentStatus string
 
 const (
 	CreditCardPaymentMethod PaymentMethod = "credit_card"
+	PromptPayPaymentMethod  PaymentMethod = "promptpay"
 	SuccessPaymentStatus    PaymentStatus = "success"
 	FailedPaymentStatus     PaymentStatus = "failed"
 	PendingPaymentStatus    PaymentStatus = "pending"
+	// RefundedPaymentStatus is the successful part of the failed split payment which is fully refunded
+	RefundedPaymentStatus PaymentStatus = "refunded"
 )
 
 type Payment struct {
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	PaidAt       *time.Time     `json:"paid_at"`
-	CreditCard   *CreditCard    `json:"credit_card"`
-	CreditCardID *uint          `json:"credit_card_id"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
-	ChargeID     string         `json:"-" gorm:"not null"`
-	Status       PaymentStatus  `json:"status" gorm:"not null"`
-	Method       PaymentMethod  `json:"method" gorm:"not null"`
-	ID           uint           `json:"id" gorm:"autoIncrement,primaryKey"`
-	InvoiceID    int            `json:"invoice_id" gorm:"not null,unique"`
-	Amount       float64        `json:"amount" gorm:"not null"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+	PaidAt       *time.Time  `json:"paid_at"`
+	ExpiresAt    *time.Time  `json:"expires_at,omitempty"`
+	CreditCard   *CreditCard `json:"credit_card"`
+	CreditCardID *uint       `json:"credit_card_id"`
+	// SplitID groups the parts of the split payment, which pays the invoice with several credit cards
+	SplitID   *string        `json:"split_id,omitempty" gorm:"index"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ChargeID  string         `json:"-" gorm:"not null"`
+	Status    PaymentStatus  `json:"status" gorm:"not null"`
+	Method    PaymentMethod  `json:"method" gorm:"not null"`
+	ID        uint           `json:"id" gorm:"autoIncrement,primaryKey"`
+	InvoiceID int            `json:"invoice_id" gorm:"not null;index"`
+	PatientID uint           `json:"patient_id" gorm:"index"`
+	Amount    float64        `json:"amount" gorm:"not null"`
+	// RefundedAmount is the total amount of the refunds of the payment
+	RefundedAmount float64 `json:"refunded_amount" gorm:"not null;default:0"`
+}
+
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case CreditCardPaymentMethod, PromptPayPaymentMethod:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case SuccessPaymentStatus, FailedPaymentStatus, PendingPaymentStatus, RefundedPaymentStatus:
+		return true
+	default:
+		return false
+	}
+}
+
+// PaymentFilters selects the payments with the status and method, and that are created within [StartDate, EndDate).
+// The empty filter isn't applied
+type PaymentFilters struct {
+	StartDate *time.Time    `json:"start_date" form:"start_date"`
+	EndDate   *time.Time    `json:"end_date" form:"end_date"`
+	Status    PaymentStatus `json:"status" form:"status" binding:"omitempty,enum" enums:"success,failed,pending,refunded"`
+	Method    PaymentMethod `json:"method" form:"method" binding:"omitempty,enum" enums:"credit_card,promptpay"`
 }
 
 type PaymentDataStore interface {
+	// Create creates the payment. If the payment is successful, the outbox of marking the invoice as paid is created in the same transaction.
+	// The part of the split payment doesn't pay the invoice on its own, so its outbox is created with the last part by CreateLastSplitPart
 	Create(payment *Payment) error
+	// CreateLastSplitPart creates the last part of the split payment. If it's successful, all parts are successful,
+	// so the outbox of marking the invoice as paid is created in the same transaction
+	CreateLastSplitPart(payment *Payment) error
 	FindLatestByInvoiceIDAndStatus(invoiceID int, status PaymentStatus) (*Payment, error)
+	FindByID(id uint) (*Payment, error)
+	FindByChargeID(chargeID string) (*Payment, error)
+	FindLatestByInvoiceID(invoiceID int) (*Payment, error)
+	// SettlePending updates the status and paid time of the pending payment and reports whether it was still pending.
+	// Only the first settlement of the payment is applied, so the redelivered webhook events are ignored.
+	// If the payment is settled as successful, the outbox of marking the invoice as paid is created in the same transaction unless it's the part of the split payment
+	SettlePending(id uint, status PaymentStatus, paidAt *time.Time) (bool, error)
+	ListCreatedBetween(from, to time.Time) ([]Payment, error)
+	// ListByPatientID lists the payments of the patient with the filters from the latest one
+	ListByPatientID(patientID uint, filters *PaymentFilters, take, skip int) ([]Payment, error)
+	CountByPatientID(patientID uint, filters *PaymentFilters) (int, error)
+	// ReserveRefund adds the refund amount to the refunded amount of the successful payment and creates the refund as pending, then reports whether it is reserved.
+	// It isn't reserved if the refunded amount would exceed the payment amount, so concurrent refunds can't over-refund the payment.
+	ReserveRefund(refund *Refund) (bool, error)
+	// ReleaseRefund subtracts the reserved amount from the refunded amount and deletes the pending refund when the refund fails
+	ReleaseRefund(refund *Refund) error
+	// MarkRefunded marks the fully refunded part of the split payment as refunded, so it isn't taken as the payment of the invoice
+	MarkRefunded(id uint) error
 }
 
 type GormPaymentDataStore struct {
@@ -41,16 +100,45 @@ type GormPaymentDataStore struct {
 }
 
 func NewGormPaymentDataStore(db *gorm.DB) (PaymentDataStore, error) {
-	return &GormPaymentDataStore{db: db}, db.AutoMigrate(&Payment{})
+	if err := db.AutoMigrate(&Payment{}, &PaidInvoiceOutbox{}, &Refund{}); err != nil {
+		return nil, err
+	}
+	return &GormPaymentDataStore{db: db}, dropInvoiceUniqueConstraint(db)
+}
+
+// dropInvoiceUniqueConstraint allows the invoice to have several payments, e.g. the parts of the split payment and the retries of the failed payment
+func dropInvoiceUniqueConstraint(db *gorm.DB) error {
+	const constraint = "payments_invoice_id_key"
+	if !db.Migrator().HasConstraint(&Payment{}, constraint) {
+		return nil
+	}
+	return db.Migrator().DropConstraint(&Payment{}, constraint)
 }
 
 func (g GormPaymentDataStore) Create(payment *Payment) error {
-	return g.db.Create(payment).Error
+	return g.create(payment, payment.SplitID == nil)
+}
+
+func (g GormPaymentDataStore) CreateLastSplitPart(payment *Payment) error {
+	return g.create(payment, true)
+}
+
+// create creates the payment and the outbox of the invoice if the payment is successful and pays the invoice
+func (g GormPaymentDataStore) create(payment *Payment, paysInvoice bool) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		if payment.Status != SuccessPaymentStatus || !paysInvoice {
+			return nil
+		}
+		return tx.Create(&PaidInvoiceOutbox{PaymentID: payment.ID, InvoiceID: payment.InvoiceID}).Error
+	})
 }
 
 func (g GormPaymentDataStore) FindLatestByInvoiceIDAndStatus(invoiceID int, status PaymentStatus) (*Payment, error) {
 	var payment Payment
-	tx := g.db.Preload("CreditCard", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Where(&Payment{InvoiceID: invoiceID, Status: status}).Order("created_at").First(&payment)
+	tx := g.db.Preload("CreditCard", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Where(&Payment{InvoiceID: invoiceID, Status: status}).Order("created_at desc").First(&payment)
 	if tx.Error != nil {
 		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -59,3 +147,139 @@ func (g GormPaymentDataStore) FindLatestByInvoiceIDAndStatus(invoiceID int, stat
 	}
 	return &payment, nil
 }
+
+func (g GormPaymentDataStore) FindByID(id uint) (*Payment, error) {
+	var payment Payment
+	if err := g.db.Preload("CreditCard", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).First(&payment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (g GormPaymentDataStore) FindByChargeID(chargeID string) (*Payment, error) {
+	var payment Payment
+	if err := g.db.Where(&Payment{ChargeID: chargeID}).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (g GormPaymentDataStore) FindLatestByInvoiceID(invoiceID int) (*Payment, error) {
+	var payment Payment
+	if err := g.db.Where(&Payment{InvoiceID: invoiceID}).Order("created_at desc").First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (g GormPaymentDataStore) SettlePending(id uint, status PaymentStatus, paidAt *time.Time) (bool, error) {
+	settled := false
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		update := tx.Model(&Payment{}).
+			Where("id = ? AND status = ?", id, PendingPaymentStatus).
+			Updates(map[string]interface{}{"status": status, "paid_at": paidAt})
+		if update.Error != nil || update.RowsAffected != 1 {
+			return update.Error
+		}
+		settled = true
+		if status != SuccessPaymentStatus {
+			return nil
+		}
+		var payment Payment
+		if err := tx.Select("invoice_id", "split_id").First(&payment, id).Error; err != nil {
+			return err
+		}
+		if payment.SplitID != nil {
+			return nil
+		}
+		return tx.Create(&PaidInvoiceOutbox{PaymentID: id, InvoiceID: payment.InvoiceID}).Error
+	})
+	return settled && err == nil, err
+}
+
+func (g GormPaymentDataStore) ListCreatedBetween(from, to time.Time) ([]Payment, error) {
+	var payments []Payment
+	if err := g.db.Where("created_at BETWEEN ? AND ?", from, to).Order("created_at").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (g GormPaymentDataStore) ListByPatientID(patientID uint, filters *PaymentFilters, take, skip int) ([]Payment, error) {
+	var payments []Payment
+	tx := g.filterByPatientID(patientID, filters).
+		Preload("CreditCard", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order("created_at desc").
+		Limit(take).
+		Offset(skip).
+		Find(&payments)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return payments, nil
+}
+
+func (g GormPaymentDataStore) CountByPatientID(patientID uint, filters *PaymentFilters) (int, error) {
+	var count int64
+	if err := g.filterByPatientID(patientID, filters).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (g GormPaymentDataStore) filterByPatientID(patientID uint, filters *PaymentFilters) *gorm.DB {
+	tx := g.db.Model(&Payment{}).Where(&Payment{PatientID: patientID, Status: filters.Status, Method: filters.Method})
+	if filters.StartDate != nil {
+		tx = tx.Where("created_at >= ?", *filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		tx = tx.Where("created_at < ?", *filters.EndDate)
+	}
+	return tx
+}
+
+// refundTolerance absorbs the floating point error of the amounts in baht which have at most 2 decimal places
+const refundTolerance = 0.001
+
+func (g GormPaymentDataStore) ReserveRefund(refund *Refund) (bool, error) {
+	reserved := false
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Payment{}).
+			Where("id = ? AND status = ? AND refunded_amount + ? <= amount + ?", refund.PaymentID, SuccessPaymentStatus, refund.Amount, refundTolerance).
+			Update("refunded_amount", gorm.Expr("refunded_amount + ?", refund.Amount))
+		if res.Error != nil || res.RowsAffected != 1 {
+			return res.Error
+		}
+		refund.Status = PendingRefundStatus
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+func (g GormPaymentDataStore) ReleaseRefund(refund *Refund) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Payment{}).Where("id = ?", refund.PaymentID).Update("refunded_amount", gorm.Expr("refunded_amount - ?", refund.Amount)).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Refund{}, refund.ID).Error
+	})
+}
+
+func (g GormPaymentDataStore) MarkRefunded(id uint) error {
+	return g.db.Model(&Payment{}).
+		Where("id = ? AND status = ? AND split_id IS NOT NULL AND refunded_amount + ? >= amount", id, SuccessPaymentStatus, refundTolerance).
+		Update("status", RefundedPaymentStatus).Error
+}
@@ -1,6 +1,7 @@
 package datastore_test
 
 import (
+	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/synthia-telemed/backend-api/pkg/datastore"
@@ -8,6 +9,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"math/rand"
+	"time"
 )
 
 var _ = Describe("Payment Datastore", Ordered, func() {
@@ -41,7 +43,7 @@ var _ = Describe("Payment Datastore", Ordered, func() {
 	})
 
 	AfterEach(func() {
-		Expect(db.Migrator().DropTable(&datastore.Patient{}, &datastore.CreditCard{}, &datastore.Payment{})).To(Succeed())
+		Expect(db.Migrator().DropTable(&datastore.Refund{}, &datastore.Patient{}, &datastore.CreditCard{}, &datastore.Payment{}, &datastore.PaidInvoiceOutbox{})).To(Succeed())
 	})
 
 	Context("Create payment", func() {
@@ -51,16 +53,53 @@ var _ = Describe("Payment Datastore", Ordered, func() {
 				Expect(paymentDataStore.Create(p)).To(Succeed())
 				Expect(p.ID).ToNot(BeZero())
 				Expect(p.CreatedAt).ToNot(BeZero())
+				var outboxCount int64
+				Expect(db.Model(&datastore.PaidInvoiceOutbox{}).Where("payment_id = ?", p.ID).Count(&outboxCount).Error).To(Succeed())
 				if status == datastore.SuccessPaymentStatus {
 					Expect(p.PaidAt).ToNot(BeZero())
+					Expect(outboxCount).To(Equal(int64(1)))
 				} else {
 					Expect(p.PaidAt).To(BeZero())
+					Expect(outboxCount).To(BeZero())
 				}
 			},
 			Entry("success payment", datastore.SuccessPaymentStatus),
 			Entry("failed payment", datastore.FailedPaymentStatus),
 			Entry("pending payment", datastore.PendingPaymentStatus),
 		)
+		It("should not create the outbox of the successful part of the split payment", func() {
+			splitID := uuid.NewString()
+			parts := []*datastore.Payment{
+				generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID),
+				generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID),
+			}
+			for _, p := range parts {
+				p.SplitID, p.InvoiceID = &splitID, parts[0].InvoiceID
+				Expect(paymentDataStore.Create(p)).To(Succeed())
+			}
+			var outboxCount int64
+			Expect(db.Model(&datastore.PaidInvoiceOutbox{}).Where("invoice_id = ?", parts[0].InvoiceID).Count(&outboxCount).Error).To(Succeed())
+			Expect(outboxCount).To(BeZero())
+		})
+		It("should create the outbox with the successful last part of the split payment", func() {
+			splitID := uuid.NewString()
+			p := generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID)
+			p.SplitID = &splitID
+			Expect(paymentDataStore.CreateLastSplitPart(p)).To(Succeed())
+			var o datastore.PaidInvoiceOutbox
+			Expect(db.Where("payment_id = ?", p.ID).First(&o).Error).To(Succeed())
+			Expect(o.InvoiceID).To(Equal(p.InvoiceID))
+			Expect(o.DoneAt).To(BeNil())
+		})
+		It("should not create the outbox with the failed last part of the split payment", func() {
+			splitID := uuid.NewString()
+			p := generateCreditCardPayment(datastore.FailedPaymentStatus, creditCard.ID)
+			p.SplitID = &splitID
+			Expect(paymentDataStore.CreateLastSplitPart(p)).To(Succeed())
+			var outboxCount int64
+			Expect(db.Model(&datastore.PaidInvoiceOutbox{}).Where("payment_id = ?", p.ID).Count(&outboxCount).Error).To(Succeed())
+			Expect(outboxCount).To(BeZero())
+		})
 	})
 
 	Context("FindLatestByInvoiceIDAndStatus", func() {
@@ -86,6 +125,20 @@ var _ = Describe("Payment Datastore", Ordered, func() {
 					Expect(p.CreditCard.CardID).To(Equal(creditCard.CardID))
 				})
 			})
+			When("invoice has several successful payments", func() {
+				var latest *datastore.Payment
+				BeforeEach(func() {
+					latest = generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID)
+					latest.InvoiceID = payment.InvoiceID
+					latest.CreatedAt = payment.CreatedAt.Add(time.Minute)
+					Expect(db.Create(latest).Error).To(Succeed())
+				})
+				It("should return the latest payment", func() {
+					p, err := paymentDataStore.FindLatestByInvoiceIDAndStatus(payment.InvoiceID, datastore.SuccessPaymentStatus)
+					Expect(err).To(BeNil())
+					Expect(p.ID).To(Equal(latest.ID))
+				})
+			})
 			When("payment is found but credit card is deleted", func() {
 				BeforeEach(func() {
 					tx := db.Delete(creditCard)
@@ -102,4 +155,263 @@ var _ = Describe("Payment Datastore", Ordered, func() {
 			})
 		})
 	})
+
+	Context("FindByID", func() {
+		It("should return nil with no error when payment is not found", func() {
+			p, err := paymentDataStore.FindByID(uint(rand.Uint32()))
+			Expect(err).To(BeNil())
+			Expect(p).To(BeNil())
+		})
+		It("should return payment with credit card preloaded", func() {
+			payment := generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID)
+			Expect(db.Create(payment).Error).To(Succeed())
+			p, err := paymentDataStore.FindByID(payment.ID)
+			Expect(err).To(BeNil())
+			Expect(p.ID).To(Equal(payment.ID))
+			Expect(p.CreditCard.CardID).To(Equal(creditCard.CardID))
+		})
+	})
+
+	Context("Refund reservation", func() {
+		var payment *datastore.Payment
+		BeforeEach(func() {
+			payment = generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID)
+			payment.Amount = 100.5
+			Expect(db.Create(payment).Error).To(Succeed())
+		})
+		newRefund := func(amount float64) *datastore.Refund {
+			return &datastore.Refund{PaymentID: payment.ID, Amount: amount}
+		}
+		refundedAmount := func() float64 {
+			var p datastore.Payment
+			Expect(db.First(&p, payment.ID).Error).To(Succeed())
+			return p.RefundedAmount
+		}
+		paymentStatus := func() datastore.PaymentStatus {
+			var p datastore.Payment
+			Expect(db.First(&p, payment.ID).Error).To(Succeed())
+			return p.Status
+		}
+
+		It("should reserve the partial refunds up to the payment amount", func() {
+			Expect(paymentDataStore.ReserveRefund(newRefund(50.25))).To(BeTrue())
+			Expect(paymentDataStore.ReserveRefund(newRefund(50.25))).To(BeTrue())
+			Expect(refundedAmount()).To(BeNumerically("~", 100.5, 0.001))
+		})
+		It("should not reserve the refund exceeding the payment amount", func() {
+			Expect(paymentDataStore.ReserveRefund(newRefund(60))).To(BeTrue())
+			Expect(paymentDataStore.ReserveRefund(newRefund(41))).To(BeFalse())
+			Expect(refundedAmount()).To(BeNumerically("~", 60, 0.001))
+		})
+		It("should not reserve the refund of the failed payment", func() {
+			Expect(db.Model(payment).Update("status", datastore.FailedPaymentStatus).Error).To(Succeed())
+			Expect(paymentDataStore.ReserveRefund(newRefund(10))).To(BeFalse())
+		})
+		It("should create the pending refund when the refund is reserved", func() {
+			refund := newRefund(30)
+			Expect(paymentDataStore.ReserveRefund(refund)).To(BeTrue())
+			Expect(refund.ID).ToNot(BeZero())
+			assertRecord(db, &datastore.Refund{ID: refund.ID, Status: datastore.PendingRefundStatus})
+		})
+		It("should not create the refund when the refund isn't reserved", func() {
+			refund := newRefund(101)
+			Expect(paymentDataStore.ReserveRefund(refund)).To(BeFalse())
+			Expect(refund.ID).To(BeZero())
+		})
+		It("should release the reserved refund", func() {
+			refund := newRefund(30)
+			Expect(paymentDataStore.ReserveRefund(refund)).To(BeTrue())
+			Expect(paymentDataStore.ReleaseRefund(refund)).To(Succeed())
+			Expect(refundedAmount()).To(BeNumerically("~", 0, 0.001))
+			Expect(db.First(&datastore.Refund{}, refund.ID).Error).To(MatchError(gorm.ErrRecordNotFound))
+		})
+		It("should mark the fully refunded part of the split payment as refunded", func() {
+			splitID := uuid.NewString()
+			Expect(db.Model(payment).Update("split_id", splitID).Error).To(Succeed())
+			Expect(paymentDataStore.ReserveRefund(newRefund(60))).To(BeTrue())
+			Expect(paymentDataStore.MarkRefunded(payment.ID)).To(Succeed())
+			Expect(paymentStatus()).To(Equal(datastore.SuccessPaymentStatus))
+			Expect(paymentDataStore.ReserveRefund(newRefund(40.5))).To(BeTrue())
+			Expect(paymentDataStore.MarkRefunded(payment.ID)).To(Succeed())
+			Expect(paymentStatus()).To(Equal(datastore.RefundedPaymentStatus))
+			Expect(paymentDataStore.ReserveRefund(newRefund(0))).To(BeFalse())
+		})
+		It("should not mark the fully refunded payment which isn't the part of the split payment", func() {
+			Expect(paymentDataStore.ReserveRefund(newRefund(100.5))).To(BeTrue())
+			Expect(paymentDataStore.MarkRefunded(payment.ID)).To(Succeed())
+			Expect(paymentStatus()).To(Equal(datastore.SuccessPaymentStatus))
+		})
+	})
+
+	Context("FindByChargeID", func() {
+		It("should return nil with no error when payment is not found", func() {
+			p, err := paymentDataStore.FindByChargeID(uuid.NewString())
+			Expect(err).To(BeNil())
+			Expect(p).To(BeNil())
+		})
+		It("should return payment of the charge", func() {
+			payment := generateCreditCardPayment(datastore.PendingPaymentStatus, creditCard.ID)
+			Expect(db.Create(payment).Error).To(Succeed())
+			p, err := paymentDataStore.FindByChargeID(payment.ChargeID)
+			Expect(err).To(BeNil())
+			Expect(p.ID).To(Equal(payment.ID))
+		})
+	})
+
+	Context("FindLatestByInvoiceID", func() {
+		It("should return nil with no error when payment is not found", func() {
+			p, err := paymentDataStore.FindLatestByInvoiceID(int(rand.Int31()))
+			Expect(err).To(BeNil())
+			Expect(p).To(BeNil())
+		})
+		It("should return the latest payment of the invoice", func() {
+			failed := generateCreditCardPayment(datastore.FailedPaymentStatus, creditCard.ID)
+			Expect(db.Create(failed).Error).To(Succeed())
+			pending := generateCreditCardPayment(datastore.PendingPaymentStatus, creditCard.ID)
+			pending.InvoiceID = failed.InvoiceID
+			Expect(db.Create(pending).Error).To(Succeed())
+			p, err := paymentDataStore.FindLatestByInvoiceID(failed.InvoiceID)
+			Expect(err).To(BeNil())
+			Expect(p.ID).To(Equal(pending.ID))
+		})
+	})
+
+	Context("SettlePending", func() {
+		var payment *datastore.Payment
+		BeforeEach(func() {
+			payment = generateCreditCardPayment(datastore.PendingPaymentStatus, creditCard.ID)
+			Expect(db.Create(payment).Error).To(Succeed())
+		})
+
+		It("should settle the pending payment only once", func() {
+			paidAt := time.Now()
+			Expect(paymentDataStore.SettlePending(payment.ID, datastore.SuccessPaymentStatus, &paidAt)).To(BeTrue())
+			Expect(paymentDataStore.SettlePending(payment.ID, datastore.FailedPaymentStatus, nil)).To(BeFalse())
+			var p datastore.Payment
+			Expect(db.First(&p, payment.ID).Error).To(Succeed())
+			Expect(p.Status).To(Equal(datastore.SuccessPaymentStatus))
+			Expect(p.PaidAt).ToNot(BeNil())
+
+			var outbox datastore.PaidInvoiceOutbox
+			Expect(db.Where("payment_id = ?", payment.ID).First(&outbox).Error).To(Succeed())
+			Expect(outbox.InvoiceID).To(Equal(payment.InvoiceID))
+			Expect(outbox.DoneAt).To(BeNil())
+		})
+		It("should not create the outbox when the payment is settled as failed", func() {
+			Expect(paymentDataStore.SettlePending(payment.ID, datastore.FailedPaymentStatus, nil)).To(BeTrue())
+			var outboxCount int64
+			Expect(db.Model(&datastore.PaidInvoiceOutbox{}).Where("payment_id = ?", payment.ID).Count(&outboxCount).Error).To(Succeed())
+			Expect(outboxCount).To(BeZero())
+		})
+		It("should not create the outbox when the part of the split payment is settled as successful", func() {
+			splitID := uuid.NewString()
+			Expect(db.Model(payment).Update("split_id", splitID).Error).To(Succeed())
+			paidAt := time.Now()
+			Expect(paymentDataStore.SettlePending(payment.ID, datastore.SuccessPaymentStatus, &paidAt)).To(BeTrue())
+			var outboxCount int64
+			Expect(db.Model(&datastore.PaidInvoiceOutbox{}).Where("payment_id = ?", payment.ID).Count(&outboxCount).Error).To(Succeed())
+			Expect(outboxCount).To(BeZero())
+		})
+	})
+
+	Context("ListCreatedBetween", func() {
+		It("should return the payments created within the range", func() {
+			old := generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID)
+			old.CreatedAt = time.Now().Add(-48 * time.Hour)
+			Expect(db.Create(old).Error).To(Succeed())
+			recent := generateCreditCardPayment(datastore.FailedPaymentStatus, creditCard.ID)
+			Expect(db.Create(recent).Error).To(Succeed())
+
+			payments, err := paymentDataStore.ListCreatedBetween(time.Now().Add(-24*time.Hour), time.Now())
+			Expect(err).To(BeNil())
+			Expect(payments).To(HaveLen(1))
+			Expect(payments[0].ID).To(Equal(recent.ID))
+		})
+	})
+
+	Context("ListByPatientID and CountByPatientID", func() {
+		var (
+			payments []*datastore.Payment
+			filters  *datastore.PaymentFilters
+		)
+		BeforeEach(func() {
+			filters = &datastore.PaymentFilters{}
+			payments = nil
+			now := time.Now()
+			for i, status := range []datastore.PaymentStatus{
+				datastore.SuccessPaymentStatus,
+				datastore.FailedPaymentStatus,
+				datastore.SuccessPaymentStatus,
+				datastore.PendingPaymentStatus,
+			} {
+				p := generateCreditCardPayment(status, creditCard.ID)
+				p.PatientID = patient.ID
+				p.CreatedAt = now.Add(-time.Duration(i) * 24 * time.Hour)
+				payments = append(payments, p)
+			}
+			payments[3].Method = datastore.PromptPayPaymentMethod
+			payments[3].CreditCardID = nil
+			other := generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID)
+			other.PatientID = patient.ID + 1
+			Expect(db.Create(append(payments, other)).Error).To(Succeed())
+		})
+
+		assertList := func(take, skip int, expected ...*datastore.Payment) {
+			list, err := paymentDataStore.ListByPatientID(patient.ID, filters, take, skip)
+			Expect(err).To(BeNil())
+			Expect(list).To(HaveLen(len(expected)))
+			for i, p := range expected {
+				Expect(list[i].ID).To(Equal(p.ID))
+			}
+		}
+		assertCount := func(expected int) {
+			count, err := paymentDataStore.CountByPatientID(patient.ID, filters)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(expected))
+		}
+
+		When("no filter is applied", func() {
+			It("should list the patient's payments from the latest one with pagination", func() {
+				assertList(2, 0, payments[0], payments[1])
+				assertList(2, 2, payments[2], payments[3])
+				assertCount(4)
+			})
+			It("should preload the credit card", func() {
+				list, err := paymentDataStore.ListByPatientID(patient.ID, filters, 1, 0)
+				Expect(err).To(BeNil())
+				Expect(list[0].CreditCard).ToNot(BeNil())
+				Expect(list[0].CreditCard.ID).To(Equal(creditCard.ID))
+			})
+		})
+		When("filter by status", func() {
+			BeforeEach(func() {
+				filters.Status = datastore.SuccessPaymentStatus
+			})
+			It("should list the payments with the status", func() {
+				assertList(10, 0, payments[0], payments[2])
+				assertCount(2)
+			})
+		})
+		When("filter by method", func() {
+			BeforeEach(func() {
+				filters.Method = datastore.PromptPayPaymentMethod
+			})
+			It("should list the payments with the method", func() {
+				assertList(10, 0, payments[3])
+				assertCount(1)
+			})
+		})
+		When("filter by date range", func() {
+			BeforeEach(func() {
+				start := time.Now().Add(-60 * time.Hour)
+				end := time.Now().Add(-12 * time.Hour)
+				filters.StartDate = &start
+				filters.EndDate = &end
+			})
+			It("should list the payments created within the range", func() {
+				assertList(10, 0, payments[1], payments[2])
+				assertCount(2)
+			})
+		})
+	})
 })
@@ -0,0 +1,52 @@
+package datastore
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type RefundStatus string
+
+const (
+	// PendingRefundStatus is the refund which is reserved but not confirmed by the payment gateway yet
+	PendingRefundStatus RefundStatus = "pending"
+	SuccessRefundStatus RefundStatus = "success"
+)
+
+// Refund is the full or partial refund of the payment
+type Refund struct {
+	CreatedAt time.Time    `json:"created_at"`
+	RefID     string       `json:"-" gorm:"not null"`
+	Reason    string       `json:"reason"`
+	Status    RefundStatus `json:"status" gorm:"not null;default:success"`
+	ID        uint         `json:"id" gorm:"autoIncrement,primaryKey"`
+	PaymentID uint         `json:"payment_id" gorm:"index;not null"`
+	Payment   *Payment     `json:"-"`
+	Amount    float64      `json:"amount" gorm:"not null"`
+}
+
+type RefundDataStore interface {
+	// Complete sets the reference ID of the payment gateway to the pending refund and marks it as successful
+	Complete(id uint, refID string) error
+	ListByPaymentID(paymentID uint) ([]Refund, error)
+}
+
+type GormRefundDataStore struct {
+	db *gorm.DB
+}
+
+func NewGormRefundDataStore(db *gorm.DB) (RefundDataStore, error) {
+	return &GormRefundDataStore{db: db}, db.AutoMigrate(&Refund{})
+}
+
+func (g GormRefundDataStore) Complete(id uint, refID string) error {
+	return g.db.Model(&Refund{}).Where("id = ? AND status = ?", id, PendingRefundStatus).Updates(&Refund{RefID: refID, Status: SuccessRefundStatus}).Error
+}
+
+func (g GormRefundDataStore) ListByPaymentID(paymentID uint) ([]Refund, error) {
+	var refunds []Refund
+	if err := g.db.Where(&Refund{PaymentID: paymentID}).Order("created_at").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
@@ -0,0 +1,76 @@
+package datastore_test
+
+import (
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("Refund Datastore", Ordered, func() {
+	var (
+		db              *gorm.DB
+		refundDataStore datastore.RefundDataStore
+		payment         *datastore.Payment
+	)
+
+	BeforeAll(func() {
+		var err error
+		db, err = gorm.Open(pg.Open(postgres.Config.DSN()), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+	})
+
+	BeforeEach(func() {
+		Expect(db.AutoMigrate(&datastore.Patient{}, &datastore.CreditCard{}, &datastore.Payment{})).To(Succeed())
+		var err error
+		refundDataStore, err = datastore.NewGormRefundDataStore(db)
+		Expect(err).To(BeNil())
+
+		patient := generatePatient()
+		Expect(db.Create(patient).Error).To(Succeed())
+		creditCard := generateCreditCard(patient.ID, false)
+		Expect(db.Create(creditCard).Error).To(Succeed())
+		payment = generateCreditCardPayment(datastore.SuccessPaymentStatus, creditCard.ID)
+		Expect(db.Create(payment).Error).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(db.Migrator().DropTable(&datastore.Refund{}, &datastore.Payment{}, &datastore.CreditCard{}, &datastore.Patient{})).To(Succeed())
+	})
+
+	Context("Complete", func() {
+		It("should set the reference ID and mark the pending refund as successful", func() {
+			refund := &datastore.Refund{PaymentID: payment.ID, Amount: 10, Status: datastore.PendingRefundStatus}
+			Expect(db.Create(refund).Error).To(Succeed())
+			refID := uuid.NewString()
+			Expect(refundDataStore.Complete(refund.ID, refID)).To(Succeed())
+			assertRecord(db, &datastore.Refund{ID: refund.ID, RefID: refID, Status: datastore.SuccessRefundStatus})
+		})
+	})
+
+	Context("ListByPaymentID", func() {
+		It("should list the refunds of the payment from oldest to latest", func() {
+			first := &datastore.Refund{PaymentID: payment.ID, RefID: uuid.NewString(), Amount: 10}
+			second := &datastore.Refund{PaymentID: payment.ID, RefID: uuid.NewString(), Amount: 20, Reason: "Billing mistake"}
+			Expect(db.Create(first).Error).To(Succeed())
+			Expect(db.Create(second).Error).To(Succeed())
+			Expect(first.ID).ToNot(BeZero())
+
+			refunds, err := refundDataStore.ListByPaymentID(payment.ID)
+			Expect(err).To(BeNil())
+			Expect(refunds).To(HaveLen(2))
+			Expect(refunds[0].ID).To(Equal(first.ID))
+			Expect(refunds[1].Reason).To(Equal(second.Reason))
+		})
+		It("should return empty list when the payment has no refund", func() {
+			refunds, err := refundDataStore.ListByPaymentID(payment.ID)
+			Expect(err).To(BeNil())
+			Expect(refunds).To(BeEmpty())
+		})
+	})
+})
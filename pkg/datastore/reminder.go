@@ -0,0 +1,35 @@
+package datastore
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// AppointmentReminder records that the reminder of the appointment at the offset before its start time has been sent
+type AppointmentReminder struct {
+	CreatedAt     time.Time     `json:"created_at"`
+	AppointmentID string        `json:"appointment_id" gorm:"uniqueIndex:idx_appointment_reminder;not null"`
+	ID            uint          `json:"id" gorm:"autoIncrement,primaryKey"`
+	Offset        time.Duration `json:"offset" gorm:"uniqueIndex:idx_appointment_reminder;not null"`
+}
+
+type ReminderDataStore interface {
+	// Claim records the reminder and reports whether the caller is the first one to claim it.
+	// It is safe to be called concurrently by multiple workers.
+	Claim(appointmentID string, offset time.Duration) (bool, error)
+}
+
+type GormReminderDataStore struct {
+	db *gorm.DB
+}
+
+func NewGormReminderDataStore(db *gorm.DB) (ReminderDataStore, error) {
+	return &GormReminderDataStore{db: db}, db.AutoMigrate(&AppointmentReminder{})
+}
+
+func (g GormReminderDataStore) Claim(appointmentID string, offset time.Duration) (bool, error) {
+	reminder := &AppointmentReminder{AppointmentID: appointmentID, Offset: offset}
+	tx := g.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	return tx.RowsAffected == 1, tx.Error
+}
@@ -0,0 +1,58 @@
+package datastore_test
+
+import (
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+var _ = Describe("Reminder Datastore", Ordered, func() {
+	var (
+		db                *gorm.DB
+		reminderDataStore datastore.ReminderDataStore
+		appointmentID     string
+	)
+
+	BeforeAll(func() {
+		var err error
+		db, err = gorm.Open(pg.Open(postgres.Config.DSN()), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+	})
+
+	BeforeEach(func() {
+		var err error
+		reminderDataStore, err = datastore.NewGormReminderDataStore(db)
+		Expect(err).To(BeNil())
+		appointmentID = uuid.NewString()
+	})
+
+	AfterEach(func() {
+		Expect(db.Migrator().DropTable(&datastore.AppointmentReminder{})).To(Succeed())
+	})
+
+	Context("Claim", func() {
+		It("should claim the reminder that hasn't been sent", func() {
+			claimed, err := reminderDataStore.Claim(appointmentID, time.Hour)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeTrue())
+			assertRecord(db, &datastore.AppointmentReminder{AppointmentID: appointmentID, Offset: time.Hour})
+		})
+		It("should not claim the reminder that has already been claimed", func() {
+			Expect(reminderDataStore.Claim(appointmentID, time.Hour)).To(BeTrue())
+			claimed, err := reminderDataStore.Claim(appointmentID, time.Hour)
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeFalse())
+		})
+		It("should claim the reminder of the same appointment with different offset", func() {
+			Expect(reminderDataStore.Claim(appointmentID, time.Hour)).To(BeTrue())
+			Expect(reminderDataStore.Claim(appointmentID, 24*time.Hour)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package datastore
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type RoomClosureReason string
+
+const (
+	ForceLeftRoomClosureReason RoomClosureReason = "FORCE_LEFT"
+	OrphanedRoomClosureReason  RoomClosureReason = "ORPHANED"
+)
+
+// RoomClosure records an appointment room that is closed without completing the appointment
+type RoomClosure struct {
+	CreatedAt     time.Time         `json:"created_at"`
+	LastHeartbeat *time.Time        `json:"last_heartbeat"` // nil if the room has never been active
+	RoomID        string            `json:"room_id" gorm:"index;not null"`
+	AppointmentID string            `json:"appointment_id" gorm:"index"`
+	Reason        RoomClosureReason `json:"reason" gorm:"not null"`
+	ID            uint              `json:"id" gorm:"autoIncrement,primaryKey"`
+	DoctorID      uint              `json:"doctor_id" gorm:"index"`
+}
+
+type RoomClosureDataStore interface {
+	Create(closure *RoomClosure) error
+	// ExistsByAppointmentID reports whether any room of the appointment has been closed
+	ExistsByAppointmentID(appointmentID string) (bool, error)
+}
+
+type GormRoomClosureDataStore struct {
+	db *gorm.DB
+}
+
+func NewGormRoomClosureDataStore(db *gorm.DB) (RoomClosureDataStore, error) {
+	return &GormRoomClosureDataStore{db: db}, db.AutoMigrate(&RoomClosure{})
+}
+
+func (g GormRoomClosureDataStore) Create(closure *RoomClosure) error {
+	return g.db.Create(closure).Error
+}
+
+func (g GormRoomClosureDataStore) ExistsByAppointmentID(appointmentID string) (bool, error) {
+	var count int64
+	if err := g.db.Model(&RoomClosure{}).Where(&RoomClosure{AppointmentID: appointmentID}).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,62 @@
+package datastore_test
+
+import (
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("Room Closure Datastore", Ordered, func() {
+	var (
+		db                   *gorm.DB
+		roomClosureDataStore datastore.RoomClosureDataStore
+	)
+
+	BeforeAll(func() {
+		var err error
+		db, err = gorm.Open(pg.Open(postgres.Config.DSN()), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+	})
+
+	BeforeEach(func() {
+		var err error
+		roomClosureDataStore, err = datastore.NewGormRoomClosureDataStore(db)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		Expect(db.Migrator().DropTable(&datastore.RoomClosure{})).To(Succeed())
+	})
+
+	Context("Create", func() {
+		It("should save room closure", func() {
+			closure := &datastore.RoomClosure{RoomID: uuid.NewString(), AppointmentID: uuid.NewString(), DoctorID: getRandomID(), Reason: datastore.OrphanedRoomClosureReason}
+			Expect(roomClosureDataStore.Create(closure)).To(Succeed())
+			assertRecord(db, &datastore.RoomClosure{ID: closure.ID, Reason: datastore.OrphanedRoomClosureReason})
+		})
+	})
+
+	Context("ExistsByAppointmentID", func() {
+		var closure *datastore.RoomClosure
+		BeforeEach(func() {
+			closure = &datastore.RoomClosure{RoomID: uuid.NewString(), AppointmentID: uuid.NewString(), DoctorID: getRandomID(), Reason: datastore.ForceLeftRoomClosureReason}
+			Expect(roomClosureDataStore.Create(closure)).To(Succeed())
+		})
+		It("should return true when the appointment has a room closure", func() {
+			exists, err := roomClosureDataStore.ExistsByAppointmentID(closure.AppointmentID)
+			Expect(err).To(BeNil())
+			Expect(exists).To(BeTrue())
+		})
+		It("should return false when the appointment has no room closure", func() {
+			exists, err := roomClosureDataStore.ExistsByAppointmentID(uuid.NewString())
+			Expect(err).To(BeNil())
+			Expect(exists).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,339 @@
+package datastore
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+// TimeOfDayLayout is the layout of StartTime and EndTime of the schedule, e.g. 09:30
+const TimeOfDayLayout = "15:04"
+
+// DateLayout is the layout of the date of schedule exception and leave day, e.g. 2022-12-31
+const DateLayout = "2006-01-02"
+
+var ErrInvalidTimeRange = errors.New("start time must be before end time")
+
+// ScheduleSlot is a weekly recurring working time of the doctor
+type ScheduleSlot struct {
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	StartTime string         `json:"start_time" gorm:"not null"`
+	EndTime   string         `json:"end_time" gorm:"not null"`
+	ID        uint           `json:"id" gorm:"autoIncrement,primaryKey"`
+	DoctorID  uint           `json:"doctor_id" gorm:"index;not null"`
+	Weekday   time.Weekday   `json:"weekday" gorm:"not null"`
+}
+
+// ScheduleException overrides the weekly schedule on a specific date.
+// An available exception adds extra working time, otherwise the time range is blocked.
+type ScheduleException struct {
+	Date        time.Time      `json:"date" gorm:"type:date;not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	StartTime   string         `json:"start_time" gorm:"not null"`
+	EndTime     string         `json:"end_time" gorm:"not null"`
+	ID          uint           `json:"id" gorm:"autoIncrement,primaryKey"`
+	DoctorID    uint           `json:"doctor_id" gorm:"index;not null"`
+	IsAvailable bool           `json:"is_available"`
+}
+
+// LeaveDay is a whole day that the doctor is not working
+type LeaveDay struct {
+	Date      time.Time      `json:"date" gorm:"type:date;not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Reason    string         `json:"reason"`
+	ID        uint           `json:"id" gorm:"autoIncrement,primaryKey"`
+	DoctorID  uint           `json:"doctor_id" gorm:"index;not null"`
+}
+
+type DoctorSchedule struct {
+	Slots      []ScheduleSlot      `json:"slots"`
+	Exceptions []ScheduleException `json:"exceptions"`
+	LeaveDays  []LeaveDay          `json:"leave_days"`
+}
+
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type ScheduleDataStore interface {
+	FindByDoctorID(doctorID uint) (*DoctorSchedule, error)
+	CreateSlot(slot *ScheduleSlot) error
+	FindSlotByID(id uint) (*ScheduleSlot, error)
+	SaveSlot(slot *ScheduleSlot) error
+	DeleteSlot(id uint) error
+	CreateException(exception *ScheduleException) error
+	FindExceptionByID(id uint) (*ScheduleException, error)
+	SaveException(exception *ScheduleException) error
+	DeleteException(id uint) error
+	CreateLeaveDay(leaveDay *LeaveDay) error
+	FindLeaveDayByID(id uint) (*LeaveDay, error)
+	SaveLeaveDay(leaveDay *LeaveDay) error
+	DeleteLeaveDay(id uint) error
+}
+
+type GormScheduleDataStore struct {
+	db *gorm.DB
+}
+
+func NewGormScheduleDataStore(db *gorm.DB) (ScheduleDataStore, error) {
+	return &GormScheduleDataStore{db: db}, db.AutoMigrate(&ScheduleSlot{}, &ScheduleException{}, &LeaveDay{})
+}
+
+func (g GormScheduleDataStore) FindByDoctorID(doctorID uint) (*DoctorSchedule, error) {
+	schedule := &DoctorSchedule{}
+	if err := g.db.Where(&ScheduleSlot{DoctorID: doctorID}).Order("weekday, start_time").Find(&schedule.Slots).Error; err != nil {
+		return nil, err
+	}
+	if err := g.db.Where(&ScheduleException{DoctorID: doctorID}).Order("date, start_time").Find(&schedule.Exceptions).Error; err != nil {
+		return nil, err
+	}
+	if err := g.db.Where(&LeaveDay{DoctorID: doctorID}).Order("date").Find(&schedule.LeaveDays).Error; err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (g GormScheduleDataStore) CreateSlot(slot *ScheduleSlot) error {
+	return g.db.Create(slot).Error
+}
+
+func (g GormScheduleDataStore) FindSlotByID(id uint) (*ScheduleSlot, error) {
+	var slot ScheduleSlot
+	if err := g.db.First(&slot, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func (g GormScheduleDataStore) SaveSlot(slot *ScheduleSlot) error {
+	return g.db.Save(slot).Error
+}
+
+func (g GormScheduleDataStore) DeleteSlot(id uint) error {
+	return g.db.Delete(&ScheduleSlot{}, id).Error
+}
+
+func (g GormScheduleDataStore) CreateException(exception *ScheduleException) error {
+	return g.db.Create(exception).Error
+}
+
+func (g GormScheduleDataStore) FindExceptionByID(id uint) (*ScheduleException, error) {
+	var exception ScheduleException
+	if err := g.db.First(&exception, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &exception, nil
+}
+
+func (g GormScheduleDataStore) SaveException(exception *ScheduleException) error {
+	return g.db.Save(exception).Error
+}
+
+func (g GormScheduleDataStore) DeleteException(id uint) error {
+	return g.db.Delete(&ScheduleException{}, id).Error
+}
+
+func (g GormScheduleDataStore) CreateLeaveDay(leaveDay *LeaveDay) error {
+	return g.db.Create(leaveDay).Error
+}
+
+func (g GormScheduleDataStore) FindLeaveDayByID(id uint) (*LeaveDay, error) {
+	var leaveDay LeaveDay
+	if err := g.db.First(&leaveDay, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &leaveDay, nil
+}
+
+func (g GormScheduleDataStore) SaveLeaveDay(leaveDay *LeaveDay) error {
+	return g.db.Save(leaveDay).Error
+}
+
+func (g GormScheduleDataStore) DeleteLeaveDay(id uint) error {
+	return g.db.Delete(&LeaveDay{}, id).Error
+}
+
+// ValidateTimeOfDayRange checks that both start and end are in TimeOfDayLayout and start is before end
+func ValidateTimeOfDayRange(start, end string) error {
+	startTime, err := time.Parse(TimeOfDayLayout, start)
+	if err != nil {
+		return err
+	}
+	endTime, err := time.Parse(TimeOfDayLayout, end)
+	if err != nil {
+		return err
+	}
+	if !startTime.Before(endTime) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// FreeSlots computes the working time ranges of the schedule within [from, to) that don't overlap with any of the busy ranges.
+// The schedule is interpreted in loc and the returned ranges are sorted by start time.
+func (s DoctorSchedule) FreeSlots(from, to time.Time, loc *time.Location, busy []TimeRange) []TimeRange {
+	free := make([]TimeRange, 0)
+	fromLocal := from.In(loc)
+	day := time.Date(fromLocal.Year(), fromLocal.Month(), fromLocal.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if s.isLeaveDay(day) {
+			continue
+		}
+		working := make([]TimeRange, 0)
+		for _, slot := range s.Slots {
+			if slot.Weekday == day.Weekday() {
+				working = append(working, timeOfDayRange(day, slot.StartTime, slot.EndTime))
+			}
+		}
+		blocked := make([]TimeRange, 0)
+		for _, e := range s.Exceptions {
+			if !isSameDate(e.Date, day) {
+				continue
+			}
+			r := timeOfDayRange(day, e.StartTime, e.EndTime)
+			if e.IsAvailable {
+				working = append(working, r)
+			} else {
+				blocked = append(blocked, r)
+			}
+		}
+		working = mergeTimeRanges(working)
+		for _, r := range append(blocked, busy...) {
+			working = subtractTimeRange(working, r)
+		}
+		for _, r := range working {
+			if r.Start.Before(from) {
+				r.Start = from
+			}
+			if r.End.After(to) {
+				r.End = to
+			}
+			if r.Start.Before(r.End) {
+				free = append(free, TimeRange{Start: r.Start.UTC(), End: r.End.UTC()})
+			}
+		}
+	}
+	return free
+}
+
+// IsAvailable checks whether the whole [start, end) range is in the working time of the schedule.
+// The doctor without any working time hasn't set up the schedule, so only the leave days and the blocked exceptions are checked.
+func (s DoctorSchedule) IsAvailable(start, end time.Time, loc *time.Location) bool {
+	if !s.hasWorkingTime() {
+		return !s.isBlocked(start, end, loc)
+	}
+	free := s.FreeSlots(start, end, loc, nil)
+	return len(free) == 1 && free[0].Start.Equal(start) && free[0].End.Equal(end)
+}
+
+func (s DoctorSchedule) hasWorkingTime() bool {
+	if len(s.Slots) > 0 {
+		return true
+	}
+	for _, e := range s.Exceptions {
+		if e.IsAvailable {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlocked checks whether any leave day or blocked exception overlaps with the [start, end) range
+func (s DoctorSchedule) isBlocked(start, end time.Time, loc *time.Location) bool {
+	r := TimeRange{Start: start, End: end}
+	for _, l := range s.LeaveDays {
+		day := time.Date(l.Date.Year(), l.Date.Month(), l.Date.Day(), 0, 0, 0, 0, loc)
+		if isOverlapping(r, TimeRange{Start: day, End: day.AddDate(0, 0, 1)}) {
+			return true
+		}
+	}
+	for _, e := range s.Exceptions {
+		day := time.Date(e.Date.Year(), e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, loc)
+		if !e.IsAvailable && isOverlapping(r, timeOfDayRange(day, e.StartTime, e.EndTime)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isOverlapping(a, b TimeRange) bool {
+	return a.Start.Before(b.End) && b.Start.Before(a.End)
+}
+
+func (s DoctorSchedule) isLeaveDay(day time.Time) bool {
+	for _, l := range s.LeaveDays {
+		if isSameDate(l.Date, day) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSameDate compares the calendar date of the stored date with the local day
+func isSameDate(date time.Time, day time.Time) bool {
+	y1, m1, d1 := date.Date()
+	y2, m2, d2 := day.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+func timeOfDayRange(day time.Time, start, end string) TimeRange {
+	startTime, _ := time.Parse(TimeOfDayLayout, start)
+	endTime, _ := time.Parse(TimeOfDayLayout, end)
+	return TimeRange{
+		Start: time.Date(day.Year(), day.Month(), day.Day(), startTime.Hour(), startTime.Minute(), 0, 0, day.Location()),
+		End:   time.Date(day.Year(), day.Month(), day.Day(), endTime.Hour(), endTime.Minute(), 0, 0, day.Location()),
+	}
+}
+
+func mergeTimeRanges(ranges []TimeRange) []TimeRange {
+	if len(ranges) == 0 {
+		return ranges
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start.Before(ranges[j].Start) })
+	merged := []TimeRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start.After(last.End) {
+			merged = append(merged, r)
+			continue
+		}
+		if r.End.After(last.End) {
+			last.End = r.End
+		}
+	}
+	return merged
+}
+
+func subtractTimeRange(ranges []TimeRange, sub TimeRange) []TimeRange {
+	result := make([]TimeRange, 0, len(ranges))
+	for _, r := range ranges {
+		if !isOverlapping(r, sub) {
+			result = append(result, r)
+			continue
+		}
+		if r.Start.Before(sub.Start) {
+			result = append(result, TimeRange{Start: r.Start, End: sub.Start})
+		}
+		if sub.End.Before(r.End) {
+			result = append(result, TimeRange{Start: sub.End, End: r.End})
+		}
+	}
+	return result
+}
@@ -0,0 +1,248 @@
+package datastore_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"math/rand"
+	"time"
+)
+
+var _ = Describe("Schedule Datastore", Ordered, func() {
+	var (
+		db                *gorm.DB
+		scheduleDataStore datastore.ScheduleDataStore
+		doctorID          uint
+		slots             []*datastore.ScheduleSlot
+		exceptions        []*datastore.ScheduleException
+		leaveDays         []*datastore.LeaveDay
+	)
+
+	BeforeAll(func() {
+		var err error
+		db, err = gorm.Open(pg.Open(postgres.Config.DSN()), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+	})
+
+	BeforeEach(func() {
+		rand.Seed(GinkgoRandomSeed())
+		var err error
+		scheduleDataStore, err = datastore.NewGormScheduleDataStore(db)
+		Expect(err).To(BeNil())
+
+		doctorID = getRandomID()
+		slots = []*datastore.ScheduleSlot{
+			generateScheduleSlot(doctorID, time.Tuesday, "13:00", "17:00"),
+			generateScheduleSlot(doctorID, time.Monday, "09:00", "12:00"),
+			generateScheduleSlot(getRandomID(), time.Monday, "09:00", "12:00"),
+		}
+		exceptions = []*datastore.ScheduleException{
+			generateScheduleException(doctorID, time.Date(2022, 10, 11, 0, 0, 0, 0, time.UTC), "10:00", "11:00", false),
+			generateScheduleException(getRandomID(), time.Date(2022, 10, 11, 0, 0, 0, 0, time.UTC), "10:00", "11:00", true),
+		}
+		leaveDays = []*datastore.LeaveDay{
+			generateLeaveDay(doctorID, time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC)),
+			generateLeaveDay(getRandomID(), time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC)),
+		}
+		Expect(db.Create(&slots).Error).To(Succeed())
+		Expect(db.Create(&exceptions).Error).To(Succeed())
+		Expect(db.Create(&leaveDays).Error).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(db.Migrator().DropTable(&datastore.ScheduleSlot{}, &datastore.ScheduleException{}, &datastore.LeaveDay{})).To(Succeed())
+	})
+
+	Context("FindByDoctorID", func() {
+		It("should return schedule of the doctor ordered by time", func() {
+			schedule, err := scheduleDataStore.FindByDoctorID(doctorID)
+			Expect(err).To(BeNil())
+			Expect(schedule.Slots).To(HaveLen(2))
+			Expect(schedule.Slots[0].ID).To(Equal(slots[1].ID))
+			Expect(schedule.Slots[1].ID).To(Equal(slots[0].ID))
+			Expect(schedule.Exceptions).To(HaveLen(1))
+			Expect(schedule.Exceptions[0].ID).To(Equal(exceptions[0].ID))
+			Expect(schedule.LeaveDays).To(HaveLen(1))
+			Expect(schedule.LeaveDays[0].ID).To(Equal(leaveDays[0].ID))
+		})
+	})
+
+	Context("Schedule slot", func() {
+		It("should create slot", func() {
+			slot := generateScheduleSlot(doctorID, time.Friday, "08:00", "10:00")
+			Expect(scheduleDataStore.CreateSlot(slot)).To(Succeed())
+			assertRecord(db, &datastore.ScheduleSlot{ID: slot.ID, Weekday: time.Friday})
+		})
+		It("should find slot by ID", func() {
+			slot, err := scheduleDataStore.FindSlotByID(slots[0].ID)
+			Expect(err).To(BeNil())
+			Expect(slot.StartTime).To(Equal(slots[0].StartTime))
+		})
+		It("should return nil when slot is not found", func() {
+			slot, err := scheduleDataStore.FindSlotByID(getRandomID())
+			Expect(err).To(BeNil())
+			Expect(slot).To(BeNil())
+		})
+		It("should save slot", func() {
+			slot := slots[0]
+			slot.EndTime = "18:00"
+			Expect(scheduleDataStore.SaveSlot(slot)).To(Succeed())
+			assertRecord(db, &datastore.ScheduleSlot{ID: slot.ID, EndTime: "18:00"})
+		})
+		It("should delete slot", func() {
+			Expect(scheduleDataStore.DeleteSlot(slots[0].ID)).To(Succeed())
+			Expect(db.First(&datastore.ScheduleSlot{}, slots[0].ID).Error).To(MatchError(gorm.ErrRecordNotFound))
+		})
+	})
+
+	Context("Schedule exception", func() {
+		It("should create exception", func() {
+			exception := generateScheduleException(doctorID, time.Date(2022, 10, 12, 0, 0, 0, 0, time.UTC), "18:00", "20:00", true)
+			Expect(scheduleDataStore.CreateException(exception)).To(Succeed())
+			assertRecord(db, &datastore.ScheduleException{ID: exception.ID, IsAvailable: true})
+		})
+		It("should find exception by ID", func() {
+			exception, err := scheduleDataStore.FindExceptionByID(exceptions[0].ID)
+			Expect(err).To(BeNil())
+			Expect(exception.Date.Format(datastore.DateLayout)).To(Equal("2022-10-11"))
+		})
+		It("should return nil when exception is not found", func() {
+			exception, err := scheduleDataStore.FindExceptionByID(getRandomID())
+			Expect(err).To(BeNil())
+			Expect(exception).To(BeNil())
+		})
+		It("should save exception", func() {
+			exception := exceptions[0]
+			exception.StartTime = "09:30"
+			Expect(scheduleDataStore.SaveException(exception)).To(Succeed())
+			assertRecord(db, &datastore.ScheduleException{ID: exception.ID, StartTime: "09:30"})
+		})
+		It("should delete exception", func() {
+			Expect(scheduleDataStore.DeleteException(exceptions[0].ID)).To(Succeed())
+			Expect(db.First(&datastore.ScheduleException{}, exceptions[0].ID).Error).To(MatchError(gorm.ErrRecordNotFound))
+		})
+	})
+
+	Context("Leave day", func() {
+		It("should create leave day", func() {
+			leaveDay := generateLeaveDay(doctorID, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+			Expect(scheduleDataStore.CreateLeaveDay(leaveDay)).To(Succeed())
+			assertRecord(db, &datastore.LeaveDay{ID: leaveDay.ID, DoctorID: doctorID})
+		})
+		It("should find leave day by ID", func() {
+			leaveDay, err := scheduleDataStore.FindLeaveDayByID(leaveDays[0].ID)
+			Expect(err).To(BeNil())
+			Expect(leaveDay.Date.Format(datastore.DateLayout)).To(Equal("2022-10-17"))
+		})
+		It("should return nil when leave day is not found", func() {
+			leaveDay, err := scheduleDataStore.FindLeaveDayByID(getRandomID())
+			Expect(err).To(BeNil())
+			Expect(leaveDay).To(BeNil())
+		})
+		It("should save leave day", func() {
+			leaveDay := leaveDays[0]
+			leaveDay.Reason = "Conference"
+			Expect(scheduleDataStore.SaveLeaveDay(leaveDay)).To(Succeed())
+			assertRecord(db, &datastore.LeaveDay{ID: leaveDay.ID, Reason: "Conference"})
+		})
+		It("should delete leave day", func() {
+			Expect(scheduleDataStore.DeleteLeaveDay(leaveDays[0].ID)).To(Succeed())
+			Expect(db.First(&datastore.LeaveDay{}, leaveDays[0].ID).Error).To(MatchError(gorm.ErrRecordNotFound))
+		})
+	})
+})
+
+var _ = Describe("Doctor Schedule", func() {
+	var (
+		loc      *time.Location
+		schedule datastore.DoctorSchedule
+	)
+
+	BeforeEach(func() {
+		loc = time.FixedZone("ICT", 7*60*60)
+		schedule = datastore.DoctorSchedule{
+			Slots: []datastore.ScheduleSlot{
+				*generateScheduleSlot(1, time.Monday, "09:00", "12:00"),
+				*generateScheduleSlot(1, time.Monday, "11:00", "13:00"),
+				*generateScheduleSlot(1, time.Tuesday, "13:00", "17:00"),
+			},
+			Exceptions: []datastore.ScheduleException{
+				*generateScheduleException(1, time.Date(2022, 10, 11, 0, 0, 0, 0, time.UTC), "14:00", "15:00", false),
+				*generateScheduleException(1, time.Date(2022, 10, 12, 0, 0, 0, 0, time.UTC), "18:00", "20:00", true),
+			},
+			LeaveDays: []datastore.LeaveDay{
+				*generateLeaveDay(1, time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC)),
+			},
+		}
+	})
+
+	Context("FreeSlots", func() {
+		It("should merge weekly slots, apply exceptions, skip leave days and subtract busy ranges", func() {
+			from := time.Date(2022, 10, 10, 0, 0, 0, 0, loc)
+			to := time.Date(2022, 10, 18, 0, 0, 0, 0, loc)
+			busy := []datastore.TimeRange{
+				{Start: time.Date(2022, 10, 10, 10, 0, 0, 0, loc), End: time.Date(2022, 10, 10, 10, 30, 0, 0, loc)},
+			}
+			Expect(schedule.FreeSlots(from, to, loc, busy)).To(Equal([]datastore.TimeRange{
+				utcRange(time.Date(2022, 10, 10, 9, 0, 0, 0, loc), time.Date(2022, 10, 10, 10, 0, 0, 0, loc)),
+				utcRange(time.Date(2022, 10, 10, 10, 30, 0, 0, loc), time.Date(2022, 10, 10, 13, 0, 0, 0, loc)),
+				utcRange(time.Date(2022, 10, 11, 13, 0, 0, 0, loc), time.Date(2022, 10, 11, 14, 0, 0, 0, loc)),
+				utcRange(time.Date(2022, 10, 11, 15, 0, 0, 0, loc), time.Date(2022, 10, 11, 17, 0, 0, 0, loc)),
+				utcRange(time.Date(2022, 10, 12, 18, 0, 0, 0, loc), time.Date(2022, 10, 12, 20, 0, 0, 0, loc)),
+			}))
+		})
+
+		It("should clip the free slots to the requested range", func() {
+			from := time.Date(2022, 10, 10, 11, 0, 0, 0, loc)
+			to := time.Date(2022, 10, 10, 12, 0, 0, 0, loc)
+			Expect(schedule.FreeSlots(from, to, loc, nil)).To(Equal([]datastore.TimeRange{utcRange(from, to)}))
+		})
+
+		It("should return empty list when the doctor has no schedule", func() {
+			from := time.Date(2022, 10, 10, 0, 0, 0, 0, loc)
+			Expect(datastore.DoctorSchedule{}.FreeSlots(from, from.AddDate(0, 0, 7), loc, nil)).To(BeEmpty())
+		})
+	})
+
+	Context("IsAvailable", func() {
+		It("should return true when the range is in the working time", func() {
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 10, 11, 30, 0, 0, loc), time.Date(2022, 10, 10, 12, 30, 0, 0, loc), loc)).To(BeTrue())
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 12, 18, 0, 0, 0, loc), time.Date(2022, 10, 12, 19, 0, 0, 0, loc), loc)).To(BeTrue())
+		})
+
+		It("should return false when the range is outside the working time", func() {
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 10, 12, 30, 0, 0, loc), time.Date(2022, 10, 10, 13, 30, 0, 0, loc), loc)).To(BeFalse())
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 11, 14, 30, 0, 0, loc), time.Date(2022, 10, 11, 15, 0, 0, 0, loc), loc)).To(BeFalse())
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 17, 9, 0, 0, 0, loc), time.Date(2022, 10, 17, 10, 0, 0, 0, loc), loc)).To(BeFalse())
+		})
+
+		It("should only check the leave days and the blocked exceptions when the doctor has no working time", func() {
+			schedule.Slots = nil
+			schedule.Exceptions = schedule.Exceptions[:1]
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 10, 20, 0, 0, 0, loc), time.Date(2022, 10, 10, 21, 0, 0, 0, loc), loc)).To(BeTrue())
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 11, 14, 30, 0, 0, loc), time.Date(2022, 10, 11, 15, 30, 0, 0, loc), loc)).To(BeFalse())
+			Expect(schedule.IsAvailable(time.Date(2022, 10, 16, 23, 30, 0, 0, loc), time.Date(2022, 10, 17, 0, 30, 0, 0, loc), loc)).To(BeFalse())
+		})
+	})
+
+	Context("ValidateTimeOfDayRange", func() {
+		It("should accept valid range", func() {
+			Expect(datastore.ValidateTimeOfDayRange("09:00", "12:30")).To(Succeed())
+		})
+		It("should reject invalid format", func() {
+			Expect(datastore.ValidateTimeOfDayRange("9am", "12:30")).ToNot(Succeed())
+		})
+		It("should reject start time after end time", func() {
+			Expect(datastore.ValidateTimeOfDayRange("13:00", "12:30")).To(MatchError(datastore.ErrInvalidTimeRange))
+		})
+	})
+})
+
+func utcRange(start, end time.Time) datastore.TimeRange {
+	return datastore.TimeRange{Start: start.UTC(), End: end.UTC()}
+}
@@ -0,0 +1,88 @@
+package datastore
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Session is the signed-in device of the patient or the doctor. It's refreshed by rotating its refresh token until it's expired or revoked
+type Session struct {
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiredAt time.Time  `json:"expired_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	ID        string     `json:"id" gorm:"primaryKey"`
+	Role      string     `json:"role" gorm:"not null;index:idx_sessions_user"`
+	// RefreshTokenID is the ID of the latest refresh token. Using the rotated one means the token is leaked, so the session is revoked
+	RefreshTokenID string `json:"-" gorm:"not null"`
+	UserAgent      string `json:"user_agent"`
+	UserID         uint   `json:"user_id" gorm:"not null;index:idx_sessions_user"`
+}
+
+type SessionDataStore interface {
+	Create(session *Session) error
+	// FindByID returns nil if the session is not found, expired or revoked
+	FindByID(id string, now time.Time) (*Session, error)
+	// Rotate replaces the refresh token of the session. It reports false if the previous token is already rotated, or the session is expired or revoked
+	Rotate(id, previousTokenID, tokenID string, expiredAt, now time.Time) (bool, error)
+	Revoke(id string, revokedAt time.Time) error
+	// RevokeAllByUser revokes the active sessions of the user and returns their IDs
+	RevokeAllByUser(userID uint, role string, revokedAt time.Time) ([]string, error)
+}
+
+type GormSessionDataStore struct {
+	db *gorm.DB
+}
+
+func NewGormSessionDataStore(db *gorm.DB) (SessionDataStore, error) {
+	return &GormSessionDataStore{db: db}, db.AutoMigrate(&Session{})
+}
+
+func (g GormSessionDataStore) Create(session *Session) error {
+	return g.db.Create(session).Error
+}
+
+func (g GormSessionDataStore) FindByID(id string, now time.Time) (*Session, error) {
+	var session Session
+	if err := g.active(now).Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (g GormSessionDataStore) Rotate(id, previousTokenID, tokenID string, expiredAt, now time.Time) (bool, error) {
+	tx := g.active(now).Model(&Session{}).
+		Where("id = ? AND refresh_token_id = ?", id, previousTokenID).
+		Updates(map[string]interface{}{"refresh_token_id": tokenID, "expired_at": expiredAt})
+	return tx.RowsAffected == 1, tx.Error
+}
+
+func (g GormSessionDataStore) Revoke(id string, revokedAt time.Time) error {
+	return g.db.Model(&Session{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt).Error
+}
+
+func (g GormSessionDataStore) RevokeAllByUser(userID uint, role string, revokedAt time.Time) ([]string, error) {
+	var ids []string
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&Session{}).
+			Where("user_id = ? AND role = ? AND revoked_at IS NULL AND expired_at > ?", userID, role, revokedAt).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return tx.Model(&Session{}).Where("id IN ?", ids).Update("revoked_at", revokedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (g GormSessionDataStore) active(now time.Time) *gorm.DB {
+	return g.db.Where("revoked_at IS NULL AND expired_at > ?", now)
+}
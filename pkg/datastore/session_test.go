@@ -0,0 +1,142 @@
+package datastore_test
+
+import (
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+var _ = Describe("Session Datastore", Ordered, func() {
+	var (
+		db               *gorm.DB
+		sessionDataStore datastore.SessionDataStore
+		sessions         []*datastore.Session
+		userID           uint
+		now              time.Time
+	)
+
+	generateSession := func(userID uint, role string, expiredAt time.Time) *datastore.Session {
+		return &datastore.Session{
+			ID:             uuid.NewString(),
+			UserID:         userID,
+			Role:           role,
+			RefreshTokenID: uuid.NewString(),
+			ExpiredAt:      expiredAt,
+		}
+	}
+
+	BeforeAll(func() {
+		var err error
+		db, err = gorm.Open(pg.Open(postgres.Config.DSN()), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+	})
+
+	BeforeEach(func() {
+		var err error
+		sessionDataStore, err = datastore.NewGormSessionDataStore(db)
+		Expect(err).To(BeNil())
+
+		now, userID = time.Now(), getRandomID()
+		revokedAt := now.Add(-time.Minute)
+		sessions = []*datastore.Session{
+			generateSession(userID, "Patient", now.Add(time.Hour)),
+			generateSession(userID, "Patient", now.Add(time.Hour)),
+			generateSession(userID, "Patient", now.Add(-time.Hour)),
+			generateSession(userID, "Doctor", now.Add(time.Hour)),
+			generateSession(getRandomID(), "Patient", now.Add(time.Hour)),
+			generateSession(userID, "Patient", now.Add(time.Hour)),
+		}
+		sessions[5].RevokedAt = &revokedAt
+		Expect(db.Create(&sessions).Error).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(db.Migrator().DropTable(&datastore.Session{})).To(Succeed())
+	})
+
+	Context("Create", func() {
+		It("should create the session", func() {
+			session := generateSession(userID, "Patient", now.Add(time.Hour))
+			Expect(sessionDataStore.Create(session)).To(Succeed())
+			assertRecord(db, &datastore.Session{ID: session.ID, RefreshTokenID: session.RefreshTokenID})
+		})
+	})
+
+	Context("FindByID", func() {
+		It("should return the active session", func() {
+			session, err := sessionDataStore.FindByID(sessions[0].ID, now)
+			Expect(err).To(BeNil())
+			Expect(session.UserID).To(Equal(userID))
+		})
+
+		It("should return nil when the session is expired", func() {
+			session, err := sessionDataStore.FindByID(sessions[2].ID, now)
+			Expect(err).To(BeNil())
+			Expect(session).To(BeNil())
+		})
+
+		It("should return nil when the session is revoked", func() {
+			session, err := sessionDataStore.FindByID(sessions[5].ID, now)
+			Expect(err).To(BeNil())
+			Expect(session).To(BeNil())
+		})
+	})
+
+	Context("Rotate", func() {
+		It("should replace the refresh token of the session", func() {
+			tokenID, expiredAt := uuid.NewString(), now.Add(2*time.Hour)
+			rotated, err := sessionDataStore.Rotate(sessions[0].ID, sessions[0].RefreshTokenID, tokenID, expiredAt, now)
+			Expect(err).To(BeNil())
+			Expect(rotated).To(BeTrue())
+			var s datastore.Session
+			Expect(db.First(&s, "id = ?", sessions[0].ID).Error).To(Succeed())
+			Expect(s.RefreshTokenID).To(Equal(tokenID))
+			Expect(s.ExpiredAt).To(BeTemporally("~", expiredAt, time.Millisecond))
+		})
+
+		It("should not rotate when the previous token is already rotated", func() {
+			rotated, err := sessionDataStore.Rotate(sessions[0].ID, uuid.NewString(), uuid.NewString(), now.Add(2*time.Hour), now)
+			Expect(err).To(BeNil())
+			Expect(rotated).To(BeFalse())
+		})
+
+		It("should not rotate the revoked session", func() {
+			rotated, err := sessionDataStore.Rotate(sessions[5].ID, sessions[5].RefreshTokenID, uuid.NewString(), now.Add(2*time.Hour), now)
+			Expect(err).To(BeNil())
+			Expect(rotated).To(BeFalse())
+		})
+	})
+
+	Context("Revoke", func() {
+		It("should revoke the session", func() {
+			Expect(sessionDataStore.Revoke(sessions[0].ID, now)).To(Succeed())
+			var s datastore.Session
+			Expect(db.First(&s, "id = ?", sessions[0].ID).Error).To(Succeed())
+			Expect(s.RevokedAt).ToNot(BeNil())
+		})
+	})
+
+	Context("RevokeAllByUser", func() {
+		It("should revoke only the active sessions of the user with the role", func() {
+			ids, err := sessionDataStore.RevokeAllByUser(userID, "Patient", now)
+			Expect(err).To(BeNil())
+			Expect(ids).To(ConsistOf(sessions[0].ID, sessions[1].ID))
+			var active []datastore.Session
+			Expect(db.Where("revoked_at IS NULL").Find(&active).Error).To(Succeed())
+			Expect(active).To(HaveLen(3))
+		})
+
+		It("should return empty when the user has no active session", func() {
+			ids, err := sessionDataStore.RevokeAllByUser(getRandomID(), "Doctor", now)
+			Expect(err).To(BeNil())
+			Expect(ids).To(BeEmpty())
+		})
+	})
+})
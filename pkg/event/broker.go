@@ -0,0 +1,36 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type Type string
+
+const (
+	NotificationType Type = "notification"
+	UnreadCountType  Type = "unread_count"
+	RoomOpenedType   Type = "room_opened"
+)
+
+// Event is the live event delivered to the patient. Data is the JSON encoded payload of the event type
+type Event struct {
+	Type Type            `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type UnreadCount struct {
+	Count int `json:"count"`
+}
+
+type RoomOpened struct {
+	AppointmentID string `json:"appointment_id"`
+	RoomID        string `json:"room_id"`
+}
+
+// Broker fans out the events to every subscriber of the patient, regardless of which replica they are connected to
+type Broker interface {
+	Publish(ctx context.Context, patientID uint, eventType Type, data interface{}) error
+	// Subscribe returns the channel of the patient's events which is closed when the context is done
+	Subscribe(ctx context.Context, patientID uint) (<-chan *Event, error)
+}
@@ -0,0 +1,52 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+)
+
+// CacheBroker delivers the events through the pub/sub of the cache
+type CacheBroker struct {
+	cacheClient cache.Client
+}
+
+func NewCacheBroker(cacheClient cache.Client) *CacheBroker {
+	return &CacheBroker{cacheClient: cacheClient}
+}
+
+func (b CacheBroker) Publish(ctx context.Context, patientID uint, eventType Type, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	message, err := json.Marshal(&Event{Type: eventType, Data: payload})
+	if err != nil {
+		return err
+	}
+	return b.cacheClient.Publish(ctx, cache.PatientEventChannel(patientID), string(message))
+}
+
+func (b CacheBroker) Subscribe(ctx context.Context, patientID uint) (<-chan *Event, error) {
+	messages, err := b.cacheClient.Subscribe(ctx, cache.PatientEventChannel(patientID))
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan *Event)
+	go func() {
+		defer close(events)
+		for message := range messages {
+			var e Event
+			// Skip the malformed message instead of breaking the stream
+			if err := json.Unmarshal([]byte(message), &e); err != nil {
+				continue
+			}
+			select {
+			case events <- &e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
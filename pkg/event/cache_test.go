@@ -0,0 +1,73 @@
+package event_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/event"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
+	"math/rand"
+)
+
+var _ = Describe("Cache Event Broker", func() {
+	var (
+		mockCtrl        *gomock.Controller
+		mockCacheClient *mock_cache_client.MockClient
+		broker          *event.CacheBroker
+		ctx             context.Context
+		patientID       uint
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
+		broker = event.NewCacheBroker(mockCacheClient)
+		ctx = context.Background()
+		patientID = uint(rand.Uint32())
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("Publish", func() {
+		It("should publish the encoded event to the patient channel", func() {
+			data := &event.UnreadCount{Count: 3}
+			mockCacheClient.EXPECT().Publish(ctx, cache.PatientEventChannel(patientID), `{"type":"unread_count","data":{"count":3}}`).Return(nil).Times(1)
+			Expect(broker.Publish(ctx, patientID, event.UnreadCountType, data)).To(Succeed())
+		})
+		It("should return error when publish error", func() {
+			mockCacheClient.EXPECT().Publish(ctx, cache.PatientEventChannel(patientID), gomock.Any()).Return(testhelper.MockError).Times(1)
+			Expect(broker.Publish(ctx, patientID, event.UnreadCountType, &event.UnreadCount{})).To(Equal(testhelper.MockError))
+		})
+	})
+
+	Context("Subscribe", func() {
+		It("should return error when subscribe error", func() {
+			mockCacheClient.EXPECT().Subscribe(ctx, cache.PatientEventChannel(patientID)).Return(nil, testhelper.MockError).Times(1)
+			_, err := broker.Subscribe(ctx, patientID)
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+		It("should decode the messages and skip the malformed one", func() {
+			messages := make(chan string, 2)
+			messages <- "not a json"
+			messages <- `{"type":"room_opened","data":{"appointment_id":"1","room_id":"room"}}`
+			close(messages)
+			mockCacheClient.EXPECT().Subscribe(ctx, cache.PatientEventChannel(patientID)).Return((<-chan string)(messages), nil).Times(1)
+
+			events, err := broker.Subscribe(ctx, patientID)
+			Expect(err).To(BeNil())
+			var e *event.Event
+			Eventually(events).Should(Receive(&e))
+			Expect(e.Type).To(Equal(event.RoomOpenedType))
+			var roomOpened event.RoomOpened
+			Expect(json.Unmarshal(e.Data, &roomOpened)).To(Succeed())
+			Expect(roomOpened).To(Equal(event.RoomOpened{AppointmentID: "1", RoomID: "room"}))
+			Eventually(events).Should(BeClosed())
+		})
+	})
+})
@@ -115,6 +115,11 @@ type Invoice struct {
 	Total            float64            `json:"total"`
 	Paid             bool               `json:"paid"`
 }
+type PatientInvoice struct {
+	CreatedAt     time.Time `json:"created_at"`
+	AppointmentID string    `json:"appointment_id"`
+	Invoice
+}
 type InvoiceItem struct {
 	Name     string  `json:"name"`
 	Price    float64 `json:"price"`
@@ -1497,6 +1497,30 @@ func (v *__assertDoctorCredentialInput) GetPassword() string { return v.Password
 // GetUsername returns __assertDoctorCredentialInput.Username, and is useful for accessing the field via an interface.
 func (v *__assertDoctorCredentialInput) GetUsername() string { return v.Username }
 
+// __createAppointmentInput is used internally by genqlient
+type __createAppointmentInput struct {
+	PatientId     string    `json:"patientId"`
+	DoctorId      int       `json:"doctorId"`
+	StartDateTime time.Time `json:"startDateTime"`
+	EndDateTime   time.Time `json:"endDateTime"`
+	Detail        string    `json:"detail"`
+}
+
+// GetPatientId returns __createAppointmentInput.PatientId, and is useful for accessing the field via an interface.
+func (v *__createAppointmentInput) GetPatientId() string { return v.PatientId }
+
+// GetDoctorId returns __createAppointmentInput.DoctorId, and is useful for accessing the field via an interface.
+func (v *__createAppointmentInput) GetDoctorId() int { return v.DoctorId }
+
+// GetStartDateTime returns __createAppointmentInput.StartDateTime, and is useful for accessing the field via an interface.
+func (v *__createAppointmentInput) GetStartDateTime() time.Time { return v.StartDateTime }
+
+// GetEndDateTime returns __createAppointmentInput.EndDateTime, and is useful for accessing the field via an interface.
+func (v *__createAppointmentInput) GetEndDateTime() time.Time { return v.EndDateTime }
+
+// GetDetail returns __createAppointmentInput.Detail, and is useful for accessing the field via an interface.
+func (v *__createAppointmentInput) GetDetail() string { return v.Detail }
+
 // __getAppointmentIdsInput is used internally by genqlient
 type __getAppointmentIdsInput struct {
 	Where *AppointmentWhereInput `json:"where,omitempty"`
@@ -1565,6 +1589,18 @@ type __getDoctorInput struct {
 // GetWhere returns __getDoctorInput.Where, and is useful for accessing the field via an interface.
 func (v *__getDoctorInput) GetWhere() *DoctorWhereInput { return v.Where }
 
+// __getDoctorsInput is used internally by genqlient
+type __getDoctorsInput struct {
+	Where   *DoctorWhereInput                 `json:"where,omitempty"`
+	OrderBy []*DoctorOrderByWithRelationInput `json:"orderBy,omitempty"`
+}
+
+// GetWhere returns __getDoctorsInput.Where, and is useful for accessing the field via an interface.
+func (v *__getDoctorsInput) GetWhere() *DoctorWhereInput { return v.Where }
+
+// GetOrderBy returns __getDoctorsInput.OrderBy, and is useful for accessing the field via an interface.
+func (v *__getDoctorsInput) GetOrderBy() []*DoctorOrderByWithRelationInput { return v.OrderBy }
+
 // __getInvoiceInput is used internally by genqlient
 type __getInvoiceInput struct {
 	Where *InvoiceWhereInput `json:"where,omitempty"`
@@ -1573,6 +1609,26 @@ type __getInvoiceInput struct {
 // GetWhere returns __getInvoiceInput.Where, and is useful for accessing the field via an interface.
 func (v *__getInvoiceInput) GetWhere() *InvoiceWhereInput { return v.Where }
 
+// __getInvoicesAppointmentInput is used internally by genqlient
+type __getInvoicesAppointmentInput struct {
+	Where *InvoiceWhereInput `json:"where,omitempty"`
+}
+
+// GetWhere returns __getInvoicesAppointmentInput.Where, and is useful for accessing the field via an interface.
+func (v *__getInvoicesAppointmentInput) GetWhere() *InvoiceWhereInput { return v.Where }
+
+// __getInvoicesInput is used internally by genqlient
+type __getInvoicesInput struct {
+	Where   *InvoiceWhereInput                 `json:"where,omitempty"`
+	OrderBy []*InvoiceOrderByWithRelationInput `json:"orderBy,omitempty"`
+}
+
+// GetWhere returns __getInvoicesInput.Where, and is useful for accessing the field via an interface.
+func (v *__getInvoicesInput) GetWhere() *InvoiceWhereInput { return v.Where }
+
+// GetOrderBy returns __getInvoicesInput.OrderBy, and is useful for accessing the field via an interface.
+func (v *__getInvoicesInput) GetOrderBy() []*InvoiceOrderByWithRelationInput { return v.OrderBy }
+
 // __getPatientInput is used internally by genqlient
 type __getPatientInput struct {
 	Where *PatientWhereInput `json:"where,omitempty"`
@@ -1613,6 +1669,84 @@ func (v *assertDoctorCredentialResponse) GetAssertDoctorPassword() bool {
 	return v.AssertDoctorPassword
 }
 
+// createAppointmentCreateAppointment includes the requested fields of the GraphQL type Appointment.
+type createAppointmentCreateAppointment struct {
+	Id              string                                    `json:"id"`
+	PatientId       string                                    `json:"patientId"`
+	StartDateTime   time.Time                                 `json:"startDateTime"`
+	EndDateTime     time.Time                                 `json:"endDateTime"`
+	Detail          string                                    `json:"detail"`
+	Status          AppointmentStatus                         `json:"status"`
+	NextAppointment *time.Time                                `json:"nextAppointment"`
+	Doctor          *createAppointmentCreateAppointmentDoctor `json:"doctor"`
+}
+
+// GetId returns createAppointmentCreateAppointment.Id, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetId() string { return v.Id }
+
+// GetPatientId returns createAppointmentCreateAppointment.PatientId, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetPatientId() string { return v.PatientId }
+
+// GetStartDateTime returns createAppointmentCreateAppointment.StartDateTime, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetStartDateTime() time.Time { return v.StartDateTime }
+
+// GetEndDateTime returns createAppointmentCreateAppointment.EndDateTime, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetEndDateTime() time.Time { return v.EndDateTime }
+
+// GetDetail returns createAppointmentCreateAppointment.Detail, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetDetail() string { return v.Detail }
+
+// GetStatus returns createAppointmentCreateAppointment.Status, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetStatus() AppointmentStatus { return v.Status }
+
+// GetNextAppointment returns createAppointmentCreateAppointment.NextAppointment, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetNextAppointment() *time.Time {
+	return v.NextAppointment
+}
+
+// GetDoctor returns createAppointmentCreateAppointment.Doctor, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointment) GetDoctor() *createAppointmentCreateAppointmentDoctor {
+	return v.Doctor
+}
+
+// createAppointmentCreateAppointmentDoctor includes the requested fields of the GraphQL type Doctor.
+type createAppointmentCreateAppointmentDoctor struct {
+	Id            string `json:"id"`
+	Initial_en    string `json:"initial_en"`
+	Firstname_en  string `json:"firstname_en"`
+	Lastname_en   string `json:"lastname_en"`
+	Position      string `json:"position"`
+	ProfilePicURL string `json:"profilePicURL"`
+}
+
+// GetId returns createAppointmentCreateAppointmentDoctor.Id, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointmentDoctor) GetId() string { return v.Id }
+
+// GetInitial_en returns createAppointmentCreateAppointmentDoctor.Initial_en, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointmentDoctor) GetInitial_en() string { return v.Initial_en }
+
+// GetFirstname_en returns createAppointmentCreateAppointmentDoctor.Firstname_en, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointmentDoctor) GetFirstname_en() string { return v.Firstname_en }
+
+// GetLastname_en returns createAppointmentCreateAppointmentDoctor.Lastname_en, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointmentDoctor) GetLastname_en() string { return v.Lastname_en }
+
+// GetPosition returns createAppointmentCreateAppointmentDoctor.Position, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointmentDoctor) GetPosition() string { return v.Position }
+
+// GetProfilePicURL returns createAppointmentCreateAppointmentDoctor.ProfilePicURL, and is useful for accessing the field via an interface.
+func (v *createAppointmentCreateAppointmentDoctor) GetProfilePicURL() string { return v.ProfilePicURL }
+
+// createAppointmentResponse is returned by createAppointment on success.
+type createAppointmentResponse struct {
+	CreateAppointment *createAppointmentCreateAppointment `json:"createAppointment"`
+}
+
+// GetCreateAppointment returns createAppointmentResponse.CreateAppointment, and is useful for accessing the field via an interface.
+func (v *createAppointmentResponse) GetCreateAppointment() *createAppointmentCreateAppointment {
+	return v.CreateAppointment
+}
+
 // getAppointmentAppointment includes the requested fields of the GraphQL type Appointment.
 type getAppointmentAppointment struct {
 	Id              string                                                `json:"id"`
@@ -2215,6 +2349,42 @@ type getDoctorResponse struct {
 // GetDoctor returns getDoctorResponse.Doctor, and is useful for accessing the field via an interface.
 func (v *getDoctorResponse) GetDoctor() *getDoctorDoctor { return v.Doctor }
 
+// getDoctorsDoctorsDoctor includes the requested fields of the GraphQL type Doctor.
+type getDoctorsDoctorsDoctor struct {
+	Id            string `json:"id"`
+	Initial_en    string `json:"initial_en"`
+	Firstname_en  string `json:"firstname_en"`
+	Lastname_en   string `json:"lastname_en"`
+	Position      string `json:"position"`
+	ProfilePicURL string `json:"profilePicURL"`
+}
+
+// GetId returns getDoctorsDoctorsDoctor.Id, and is useful for accessing the field via an interface.
+func (v *getDoctorsDoctorsDoctor) GetId() string { return v.Id }
+
+// GetInitial_en returns getDoctorsDoctorsDoctor.Initial_en, and is useful for accessing the field via an interface.
+func (v *getDoctorsDoctorsDoctor) GetInitial_en() string { return v.Initial_en }
+
+// GetFirstname_en returns getDoctorsDoctorsDoctor.Firstname_en, and is useful for accessing the field via an interface.
+func (v *getDoctorsDoctorsDoctor) GetFirstname_en() string { return v.Firstname_en }
+
+// GetLastname_en returns getDoctorsDoctorsDoctor.Lastname_en, and is useful for accessing the field via an interface.
+func (v *getDoctorsDoctorsDoctor) GetLastname_en() string { return v.Lastname_en }
+
+// GetPosition returns getDoctorsDoctorsDoctor.Position, and is useful for accessing the field via an interface.
+func (v *getDoctorsDoctorsDoctor) GetPosition() string { return v.Position }
+
+// GetProfilePicURL returns getDoctorsDoctorsDoctor.ProfilePicURL, and is useful for accessing the field via an interface.
+func (v *getDoctorsDoctorsDoctor) GetProfilePicURL() string { return v.ProfilePicURL }
+
+// getDoctorsResponse is returned by getDoctors on success.
+type getDoctorsResponse struct {
+	Doctors []*getDoctorsDoctorsDoctor `json:"doctors"`
+}
+
+// GetDoctors returns getDoctorsResponse.Doctors, and is useful for accessing the field via an interface.
+func (v *getDoctorsResponse) GetDoctors() []*getDoctorsDoctorsDoctor { return v.Doctors }
+
 // getInvoiceInvoice includes the requested fields of the GraphQL type Invoice.
 type getInvoiceInvoice struct {
 	CreatedAt       time.Time                           `json:"createdAt"`
@@ -2277,6 +2447,214 @@ type getInvoiceResponse struct {
 // GetInvoice returns getInvoiceResponse.Invoice, and is useful for accessing the field via an interface.
 func (v *getInvoiceResponse) GetInvoice() *getInvoiceInvoice { return v.Invoice }
 
+// getInvoicesAppointmentInvoicesInvoice includes the requested fields of the GraphQL type Invoice.
+type getInvoicesAppointmentInvoicesInvoice struct {
+	Id          string                                            `json:"id"`
+	Appointment *getInvoicesAppointmentInvoicesInvoiceAppointment `json:"appointment"`
+}
+
+// GetId returns getInvoicesAppointmentInvoicesInvoice.Id, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoice) GetId() string { return v.Id }
+
+// GetAppointment returns getInvoicesAppointmentInvoicesInvoice.Appointment, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoice) GetAppointment() *getInvoicesAppointmentInvoicesInvoiceAppointment {
+	return v.Appointment
+}
+
+// getInvoicesAppointmentInvoicesInvoiceAppointment includes the requested fields of the GraphQL type Appointment.
+type getInvoicesAppointmentInvoicesInvoiceAppointment struct {
+	Id            string                                                   `json:"id"`
+	StartDateTime time.Time                                                `json:"startDateTime"`
+	EndDateTime   time.Time                                                `json:"endDateTime"`
+	Status        AppointmentStatus                                        `json:"status"`
+	Detail        string                                                   `json:"detail"`
+	Doctor        *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor  `json:"doctor"`
+	Patient       *getInvoicesAppointmentInvoicesInvoiceAppointmentPatient `json:"patient"`
+}
+
+// GetId returns getInvoicesAppointmentInvoicesInvoiceAppointment.Id, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointment) GetId() string { return v.Id }
+
+// GetStartDateTime returns getInvoicesAppointmentInvoicesInvoiceAppointment.StartDateTime, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointment) GetStartDateTime() time.Time {
+	return v.StartDateTime
+}
+
+// GetEndDateTime returns getInvoicesAppointmentInvoicesInvoiceAppointment.EndDateTime, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointment) GetEndDateTime() time.Time {
+	return v.EndDateTime
+}
+
+// GetStatus returns getInvoicesAppointmentInvoicesInvoiceAppointment.Status, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointment) GetStatus() AppointmentStatus {
+	return v.Status
+}
+
+// GetDetail returns getInvoicesAppointmentInvoicesInvoiceAppointment.Detail, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointment) GetDetail() string { return v.Detail }
+
+// GetDoctor returns getInvoicesAppointmentInvoicesInvoiceAppointment.Doctor, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointment) GetDoctor() *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor {
+	return v.Doctor
+}
+
+// GetPatient returns getInvoicesAppointmentInvoicesInvoiceAppointment.Patient, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointment) GetPatient() *getInvoicesAppointmentInvoicesInvoiceAppointmentPatient {
+	return v.Patient
+}
+
+// getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor includes the requested fields of the GraphQL type Doctor.
+type getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor struct {
+	Id            string `json:"id"`
+	Initial_en    string `json:"initial_en"`
+	Firstname_en  string `json:"firstname_en"`
+	Lastname_en   string `json:"lastname_en"`
+	Position      string `json:"position"`
+	ProfilePicURL string `json:"profilePicURL"`
+}
+
+// GetId returns getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor.Id, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor) GetId() string { return v.Id }
+
+// GetInitial_en returns getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor.Initial_en, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor) GetInitial_en() string {
+	return v.Initial_en
+}
+
+// GetFirstname_en returns getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor.Firstname_en, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor) GetFirstname_en() string {
+	return v.Firstname_en
+}
+
+// GetLastname_en returns getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor.Lastname_en, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor) GetLastname_en() string {
+	return v.Lastname_en
+}
+
+// GetPosition returns getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor.Position, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor) GetPosition() string {
+	return v.Position
+}
+
+// GetProfilePicURL returns getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor.ProfilePicURL, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentDoctor) GetProfilePicURL() string {
+	return v.ProfilePicURL
+}
+
+// getInvoicesAppointmentInvoicesInvoiceAppointmentPatient includes the requested fields of the GraphQL type Patient.
+type getInvoicesAppointmentInvoicesInvoiceAppointmentPatient struct {
+	Id            string `json:"id"`
+	Initial_en    string `json:"initial_en"`
+	Firstname_en  string `json:"firstname_en"`
+	Lastname_en   string `json:"lastname_en"`
+	ProfilePicURL string `json:"profilePicURL"`
+}
+
+// GetId returns getInvoicesAppointmentInvoicesInvoiceAppointmentPatient.Id, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentPatient) GetId() string { return v.Id }
+
+// GetInitial_en returns getInvoicesAppointmentInvoicesInvoiceAppointmentPatient.Initial_en, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentPatient) GetInitial_en() string {
+	return v.Initial_en
+}
+
+// GetFirstname_en returns getInvoicesAppointmentInvoicesInvoiceAppointmentPatient.Firstname_en, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentPatient) GetFirstname_en() string {
+	return v.Firstname_en
+}
+
+// GetLastname_en returns getInvoicesAppointmentInvoicesInvoiceAppointmentPatient.Lastname_en, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentPatient) GetLastname_en() string {
+	return v.Lastname_en
+}
+
+// GetProfilePicURL returns getInvoicesAppointmentInvoicesInvoiceAppointmentPatient.ProfilePicURL, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentInvoicesInvoiceAppointmentPatient) GetProfilePicURL() string {
+	return v.ProfilePicURL
+}
+
+// getInvoicesAppointmentResponse is returned by getInvoicesAppointment on success.
+type getInvoicesAppointmentResponse struct {
+	Invoices []*getInvoicesAppointmentInvoicesInvoice `json:"invoices"`
+}
+
+// GetInvoices returns getInvoicesAppointmentResponse.Invoices, and is useful for accessing the field via an interface.
+func (v *getInvoicesAppointmentResponse) GetInvoices() []*getInvoicesAppointmentInvoicesInvoice {
+	return v.Invoices
+}
+
+// getInvoicesInvoicesInvoice includes the requested fields of the GraphQL type Invoice.
+type getInvoicesInvoicesInvoice struct {
+	Id              string                                               `json:"id"`
+	CreatedAt       time.Time                                            `json:"createdAt"`
+	Total           float64                                              `json:"total"`
+	Paid            bool                                                 `json:"paid"`
+	AppointmentId   int                                                  `json:"appointmentId"`
+	InvoiceItems    []*getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem `json:"invoiceItems"`
+	InvoiceDiscount []*getInvoicesInvoicesInvoiceInvoiceDiscount         `json:"InvoiceDiscount"`
+}
+
+// GetId returns getInvoicesInvoicesInvoice.Id, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoice) GetId() string { return v.Id }
+
+// GetCreatedAt returns getInvoicesInvoicesInvoice.CreatedAt, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoice) GetCreatedAt() time.Time { return v.CreatedAt }
+
+// GetTotal returns getInvoicesInvoicesInvoice.Total, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoice) GetTotal() float64 { return v.Total }
+
+// GetPaid returns getInvoicesInvoicesInvoice.Paid, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoice) GetPaid() bool { return v.Paid }
+
+// GetAppointmentId returns getInvoicesInvoicesInvoice.AppointmentId, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoice) GetAppointmentId() int { return v.AppointmentId }
+
+// GetInvoiceItems returns getInvoicesInvoicesInvoice.InvoiceItems, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoice) GetInvoiceItems() []*getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem {
+	return v.InvoiceItems
+}
+
+// GetInvoiceDiscount returns getInvoicesInvoicesInvoice.InvoiceDiscount, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoice) GetInvoiceDiscount() []*getInvoicesInvoicesInvoiceInvoiceDiscount {
+	return v.InvoiceDiscount
+}
+
+// getInvoicesInvoicesInvoiceInvoiceDiscount includes the requested fields of the GraphQL type InvoiceDiscount.
+type getInvoicesInvoicesInvoiceInvoiceDiscount struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// GetName returns getInvoicesInvoicesInvoiceInvoiceDiscount.Name, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoiceInvoiceDiscount) GetName() string { return v.Name }
+
+// GetAmount returns getInvoicesInvoicesInvoiceInvoiceDiscount.Amount, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoiceInvoiceDiscount) GetAmount() float64 { return v.Amount }
+
+// getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem includes the requested fields of the GraphQL type InvoiceItem.
+type getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem struct {
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+}
+
+// GetName returns getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem.Name, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem) GetName() string { return v.Name }
+
+// GetPrice returns getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem.Price, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem) GetPrice() float64 { return v.Price }
+
+// GetQuantity returns getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem.Quantity, and is useful for accessing the field via an interface.
+func (v *getInvoicesInvoicesInvoiceInvoiceItemsInvoiceItem) GetQuantity() int { return v.Quantity }
+
+// getInvoicesResponse is returned by getInvoices on success.
+type getInvoicesResponse struct {
+	Invoices []*getInvoicesInvoicesInvoice `json:"invoices"`
+}
+
+// GetInvoices returns getInvoicesResponse.Invoices, and is useful for accessing the field via an interface.
+func (v *getInvoicesResponse) GetInvoices() []*getInvoicesInvoicesInvoice { return v.Invoices }
+
 // getPatientPatient includes the requested fields of the GraphQL type Patient.
 type getPatientPatient struct {
 	UpdatedAt     time.Time `json:"updatedAt"`
@@ -2445,6 +2823,60 @@ query assertDoctorCredential ($password: String!, $username: String!) {
 	return &data, err
 }
 
+func createAppointment(
+	ctx context.Context,
+	client graphql.Client,
+	patientId string,
+	doctorId int,
+	startDateTime time.Time,
+	endDateTime time.Time,
+	detail string,
+) (*createAppointmentResponse, error) {
+	req := &graphql.Request{
+		OpName: "createAppointment",
+		Query: `
+mutation createAppointment ($patientId: String!, $doctorId: Int!, $startDateTime: DateTime!, $endDateTime: DateTime!, $detail: String!) {
+	createAppointment(appointment: {patient:{connect:{id:$patientId}},doctor:{connect:{id:$doctorId}},startDateTime:$startDateTime,endDateTime:$endDateTime,detail:$detail,status:SCHEDULED}) {
+		id
+		patientId
+		startDateTime
+		endDateTime
+		detail
+		status
+		nextAppointment
+		doctor {
+			id
+			initial_en
+			firstname_en
+			lastname_en
+			position
+			profilePicURL
+		}
+	}
+}
+`,
+		Variables: &__createAppointmentInput{
+			PatientId:     patientId,
+			DoctorId:      doctorId,
+			StartDateTime: startDateTime,
+			EndDateTime:   endDateTime,
+			Detail:        detail,
+		},
+	}
+	var err error
+
+	var data createAppointmentResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
 func getAppointment(
 	ctx context.Context,
 	client graphql.Client,
@@ -2755,6 +3187,45 @@ query getDoctorAppointment ($where: AppointmentWhereInput!) {
 	return &data, err
 }
 
+func getDoctors(
+	ctx context.Context,
+	client graphql.Client,
+	where *DoctorWhereInput,
+	orderBy []*DoctorOrderByWithRelationInput,
+) (*getDoctorsResponse, error) {
+	req := &graphql.Request{
+		OpName: "getDoctors",
+		Query: `
+query getDoctors ($where: DoctorWhereInput, $orderBy: [DoctorOrderByWithRelationInput!]) {
+	doctors(where: $where, orderBy: $orderBy) {
+		id
+		initial_en
+		firstname_en
+		lastname_en
+		position
+		profilePicURL
+	}
+}
+`,
+		Variables: &__getDoctorsInput{
+			Where:   where,
+			OrderBy: orderBy,
+		},
+	}
+	var err error
+
+	var data getDoctorsResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
 func getInvoice(
 	ctx context.Context,
 	client graphql.Client,
@@ -2798,6 +3269,107 @@ query getInvoice ($where: InvoiceWhereInput!) {
 	return &data, err
 }
 
+func getInvoices(
+	ctx context.Context,
+	client graphql.Client,
+	where *InvoiceWhereInput,
+	orderBy []*InvoiceOrderByWithRelationInput,
+) (*getInvoicesResponse, error) {
+	req := &graphql.Request{
+		OpName: "getInvoices",
+		Query: `
+query getInvoices ($where: InvoiceWhereInput, $orderBy: [InvoiceOrderByWithRelationInput!]) {
+	invoices(where: $where, orderBy: $orderBy) {
+		id
+		createdAt
+		total
+		paid
+		appointmentId
+		invoiceItems {
+			name
+			price
+			quantity
+		}
+		InvoiceDiscount {
+			name
+			amount
+		}
+	}
+}
+`,
+		Variables: &__getInvoicesInput{
+			Where:   where,
+			OrderBy: orderBy,
+		},
+	}
+	var err error
+
+	var data getInvoicesResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
+func getInvoicesAppointment(
+	ctx context.Context,
+	client graphql.Client,
+	where *InvoiceWhereInput,
+) (*getInvoicesAppointmentResponse, error) {
+	req := &graphql.Request{
+		OpName: "getInvoicesAppointment",
+		Query: `
+query getInvoicesAppointment ($where: InvoiceWhereInput) {
+	invoices(where: $where) {
+		id
+		appointment {
+			id
+			startDateTime
+			endDateTime
+			status
+			detail
+			doctor {
+				id
+				initial_en
+				firstname_en
+				lastname_en
+				position
+				profilePicURL
+			}
+			patient {
+				id
+				initial_en
+				firstname_en
+				lastname_en
+				profilePicURL
+			}
+		}
+	}
+}
+`,
+		Variables: &__getInvoicesAppointmentInput{
+			Where: where,
+		},
+	}
+	var err error
+
+	var data getInvoicesAppointmentResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
 func getPatient(
 	ctx context.Context,
 	client graphql.Client,
@@ -17,14 +17,25 @@ type SystemClient interface {
 	FindDoctorByUsername(ctx context.Context, username string) (*Doctor, error)
 	FindInvoiceByID(ctx context.Context, id int) (*InvoiceOverview, error)
 	PaidInvoice(ctx context.Context, id int) error
+	// ListAppointmentsByInvoiceIDs returns the appointment overviews mapped by their invoice ID. The invoice which isn't found is omitted
+	ListAppointmentsByInvoiceIDs(ctx context.Context, invoiceIDs []int) (map[int]*AppointmentOverview, error)
+	ListInvoicesByPatientID(ctx context.Context, patientID string) ([]*PatientInvoice, error)
 	ListAppointmentsByPatientID(ctx context.Context, patientID string, since time.Time) ([]*AppointmentOverview, error)
 	ListAppointmentsByDoctorID(ctx context.Context, doctorID string, date time.Time) ([]*AppointmentOverview, error)
+	// ListAppointmentsByDoctorIDInRange lists the appointments of the doctor which aren't cancelled and overlap with the [start, end) time range
+	ListAppointmentsByDoctorIDInRange(ctx context.Context, doctorID string, start, end time.Time) ([]*AppointmentOverview, error)
 	ListAppointmentsWithFilters(ctx context.Context, filters *ListAppointmentsFilters, take, skip int) ([]*AppointmentOverview, error)
 	CountAppointmentsWithFilters(ctx context.Context, filters *ListAppointmentsFilters) (int, error)
 	FindAppointmentByID(ctx context.Context, appointmentID int) (*Appointment, error)
 	FindDoctorAppointmentByID(ctx context.Context, appointmentID int) (*DoctorAppointment, error)
 	SetAppointmentStatus(ctx context.Context, appointmentID int, status SettableAppointmentStatus) error
 	CategorizeAppointmentByStatus(apps []*AppointmentOverview) *CategorizedAppointment
+	FindDoctorByID(ctx context.Context, id string) (*Doctor, error)
+	ListAvailableDoctors(ctx context.Context, start, end time.Time) ([]*DoctorOverview, error)
+	IsDoctorAvailable(ctx context.Context, doctorID string, start, end time.Time, ignoredAppointmentIDs ...int) (bool, error)
+	IsPatientAvailable(ctx context.Context, patientID string, start, end time.Time, ignoredAppointmentIDs ...int) (bool, error)
+	CreateAppointment(ctx context.Context, params *CreateAppointmentParams) (*Appointment, error)
+	RescheduleAppointment(ctx context.Context, appointment *Appointment, start, end time.Time) (*Appointment, error)
 }
 type Config struct {
 	HospitalSysEndpoint string `env:"HOSPITAL_SYS_ENDPOINT,required"`
@@ -89,7 +100,20 @@ func (c GraphQLClient) AssertDoctorCredential(ctx context.Context, username, pas
 }
 
 func (c GraphQLClient) FindDoctorByUsername(ctx context.Context, username string) (*Doctor, error) {
-	resp, err := getDoctor(ctx, c.client, &DoctorWhereInput{Username: &StringFilter{Equals: &username}})
+	return c.getAndParseDoctor(ctx, &DoctorWhereInput{Username: &StringFilter{Equals: &username}})
+}
+
+func (c GraphQLClient) FindDoctorByID(ctx context.Context, id string) (*Doctor, error) {
+	doctorIDInt64, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	doctorIDInt := int(doctorIDInt64)
+	return c.getAndParseDoctor(ctx, &DoctorWhereInput{Id: &IntFilter{Equals: &doctorIDInt}})
+}
+
+func (c GraphQLClient) getAndParseDoctor(ctx context.Context, where *DoctorWhereInput) (*Doctor, error) {
+	resp, err := getDoctor(ctx, c.client, where)
 	if err != nil || resp.GetDoctor() == nil {
 		return nil, err
 	}
@@ -134,6 +158,85 @@ func (c GraphQLClient) PaidInvoice(ctx context.Context, id int) error {
 	return err
 }
 
+func (c GraphQLClient) ListAppointmentsByInvoiceIDs(ctx context.Context, invoiceIDs []int) (map[int]*AppointmentOverview, error) {
+	resp, err := getInvoicesAppointment(ctx, c.client, &InvoiceWhereInput{Id: &IntFilter{In: invoiceIDs}})
+	if err != nil {
+		return nil, err
+	}
+	appointments := make(map[int]*AppointmentOverview, len(resp.Invoices))
+	for _, invoice := range resp.Invoices {
+		invoiceID, err := strconv.ParseInt(invoice.Id, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		a := invoice.Appointment
+		appointments[int(invoiceID)] = &AppointmentOverview{
+			Id:            a.Id,
+			StartDateTime: a.StartDateTime,
+			EndDateTime:   a.EndDateTime,
+			Status:        a.Status,
+			Detail:        a.Detail,
+			Doctor: DoctorOverview{
+				ID:            a.Doctor.Id,
+				FullName:      parseFullName(a.Doctor.Initial_en, a.Doctor.Firstname_en, a.Doctor.Lastname_en),
+				Position:      a.Doctor.Position,
+				ProfilePicURL: a.Doctor.ProfilePicURL,
+			},
+			Patient: PatientOverview{
+				ID:            a.Patient.Id,
+				FullName:      parseFullName(a.Patient.Initial_en, a.Patient.Firstname_en, a.Patient.Lastname_en),
+				ProfilePicURL: a.Patient.ProfilePicURL,
+			},
+		}
+	}
+	return appointments, nil
+}
+
+func (c GraphQLClient) ListInvoicesByPatientID(ctx context.Context, patientID string) ([]*PatientInvoice, error) {
+	desc := SortOrderDesc
+	resp, err := getInvoices(ctx, c.client, &InvoiceWhereInput{
+		Appointment: &AppointmentRelationFilter{Is: &AppointmentWhereInput{PatientId: &StringFilter{Equals: &patientID}}},
+	}, []*InvoiceOrderByWithRelationInput{
+		{CreatedAt: &desc},
+	})
+	if err != nil {
+		return nil, err
+	}
+	invoices := make([]*PatientInvoice, len(resp.Invoices))
+	for i, in := range resp.Invoices {
+		invoiceID, err := strconv.ParseInt(in.Id, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		invoice := &PatientInvoice{
+			CreatedAt:     in.CreatedAt,
+			AppointmentID: strconv.Itoa(in.AppointmentId),
+			Invoice: Invoice{
+				Id:               int(invoiceID),
+				Total:            in.Total,
+				Paid:             in.Paid,
+				InvoiceItems:     make([]*InvoiceItem, len(in.InvoiceItems)),
+				InvoiceDiscounts: make([]*InvoiceDiscount, len(in.InvoiceDiscount)),
+			},
+		}
+		for j, it := range in.InvoiceItems {
+			invoice.InvoiceItems[j] = &InvoiceItem{
+				Name:     it.Name,
+				Price:    it.Price,
+				Quantity: it.Quantity,
+			}
+		}
+		for j, dis := range in.InvoiceDiscount {
+			invoice.InvoiceDiscounts[j] = &InvoiceDiscount{
+				Name:   dis.Name,
+				Amount: dis.Amount,
+			}
+		}
+		invoices[i] = invoice
+	}
+	return invoices, nil
+}
+
 func (c GraphQLClient) ListAppointmentsByPatientID(ctx context.Context, patientID string, since time.Time) ([]*AppointmentOverview, error) {
 	desc := SortOrderDesc
 	resp, err := getAppointments(ctx, c.client, &AppointmentWhereInput{
@@ -171,6 +274,27 @@ func (c GraphQLClient) ListAppointmentsByDoctorID(ctx context.Context, doctorID
 	return c.parseHospitalAppointmentToAppointmentOverview(resp.Appointments), nil
 }
 
+func (c GraphQLClient) ListAppointmentsByDoctorIDInRange(ctx context.Context, doctorID string, start, end time.Time) ([]*AppointmentOverview, error) {
+	doctorIDInt64, err := strconv.ParseInt(doctorID, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	doctorIDInt := int(doctorIDInt64)
+	asc := SortOrderAsc
+	resp, err := getAppointments(ctx, c.client, &AppointmentWhereInput{
+		DoctorId:      &IntFilter{Equals: &doctorIDInt},
+		Status:        &EnumAppointmentStatusFilter{NotIn: []AppointmentStatus{AppointmentStatusCancelled}},
+		StartDateTime: &DateTimeFilter{Lt: &end},
+		EndDateTime:   &DateTimeFilter{Gt: &start},
+	}, []*AppointmentOrderByWithRelationInput{
+		{StartDateTime: &asc},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.parseHospitalAppointmentToAppointmentOverview(resp.Appointments), nil
+}
+
 type ListAppointmentsFilters struct {
 	Text      *string           `json:"text" form:"text"`
 	StartDate *time.Time        `json:"start_date" form:"start_date"`
@@ -178,6 +302,11 @@ type ListAppointmentsFilters struct {
 	DoctorID  *string           `swaggerignore:"true"`
 	PatientID *string           `swaggerignore:"true"`
 	Status    AppointmentStatus `json:"status" form:"status" binding:"required,enum" enums:"CANCELLED,COMPLETED,SCHEDULED"`
+
+	// StartAfter and StartBefore select appointments that start within [StartAfter, StartBefore).
+	// When both are set, PatientID and DoctorID can be omitted to list the appointments of everyone.
+	StartAfter  *time.Time `json:"-" form:"-" swaggerignore:"true"`
+	StartBefore *time.Time `json:"-" form:"-" swaggerignore:"true"`
 }
 
 func (c GraphQLClient) ListAppointmentsWithFilters(ctx context.Context, filters *ListAppointmentsFilters, take, skip int) ([]*AppointmentOverview, error) {
@@ -219,7 +348,7 @@ func (c GraphQLClient) parseListAppointmentsFiltersToAppointmentWhereInput(filte
 		}
 		doctorIDInt := int(doctorIDInt64)
 		where.DoctorId = &IntFilter{Equals: &doctorIDInt}
-	} else {
+	} else if filters.StartAfter == nil || filters.StartBefore == nil {
 		return nil, errors.New("neither PatientID nor DoctorID is supplied")
 	}
 	if filters.Text != nil {
@@ -240,6 +369,11 @@ func (c GraphQLClient) parseListAppointmentsFiltersToAppointmentWhereInput(filte
 		endDateTime := time.Date(et.Year(), et.Month(), et.Day(), 23, 59, 59, 0, et.Location())
 		where.StartDateTime = &DateTimeFilter{Gte: &startDateTime, Lt: &endDateTime}
 	}
+	if filters.StartAfter != nil && filters.StartBefore != nil {
+		where.AND = []*AppointmentWhereInput{
+			{StartDateTime: &DateTimeFilter{Gte: filters.StartAfter, Lt: filters.StartBefore}},
+		}
+	}
 	return where, nil
 }
 
@@ -386,6 +520,135 @@ func (c GraphQLClient) FindAppointmentByID(ctx context.Context, appointmentID in
 	return appointment, nil
 }
 
+// scheduledAppointmentsInRangeWhere matches scheduled appointments that overlap with the [start, end) time range
+func scheduledAppointmentsInRangeWhere(start, end time.Time, ignoredAppointmentIDs ...int) *AppointmentWhereInput {
+	status := AppointmentStatusScheduled
+	where := &AppointmentWhereInput{
+		Status:        &EnumAppointmentStatusFilter{Equals: &status},
+		StartDateTime: &DateTimeFilter{Lt: &end},
+		EndDateTime:   &DateTimeFilter{Gt: &start},
+	}
+	if len(ignoredAppointmentIDs) != 0 {
+		where.Id = &IntFilter{NotIn: ignoredAppointmentIDs}
+	}
+	return where
+}
+
+func (c GraphQLClient) ListAvailableDoctors(ctx context.Context, start, end time.Time) ([]*DoctorOverview, error) {
+	asc := SortOrderAsc
+	resp, err := getDoctors(ctx, c.client, &DoctorWhereInput{
+		Appointments: &AppointmentListRelationFilter{None: scheduledAppointmentsInRangeWhere(start, end)},
+	}, []*DoctorOrderByWithRelationInput{{Id: &asc}})
+	if err != nil {
+		return nil, err
+	}
+	doctors := make([]*DoctorOverview, len(resp.Doctors))
+	for i, d := range resp.Doctors {
+		doctors[i] = &DoctorOverview{
+			ID:            d.Id,
+			FullName:      parseFullName(d.Initial_en, d.Firstname_en, d.Lastname_en),
+			Position:      d.Position,
+			ProfilePicURL: d.ProfilePicURL,
+		}
+	}
+	return doctors, nil
+}
+
+func (c GraphQLClient) IsDoctorAvailable(ctx context.Context, doctorID string, start, end time.Time, ignoredAppointmentIDs ...int) (bool, error) {
+	doctorIDInt64, err := strconv.ParseInt(doctorID, 10, 32)
+	if err != nil {
+		return false, err
+	}
+	doctorIDInt := int(doctorIDInt64)
+	where := scheduledAppointmentsInRangeWhere(start, end, ignoredAppointmentIDs...)
+	where.DoctorId = &IntFilter{Equals: &doctorIDInt}
+	return c.isAppointmentNotFound(ctx, where)
+}
+
+func (c GraphQLClient) IsPatientAvailable(ctx context.Context, patientID string, start, end time.Time, ignoredAppointmentIDs ...int) (bool, error) {
+	where := scheduledAppointmentsInRangeWhere(start, end, ignoredAppointmentIDs...)
+	where.PatientId = &StringFilter{Equals: &patientID}
+	return c.isAppointmentNotFound(ctx, where)
+}
+
+func (c GraphQLClient) isAppointmentNotFound(ctx context.Context, where *AppointmentWhereInput) (bool, error) {
+	resp, err := getAppointmentIds(ctx, c.client, where)
+	if err != nil {
+		return false, err
+	}
+	return len(resp.Appointments) == 0, nil
+}
+
+type CreateAppointmentParams struct {
+	StartDateTime time.Time
+	EndDateTime   time.Time
+	PatientID     string
+	DoctorID      string
+	Detail        string
+}
+
+func (c GraphQLClient) CreateAppointment(ctx context.Context, params *CreateAppointmentParams) (*Appointment, error) {
+	doctorIDInt64, err := strconv.ParseInt(params.DoctorID, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := createAppointment(ctx, c.client, params.PatientID, int(doctorIDInt64), params.StartDateTime, params.EndDateTime, params.Detail)
+	if err != nil {
+		return nil, err
+	}
+	app := resp.GetCreateAppointment()
+	return &Appointment{
+		Id:              app.GetId(),
+		PatientID:       app.GetPatientId(),
+		StartDateTime:   app.GetStartDateTime(),
+		EndDateTime:     app.GetEndDateTime(),
+		NextAppointment: app.GetNextAppointment(),
+		Detail:          app.GetDetail(),
+		Status:          app.GetStatus(),
+		Doctor: DoctorOverview{
+			ID:            app.Doctor.GetId(),
+			FullName:      parseFullName(app.Doctor.GetInitial_en(), app.Doctor.GetFirstname_en(), app.Doctor.GetLastname_en()),
+			Position:      app.Doctor.GetPosition(),
+			ProfilePicURL: app.Doctor.GetProfilePicURL(),
+		},
+		Prescriptions: make([]*Prescription, 0),
+	}, nil
+}
+
+// RescheduleAppointment creates a new scheduled appointment with the same patient, doctor and detail in the new time slot, then cancels the original one.
+// The new appointment is cancelled if the original one fails to be cancelled, so the patient doesn't end up with both of them scheduled
+func (c GraphQLClient) RescheduleAppointment(ctx context.Context, appointment *Appointment, start, end time.Time) (*Appointment, error) {
+	appointmentID, err := strconv.ParseInt(appointment.Id, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	newAppointment, err := c.CreateAppointment(ctx, &CreateAppointmentParams{
+		StartDateTime: start,
+		EndDateTime:   end,
+		PatientID:     appointment.PatientID,
+		DoctorID:      appointment.Doctor.ID,
+		Detail:        appointment.Detail,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetAppointmentStatus(ctx, int(appointmentID), SettableAppointmentStatusCancelled); err != nil {
+		if rollbackErr := c.cancelAppointment(ctx, newAppointment.Id); rollbackErr != nil {
+			return nil, fmt.Errorf("%w; failed to cancel the new appointment %s: %v", err, newAppointment.Id, rollbackErr)
+		}
+		return nil, err
+	}
+	return newAppointment, nil
+}
+
+func (c GraphQLClient) cancelAppointment(ctx context.Context, id string) error {
+	appointmentID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return err
+	}
+	return c.SetAppointmentStatus(ctx, int(appointmentID), SettableAppointmentStatusCancelled)
+}
+
 func parseFullName(init, first, last string) string {
 	return fmt.Sprintf("%s %s %s", init, first, last)
 }
@@ -422,6 +685,25 @@ type CategorizedAppointment struct {
 	Completed []*AppointmentOverview `json:"completed"`
 	Scheduled []*AppointmentOverview `json:"scheduled"`
 	Cancelled []*AppointmentOverview `json:"cancelled"`
+	NoShow    []*AppointmentOverview `json:"no_show"`
+}
+
+// SeparateNoShow moves the cancelled appointments whose ID is in noShowIDs to NoShow.
+// The hospital system has no no-show status, so no-show appointments are stored as cancelled.
+func (c *CategorizedAppointment) SeparateNoShow(noShowIDs []string) {
+	isNoShow := make(map[string]bool, len(noShowIDs))
+	for _, id := range noShowIDs {
+		isNoShow[id] = true
+	}
+	cancelled := make([]*AppointmentOverview, 0, len(c.Cancelled))
+	for _, a := range c.Cancelled {
+		if isNoShow[a.Id] {
+			c.NoShow = append(c.NoShow, a)
+		} else {
+			cancelled = append(cancelled, a)
+		}
+	}
+	c.Cancelled = cancelled
 }
 
 func (c GraphQLClient) CategorizeAppointmentByStatus(apps []*AppointmentOverview) *CategorizedAppointment {
@@ -429,6 +711,7 @@ func (c GraphQLClient) CategorizeAppointmentByStatus(apps []*AppointmentOverview
 		Completed: make([]*AppointmentOverview, 0),
 		Scheduled: make([]*AppointmentOverview, 0),
 		Cancelled: make([]*AppointmentOverview, 0),
+		NoShow:    make([]*AppointmentOverview, 0),
 	}
 	for _, a := range apps {
 		switch a.Status {
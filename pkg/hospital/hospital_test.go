@@ -113,6 +113,119 @@ var _ = Describe("Hospital Client", func() {
 		})
 	})
 
+	Context("FindDoctorByID", func() {
+		When("doctor is not found", func() {
+			It("should return nil with no error", func() {
+				doctor, err := graphQLClient.FindDoctorByID(ctx, "9999999")
+				Expect(err).To(BeNil())
+				Expect(doctor).To(BeNil())
+			})
+		})
+
+		When("doctor is found", func() {
+			It("should return doctor", func() {
+				doctor, err := graphQLClient.FindDoctorByID(ctx, "5")
+				Expect(err).To(BeNil())
+				Expect(doctor.Id).To(Equal("5"))
+			})
+		})
+	})
+
+	Context("Appointment availability", func() {
+		var (
+			start time.Time
+			end   time.Time
+		)
+		BeforeEach(func() {
+			start = time.Date(2023, 9, 6, 6, 20, 0, 0, time.UTC)
+			end = start.Add(time.Minute * 30)
+		})
+
+		When("doctor has scheduled appointment in the time slot", func() {
+			It("should return false", func() {
+				isAvailable, err := graphQLClient.IsDoctorAvailable(ctx, "22", start, end)
+				Expect(err).To(BeNil())
+				Expect(isAvailable).To(BeFalse())
+			})
+			It("should not include the doctor in the list of available doctors", func() {
+				doctors, err := graphQLClient.ListAvailableDoctors(ctx, start, end)
+				Expect(err).To(BeNil())
+				Expect(doctors).ToNot(BeEmpty())
+				for _, d := range doctors {
+					Expect(d.ID).ToNot(Equal("22"))
+				}
+			})
+		})
+		When("doctor has no scheduled appointment in the time slot", func() {
+			It("should return true", func() {
+				isAvailable, err := graphQLClient.IsDoctorAvailable(ctx, "22", start.Add(time.Hour), end.Add(time.Hour))
+				Expect(err).To(BeNil())
+				Expect(isAvailable).To(BeTrue())
+			})
+		})
+		When("patient has scheduled appointment in the time slot", func() {
+			It("should return false", func() {
+				isAvailable, err := graphQLClient.IsPatientAvailable(ctx, "HN-209464", start, end)
+				Expect(err).To(BeNil())
+				Expect(isAvailable).To(BeFalse())
+			})
+		})
+		When("patient has no scheduled appointment in the time slot", func() {
+			It("should return true", func() {
+				isAvailable, err := graphQLClient.IsPatientAvailable(ctx, "HN-209464", start.Add(time.Hour), end.Add(time.Hour))
+				Expect(err).To(BeNil())
+				Expect(isAvailable).To(BeTrue())
+			})
+		})
+	})
+
+	Context("CreateAppointment", func() {
+		It("should create scheduled appointment", func() {
+			params := &hospital.CreateAppointmentParams{
+				PatientID:     "HN-209464",
+				DoctorID:      "5",
+				StartDateTime: time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC),
+				EndDateTime:   time.Date(2030, 1, 1, 10, 30, 0, 0, time.UTC),
+				Detail:        uuid.NewString(),
+			}
+			appointment, err := graphQLClient.CreateAppointment(ctx, params)
+			Expect(err).To(BeNil())
+			Expect(appointment.PatientID).To(Equal(params.PatientID))
+			Expect(appointment.Doctor.ID).To(Equal(params.DoctorID))
+			Expect(appointment.Status).To(Equal(hospital.AppointmentStatusScheduled))
+			Expect(appointment.StartDateTime.Equal(params.StartDateTime)).To(BeTrue())
+
+			isAvailable, err := graphQLClient.IsDoctorAvailable(ctx, params.DoctorID, params.StartDateTime, params.EndDateTime)
+			Expect(err).To(BeNil())
+			Expect(isAvailable).To(BeFalse())
+		})
+	})
+
+	Context("RescheduleAppointment", func() {
+		It("should create new appointment in the new time slot and cancel the original one", func() {
+			appointment, err := graphQLClient.FindAppointmentByID(ctx, 24)
+			Expect(err).To(BeNil())
+			start := time.Date(2030, 2, 1, 10, 0, 0, 0, time.UTC)
+			end := start.Add(time.Minute * 30)
+
+			isAvailable, err := graphQLClient.IsPatientAvailable(ctx, appointment.PatientID, appointment.StartDateTime, appointment.EndDateTime, 24)
+			Expect(err).To(BeNil())
+			Expect(isAvailable).To(BeTrue())
+
+			newAppointment, err := graphQLClient.RescheduleAppointment(ctx, appointment, start, end)
+			Expect(err).To(BeNil())
+			Expect(newAppointment.Id).ToNot(Equal(appointment.Id))
+			Expect(newAppointment.PatientID).To(Equal(appointment.PatientID))
+			Expect(newAppointment.Doctor.ID).To(Equal(appointment.Doctor.ID))
+			Expect(newAppointment.Detail).To(Equal(appointment.Detail))
+			Expect(newAppointment.StartDateTime.Equal(start)).To(BeTrue())
+
+			cancelled, err := graphQLClient.FindAppointmentByID(ctx, 24)
+			Expect(err).To(BeNil())
+			Expect(cancelled.Status).To(Equal(hospital.AppointmentStatusCancelled))
+		})
+	})
+
 	Context("FindInvoiceByID", func() {
 		When("invoice not found", func() {
 			It("should return nil with no error", func() {
@@ -139,6 +252,55 @@ var _ = Describe("Hospital Client", func() {
 		})
 	})
 
+	Context("ListAppointmentsByInvoiceIDs", func() {
+		When("no invoice is found", func() {
+			It("should return empty map with no error", func() {
+				appointments, err := graphQLClient.ListAppointmentsByInvoiceIDs(ctx, []int{int(rand.Int31())})
+				Expect(err).To(BeNil())
+				Expect(appointments).To(BeEmpty())
+			})
+		})
+		When("invoices are found", func() {
+			It("should return appointments mapped by invoice ID", func() {
+				appointments, err := graphQLClient.ListAppointmentsByInvoiceIDs(ctx, []int{1, 3})
+				Expect(err).To(BeNil())
+				Expect(appointments).To(HaveLen(2))
+				Expect(appointments[1].Id).To(Equal("2"))
+				Expect(appointments[1].Patient.ID).To(Equal("HN-414878"))
+				Expect(appointments[3].Id).To(Equal("9"))
+			})
+		})
+	})
+
+	Context("ListInvoicesByPatientID", func() {
+		When("no invoice is found", func() {
+			It("should return empty slice with no error", func() {
+				invoices, err := graphQLClient.ListInvoicesByPatientID(ctx, "HN-something")
+				Expect(err).To(BeNil())
+				Expect(invoices).To(BeEmpty())
+			})
+		})
+		When("invoices are found", func() {
+			It("should return the invoices ordered by created time", func() {
+				invoices, err := graphQLClient.ListInvoicesByPatientID(ctx, "HN-285237")
+				Expect(err).To(BeNil())
+				Expect(invoices).To(HaveLen(2))
+				Expect(invoices[0].Id).To(Equal(26))
+				Expect(invoices[0].AppointmentID).To(Equal("98"))
+				Expect(invoices[0].Paid).To(BeFalse())
+				Expect(invoices[1].Id).To(Equal(12))
+				Expect(invoices[1].Paid).To(BeTrue())
+			})
+			It("should list the discounts with the total after the discounts", func() {
+				invoices, err := graphQLClient.ListInvoicesByPatientID(ctx, "HN-796550")
+				Expect(err).To(BeNil())
+				Expect(invoices).To(HaveLen(1))
+				Expect(invoices[0].InvoiceDiscounts).To(HaveLen(1))
+				Expect(invoices[0].Total).To(Equal(2748108.0))
+			})
+		})
+	})
+
 	Context("ListAppointmentsByPatientID", func() {
 		When("no appointment is found", func() {
 			It("should return empty slice with no error", func() {
@@ -178,6 +340,18 @@ var _ = Describe("Hospital Client", func() {
 		})
 	})
 
+	Context("ListAppointmentsByDoctorIDInRange", func() {
+		It("should return the appointments overlapping with the range except the cancelled ones", func() {
+			start := time.Date(2022, 9, 7, 0, 0, 0, 0, time.UTC)
+			appointments, err := graphQLClient.ListAppointmentsByDoctorIDInRange(ctx, "9", start, start.AddDate(0, 0, 2))
+			Expect(err).To(BeNil())
+			Expect(appointments).To(HaveLen(3))
+			for _, a := range appointments {
+				Expect(a.Status).ToNot(Equal(hospital.AppointmentStatusCancelled))
+			}
+		})
+	})
+
 	Context("FindAppointmentByID", func() {
 		When("appointment is not found", func() {
 			It("should return nil with no error", func() {
@@ -259,6 +433,16 @@ var _ = Describe("Hospital Client", func() {
 		})
 	})
 
+	Context("SeparateNoShow", func() {
+		It("should move no-show appointments out of cancelled", func() {
+			cancelled := testhelper.GenerateAppointmentOverviews(hospital.AppointmentStatusCancelled, 3)
+			categorized := &hospital.CategorizedAppointment{Cancelled: cancelled, NoShow: make([]*hospital.AppointmentOverview, 0)}
+			categorized.SeparateNoShow([]string{cancelled[1].Id})
+			Expect(categorized.Cancelled).To(Equal([]*hospital.AppointmentOverview{cancelled[0], cancelled[2]}))
+			Expect(categorized.NoShow).To(Equal([]*hospital.AppointmentOverview{cancelled[1]}))
+		})
+	})
+
 	Context("ReverseSlice", func() {
 		It("should reserve the order of elements", func() {
 			s := []int{1, 2, 3, 4, 5}
@@ -372,6 +556,18 @@ var _ = Describe("Hospital Client", func() {
 					Expect(appointments[0].Id).To(Equal("25"))
 				})
 			})
+			When("PatientID and DoctorID are not set but start time range is set", func() {
+				BeforeEach(func() {
+					startAfter := time.Date(2023, 9, 6, 6, 0, 0, 0, time.UTC)
+					startBefore := startAfter.Add(time.Hour)
+					filters = &hospital.ListAppointmentsFilters{Status: hospital.AppointmentStatusScheduled, StartAfter: &startAfter, StartBefore: &startBefore}
+				})
+				It("should return the appointments that start within the range", func() {
+					Expect(err).To(BeNil())
+					Expect(appointments).To(HaveLen(1))
+					Expect(appointments[0].Id).To(Equal("12"))
+				})
+			})
 		})
 
 		Context("there is no text or date filters", func() {
@@ -5,7 +5,11 @@ import (
 )
 
 type Client interface {
+	// Send pushes the notification to the patient with the ID
 	Send(ctx context.Context, params SendParams, data map[string]string) error
+	// SendToDoctor pushes the notification to the doctor's device token. It's published on the doctor channel,
+	// which is apart from the patient's, so it never reaches the patient who happens to have the same ID
+	SendToDoctor(ctx context.Context, token string, params SendParams, data map[string]string) error
 }
 
 type SendParams struct {
@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+)
+
+// NotifyDoctor pushes the notification to the device of the doctor with the reference ID in the hospital system.
+// It's skipped if the doctor never signed in or has no device token
+func NotifyDoctor(ctx context.Context, client Client, doctorDataStore datastore.DoctorDataStore, doctorRefID, title, body string, data map[string]string) error {
+	doctor, err := doctorDataStore.FindByRefID(doctorRefID)
+	if err != nil {
+		return err
+	}
+	if doctor == nil || doctor.NotificationToken == "" {
+		return nil
+	}
+	params := SendParams{
+		ID:    fmt.Sprintf("%d", doctor.ID),
+		Title: title,
+		Body:  body,
+	}
+	return client.SendToDoctor(ctx, doctor.NotificationToken, params, data)
+}
@@ -15,6 +15,9 @@ type Config struct {
 	QueueName    string `env:"RABBITMQ_NOTIFICATION_QUEUE_NAME" envDefault:"push-notification-queue"`
 	ExchangeName string `env:"RABBITMQ_NOTIFICATION_EXCHANGE_NAME" envDefault:"notification"`
 	RoutingKey   string `env:"RABBITMQ_NOTIFICATION_ROUTING_KEY" envDefault:"push-notification"`
+	// DoctorQueueName and DoctorRoutingKey are of the doctor channel, whose consumer pushes to the device token in the message
+	DoctorQueueName  string `env:"RABBITMQ_DOCTOR_NOTIFICATION_QUEUE_NAME" envDefault:"doctor-push-notification-queue"`
+	DoctorRoutingKey string `env:"RABBITMQ_DOCTOR_NOTIFICATION_ROUTING_KEY" envDefault:"doctor-push-notification"`
 }
 
 func (c Config) GetURL() string {
@@ -22,11 +25,12 @@ func (c Config) GetURL() string {
 }
 
 type RabbitMQNotificationClient struct {
-	exchangeName string
-	routingKey   string
-	connection   *amqp.Connection
-	channel      *amqp.Channel
-	queue        *amqp.Queue
+	exchangeName     string
+	routingKey       string
+	doctorRoutingKey string
+	connection       *amqp.Connection
+	channel          *amqp.Channel
+	queue            *amqp.Queue
 }
 
 func NewRabbitMQNotificationClient(c *Config) (*RabbitMQNotificationClient, error) {
@@ -49,8 +53,15 @@ func NewRabbitMQNotificationClient(c *Config) (*RabbitMQNotificationClient, erro
 	if err := ch.QueueBind(q.Name, c.RoutingKey, c.ExchangeName, false, nil); err != nil {
 		return nil, err
 	}
+	doctorQueue, err := ch.QueueDeclare(c.DoctorQueueName, true, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := ch.QueueBind(doctorQueue.Name, c.DoctorRoutingKey, c.ExchangeName, false, nil); err != nil {
+		return nil, err
+	}
 
-	return &RabbitMQNotificationClient{channel: ch, queue: &q, connection: conn, exchangeName: c.ExchangeName, routingKey: c.RoutingKey}, nil
+	return &RabbitMQNotificationClient{channel: ch, queue: &q, connection: conn, exchangeName: c.ExchangeName, routingKey: c.RoutingKey, doctorRoutingKey: c.DoctorRoutingKey}, nil
 }
 
 func (c *RabbitMQNotificationClient) Close() error {
@@ -62,6 +73,7 @@ func (c *RabbitMQNotificationClient) Close() error {
 
 type payload struct {
 	ID    string            `json:"id,omitempty"`
+	Token string            `json:"token,omitempty"`
 	Title string            `json:"title,omitempty"`
 	Body  string            `json:"body,omitempty"`
 	Data  map[string]string `json:"data,omitempty"`
@@ -77,7 +89,16 @@ func parseSendParamsToPayload(params SendParams, data map[string]string) *payloa
 }
 
 func (c *RabbitMQNotificationClient) Send(ctx context.Context, params SendParams, data map[string]string) error {
+	return c.publish(ctx, c.routingKey, parseSendParamsToPayload(params, data))
+}
+
+func (c *RabbitMQNotificationClient) SendToDoctor(ctx context.Context, token string, params SendParams, data map[string]string) error {
 	payload := parseSendParamsToPayload(params, data)
+	payload.Token = token
+	return c.publish(ctx, c.doctorRoutingKey, payload)
+}
+
+func (c *RabbitMQNotificationClient) publish(ctx context.Context, routingKey string, payload *payload) error {
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -88,5 +109,5 @@ func (c *RabbitMQNotificationClient) Send(ctx context.Context, params SendParams
 		Priority:     0,
 		Body:         payloadJSON,
 	}
-	return c.channel.PublishWithContext(ctx, c.exchangeName, c.routingKey, false, false, msg)
+	return c.channel.PublishWithContext(ctx, c.exchangeName, routingKey, false, false, msg)
 }
@@ -1,17 +1,34 @@
 package payment
 
+import (
+	"math"
+	"time"
+)
+
 type Client interface {
 	CreateCustomer(patientID uint) (string, error)
 	AddCreditCard(customerID, cardToken string) (*Card, error)
 	RemoveCreditCard(customerID, cardID string) error
 	PayWithCreditCard(customerID, cardID, refID string, amount int) (*Payment, error)
+	PayWithPromptPay(refID string, amount int) (*PromptPay, error)
+	Refund(chargeID string, amount int) (*Refund, error)
+	GetCharge(chargeID string) (*Payment, error)
+	ListCharges(from, to time.Time) ([]*Payment, error)
+	ParseWebhookEvent(payload []byte, signature, timestamp string) (*WebhookEvent, error)
+}
+
+// ToSatang converts the amount in baht to satang, the unit of the charge and the refund.
+// It's rounded since the amount in baht isn't exact in float, e.g. 19.99*100 is 1998.9999999999998
+func ToSatang(amount float64) int {
+	return int(math.Round(amount * 100))
 }
 
 type Card struct {
 	ID          string `json:"id"`
 	Last4Digits string `json:"last_4_digits"`
 	Brand       string `json:"brand"`
-	Expiry      string `json:"expiry"`
+	ExpiryMonth int    `json:"expiry_month"`
+	ExpiryYear  int    `json:"expiry_year"`
 }
 
 type Payment struct {
@@ -19,7 +36,32 @@ type Payment struct {
 	FailureCode    *string `json:"failure_code"`
 	Currency       string  `json:"currency"`
 	ID             string  `json:"id"`
+	RefID          string  `json:"ref_id"`
 	Amount         int     `json:"amount"`
 	Paid           bool    `json:"paid"`
 	Success        bool    `json:"success"`
+	Pending        bool    `json:"pending"`
+	// AuthorizeURI is the page of 3-D Secure for the patient to authorize the pending charge
+	AuthorizeURI string `json:"authorize_uri,omitempty"`
+}
+
+// PromptPay is the pending charge which is paid by scanning the QR code before it expires
+type PromptPay struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	ChargeID  string    `json:"charge_id"`
+	QRCodeURI string    `json:"qr_code_uri"`
+	Amount    int       `json:"amount"`
+}
+
+type Refund struct {
+	ID       string `json:"id"`
+	ChargeID string `json:"charge_id"`
+	Currency string `json:"currency"`
+	Amount   int    `json:"amount"`
+}
+
+type WebhookEvent struct {
+	ID       string `json:"id"`
+	Key      string `json:"key"`
+	ChargeID string `json:"charge_id"`
 }
@@ -4,15 +4,27 @@ import (
 	"fmt"
 	"github.com/omise/omise-go"
 	"github.com/omise/omise-go/operations"
+	"net/url"
+	"time"
 )
 
 type Config struct {
 	PublicKey string `env:"OMISE_PUBLIC_KEY,required"`
 	SecretKey string `env:"OMISE_SECRET_KEY,required"`
+	// WebhookSecret is the base64 encoded secret for verifying the webhook signature.
+	// The signature isn't verified if it's empty, so it's only allowed in development mode
+	WebhookSecret string `env:"OMISE_WEBHOOK_SECRET" envDefault:""`
+	// WebhookTolerance is how far the webhook timestamp can be from now, so the captured webhook can't be replayed later
+	WebhookTolerance time.Duration `env:"OMISE_WEBHOOK_TOLERANCE" envDefault:"5m"`
+	// ReturnURI is where the patient is redirected to after 3-D Secure. The ref ID of the charge is appended as ref_id query
+	ReturnURI string `env:"OMISE_RETURN_URI" envDefault:""`
 }
 
 type OmisePaymentClient struct {
-	client *omise.Client
+	client           *omise.Client
+	webhookSecret    string
+	webhookTolerance time.Duration
+	returnURI        string
 }
 
 func NewOmisePaymentClient(c *Config) (*OmisePaymentClient, error) {
@@ -20,7 +32,7 @@ func NewOmisePaymentClient(c *Config) (*OmisePaymentClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &OmisePaymentClient{client: client}, nil
+	return &OmisePaymentClient{client: client, webhookSecret: c.WebhookSecret, webhookTolerance: c.WebhookTolerance, returnURI: c.ReturnURI}, nil
 }
 
 func (c OmisePaymentClient) CreateCustomer(patientID uint) (string, error) {
@@ -59,7 +71,8 @@ func (c OmisePaymentClient) AddCreditCard(customerID, cardToken string) (*Card,
 		ID:          card.ID,
 		Last4Digits: card.LastDigits,
 		Brand:       card.Brand,
-		Expiry:      fmt.Sprintf("%d/%d", int(card.ExpirationMonth), card.ExpirationYear),
+		ExpiryMonth: int(card.ExpirationMonth),
+		ExpiryYear:  card.ExpirationYear,
 	}, nil
 }
 
@@ -72,17 +85,118 @@ func (c OmisePaymentClient) PayWithCreditCard(customerID, cardID, refID string,
 		DontCapture: false,
 		Metadata:    map[string]interface{}{"ref_id": refID},
 	}
+	if c.returnURI != "" {
+		createChargeOps.ReturnURI = fmt.Sprintf("%s?%s", c.returnURI, url.Values{"ref_id": {refID}}.Encode())
+	}
 	if err := c.client.Do(charge, createChargeOps); err != nil {
 		return nil, err
 	}
+	return parseCharge(charge), nil
+}
+
+// promptPayCharge is the charge with the expiry which isn't supported by omise-go
+type promptPayCharge struct {
+	omise.Charge
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PayWithPromptPay creates the PromptPay source and charges it. The charge is pending until the QR code is paid or expired
+func (c OmisePaymentClient) PayWithPromptPay(refID string, amount int) (*PromptPay, error) {
+	source, createSourceOps := &omise.Source{}, &operations.CreateSource{
+		Type:     "promptpay",
+		Amount:   int64(amount),
+		Currency: "THB",
+	}
+	if err := c.client.Do(source, createSourceOps); err != nil {
+		return nil, err
+	}
+	charge, createChargeOps := &promptPayCharge{}, &operations.CreateCharge{
+		Source:   source.ID,
+		Amount:   int64(amount),
+		Currency: "THB",
+		Metadata: map[string]interface{}{"ref_id": refID},
+	}
+	if err := c.client.Do(charge, createChargeOps); err != nil {
+		return nil, err
+	}
+	promptPay := &PromptPay{
+		ChargeID:  charge.ID,
+		Amount:    int(charge.Amount),
+		ExpiresAt: charge.ExpiresAt,
+	}
+	if charge.Source != nil && charge.Source.ScannableCode != nil && charge.Source.ScannableCode.Image != nil {
+		promptPay.QRCodeURI = charge.Source.ScannableCode.Image.DownloadURI
+	}
+	return promptPay, nil
+}
+
+// GetCharge retrieves the latest state of the charge, e.g. after the pending charge is settled
+func (c OmisePaymentClient) GetCharge(chargeID string) (*Payment, error) {
+	charge, retrieveChargeOps := &omise.Charge{}, &operations.RetrieveCharge{ChargeID: chargeID}
+	if err := c.client.Do(charge, retrieveChargeOps); err != nil {
+		return nil, err
+	}
+	return parseCharge(charge), nil
+}
+
+// chargeListPageSize is the maximum page size of Omise's list API
+const chargeListPageSize = 100
+
+// ListCharges returns the charges created within the range from oldest to latest
+func (c OmisePaymentClient) ListCharges(from, to time.Time) ([]*Payment, error) {
+	var payments []*Payment
+	for offset := 0; ; offset += chargeListPageSize {
+		charges, listChargesOps := &omise.ChargeList{}, &operations.ListCharges{
+			List: operations.List{
+				Offset: offset,
+				Limit:  chargeListPageSize,
+				From:   from,
+				To:     to,
+				Order:  omise.Chronological,
+			},
+		}
+		if err := c.client.Do(charges, listChargesOps); err != nil {
+			return nil, err
+		}
+		for _, charge := range charges.Data {
+			payments = append(payments, parseCharge(charge))
+		}
+		if len(charges.Data) < chargeListPageSize {
+			return payments, nil
+		}
+	}
+}
+
+func parseCharge(charge *omise.Charge) *Payment {
+	refID, _ := charge.Metadata["ref_id"].(string)
 	return &Payment{
 		ID:             charge.ID,
+		RefID:          refID,
 		Amount:         int(charge.Amount),
 		Currency:       charge.Currency,
 		Paid:           charge.Paid,
 		Success:        charge.Status == omise.ChargeSuccessful,
+		Pending:        charge.Status == omise.ChargePending,
+		AuthorizeURI:   charge.AuthorizeURI,
 		FailureCode:    charge.FailureCode,
 		FailureMessage: charge.FailureMessage,
+	}
+}
+
+// Refund refunds the amount in satang of the charge. The amount can be less than the charged amount for partial refund
+func (c OmisePaymentClient) Refund(chargeID string, amount int) (*Refund, error) {
+	refund, createRefundOps := &omise.Refund{}, &operations.CreateRefund{
+		ChargeID: chargeID,
+		Amount:   int64(amount),
+	}
+	if err := c.client.Do(refund, createRefundOps); err != nil {
+		return nil, err
+	}
+	return &Refund{
+		ID:       refund.ID,
+		ChargeID: refund.Charge,
+		Currency: refund.Currency,
+		Amount:   int(refund.Amount),
 	}, nil
 }
 
@@ -64,7 +64,8 @@ var _ = Describe("Omise Payment Client", func() {
 			Expect(card.ID).NotTo(BeEmpty())
 			Expect(card.Brand).NotTo(BeEmpty())
 			Expect(card.Last4Digits).NotTo(BeEmpty())
-			Expect(card.Expiry).To(Equal(fmt.Sprintf("12/%d", time.Now().Year())))
+			Expect(card.ExpiryMonth).To(Equal(12))
+			Expect(card.ExpiryYear).To(Equal(time.Now().Year()))
 			Expect(card.Last4Digits).To(Equal("1113"))
 
 			customer, getCustomer := &omise.Customer{}, &operations.RetrieveCustomer{CustomerID: testCustomerID}
@@ -151,6 +152,66 @@ var _ = Describe("Omise Payment Client", func() {
 			Entry("failed_processing", "3530111111170013", "failed_processing"),
 		)
 	})
+
+	Context("Pay with PromptPay", func() {
+		It("should create pending charge with QR code", func() {
+			amount := (rand.Intn(100000)+20)*100 + 99
+			p, err := paymentClient.PayWithPromptPay(fmt.Sprintf("test-ref-%d", rand.Int()), amount)
+			Expect(err).To(BeNil())
+			Expect(p.ChargeID).ToNot(BeEmpty())
+			Expect(p.Amount).To(Equal(amount))
+			Expect(p.QRCodeURI).ToNot(BeEmpty())
+			Expect(p.ExpiresAt).To(BeTemporally(">", time.Now()))
+
+			charge, err := paymentClient.GetCharge(p.ChargeID)
+			Expect(err).To(BeNil())
+			Expect(charge.Pending).To(BeTrue())
+		})
+	})
+
+	Context("List charges", func() {
+		It("should list the charges created within the range", func() {
+			from := time.Now().Add(-time.Minute)
+			token, cardID := createCardToken(client, "4242424242424242")
+			attachCardToCustomer(client, testCustomerID, token)
+			refID := fmt.Sprintf("test-ref-%d", rand.Int())
+			p, err := paymentClient.PayWithCreditCard(testCustomerID, cardID, refID, 10000)
+			Expect(err).To(BeNil())
+
+			charges, err := paymentClient.ListCharges(from, time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			Expect(charges).To(ContainElement(HaveField("ID", p.ID)))
+			Expect(charges).To(ContainElement(HaveField("RefID", refID)))
+		})
+	})
+
+	Context("Refund", func() {
+		var (
+			chargeID string
+			amount   int
+		)
+		BeforeEach(func() {
+			amount = (rand.Intn(100000)+20)*100 + 99
+			token, cardID := createCardToken(client, "4242424242424242")
+			attachCardToCustomer(client, testCustomerID, token)
+			p, err := paymentClient.PayWithCreditCard(testCustomerID, cardID, fmt.Sprintf("test-ref-%d", rand.Int()), amount)
+			Expect(err).To(BeNil())
+			chargeID = p.ID
+		})
+
+		It("should partially refund the charge", func() {
+			r, err := paymentClient.Refund(chargeID, amount/2)
+			Expect(err).To(BeNil())
+			Expect(r.ID).ToNot(BeEmpty())
+			Expect(r.ChargeID).To(Equal(chargeID))
+			Expect(r.Amount).To(Equal(amount / 2))
+		})
+
+		It("should return error when refund more than the charged amount", func() {
+			_, err := paymentClient.Refund(chargeID, amount+100)
+			Expect(err).ToNot(BeNil())
+		})
+	})
 })
 
 func createCardToken(client *omise.Client, number string) (string, string) {
@@ -0,0 +1,87 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+type omiseWebhookEvent struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Data struct {
+		Object string `json:"object"`
+		ID     string `json:"id"`
+	} `json:"data"`
+}
+
+// ParseWebhookEvent verifies the signature of the webhook payload and parses the event.
+// The signature and timestamp are from the Omise-Signature and Omise-Signature-Timestamp header respectively.
+// The timestamp further than the tolerance from now is rejected as the signature is invalid.
+// ChargeID of the event is empty if the event isn't about a charge
+func (c OmisePaymentClient) ParseWebhookEvent(payload []byte, signature, timestamp string) (*WebhookEvent, error) {
+	if c.webhookSecret != "" {
+		if err := verifyWebhookTimestamp(timestamp, time.Now(), c.webhookTolerance); err != nil {
+			return nil, err
+		}
+		if err := verifyWebhookSignature(c.webhookSecret, payload, signature, timestamp); err != nil {
+			return nil, err
+		}
+	}
+	var e omiseWebhookEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, err
+	}
+	event := &WebhookEvent{ID: e.ID, Key: e.Key}
+	if e.Data.Object == "charge" {
+		event.ChargeID = e.Data.ID
+	}
+	return event, nil
+}
+
+// verifyWebhookTimestamp checks that the Unix timestamp in seconds is within the tolerance from now
+func verifyWebhookTimestamp(timestamp string, now time.Time, tolerance time.Duration) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+	diff := now.Sub(time.Unix(sec, 0))
+	if diff > tolerance || diff < -tolerance {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// verifyWebhookSignature checks the HMAC-SHA256 of "timestamp.payload" against the signatures.
+// The header can contain multiple comma separated signatures during the secret rotation
+func verifyWebhookSignature(secret string, payload []byte, signature, timestamp string) error {
+	if signature == "" || timestamp == "" {
+		return ErrInvalidWebhookSignature
+	}
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, s := range strings.Split(signature, ",") {
+		sig, err := hex.DecodeString(strings.TrimSpace(s))
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookSignature
+}
@@ -0,0 +1,85 @@
+package payment_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/payment"
+	"strconv"
+	"time"
+)
+
+var _ = Describe("Omise Webhook", func() {
+	var (
+		paymentClient payment.Client
+		secret        []byte
+		payload       []byte
+		timestamp     string
+	)
+
+	sign := func(key []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(payload)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	BeforeEach(func() {
+		var err error
+		secret = []byte("webhook-secret")
+		paymentClient, err = payment.NewOmisePaymentClient(&payment.Config{
+			PublicKey:        "pkey_test_webhook",
+			SecretKey:        "skey_test_webhook",
+			WebhookSecret:    base64.StdEncoding.EncodeToString(secret),
+			WebhookTolerance: 5 * time.Minute,
+		})
+		Expect(err).To(BeNil())
+		timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		payload = []byte(`{"object":"event","id":"evnt_test_1","key":"charge.complete","data":{"object":"charge","id":"chrg_test_1"}}`)
+	})
+
+	It("should parse the charge event with valid signature", func() {
+		event, err := paymentClient.ParseWebhookEvent(payload, sign(secret), timestamp)
+		Expect(err).To(BeNil())
+		Expect(event).To(Equal(&payment.WebhookEvent{ID: "evnt_test_1", Key: "charge.complete", ChargeID: "chrg_test_1"}))
+	})
+
+	It("should accept any of the comma separated signatures", func() {
+		signature := fmt.Sprintf("%s,%s", sign([]byte("old-secret")), sign(secret))
+		_, err := paymentClient.ParseWebhookEvent(payload, signature, timestamp)
+		Expect(err).To(BeNil())
+	})
+
+	It("should return error when the signature doesn't match", func() {
+		_, err := paymentClient.ParseWebhookEvent(payload, sign([]byte("another-secret")), timestamp)
+		Expect(err).To(Equal(payment.ErrInvalidWebhookSignature))
+	})
+
+	It("should return error when the signature is missing", func() {
+		_, err := paymentClient.ParseWebhookEvent(payload, "", timestamp)
+		Expect(err).To(Equal(payment.ErrInvalidWebhookSignature))
+	})
+
+	It("should return error when the timestamp is stale", func() {
+		timestamp = strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+		_, err := paymentClient.ParseWebhookEvent(payload, sign(secret), timestamp)
+		Expect(err).To(Equal(payment.ErrInvalidWebhookSignature))
+	})
+
+	It("should return error when the timestamp is invalid", func() {
+		timestamp = "yesterday"
+		_, err := paymentClient.ParseWebhookEvent(payload, sign(secret), timestamp)
+		Expect(err).To(Equal(payment.ErrInvalidWebhookSignature))
+	})
+
+	It("should leave charge ID empty when the event isn't about a charge", func() {
+		payload = []byte(`{"object":"event","id":"evnt_test_2","key":"customer.create","data":{"object":"customer","id":"cust_test_1"}}`)
+		event, err := paymentClient.ParseWebhookEvent(payload, sign(secret), timestamp)
+		Expect(err).To(BeNil())
+		Expect(event.ChargeID).To(BeEmpty())
+	})
+})
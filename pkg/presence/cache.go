@@ -0,0 +1,74 @@
+package presence
+
+import (
+	"context"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"time"
+)
+
+type Config struct {
+	TTL time.Duration `env:"PRESENCE_TTL" envDefault:"30s"`
+}
+
+// CacheTracker stores the last seen time of the patient with TTL, so the patient becomes absent when the heartbeat stops
+type CacheTracker struct {
+	cacheClient cache.Client
+	clock       clock.Clock
+	ttl         time.Duration
+}
+
+func NewCacheTracker(config *Config, cacheClient cache.Client, c clock.Clock) *CacheTracker {
+	return &CacheTracker{
+		cacheClient: cacheClient,
+		clock:       c,
+		ttl:         config.TTL,
+	}
+}
+
+func (t CacheTracker) CheckIn(ctx context.Context, appointmentID string) (*State, error) {
+	now := t.clock.Now().UTC()
+	if err := t.cacheClient.Set(ctx, cache.WaitingRoomPresenceKey(appointmentID), now.Format(time.RFC3339), t.ttl); err != nil {
+		return nil, err
+	}
+	return &State{Status: StatusWaiting, LastSeenAt: &now}, nil
+}
+
+func (t CacheTracker) CheckOut(ctx context.Context, appointmentID string) error {
+	return t.cacheClient.Delete(ctx, cache.WaitingRoomPresenceKey(appointmentID))
+}
+
+func (t CacheTracker) Get(ctx context.Context, appointmentID string) (*State, error) {
+	lastSeen, err := t.cacheClient.Get(ctx, cache.WaitingRoomPresenceKey(appointmentID), false)
+	if err != nil {
+		return nil, err
+	}
+	return parseState(lastSeen), nil
+}
+
+func (t CacheTracker) GetMany(ctx context.Context, appointmentIDs []string) ([]*State, error) {
+	states := make([]*State, len(appointmentIDs))
+	if len(appointmentIDs) == 0 {
+		return states, nil
+	}
+	keys := make([]string, len(appointmentIDs))
+	for i, id := range appointmentIDs {
+		keys[i] = cache.WaitingRoomPresenceKey(id)
+	}
+	values, err := t.cacheClient.MultipleGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		states[i] = parseState(v)
+	}
+	return states, nil
+}
+
+func parseState(lastSeen string) *State {
+	lastSeenAt, err := time.Parse(time.RFC3339, lastSeen)
+	if err != nil {
+		return &State{Status: StatusAbsent}
+	}
+	return &State{Status: StatusWaiting, LastSeenAt: &lastSeenAt}
+}
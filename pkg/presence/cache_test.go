@@ -0,0 +1,102 @@
+package presence_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/cache"
+	"github.com/synthia-telemed/backend-api/pkg/presence"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_cache_client"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"time"
+)
+
+var _ = Describe("Cache Presence Tracker", func() {
+	var (
+		mockCtrl        *gomock.Controller
+		mockCacheClient *mock_cache_client.MockClient
+		mockClock       *mock_clock.MockClock
+		tracker         *presence.CacheTracker
+		ctx             context.Context
+		ttl             time.Duration
+		appointmentID   string
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCacheClient = mock_cache_client.NewMockClient(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		ttl = time.Second * 30
+		tracker = presence.NewCacheTracker(&presence.Config{TTL: ttl}, mockCacheClient, mockClock)
+		ctx = context.Background()
+		appointmentID = uuid.NewString()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("CheckIn", func() {
+		It("should set last seen time with TTL", func() {
+			now := time.Now().UTC().Truncate(time.Second)
+			mockClock.EXPECT().Now().Return(now).Times(1)
+			mockCacheClient.EXPECT().Set(ctx, cache.WaitingRoomPresenceKey(appointmentID), now.Format(time.RFC3339), ttl).Return(nil).Times(1)
+			state, err := tracker.CheckIn(ctx, appointmentID)
+			Expect(err).To(BeNil())
+			Expect(state.Status).To(Equal(presence.StatusWaiting))
+			Expect(*state.LastSeenAt).To(Equal(now))
+		})
+		It("should return error when set cache error", func() {
+			mockClock.EXPECT().Now().Return(time.Now()).Times(1)
+			mockCacheClient.EXPECT().Set(ctx, cache.WaitingRoomPresenceKey(appointmentID), gomock.Any(), ttl).Return(testhelper.MockError).Times(1)
+			_, err := tracker.CheckIn(ctx, appointmentID)
+			Expect(err).To(Equal(testhelper.MockError))
+		})
+	})
+
+	Context("CheckOut", func() {
+		It("should delete the presence", func() {
+			mockCacheClient.EXPECT().Delete(ctx, cache.WaitingRoomPresenceKey(appointmentID)).Return(nil).Times(1)
+			Expect(tracker.CheckOut(ctx, appointmentID)).To(Succeed())
+		})
+	})
+
+	Context("Get", func() {
+		It("should return waiting when the last seen time exists", func() {
+			lastSeen := time.Now().UTC().Truncate(time.Second)
+			mockCacheClient.EXPECT().Get(ctx, cache.WaitingRoomPresenceKey(appointmentID), false).Return(lastSeen.Format(time.RFC3339), nil).Times(1)
+			state, err := tracker.Get(ctx, appointmentID)
+			Expect(err).To(BeNil())
+			Expect(state.Status).To(Equal(presence.StatusWaiting))
+			Expect(state.LastSeenAt.Equal(lastSeen)).To(BeTrue())
+		})
+		It("should return absent when the last seen time is expired", func() {
+			mockCacheClient.EXPECT().Get(ctx, cache.WaitingRoomPresenceKey(appointmentID), false).Return("", nil).Times(1)
+			state, err := tracker.Get(ctx, appointmentID)
+			Expect(err).To(BeNil())
+			Expect(state.Status).To(Equal(presence.StatusAbsent))
+			Expect(state.LastSeenAt).To(BeNil())
+		})
+	})
+
+	Context("GetMany", func() {
+		It("should return states in the same order as the appointment IDs", func() {
+			otherID := uuid.NewString()
+			lastSeen := time.Now().UTC().Truncate(time.Second)
+			mockCacheClient.EXPECT().MultipleGet(ctx, cache.WaitingRoomPresenceKey(appointmentID), cache.WaitingRoomPresenceKey(otherID)).Return([]string{"", lastSeen.Format(time.RFC3339)}, nil).Times(1)
+			states, err := tracker.GetMany(ctx, []string{appointmentID, otherID})
+			Expect(err).To(BeNil())
+			Expect(states).To(HaveLen(2))
+			Expect(states[0].Status).To(Equal(presence.StatusAbsent))
+			Expect(states[1].Status).To(Equal(presence.StatusWaiting))
+		})
+		It("should not query cache when no appointment ID is given", func() {
+			states, err := tracker.GetMany(ctx, nil)
+			Expect(err).To(BeNil())
+			Expect(states).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package presence_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPresence(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Presence Suite")
+}
@@ -0,0 +1,28 @@
+package presence
+
+import (
+	"context"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusWaiting Status = "WAITING"
+	StatusAbsent  Status = "ABSENT"
+)
+
+// State is the presence of the patient in the waiting room of the appointment
+type State struct {
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	Status     Status     `json:"status" enums:"WAITING,ABSENT"`
+}
+
+type Tracker interface {
+	// CheckIn marks the patient as waiting. It must be called periodically to stay waiting.
+	CheckIn(ctx context.Context, appointmentID string) (*State, error)
+	CheckOut(ctx context.Context, appointmentID string) error
+	Get(ctx context.Context, appointmentID string) (*State, error)
+	// GetMany returns the states in the same order as the appointment IDs
+	GetMany(ctx context.Context, appointmentIDs []string) ([]*State, error)
+}
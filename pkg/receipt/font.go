@@ -0,0 +1,216 @@
+package receipt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidFont = errors.New("invalid TrueType font")
+
+// font is the TrueType font which is embedded into the PDF as is. Only the tables that are needed for mapping the text to glyphs
+// and measuring the text are parsed
+type font struct {
+	glyphIndex    map[rune]uint16
+	data          []byte
+	advanceWidths []uint16
+	bbox          [4]int16
+	unitsPerEm    uint16
+	ascent        int16
+	descent       int16
+}
+
+func parseFont(data []byte) (*font, error) {
+	tables, err := parseTableDirectory(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("%w: %s table not found", ErrInvalidFont, tag)
+		}
+	}
+	f := &font{data: data}
+
+	head := tables["head"]
+	if len(head) < 44 {
+		return nil, fmt.Errorf("%w: head table is too short", ErrInvalidFont)
+	}
+	f.unitsPerEm = binary.BigEndian.Uint16(head[18:])
+	if f.unitsPerEm == 0 {
+		return nil, fmt.Errorf("%w: unitsPerEm is zero", ErrInvalidFont)
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+
+	hhea := tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, fmt.Errorf("%w: hhea table is too short", ErrInvalidFont)
+	}
+	f.ascent = int16(binary.BigEndian.Uint16(hhea[4:]))
+	f.descent = int16(binary.BigEndian.Uint16(hhea[6:]))
+	numberOfHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+
+	hmtx := tables["hmtx"]
+	if numberOfHMetrics == 0 || len(hmtx) < numberOfHMetrics*4 {
+		return nil, fmt.Errorf("%w: hmtx table is too short", ErrInvalidFont)
+	}
+	f.advanceWidths = make([]uint16, numberOfHMetrics)
+	for i := range f.advanceWidths {
+		f.advanceWidths[i] = binary.BigEndian.Uint16(hmtx[4*i:])
+	}
+
+	if f.glyphIndex, err = parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func parseTableDirectory(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("%w: file is too short", ErrInvalidFont)
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, fmt.Errorf("%w: table directory is too short", ErrInvalidFont)
+	}
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		record := data[12+16*i:]
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: table %s is out of bound", ErrInvalidFont, record[:4])
+		}
+		tables[string(record[:4])] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// parseCmap maps the Unicode code points to the glyph indexes using the Windows Unicode subtable.
+// Format 12 is preferred over format 4 as it covers the characters outside the Basic Multilingual Plane
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("%w: cmap table is too short", ErrInvalidFont)
+	}
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables && len(cmap) >= 4+8*(i+1); i++ {
+		record := cmap[4+8*i:]
+		platformID := binary.BigEndian.Uint16(record)
+		encodingID := binary.BigEndian.Uint16(record[2:])
+		offset := binary.BigEndian.Uint32(record[4:])
+		if platformID != 0 && !(platformID == 3 && (encodingID == 1 || encodingID == 10)) {
+			continue
+		}
+		if uint64(offset)+2 > uint64(len(cmap)) {
+			return nil, fmt.Errorf("%w: cmap subtable is out of bound", ErrInvalidFont)
+		}
+		subtable := cmap[offset:]
+		switch binary.BigEndian.Uint16(subtable) {
+		case 4:
+			format4 = subtable
+		case 12:
+			format12 = subtable
+		}
+	}
+	switch {
+	case format12 != nil:
+		return parseCmapFormat12(format12)
+	case format4 != nil:
+		return parseCmapFormat4(format4)
+	}
+	return nil, fmt.Errorf("%w: Unicode cmap subtable not found", ErrInvalidFont)
+}
+
+func parseCmapFormat4(subtable []byte) (map[rune]uint16, error) {
+	if len(subtable) < 14 {
+		return nil, fmt.Errorf("%w: cmap format 4 is too short", ErrInvalidFont)
+	}
+	segCount := int(binary.BigEndian.Uint16(subtable[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segCount + 2
+	idDeltas := startCodes + 2*segCount
+	idRangeOffsets := idDeltas + 2*segCount
+	if len(subtable) < idRangeOffsets+2*segCount {
+		return nil, fmt.Errorf("%w: cmap format 4 is too short", ErrInvalidFont)
+	}
+	glyphIndex := make(map[rune]uint16)
+	for i := 0; i < segCount; i++ {
+		end := int(binary.BigEndian.Uint16(subtable[endCodes+2*i:]))
+		start := int(binary.BigEndian.Uint16(subtable[startCodes+2*i:]))
+		delta := binary.BigEndian.Uint16(subtable[idDeltas+2*i:])
+		rangeOffsetPos := idRangeOffsets + 2*i
+		rangeOffset := int(binary.BigEndian.Uint16(subtable[rangeOffsetPos:]))
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var glyph uint16
+			if rangeOffset == 0 {
+				glyph = uint16(c) + delta
+			} else {
+				pos := rangeOffsetPos + rangeOffset + 2*(c-start)
+				if pos+2 > len(subtable) {
+					return nil, fmt.Errorf("%w: cmap format 4 glyph is out of bound", ErrInvalidFont)
+				}
+				if glyph = binary.BigEndian.Uint16(subtable[pos:]); glyph != 0 {
+					glyph += delta
+				}
+			}
+			if glyph != 0 {
+				glyphIndex[rune(c)] = glyph
+			}
+		}
+	}
+	return glyphIndex, nil
+}
+
+func parseCmapFormat12(subtable []byte) (map[rune]uint16, error) {
+	if len(subtable) < 16 {
+		return nil, fmt.Errorf("%w: cmap format 12 is too short", ErrInvalidFont)
+	}
+	numGroups := int(binary.BigEndian.Uint32(subtable[12:]))
+	if len(subtable) < 16+12*numGroups {
+		return nil, fmt.Errorf("%w: cmap format 12 is too short", ErrInvalidFont)
+	}
+	glyphIndex := make(map[rune]uint16)
+	for i := 0; i < numGroups; i++ {
+		group := subtable[16+12*i:]
+		start := binary.BigEndian.Uint32(group)
+		end := binary.BigEndian.Uint32(group[4:])
+		startGlyph := binary.BigEndian.Uint32(group[8:])
+		if end < start || end > 0x10FFFF {
+			return nil, fmt.Errorf("%w: cmap format 12 group is invalid", ErrInvalidFont)
+		}
+		for c := start; c <= end; c++ {
+			glyphIndex[rune(c)] = uint16(startGlyph + c - start)
+		}
+	}
+	return glyphIndex, nil
+}
+
+// glyph returns the glyph index of the rune. The missing rune is mapped to the .notdef glyph
+func (f *font) glyph(r rune) uint16 {
+	return f.glyphIndex[r]
+}
+
+// advanceWidth returns the advance width of the glyph in the PDF text space unit, i.e. 1/1000 of the font size
+func (f *font) advanceWidth(glyph uint16) int {
+	width := f.advanceWidths[len(f.advanceWidths)-1]
+	if int(glyph) < len(f.advanceWidths) {
+		width = f.advanceWidths[glyph]
+	}
+	return f.scale(int(width))
+}
+
+func (f *font) scale(v int) int {
+	return v * 1000 / int(f.unitsPerEm)
+}
+
+// textWidth returns the width of the text in point when it's rendered in the font size
+func (f *font) textWidth(text string, size float64) float64 {
+	width := 0
+	for _, r := range text {
+		width += f.advanceWidth(f.glyph(r))
+	}
+	return float64(width) * size / 1000
+}
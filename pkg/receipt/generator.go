@@ -0,0 +1,47 @@
+package receipt
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoFontPath = errors.New("RECEIPT_FONT_PATH is required to generate the receipt")
+
+type Config struct {
+	// FontPath is the TrueType font which supports both Thai and English characters, e.g. Sarabun.
+	// It's only required by the API generating the receipt, so the other services start without it
+	FontPath string `env:"RECEIPT_FONT_PATH"`
+}
+
+type Generator interface {
+	Generate(r *Receipt) ([]byte, error)
+}
+
+type Receipt struct {
+	PaidAt        time.Time
+	PatientNameTH string
+	PatientNameEN string
+	ChargeID      string
+	// Card is the credit card which is charged. The receipt of the payment without credit card is paid by PromptPay
+	Card      *Card
+	Items     []Item
+	Discounts []Discount
+	InvoiceID int
+	Amount    float64
+}
+
+type Card struct {
+	Brand       string
+	Last4Digits string
+}
+
+type Item struct {
+	Name     string
+	Price    float64
+	Quantity int
+}
+
+type Discount struct {
+	Name   string
+	Amount float64
+}
@@ -0,0 +1,337 @@
+package receipt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	marginX      = 50.0
+	marginTop    = 60.0
+	marginBottom = 60.0
+	fontSize     = 11.0
+	lineHeight   = 18.0
+	valueX       = 230.0
+	quantityX    = 340.0
+	priceX       = 450.0
+	amountX      = pageWidth - marginX
+	fontName     = "ReceiptFont"
+	paidAtLayout = "02/01/2006 15:04"
+)
+
+// PDFGenerator renders the bilingual Thai/English receipt in A4 PDF with the TrueType font embedded
+type PDFGenerator struct {
+	font     *font
+	location *time.Location
+}
+
+func NewPDFGenerator(c *Config, location *time.Location) (*PDFGenerator, error) {
+	if c.FontPath == "" {
+		return nil, ErrNoFontPath
+	}
+	data, err := os.ReadFile(c.FontPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseFont(data)
+	if err != nil {
+		return nil, err
+	}
+	return &PDFGenerator{font: f, location: location}, nil
+}
+
+func (g PDFGenerator) Generate(r *Receipt) ([]byte, error) {
+	d := newDocument(g.font)
+	d.textCenter(pageWidth/2, 20, "ใบเสร็จรับเงิน / Receipt")
+	d.y -= 16
+
+	d.row("เลขที่ใบแจ้งหนี้ / Invoice No.", strconv.Itoa(r.InvoiceID))
+	d.row("ชื่อผู้ป่วย / Patient Name", r.PatientNameTH)
+	if r.PatientNameEN != "" {
+		d.row("", r.PatientNameEN)
+	}
+	d.row("วันที่ชำระเงิน / Payment Date", r.PaidAt.In(g.location).Format(paidAtLayout))
+	d.row("ชำระโดย / Paid By", paymentMethod(r.Card))
+	d.row("เลขอ้างอิง / Reference No.", r.ChargeID)
+	d.y -= lineHeight / 2
+
+	d.rule()
+	d.newLine()
+	d.text(marginX, fontSize, "รายการ / Description")
+	d.textRight(quantityX, fontSize, "จำนวน / Qty")
+	d.textRight(priceX, fontSize, "ราคา / Unit Price")
+	d.textRight(amountX, fontSize, "จำนวนเงิน / Amount")
+	d.y -= lineHeight / 2
+	d.rule()
+
+	subtotal := 0.0
+	for _, item := range r.Items {
+		amount := item.Price * float64(item.Quantity)
+		subtotal += amount
+		d.newLine()
+		d.text(marginX, fontSize, item.Name)
+		d.textRight(quantityX, fontSize, strconv.Itoa(item.Quantity))
+		d.textRight(priceX, fontSize, formatAmount(item.Price))
+		d.textRight(amountX, fontSize, formatAmount(amount))
+	}
+	discount := 0.0
+	for _, dis := range r.Discounts {
+		discount += dis.Amount
+		d.newLine()
+		d.text(marginX, fontSize, dis.Name)
+		d.textRight(amountX, fontSize, formatAmount(-dis.Amount))
+	}
+	d.y -= lineHeight / 2
+	d.rule()
+
+	d.summary("ยอดรวม / Subtotal", formatAmount(subtotal))
+	d.summary("ส่วนลด / Discount", formatAmount(discount))
+	d.summary("ยอดชำระ / Total Paid (THB)", formatAmount(r.Amount))
+	d.y -= lineHeight
+	d.newLine()
+	d.text(marginX, 9, "เอกสารนี้ออกโดยระบบอิเล็กทรอนิกส์ / This receipt is electronically generated")
+	return d.bytes()
+}
+
+func paymentMethod(card *Card) string {
+	if card == nil {
+		return "พร้อมเพย์ / PromptPay"
+	}
+	return fmt.Sprintf("%s **** %s", card.Brand, card.Last4Digits)
+}
+
+// formatAmount formats the amount in 2 decimal places with the thousands separators, e.g. 1,234.50
+func formatAmount(amount float64) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String() + fraction
+}
+
+// document lays out the text from top to bottom and breaks the page when the space is running out
+type document struct {
+	font *font
+	// glyphs are the runes of the rendered glyphs for mapping the text back to Unicode, so the text can be searched and copied
+	glyphs map[uint16]rune
+	pages  []*bytes.Buffer
+	y      float64
+}
+
+func newDocument(f *font) *document {
+	d := &document{font: f, glyphs: make(map[uint16]rune)}
+	d.newPage()
+	return d
+}
+
+func (d *document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - marginTop
+}
+
+func (d *document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// newLine moves to the next line, or to the next page if the line doesn't fit
+func (d *document) newLine() {
+	if d.y-lineHeight < marginBottom {
+		d.newPage()
+	}
+	d.y -= lineHeight
+}
+
+func (d *document) row(label, value string) {
+	d.newLine()
+	d.text(marginX, fontSize, label)
+	d.text(valueX, fontSize, value)
+}
+
+func (d *document) summary(label, value string) {
+	d.newLine()
+	d.textRight(priceX, fontSize, label)
+	d.textRight(amountX, fontSize, value)
+}
+
+func (d *document) rule() {
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", marginX, d.y, amountX, d.y)
+}
+
+func (d *document) textCenter(x, size float64, s string) {
+	d.newLine()
+	d.text(x-d.font.textWidth(s, size)/2, size, s)
+}
+
+func (d *document) textRight(x, size float64, s string) {
+	d.text(x-d.font.textWidth(s, size), size, s)
+}
+
+func (d *document) text(x, size float64, s string) {
+	if s == "" {
+		return
+	}
+	var hex strings.Builder
+	for _, r := range s {
+		glyph := d.font.glyph(r)
+		if _, ok := d.glyphs[glyph]; !ok && glyph != 0 {
+			d.glyphs[glyph] = r
+		}
+		fmt.Fprintf(&hex, "%04X", glyph)
+	}
+	fmt.Fprintf(d.page(), "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, d.y, hex.String())
+}
+
+func (d *document) sortedGlyphs() []uint16 {
+	glyphs := make([]uint16, 0, len(d.glyphs))
+	for glyph := range d.glyphs {
+		glyphs = append(glyphs, glyph)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// bytes serializes the document. The font objects come first, then the page and content objects of each page
+func (d *document) bytes() ([]byte, error) {
+	w := &pdfWriter{}
+	w.buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	const (
+		catalogObj = iota + 1
+		pagesObj
+		fontObj
+		cidFontObj
+		descriptorObj
+		fontFileObj
+		toUnicodeObj
+		firstPageObj
+	)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+2*i)
+	}
+
+	w.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+	w.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	w.object(fontObj, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		fontName, cidFontObj, toUnicodeObj))
+	w.object(cidFontObj, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		fontName, descriptorObj, d.widths()))
+	f := d.font
+	w.object(descriptorObj, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		fontName, f.scale(int(f.bbox[0])), f.scale(int(f.bbox[1])), f.scale(int(f.bbox[2])), f.scale(int(f.bbox[3])),
+		f.scale(int(f.ascent)), f.scale(int(f.descent)), f.scale(int(f.ascent)), fontFileObj))
+	if err := w.stream(fontFileObj, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+		return nil, err
+	}
+	if err := w.stream(toUnicodeObj, "", d.toUnicode()); err != nil {
+		return nil, err
+	}
+	for i, page := range d.pages {
+		pageObj := firstPageObj + 2*i
+		w.object(pageObj, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObj, pageWidth, pageHeight, fontObj, pageObj+1))
+		if err := w.stream(pageObj+1, "", page.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return w.finish(catalogObj), nil
+}
+
+// widths lists the advance widths of the rendered glyphs, so the viewer doesn't fall back to the default width
+func (d *document) widths() string {
+	var b strings.Builder
+	for _, glyph := range d.sortedGlyphs() {
+		fmt.Fprintf(&b, "%d [%d] ", glyph, d.font.advanceWidth(glyph))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (d *document) toUnicode() []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	glyphs := d.sortedGlyphs()
+	// Each bfchar block is limited to 100 entries
+	for start := 0; start < len(glyphs); start += 100 {
+		end := start + 100
+		if end > len(glyphs) {
+			end = len(glyphs)
+		}
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, glyph := range glyphs[start:end] {
+			fmt.Fprintf(&b, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{d.glyphs[glyph]}) {
+				fmt.Fprintf(&b, "%04X", unit)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// pdfWriter writes the objects and keeps their offsets for the cross-reference table
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *pdfWriter) begin(id int) {
+	for len(w.offsets) < id {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n", id)
+}
+
+func (w *pdfWriter) object(id int, dict string) {
+	w.begin(id)
+	fmt.Fprintf(&w.buf, "%s\nendobj\n", dict)
+}
+
+func (w *pdfWriter) stream(id int, extraDict string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	w.begin(id)
+	fmt.Fprintf(&w.buf, "<< /Length %d /Filter /FlateDecode %s >>\nstream\n", compressed.Len(), extraDict)
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+func (w *pdfWriter) finish(rootObj int) []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, rootObj, xref)
+	return w.buf.Bytes()
+}
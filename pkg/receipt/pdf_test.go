@@ -0,0 +1,209 @@
+package receipt_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/receipt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	firstCodePoint = 0x20
+	lastCodePoint  = 0x0E7F
+)
+
+// buildFont builds the minimal TrueType font which maps the code points from space to the end of Thai block to the consecutive glyphs
+func buildFont() []byte {
+	be := binary.BigEndian
+	head := make([]byte, 54)
+	be.PutUint16(head[18:], 1000)
+	for i, v := range []int16{0, -200, 1000, 800} {
+		be.PutUint16(head[36+2*i:], uint16(v))
+	}
+	hhea := make([]byte, 36)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0xFFFF-200+1))
+	be.PutUint16(hhea[34:], 1)
+	hmtx := make([]byte, 4)
+	be.PutUint16(hmtx, 500)
+
+	// Format 4 with a segment of the code points and the terminating segment
+	subtable := make([]byte, 32)
+	be.PutUint16(subtable, 4)
+	be.PutUint16(subtable[2:], 32)
+	be.PutUint16(subtable[6:], 4)
+	be.PutUint16(subtable[14:], lastCodePoint)
+	be.PutUint16(subtable[16:], 0xFFFF)
+	be.PutUint16(subtable[20:], firstCodePoint)
+	be.PutUint16(subtable[22:], 0xFFFF)
+	be.PutUint16(subtable[24:], 0x10000+1-firstCodePoint)
+	be.PutUint16(subtable[26:], 1)
+	cmap := make([]byte, 12)
+	be.PutUint16(cmap[2:], 1)
+	be.PutUint16(cmap[4:], 3)
+	be.PutUint16(cmap[6:], 1)
+	be.PutUint32(cmap[8:], 12)
+	cmap = append(cmap, subtable...)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{{"cmap", cmap}, {"head", head}, {"hhea", hhea}, {"hmtx", hmtx}}
+	font := make([]byte, 12+16*len(tables))
+	be.PutUint32(font, 0x00010000)
+	be.PutUint16(font[4:], uint16(len(tables)))
+	for i, t := range tables {
+		record := font[12+16*i:]
+		copy(record, t.tag)
+		be.PutUint32(record[8:], uint32(len(font)))
+		be.PutUint32(record[12:], uint32(len(t.data)))
+		font = append(font, t.data...)
+	}
+	return font
+}
+
+// glyphHex encodes the text in the glyphs of the built font
+func glyphHex(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		fmt.Fprintf(&b, "%04X", int(r)-firstCodePoint+1)
+	}
+	return b.String()
+}
+
+// inflateStreams returns the decompressed content of all streams in the PDF
+func inflateStreams(pdf []byte) string {
+	var content strings.Builder
+	for _, match := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(pdf, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(match[1]))
+		Expect(err).To(BeNil())
+		data, err := io.ReadAll(r)
+		Expect(err).To(BeNil())
+		content.Write(data)
+	}
+	return content.String()
+}
+
+var _ = Describe("PDF Receipt Generator", func() {
+	var (
+		config    *receipt.Config
+		generator *receipt.PDFGenerator
+		r         *receipt.Receipt
+		location  *time.Location
+	)
+
+	BeforeEach(func() {
+		var err error
+		location, err = time.LoadLocation("Asia/Bangkok")
+		Expect(err).To(BeNil())
+		config = &receipt.Config{FontPath: filepath.Join(GinkgoT().TempDir(), "font.ttf")}
+		Expect(os.WriteFile(config.FontPath, buildFont(), 0o600)).To(Succeed())
+		r = &receipt.Receipt{
+			PaidAt:        time.Date(2022, 10, 17, 8, 30, 0, 0, time.UTC),
+			PatientNameTH: "นาย สมชาย ใจดี",
+			PatientNameEN: "Mr. Somchai Jaidee",
+			ChargeID:      "chrg_test_123",
+			Card:          &receipt.Card{Brand: "Visa", Last4Digits: "4242"},
+			Items: []receipt.Item{
+				{Name: "ค่าตรวจ / Consultation", Price: 1000, Quantity: 1},
+				{Name: "Paracetamol", Price: 117.25, Quantity: 2},
+			},
+			Discounts: []receipt.Discount{{Name: "Member", Amount: 100}},
+			InvoiceID: 42,
+			Amount:    1134.5,
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		generator, err = receipt.NewPDFGenerator(config, location)
+		Expect(err).To(BeNil())
+	})
+
+	When("font path isn't set", func() {
+		It("should return no font path error", func() {
+			_, err := receipt.NewPDFGenerator(&receipt.Config{}, location)
+			Expect(err).To(Equal(receipt.ErrNoFontPath))
+		})
+	})
+
+	When("font file doesn't exist", func() {
+		It("should return error", func() {
+			_, err := receipt.NewPDFGenerator(&receipt.Config{FontPath: "not-exist.ttf"}, location)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	When("font file is invalid", func() {
+		It("should return invalid font error", func() {
+			Expect(os.WriteFile(config.FontPath, []byte("not a font"), 0o600)).To(Succeed())
+			_, err := receipt.NewPDFGenerator(config, location)
+			Expect(err).To(MatchError(receipt.ErrInvalidFont))
+		})
+	})
+
+	It("should generate the PDF receipt", func() {
+		pdf, err := generator.Generate(r)
+		Expect(err).To(BeNil())
+		Expect(pdf).To(HavePrefix("%PDF-1.4"))
+		Expect(pdf).To(HaveSuffix("%%EOF\n"))
+		Expect(string(pdf)).To(ContainSubstring("/Count 1"))
+
+		startXref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+		Expect(startXref).ToNot(BeNil())
+		offset, err := strconv.Atoi(string(startXref[1]))
+		Expect(err).To(BeNil())
+		Expect(pdf[offset:]).To(HavePrefix("xref"))
+
+		content := inflateStreams(pdf)
+		for _, text := range []string{
+			"42",
+			"นาย สมชาย ใจดี",
+			"Mr. Somchai Jaidee",
+			"17/10/2022 15:30",
+			"Visa **** 4242",
+			"chrg_test_123",
+			"ค่าตรวจ / Consultation",
+			"234.50",
+			"-100.00",
+			"1,234.50",
+			"1,134.50",
+		} {
+			Expect(content).To(ContainSubstring(glyphHex(text)), text)
+		}
+	})
+
+	When("the receipt is paid by PromptPay", func() {
+		BeforeEach(func() {
+			r.Card = nil
+		})
+		It("should render PromptPay as the payment method", func() {
+			pdf, err := generator.Generate(r)
+			Expect(err).To(BeNil())
+			Expect(inflateStreams(pdf)).To(ContainSubstring(glyphHex("พร้อมเพย์ / PromptPay")))
+		})
+	})
+
+	When("the items don't fit in a page", func() {
+		BeforeEach(func() {
+			for i := 0; i < 50; i++ {
+				r.Items = append(r.Items, receipt.Item{Name: fmt.Sprintf("Item %d", i), Price: 10, Quantity: 1})
+			}
+		})
+		It("should break into multiple pages", func() {
+			pdf, err := generator.Generate(r)
+			Expect(err).To(BeNil())
+			Expect(string(pdf)).To(ContainSubstring("/Count 2"))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/synthia-telemed/backend-api/pkg/session"
+	"github.com/synthia-telemed/backend-api/pkg/token"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type AuthMode string
+
+const (
+	// HeaderAuthMode trusts X-USER-ID and X-SESSION-ID header set by the gateway in front of the API
+	HeaderAuthMode AuthMode = "header"
+	// JWSAuthMode verifies the JWS in the Authorization header in-process, so the API can be exposed directly
+	JWSAuthMode AuthMode = "jws"
+)
+
+func (m AuthMode) IsValid() bool {
+	switch m {
+	case HeaderAuthMode, JWSAuthMode:
+		return true
+	}
+	return false
+}
+
+var (
+	ErrRoleMismatch   = NewErrorResponse("Token isn't issued for this API")
+	ErrSessionRevoked = NewErrorResponse("Session is signed out or expired")
+	// ErrAuthUnavailable hides the failure of the token service or the datastore from the client
+	ErrAuthUnavailable = NewErrorResponse("Unable to verify the token, please try again later")
+)
+
+// JWSAuthentication verifies the bearer JWS and sets X-USER-ID and X-SESSION-ID header from its claims, which are read by
+// GinHandler.ParseUserID as if they're set by the gateway. The headers sent by the client are always discarded.
+// The request without a valid JWS passes through without the user, so it's rejected only by the routes requiring one,
+// while the JWS issued for the other role or of the signed-out session is rejected right away
+func JWSAuthentication(verifier token.Verifier, sessionChecker session.Checker, role string, logger *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Header.Del("X-USER-ID")
+		c.Request.Header.Del("X-SESSION-ID")
+		jws := strings.TrimSpace(strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer "))
+		if jws == "" {
+			return
+		}
+		claims, err := verifier.Verify(jws)
+		if err != nil {
+			logger.Errorw("verifier.Verify error", "error", err.Error())
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrAuthUnavailable)
+			return
+		}
+		if claims == nil {
+			return
+		}
+		if claims.Role != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrRoleMismatch)
+			return
+		}
+		// The JWS without the session ID can't be signed out, so it's rejected as well
+		active, err := isSessionActive(sessionChecker, claims.SessionID)
+		if err != nil {
+			logger.Errorw("sessionChecker.IsActive error", "error", err.Error())
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrAuthUnavailable)
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrSessionRevoked)
+			return
+		}
+		c.Request.Header.Set("X-USER-ID", strconv.FormatUint(claims.UserID, 10))
+		c.Request.Header.Set("X-SESSION-ID", claims.SessionID)
+	}
+}
+
+// SessionRevocation rejects the request whose X-SESSION-ID header, set by the gateway, is of the signed-out session.
+// The request without the header passes through since the token issued before the session is tracked has no session ID
+func SessionRevocation(sessionChecker session.Checker, logger *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.Request.Header.Get("X-SESSION-ID")
+		if sessionID == "" {
+			return
+		}
+		active, err := sessionChecker.IsActive(sessionID)
+		if err != nil {
+			logger.Errorw("sessionChecker.IsActive error", "error", err.Error())
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrAuthUnavailable)
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrSessionRevoked)
+		}
+	}
+}
+
+func isSessionActive(sessionChecker session.Checker, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+	return sessionChecker.IsActive(sessionID)
+}
@@ -0,0 +1,202 @@
+package server_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/server"
+	"github.com/synthia-telemed/backend-api/pkg/token"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_session"
+	"github.com/synthia-telemed/backend-api/test/mock_token_service"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("JWS authentication", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		c            *gin.Context
+		rec          *httptest.ResponseRecorder
+		mockVerifier *mock_token_service.MockVerifier
+		mockChecker  *mock_session.MockChecker
+		handlerFunc  gin.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		mockCtrl, rec, c = testhelper.InitHandlerTest()
+		mockVerifier = mock_token_service.NewMockVerifier(mockCtrl)
+		mockChecker = mock_session.NewMockChecker(mockCtrl)
+		handlerFunc = server.JWSAuthentication(mockVerifier, mockChecker, "Patient", zap.NewNop().Sugar())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("X-USER-ID", "1")
+		c.Request.Header.Set("X-SESSION-ID", "forged-session-id")
+	})
+
+	JustBeforeEach(func() {
+		handlerFunc(c)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	When("Authorization header is not present", func() {
+		It("should discard the user ID header from the client", func() {
+			Expect(c.IsAborted()).To(BeFalse())
+			Expect(c.Request.Header.Get("X-USER-ID")).To(BeEmpty())
+			Expect(c.Request.Header.Get("X-SESSION-ID")).To(BeEmpty())
+		})
+	})
+
+	When("JWS is invalid", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("Authorization", "Bearer invalid")
+			mockVerifier.EXPECT().Verify("invalid").Return(nil, nil).Times(1)
+		})
+		It("should pass through without the user", func() {
+			Expect(c.IsAborted()).To(BeFalse())
+			Expect(c.Request.Header.Get("X-USER-ID")).To(BeEmpty())
+			Expect(c.Request.Header.Get("X-SESSION-ID")).To(BeEmpty())
+		})
+	})
+
+	When("JWS verification error", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("Authorization", "Bearer jws")
+			mockVerifier.EXPECT().Verify("jws").Return(nil, testhelper.MockError).Times(1)
+		})
+		It("should return 503 without the error detail", func() {
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			testhelper.AssertErrorResponseBody(rec.Body, server.ErrAuthUnavailable)
+		})
+	})
+
+	When("JWS is issued for the other role", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("Authorization", "Bearer jws")
+			mockVerifier.EXPECT().Verify("jws").Return(&token.Claims{UserID: 99, Role: "Doctor"}, nil).Times(1)
+		})
+		It("should return 403", func() {
+			Expect(rec.Code).To(Equal(http.StatusForbidden))
+			testhelper.AssertErrorResponseBody(rec.Body, server.ErrRoleMismatch)
+		})
+	})
+
+	When("session of the JWS is signed out", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("Authorization", "Bearer jws")
+			mockVerifier.EXPECT().Verify("jws").Return(&token.Claims{UserID: 99, Role: "Patient", SessionID: "session-id"}, nil).Times(1)
+			mockChecker.EXPECT().IsActive("session-id").Return(false, nil).Times(1)
+		})
+		It("should return 401", func() {
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			testhelper.AssertErrorResponseBody(rec.Body, server.ErrSessionRevoked)
+			Expect(c.Request.Header.Get("X-USER-ID")).To(BeEmpty())
+		})
+	})
+
+	When("JWS has no session ID", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("Authorization", "Bearer jws")
+			mockVerifier.EXPECT().Verify("jws").Return(&token.Claims{UserID: 99, Role: "Patient"}, nil).Times(1)
+		})
+		It("should return 401", func() {
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			testhelper.AssertErrorResponseBody(rec.Body, server.ErrSessionRevoked)
+		})
+	})
+
+	When("session check error", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("Authorization", "Bearer jws")
+			mockVerifier.EXPECT().Verify("jws").Return(&token.Claims{UserID: 99, Role: "Patient", SessionID: "session-id"}, nil).Times(1)
+			mockChecker.EXPECT().IsActive("session-id").Return(false, testhelper.MockError).Times(1)
+		})
+		It("should return 503 without the error detail", func() {
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			testhelper.AssertErrorResponseBody(rec.Body, server.ErrAuthUnavailable)
+		})
+	})
+
+	When("JWS is valid", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("Authorization", "Bearer jws")
+			mockVerifier.EXPECT().Verify("jws").Return(&token.Claims{UserID: 99, Role: "Patient", SessionID: "session-id"}, nil).Times(1)
+			mockChecker.EXPECT().IsActive("session-id").Return(true, nil).Times(1)
+		})
+		It("should set the user ID and session ID from the claims", func() {
+			Expect(c.IsAborted()).To(BeFalse())
+			server.GinHandler{Logger: zap.NewNop().Sugar()}.ParseUserID(c)
+			Expect(c.IsAborted()).To(BeFalse())
+			Expect(c.GetUint("UserID")).To(Equal(uint(99)))
+			Expect(c.GetString("SessionID")).To(Equal("session-id"))
+		})
+	})
+})
+
+var _ = Describe("Session revocation", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		c           *gin.Context
+		rec         *httptest.ResponseRecorder
+		mockChecker *mock_session.MockChecker
+		handlerFunc gin.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		mockCtrl, rec, c = testhelper.InitHandlerTest()
+		mockChecker = mock_session.NewMockChecker(mockCtrl)
+		handlerFunc = server.SessionRevocation(mockChecker, zap.NewNop().Sugar())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("X-USER-ID", "99")
+	})
+
+	JustBeforeEach(func() {
+		handlerFunc(c)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	When("X-SESSION-ID is not present", func() {
+		It("should pass through", func() {
+			Expect(c.IsAborted()).To(BeFalse())
+		})
+	})
+
+	When("session is active", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("X-SESSION-ID", "session-id")
+			mockChecker.EXPECT().IsActive("session-id").Return(true, nil).Times(1)
+		})
+		It("should pass through", func() {
+			Expect(c.IsAborted()).To(BeFalse())
+		})
+	})
+
+	When("session is signed out", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("X-SESSION-ID", "session-id")
+			mockChecker.EXPECT().IsActive("session-id").Return(false, nil).Times(1)
+		})
+		It("should return 401", func() {
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			testhelper.AssertErrorResponseBody(rec.Body, server.ErrSessionRevoked)
+		})
+	})
+
+	When("session check error", func() {
+		BeforeEach(func() {
+			c.Request.Header.Set("X-SESSION-ID", "session-id")
+			mockChecker.EXPECT().IsActive("session-id").Return(false, testhelper.MockError).Times(1)
+		})
+		It("should return 503", func() {
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			testhelper.AssertErrorResponseBody(rec.Body, server.ErrAuthUnavailable)
+		})
+	})
+})
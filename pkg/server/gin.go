@@ -39,6 +39,14 @@ func RegisterValidator() {
 func NewGinServer(cfg *config.Config, logger *zap.SugaredLogger) *Server {
 	gin.SetMode(cfg.GinMode)
 	router := gin.New()
+	// X-Forwarded-For is only trusted from the proxies in front of the API, so the client can't spoof its IP, e.g. to evade the rate limit.
+	// Behind the proxies without trusting them, every client has the proxy's IP and shares the same rate limit
+	if cfg.Mode != "development" && len(cfg.TrustedProxies) == 0 {
+		logger.Fatal("TRUSTED_PROXIES is required outside development mode")
+	}
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatalw("Invalid trusted proxies", "proxies", cfg.TrustedProxies, "error", err)
+	}
 	router.Use(gin.Recovery())
 	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/api/healthcheck"}}))
 	router.Use(sentrygin.New(sentrygin.Options{Repanic: true}))
@@ -0,0 +1,50 @@
+package server_test
+
+import (
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/config"
+	"github.com/synthia-telemed/backend-api/pkg/server"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("Gin server", func() {
+	var (
+		cfg *config.Config
+		rec *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		cfg = &config.Config{GinMode: gin.TestMode, Mode: "development", Port: 8080}
+		rec = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		s := server.NewGinServer(cfg, zap.NewNop().Sugar())
+		s.GET("/ip", func(c *gin.Context) {
+			c.String(http.StatusOK, c.ClientIP())
+		})
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.0.0.1:12345"
+		req.Header.Set("X-Forwarded-For", "203.0.113.1")
+		s.ServeHTTP(rec, req)
+	})
+
+	When("no proxy is trusted", func() {
+		It("should ignore X-Forwarded-For and use the remote address", func() {
+			Expect(rec.Body.String()).To(Equal("10.0.0.1"))
+		})
+	})
+
+	When("the request is from the trusted proxy", func() {
+		BeforeEach(func() {
+			cfg.TrustedProxies = []string{"10.0.0.0/8"}
+		})
+		It("should use the client IP in X-Forwarded-For", func() {
+			Expect(rec.Body.String()).To(Equal("203.0.113.1"))
+		})
+	})
+})
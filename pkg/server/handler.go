@@ -40,9 +40,15 @@ func (h GinHandler) ParseUserID(c *gin.Context) {
 		return
 	}
 	c.Set("UserID", uint(uintID))
+	// X-SESSION-ID is the session ID in the token, which is absent in the token issued before the session is tracked
+	c.Set("SessionID", c.Request.Header.Get("X-SESSION-ID"))
 }
 
 func (h GinHandler) GetUserID(c *gin.Context) uint {
 	id, _ := c.Get("UserID")
 	return id.(uint)
 }
+
+func (h GinHandler) GetSessionID(c *gin.Context) string {
+	return c.GetString("SessionID")
+}
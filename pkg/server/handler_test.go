@@ -62,5 +62,16 @@ var _ = Describe("Server handler", func() {
 				Expect(id).To(Equal(uint(99)))
 			})
 		})
+
+		When("X-SESSION-ID is present", func() {
+			BeforeEach(func() {
+				c.Request.Header.Set("X-USER-ID", "99")
+				c.Request.Header.Set("X-SESSION-ID", "session-id")
+			})
+			It("should set the session ID", func() {
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(h.GetSessionID(c)).To(Equal("session-id"))
+			})
+		})
 	})
 })
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"sync"
+	"time"
+)
+
+type checkResult struct {
+	expiredAt time.Time
+	active    bool
+}
+
+// CachedChecker looks up the session in the datastore and caches the result for the TTL to avoid querying on every request.
+// The revoked session is therefore still accepted for at most the TTL
+type CachedChecker struct {
+	sessionDataStore datastore.SessionDataStore
+	clock            clock.Clock
+	results          map[string]checkResult
+	sweptAt          time.Time
+	ttl              time.Duration
+	mu               sync.Mutex
+}
+
+func NewCachedChecker(sessionDataStore datastore.SessionDataStore, c clock.Clock, ttl time.Duration) *CachedChecker {
+	return &CachedChecker{
+		sessionDataStore: sessionDataStore,
+		clock:            c,
+		results:          map[string]checkResult{},
+		ttl:              ttl,
+	}
+}
+
+func (c *CachedChecker) IsActive(sessionID string) (bool, error) {
+	now := c.clock.Now()
+	c.mu.Lock()
+	result, ok := c.results[sessionID]
+	c.mu.Unlock()
+	if ok && now.Before(result.expiredAt) {
+		return result.active, nil
+	}
+
+	session, err := c.sessionDataStore.FindByID(sessionID, now)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(now)
+	c.results[sessionID] = checkResult{expiredAt: now.Add(c.ttl), active: session != nil}
+	return session != nil, nil
+}
+
+// sweep removes the expired results at most once per TTL, so the cache doesn't grow with every session ever seen
+func (c *CachedChecker) sweep(now time.Time) {
+	if now.Before(c.sweptAt.Add(c.ttl)) {
+		return
+	}
+	for id, result := range c.results {
+		if !now.Before(result.expiredAt) {
+			delete(c.results, id)
+		}
+	}
+	c.sweptAt = now
+}
@@ -0,0 +1,77 @@
+package session_test
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/session"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"time"
+)
+
+var _ = Describe("Cached Session Checker", func() {
+	var (
+		mockCtrl             *gomock.Controller
+		mockSessionDataStore *mock_datastore.MockSessionDataStore
+		mockClock            *mock_clock.MockClock
+		checker              *session.CachedChecker
+		now                  time.Time
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockSessionDataStore = mock_datastore.NewMockSessionDataStore(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		checker = session.NewCachedChecker(mockSessionDataStore, mockClock, 10*time.Second)
+		now = time.Now()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should report the active session and cache it within the TTL", func() {
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		mockClock.EXPECT().Now().Return(now.Add(5 * time.Second)).Times(1)
+		mockSessionDataStore.EXPECT().FindByID("session-id", now).Return(&datastore.Session{ID: "session-id"}, nil).Times(1)
+		for i := 0; i < 2; i++ {
+			active, err := checker.IsActive("session-id")
+			Expect(err).To(BeNil())
+			Expect(active).To(BeTrue())
+		}
+	})
+
+	It("should look up the session again after the TTL", func() {
+		later := now.Add(11 * time.Second)
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		mockClock.EXPECT().Now().Return(later).Times(1)
+		mockSessionDataStore.EXPECT().FindByID("session-id", now).Return(&datastore.Session{ID: "session-id"}, nil).Times(1)
+		mockSessionDataStore.EXPECT().FindByID("session-id", later).Return(nil, nil).Times(1)
+		active, err := checker.IsActive("session-id")
+		Expect(err).To(BeNil())
+		Expect(active).To(BeTrue())
+		active, err = checker.IsActive("session-id")
+		Expect(err).To(BeNil())
+		Expect(active).To(BeFalse())
+	})
+
+	It("should report the revoked session as inactive", func() {
+		mockClock.EXPECT().Now().Return(now).Times(1)
+		mockSessionDataStore.EXPECT().FindByID("session-id", now).Return(nil, nil).Times(1)
+		active, err := checker.IsActive("session-id")
+		Expect(err).To(BeNil())
+		Expect(active).To(BeFalse())
+	})
+
+	It("should return error and not cache it when find session error", func() {
+		mockClock.EXPECT().Now().Return(now).Times(2)
+		mockSessionDataStore.EXPECT().FindByID("session-id", now).Return(nil, testhelper.MockError).Times(2)
+		for i := 0; i < 2; i++ {
+			_, err := checker.IsActive("session-id")
+			Expect(err).To(Equal(testhelper.MockError))
+		}
+	})
+})
@@ -0,0 +1,27 @@
+package session
+
+import "time"
+
+// Tokens is the JWS and the refresh token of the session
+type Tokens struct {
+	RefreshTokenExpiredAt time.Time
+	Token                 string
+	RefreshToken          string
+	SessionID             string
+}
+
+type Manager interface {
+	// Create signs in the user on the new session
+	Create(userID uint, role, userAgent string) (*Tokens, error)
+	// Refresh rotates the refresh token of the user with the role. It returns nil if the refresh token is invalid,
+	// and revokes the session if the refresh token is already rotated since it must be leaked
+	Refresh(refreshToken, role string) (*Tokens, error)
+	Revoke(sessionID string) error
+	// RevokeAll signs out the user from all devices
+	RevokeAll(userID uint, role string) error
+}
+
+// Checker tells whether the session is still active, so the token of the signed-out session is rejected before it's expired
+type Checker interface {
+	IsActive(sessionID string) (bool, error)
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"github.com/google/uuid"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/token"
+)
+
+// TokenManager issues the tokens of the session from the token service and keeps the session in the datastore,
+// so the session can be listed and revoked by the user
+type TokenManager struct {
+	tokenService     token.Service
+	sessionDataStore datastore.SessionDataStore
+	clock            clock.Clock
+}
+
+func NewTokenManager(tokenService token.Service, sessionDataStore datastore.SessionDataStore, c clock.Clock) *TokenManager {
+	return &TokenManager{
+		tokenService:     tokenService,
+		sessionDataStore: sessionDataStore,
+		clock:            c,
+	}
+}
+
+func (m TokenManager) Create(userID uint, role, userAgent string) (*Tokens, error) {
+	sessionID := uuid.NewString()
+	refreshToken, err := m.tokenService.GenerateRefreshToken(uint64(userID), role, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session := &datastore.Session{
+		ID:             sessionID,
+		UserID:         userID,
+		Role:           role,
+		RefreshTokenID: refreshToken.ID,
+		ExpiredAt:      refreshToken.ExpiredAt,
+		UserAgent:      userAgent,
+	}
+	if err := m.sessionDataStore.Create(session); err != nil {
+		return nil, err
+	}
+	return m.issue(userID, role, sessionID, refreshToken)
+}
+
+func (m TokenManager) Refresh(refreshToken, role string) (*Tokens, error) {
+	rotated, err := m.tokenService.RotateRefreshToken(refreshToken)
+	if err != nil || rotated == nil {
+		return nil, err
+	}
+	// The refresh token of the doctor can't be used to refresh the patient, and vice versa
+	if rotated.Role != role {
+		return nil, nil
+	}
+	ok, err := m.sessionDataStore.Rotate(rotated.SessionID, rotated.PreviousTokenID, rotated.ID, rotated.ExpiredAt, m.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, m.Revoke(rotated.SessionID)
+	}
+	return m.issue(uint(rotated.UserID), role, rotated.SessionID, &rotated.RefreshToken)
+}
+
+func (m TokenManager) Revoke(sessionID string) error {
+	if err := m.sessionDataStore.Revoke(sessionID, m.clock.Now()); err != nil {
+		return err
+	}
+	return m.tokenService.RevokeRefreshToken(sessionID)
+}
+
+func (m TokenManager) RevokeAll(userID uint, role string) error {
+	sessionIDs, err := m.sessionDataStore.RevokeAllByUser(userID, role, m.clock.Now())
+	if err != nil {
+		return err
+	}
+	for _, id := range sessionIDs {
+		if err := m.tokenService.RevokeRefreshToken(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m TokenManager) issue(userID uint, role, sessionID string, refreshToken *token.RefreshToken) (*Tokens, error) {
+	jws, err := m.tokenService.GenerateToken(uint64(userID), role, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		RefreshTokenExpiredAt: refreshToken.ExpiredAt,
+		Token:                 jws,
+		RefreshToken:          refreshToken.Token,
+		SessionID:             sessionID,
+	}, nil
+}
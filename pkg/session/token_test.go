@@ -0,0 +1,147 @@
+package session_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/datastore"
+	"github.com/synthia-telemed/backend-api/pkg/session"
+	"github.com/synthia-telemed/backend-api/pkg/token"
+	testhelper "github.com/synthia-telemed/backend-api/test/helper"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"github.com/synthia-telemed/backend-api/test/mock_datastore"
+	"github.com/synthia-telemed/backend-api/test/mock_token_service"
+	"time"
+)
+
+var _ = Describe("Token Session Manager", func() {
+	var (
+		mockCtrl             *gomock.Controller
+		mockTokenService     *mock_token_service.MockService
+		mockSessionDataStore *mock_datastore.MockSessionDataStore
+		mockClock            *mock_clock.MockClock
+		manager              *session.TokenManager
+		now                  time.Time
+		refreshToken         *token.RefreshToken
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockTokenService = mock_token_service.NewMockService(mockCtrl)
+		mockSessionDataStore = mock_datastore.NewMockSessionDataStore(mockCtrl)
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		manager = session.NewTokenManager(mockTokenService, mockSessionDataStore, mockClock)
+		now = time.Now()
+		refreshToken = &token.RefreshToken{Token: "refresh_token", ID: uuid.NewString(), ExpiredAt: now.Add(30 * 24 * time.Hour)}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("Create", func() {
+		var sessionID string
+		BeforeEach(func() {
+			mockTokenService.EXPECT().GenerateRefreshToken(uint64(7), "Patient", gomock.Any()).DoAndReturn(func(_ uint64, _, id string) (*token.RefreshToken, error) {
+				sessionID = id
+				return refreshToken, nil
+			}).Times(1)
+		})
+
+		It("should keep the session and issue its tokens", func() {
+			mockSessionDataStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(s *datastore.Session) error {
+				Expect(s).To(Equal(&datastore.Session{ID: sessionID, UserID: 7, Role: "Patient", RefreshTokenID: refreshToken.ID, ExpiredAt: refreshToken.ExpiredAt, UserAgent: "okhttp/4.9"}))
+				return nil
+			}).Times(1)
+			mockTokenService.EXPECT().GenerateToken(uint64(7), "Patient", gomock.Any()).Return("token", nil).Times(1)
+			tokens, err := manager.Create(7, "Patient", "okhttp/4.9")
+			Expect(err).To(BeNil())
+			Expect(tokens).To(Equal(&session.Tokens{RefreshTokenExpiredAt: refreshToken.ExpiredAt, Token: "token", RefreshToken: "refresh_token", SessionID: sessionID}))
+		})
+
+		It("should return error when create session error", func() {
+			mockSessionDataStore.EXPECT().Create(gomock.Any()).Return(testhelper.MockError).Times(1)
+			tokens, err := manager.Create(7, "Patient", "okhttp/4.9")
+			Expect(err).To(Equal(testhelper.MockError))
+			Expect(tokens).To(BeNil())
+		})
+	})
+
+	Context("Refresh", func() {
+		var rotated *token.RotatedRefreshToken
+		BeforeEach(func() {
+			rotated = &token.RotatedRefreshToken{RefreshToken: *refreshToken, Role: "Patient", SessionID: uuid.NewString(), PreviousTokenID: uuid.NewString(), UserID: 7}
+		})
+
+		When("refresh token is invalid", func() {
+			BeforeEach(func() {
+				mockTokenService.EXPECT().RotateRefreshToken("refresh_token").Return(nil, nil).Times(1)
+			})
+			It("should return nil", func() {
+				tokens, err := manager.Refresh("refresh_token", "Patient")
+				Expect(err).To(BeNil())
+				Expect(tokens).To(BeNil())
+			})
+		})
+
+		When("refresh token is of the other role", func() {
+			BeforeEach(func() {
+				rotated.Role = "Doctor"
+				mockTokenService.EXPECT().RotateRefreshToken("refresh_token").Return(rotated, nil).Times(1)
+			})
+			It("should return nil", func() {
+				tokens, err := manager.Refresh("refresh_token", "Patient")
+				Expect(err).To(BeNil())
+				Expect(tokens).To(BeNil())
+			})
+		})
+
+		When("refresh token is already rotated", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(2)
+				mockTokenService.EXPECT().RotateRefreshToken("refresh_token").Return(rotated, nil).Times(1)
+				mockSessionDataStore.EXPECT().Rotate(rotated.SessionID, rotated.PreviousTokenID, rotated.ID, rotated.ExpiredAt, now).Return(false, nil).Times(1)
+				mockSessionDataStore.EXPECT().Revoke(rotated.SessionID, now).Return(nil).Times(1)
+				mockTokenService.EXPECT().RevokeRefreshToken(rotated.SessionID).Return(nil).Times(1)
+			})
+			It("should revoke the session and return nil", func() {
+				tokens, err := manager.Refresh("refresh_token", "Patient")
+				Expect(err).To(BeNil())
+				Expect(tokens).To(BeNil())
+			})
+		})
+
+		When("refresh token is rotated", func() {
+			BeforeEach(func() {
+				mockClock.EXPECT().Now().Return(now).Times(1)
+				mockTokenService.EXPECT().RotateRefreshToken("refresh_token").Return(rotated, nil).Times(1)
+				mockSessionDataStore.EXPECT().Rotate(rotated.SessionID, rotated.PreviousTokenID, rotated.ID, rotated.ExpiredAt, now).Return(true, nil).Times(1)
+				mockTokenService.EXPECT().GenerateToken(uint64(7), "Patient", rotated.SessionID).Return("token", nil).Times(1)
+			})
+			It("should return the tokens of the session", func() {
+				tokens, err := manager.Refresh("refresh_token", "Patient")
+				Expect(err).To(BeNil())
+				Expect(tokens).To(Equal(&session.Tokens{RefreshTokenExpiredAt: rotated.ExpiredAt, Token: "token", RefreshToken: rotated.Token, SessionID: rotated.SessionID}))
+			})
+		})
+	})
+
+	Context("RevokeAll", func() {
+		It("should revoke every active session of the user", func() {
+			ids := []string{uuid.NewString(), uuid.NewString()}
+			mockClock.EXPECT().Now().Return(now).Times(1)
+			mockSessionDataStore.EXPECT().RevokeAllByUser(uint(7), "Doctor", now).Return(ids, nil).Times(1)
+			mockTokenService.EXPECT().RevokeRefreshToken(ids[0]).Return(nil).Times(1)
+			mockTokenService.EXPECT().RevokeRefreshToken(ids[1]).Return(nil).Times(1)
+			Expect(manager.RevokeAll(7, "Doctor")).To(Succeed())
+		})
+
+		It("should return error when revoke refresh token error", func() {
+			mockClock.EXPECT().Now().Return(now).Times(1)
+			mockSessionDataStore.EXPECT().RevokeAllByUser(uint(7), "Doctor", now).Return([]string{uuid.NewString()}, nil).Times(1)
+			mockTokenService.EXPECT().RevokeRefreshToken(gomock.Any()).Return(testhelper.MockError).Times(1)
+			Expect(manager.RevokeAll(7, "Doctor")).To(Equal(testhelper.MockError))
+		})
+	})
+})
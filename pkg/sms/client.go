@@ -3,3 +3,48 @@ package sms
 type Client interface {
 	Send(to, body string) error
 }
+
+// Channel is how the message is delivered to the patient
+type Channel string
+
+const (
+	SMSChannel   Channel = "sms"
+	EmailChannel Channel = "email"
+	LINEChannel  Channel = "line"
+)
+
+func (c Channel) IsValid() bool {
+	switch c {
+	case SMSChannel, EmailChannel, LINEChannel:
+		return true
+	default:
+		return false
+	}
+}
+
+// Recipient is the addresses of the patient on each channel. The empty address means the patient can't be reached on that channel
+type Recipient struct {
+	PhoneNumber string  `json:"phone_number"`
+	Email       string  `json:"email"`
+	LINEUserID  string  `json:"line_user_id"`
+	Preferred   Channel `json:"preferred"`
+}
+
+// Address returns the address of the recipient on the channel
+func (r Recipient) Address(channel Channel) string {
+	switch channel {
+	case SMSChannel:
+		return r.PhoneNumber
+	case EmailChannel:
+		return r.Email
+	case LINEChannel:
+		return r.LINEUserID
+	default:
+		return ""
+	}
+}
+
+// Sender delivers the message to the recipient through one of the channels and returns the channel it's delivered through
+type Sender interface {
+	Send(recipient *Recipient, body string) (Channel, error)
+}
@@ -0,0 +1,46 @@
+package sms
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type EmailConfig struct {
+	Host     string `env:"SMTP_HOST" envDefault:""`
+	Port     int    `env:"SMTP_PORT" envDefault:"587"`
+	Username string `env:"SMTP_USERNAME" envDefault:""`
+	Password string `env:"SMTP_PASSWORD" envDefault:""`
+	From     string `env:"SMTP_FROM" envDefault:""`
+	Subject  string `env:"SMTP_SUBJECT" envDefault:"Synthia Telemedicine"`
+}
+
+// SMTPClient sends the message as the plain text email
+type SMTPClient struct {
+	auth    smtp.Auth
+	addr    string
+	from    string
+	subject string
+}
+
+func NewSMTPClient(config *EmailConfig) *SMTPClient {
+	return &SMTPClient{
+		auth:    smtp.PlainAuth("", config.Username, config.Password, config.Host),
+		addr:    fmt.Sprintf("%s:%d", config.Host, config.Port),
+		from:    config.From,
+		subject: config.Subject,
+	}
+}
+
+func (c SMTPClient) Send(to, body string) error {
+	msg := strings.Join([]string{
+		fmt.Sprintf("From: %s", c.from),
+		fmt.Sprintf("To: %s", to),
+		fmt.Sprintf("Subject: %s", c.subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	return smtp.SendMail(c.addr, c.auth, c.from, []string{to}, []byte(msg))
+}
@@ -0,0 +1,64 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const linePushMessageURL = "https://api.line.me/v2/bot/message/push"
+
+type LINEConfig struct {
+	ChannelAccessToken string `env:"LINE_CHANNEL_ACCESS_TOKEN" envDefault:""`
+}
+
+// LINEClient pushes the message to the LINE user who has added the official account as a friend
+type LINEClient struct {
+	httpClient  *http.Client
+	accessToken string
+	url         string
+}
+
+func NewLINEClient(config *LINEConfig) *LINEClient {
+	return &LINEClient{
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		accessToken: config.ChannelAccessToken,
+		url:         linePushMessageURL,
+	}
+}
+
+type linePushMessage struct {
+	To       string        `json:"to"`
+	Messages []lineMessage `json:"messages"`
+}
+
+type lineMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (c LINEClient) Send(to, body string) error {
+	payload, err := json.Marshal(&linePushMessage{To: to, Messages: []lineMessage{{Type: "text", Text: body}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		resBody, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("LINE push message failed with status %d: %s", res.StatusCode, resBody)
+	}
+	return nil
+}
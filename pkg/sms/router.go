@@ -0,0 +1,58 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNoAvailableChannel = errors.New("recipient can't be reached on any channel")
+
+type RouterConfig struct {
+	FallbackOrder []Channel `env:"MESSAGE_CHANNEL_FALLBACK_ORDER" envDefault:"sms,email,line"`
+}
+
+// Router sends the message through the preferred channel of the recipient, then the other channels in the fallback order
+// until it's delivered. The channel without the client or the recipient's address is skipped
+type Router struct {
+	clients       map[Channel]Client
+	fallbackOrder []Channel
+}
+
+func NewRouter(config *RouterConfig, clients map[Channel]Client) *Router {
+	return &Router{clients: clients, fallbackOrder: config.FallbackOrder}
+}
+
+func (r Router) Send(recipient *Recipient, body string) (Channel, error) {
+	var lastErr error
+	for _, channel := range r.channels(recipient.Preferred) {
+		client, ok := r.clients[channel]
+		address := recipient.Address(channel)
+		if !ok || address == "" {
+			continue
+		}
+		if err := client.Send(address, body); err != nil {
+			lastErr = fmt.Errorf("%s channel: %w", channel, err)
+			continue
+		}
+		return channel, nil
+	}
+	// Only the error of the last tried channel is returned, as the message isn't delivered anyway
+	if lastErr == nil {
+		return "", ErrNoAvailableChannel
+	}
+	return "", lastErr
+}
+
+// channels returns the preferred channel followed by the other channels in the fallback order
+func (r Router) channels(preferred Channel) []Channel {
+	channels := make([]Channel, 0, len(r.fallbackOrder)+1)
+	if preferred.IsValid() {
+		channels = append(channels, preferred)
+	}
+	for _, channel := range r.fallbackOrder {
+		if channel != preferred {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
@@ -0,0 +1,107 @@
+package sms_test
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/sms"
+)
+
+var _ = Describe("Router", func() {
+	var (
+		router    *sms.Router
+		clients   map[sms.Channel]sms.Client
+		smsStub   *sms.StubClient
+		emailStub *sms.StubClient
+		recipient *sms.Recipient
+	)
+
+	BeforeEach(func() {
+		smsStub, emailStub = sms.NewStubClient(), sms.NewStubClient()
+		clients = map[sms.Channel]sms.Client{sms.SMSChannel: smsStub, sms.EmailChannel: emailStub, sms.LINEChannel: sms.NewStubClient()}
+		recipient = &sms.Recipient{PhoneNumber: "0812223330", Email: "patient@example.com"}
+	})
+
+	JustBeforeEach(func() {
+		router = sms.NewRouter(&sms.RouterConfig{FallbackOrder: []sms.Channel{sms.SMSChannel, sms.EmailChannel, sms.LINEChannel}}, clients)
+	})
+
+	When("recipient has no preferred channel", func() {
+		It("should send through the first channel of the fallback order", func() {
+			channel, err := router.Send(recipient, "hello")
+			Expect(err).To(BeNil())
+			Expect(channel).To(Equal(sms.SMSChannel))
+			Expect(smsStub.Messages()).To(Equal([]sms.Message{{To: recipient.PhoneNumber, Body: "hello"}}))
+			Expect(emailStub.Messages()).To(BeEmpty())
+		})
+	})
+
+	When("recipient prefers email", func() {
+		BeforeEach(func() {
+			recipient.Preferred = sms.EmailChannel
+		})
+		It("should send through email", func() {
+			channel, err := router.Send(recipient, "hello")
+			Expect(err).To(BeNil())
+			Expect(channel).To(Equal(sms.EmailChannel))
+			Expect(emailStub.Messages()).To(Equal([]sms.Message{{To: recipient.Email, Body: "hello"}}))
+			Expect(smsStub.Messages()).To(BeEmpty())
+		})
+	})
+
+	When("recipient prefers the channel without the address", func() {
+		BeforeEach(func() {
+			recipient.Preferred = sms.LINEChannel
+		})
+		It("should fall back to the next channel", func() {
+			channel, err := router.Send(recipient, "hello")
+			Expect(err).To(BeNil())
+			Expect(channel).To(Equal(sms.SMSChannel))
+		})
+	})
+
+	When("the preferred channel fails", func() {
+		BeforeEach(func() {
+			recipient.Preferred = sms.SMSChannel
+			clients[sms.SMSChannel] = sms.NewFailingStubClient(errors.New("SMS is down"))
+		})
+		It("should fall back to the next channel", func() {
+			channel, err := router.Send(recipient, "hello")
+			Expect(err).To(BeNil())
+			Expect(channel).To(Equal(sms.EmailChannel))
+			Expect(emailStub.Messages()).To(HaveLen(1))
+		})
+	})
+
+	When("the channel isn't configured", func() {
+		BeforeEach(func() {
+			delete(clients, sms.SMSChannel)
+		})
+		It("should skip the channel", func() {
+			channel, err := router.Send(recipient, "hello")
+			Expect(err).To(BeNil())
+			Expect(channel).To(Equal(sms.EmailChannel))
+		})
+	})
+
+	When("all channels fail", func() {
+		BeforeEach(func() {
+			clients[sms.SMSChannel] = sms.NewFailingStubClient(errors.New("SMS is down"))
+			clients[sms.EmailChannel] = sms.NewFailingStubClient(errors.New("email is down"))
+		})
+		It("should return the error of the last channel", func() {
+			_, err := router.Send(recipient, "hello")
+			Expect(err).To(MatchError(ContainSubstring("email is down")))
+		})
+	})
+
+	When("recipient can't be reached on any channel", func() {
+		BeforeEach(func() {
+			recipient = &sms.Recipient{}
+		})
+		It("should return ErrNoAvailableChannel", func() {
+			_, err := router.Send(recipient, "hello")
+			Expect(err).To(Equal(sms.ErrNoAvailableChannel))
+		})
+	})
+})
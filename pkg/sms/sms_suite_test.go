@@ -0,0 +1,13 @@
+package sms_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSms(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SMS Suite")
+}
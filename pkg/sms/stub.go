@@ -0,0 +1,42 @@
+package sms
+
+import "sync"
+
+// Message is the message sent by the StubClient
+type Message struct {
+	To   string
+	Body string
+}
+
+// StubClient keeps the sent messages in memory instead of delivering them, so any channel can be used locally and in tests
+type StubClient struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+func NewStubClient() *StubClient {
+	return &StubClient{}
+}
+
+// NewFailingStubClient returns the stub which fails to send every message with err, e.g. the channel is down
+func NewFailingStubClient(err error) *StubClient {
+	return &StubClient{err: err}
+}
+
+func (c *StubClient) Send(to, body string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, Message{To: to, Body: body})
+	return nil
+}
+
+// Messages returns the sent messages from oldest to latest
+func (c *StubClient) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
@@ -4,17 +4,41 @@ import (
 	"context"
 	"github.com/synthia-telemed/backend-api/pkg/token/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"time"
 )
 
 type Service interface {
-	GenerateToken(userID uint64, role string) (string, error)
+	// GenerateToken issues the JWS of the user which carries the session ID, so it's rejected once the session is revoked
+	GenerateToken(userID uint64, role, sessionID string) (string, error)
+	GenerateRefreshToken(userID uint64, role, sessionID string) (*RefreshToken, error)
+	// RotateRefreshToken returns nil if the refresh token is invalid, expired or its session is revoked
+	RotateRefreshToken(refreshToken string) (*RotatedRefreshToken, error)
+	RevokeRefreshToken(sessionID string) error
 }
 
 type Config struct {
 	Endpoint string `env:"TOKEN_SERVICE_ENDPOINT,required"`
 }
 
+type RefreshToken struct {
+	ExpiredAt time.Time
+	Token     string
+	// ID is the ID of the refresh token, which is kept in the session to detect the reuse of the rotated token
+	ID string
+}
+
+// RotatedRefreshToken is the next refresh token of the session
+type RotatedRefreshToken struct {
+	RefreshToken
+	Role            string
+	SessionID       string
+	PreviousTokenID string
+	UserID          uint64
+}
+
 type GRPCTokenService struct {
 	tokenClient proto.TokenClient
 }
@@ -34,10 +58,11 @@ func NewGRPCTokenServiceWithClient(tokenClient proto.TokenClient) *GRPCTokenServ
 	return &GRPCTokenService{tokenClient: tokenClient}
 }
 
-func (s GRPCTokenService) GenerateToken(userID uint64, role string) (string, error) {
+func (s GRPCTokenService) GenerateToken(userID uint64, role, sessionID string) (string, error) {
 	req := &proto.GenerateTokenRequest{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 	}
 	res, err := s.tokenClient.GenerateToken(context.Background(), req)
 	if err != nil {
@@ -45,3 +70,46 @@ func (s GRPCTokenService) GenerateToken(userID uint64, role string) (string, err
 	}
 	return res.GetToken(), nil
 }
+
+func (s GRPCTokenService) GenerateRefreshToken(userID uint64, role, sessionID string) (*RefreshToken, error) {
+	req := &proto.GenerateRefreshTokenRequest{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+	}
+	res, err := s.tokenClient.GenerateRefreshToken(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	return parseRefreshToken(res), nil
+}
+
+func (s GRPCTokenService) RotateRefreshToken(refreshToken string) (*RotatedRefreshToken, error) {
+	res, err := s.tokenClient.RotateRefreshToken(context.Background(), &proto.RotateRefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		if code := status.Code(err); code == codes.Unauthenticated || code == codes.InvalidArgument {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &RotatedRefreshToken{
+		RefreshToken:    *parseRefreshToken(res.GetRefreshToken()),
+		Role:            res.GetRole(),
+		SessionID:       res.GetSessionID(),
+		PreviousTokenID: res.GetPreviousTokenID(),
+		UserID:          res.GetUserID(),
+	}, nil
+}
+
+func (s GRPCTokenService) RevokeRefreshToken(sessionID string) error {
+	_, err := s.tokenClient.RevokeRefreshToken(context.Background(), &proto.RevokeRefreshTokenRequest{SessionID: sessionID})
+	return err
+}
+
+func parseRefreshToken(res *proto.RefreshTokenResponse) *RefreshToken {
+	return &RefreshToken{
+		ExpiredAt: time.Unix(res.GetExpiredAt(), 0),
+		Token:     res.GetRefreshToken(),
+		ID:        res.GetTokenID(),
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/synthia-telemed/backend-api/pkg/token"
 	pb "github.com/synthia-telemed/backend-api/pkg/token/proto"
 	"github.com/synthia-telemed/backend-api/test/mock_token_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
 )
 
 var _ = Describe("Token gRPC Service", func() {
@@ -26,8 +29,9 @@ var _ = Describe("Token gRPC Service", func() {
 		tokenService = token.NewGRPCTokenServiceWithClient(mockTokenClient)
 
 		req = &pb.GenerateTokenRequest{
-			Role:   "doctor",
-			UserID: 99,
+			Role:      "doctor",
+			UserID:    99,
+			SessionID: "session-id",
 		}
 		res = &pb.TokenResponse{Token: "signed_token"}
 	})
@@ -42,7 +46,7 @@ var _ = Describe("Token gRPC Service", func() {
 		})
 
 		It("should return token", func() {
-			token, err := tokenService.GenerateToken(req.UserID, req.Role)
+			token, err := tokenService.GenerateToken(req.UserID, req.Role, req.SessionID)
 			Expect(err).To(BeNil())
 			Expect(token).To(Equal(res.Token))
 		})
@@ -54,9 +58,88 @@ var _ = Describe("Token gRPC Service", func() {
 		})
 
 		It("should return error", func() {
-			token, err := tokenService.GenerateToken(req.UserID, req.Role)
+			token, err := tokenService.GenerateToken(req.UserID, req.Role, req.SessionID)
 			Expect(err).ToNot(BeNil())
 			Expect(token).To(BeEmpty())
 		})
 	})
+
+	Context("GenerateRefreshToken", func() {
+		var refreshReq *pb.GenerateRefreshTokenRequest
+		BeforeEach(func() {
+			refreshReq = &pb.GenerateRefreshTokenRequest{Role: "doctor", UserID: 99, SessionID: "session-id"}
+		})
+
+		When("generation success", func() {
+			BeforeEach(func() {
+				mockTokenClient.EXPECT().GenerateRefreshToken(gomock.Any(), refreshReq).Return(&pb.RefreshTokenResponse{RefreshToken: "refresh_token", TokenID: "token-id", ExpiredAt: 1700000000}, nil).Times(1)
+			})
+
+			It("should return refresh token with its ID and expiry", func() {
+				refreshToken, err := tokenService.GenerateRefreshToken(refreshReq.UserID, refreshReq.Role, refreshReq.SessionID)
+				Expect(err).To(BeNil())
+				Expect(refreshToken).To(Equal(&token.RefreshToken{Token: "refresh_token", ID: "token-id", ExpiredAt: time.Unix(1700000000, 0)}))
+			})
+		})
+	})
+
+	Context("RotateRefreshToken", func() {
+		rotateReq := &pb.RotateRefreshTokenRequest{RefreshToken: "refresh_token"}
+
+		When("refresh token is rejected", func() {
+			BeforeEach(func() {
+				mockTokenClient.EXPECT().RotateRefreshToken(gomock.Any(), rotateReq).Return(nil, status.Error(codes.Unauthenticated, "token is revoked")).Times(1)
+			})
+
+			It("should return nil without error", func() {
+				rotated, err := tokenService.RotateRefreshToken(rotateReq.RefreshToken)
+				Expect(err).To(BeNil())
+				Expect(rotated).To(BeNil())
+			})
+		})
+
+		When("token service is unavailable", func() {
+			BeforeEach(func() {
+				mockTokenClient.EXPECT().RotateRefreshToken(gomock.Any(), rotateReq).Return(nil, status.Error(codes.Unavailable, "unavailable")).Times(1)
+			})
+
+			It("should return error", func() {
+				rotated, err := tokenService.RotateRefreshToken(rotateReq.RefreshToken)
+				Expect(err).ToNot(BeNil())
+				Expect(rotated).To(BeNil())
+			})
+		})
+
+		When("refresh token is rotated", func() {
+			BeforeEach(func() {
+				res := &pb.RotateRefreshTokenResponse{
+					UserID:          99,
+					Role:            "doctor",
+					SessionID:       "session-id",
+					PreviousTokenID: "previous-token-id",
+					RefreshToken:    &pb.RefreshTokenResponse{RefreshToken: "next_refresh_token", TokenID: "token-id", ExpiredAt: 1700000000},
+				}
+				mockTokenClient.EXPECT().RotateRefreshToken(gomock.Any(), rotateReq).Return(res, nil).Times(1)
+			})
+
+			It("should return the next refresh token of the session", func() {
+				rotated, err := tokenService.RotateRefreshToken(rotateReq.RefreshToken)
+				Expect(err).To(BeNil())
+				Expect(rotated).To(Equal(&token.RotatedRefreshToken{
+					RefreshToken:    token.RefreshToken{Token: "next_refresh_token", ID: "token-id", ExpiredAt: time.Unix(1700000000, 0)},
+					Role:            "doctor",
+					SessionID:       "session-id",
+					PreviousTokenID: "previous-token-id",
+					UserID:          99,
+				}))
+			})
+		})
+	})
+
+	Context("RevokeRefreshToken", func() {
+		It("should revoke the session", func() {
+			mockTokenClient.EXPECT().RevokeRefreshToken(gomock.Any(), &pb.RevokeRefreshTokenRequest{SessionID: "session-id"}).Return(&pb.RevokeRefreshTokenResponse{}, nil).Times(1)
+			Expect(tokenService.RevokeRefreshToken("session-id")).To(Succeed())
+		})
+	})
 })
@@ -0,0 +1,298 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/synthia-telemed/backend-api/pkg/clock"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoVerificationKey = errors.New("either public key or JWKS endpoint of the token service is required")
+
+type Verifier interface {
+	// Verify checks the signature and the expiry of the JWS. It returns nil if the JWS is invalid or expired
+	Verify(jws string) (*Claims, error)
+}
+
+type VerifierConfig struct {
+	// PublicKey is the PEM encoded public key of the token service, which takes precedence over the JWKS endpoint
+	PublicKey       string        `env:"TOKEN_PUBLIC_KEY"`
+	JWKSEndpoint    string        `env:"TOKEN_JWKS_ENDPOINT"`
+	JWKSRefreshWait time.Duration `env:"TOKEN_JWKS_REFRESH_WAIT" envDefault:"1m"`
+	// Leeway is the tolerance of the clock skew between the token service and the API
+	Leeway time.Duration `env:"TOKEN_LEEWAY" envDefault:"30s"`
+}
+
+// Claims is the payload of the JWS issued by GenerateToken
+type Claims struct {
+	Role      string `json:"role"`
+	SessionID string `json:"sessionID"`
+	UserID    uint64 `json:"userID"`
+	ExpiredAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+type jwsHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWSVerifier verifies the JWS with either the static public key or the keys in the JWKS of the token service.
+// The JWKS is fetched again when the JWS is signed by an unknown key, but not more often than the refresh wait
+type JWSVerifier struct {
+	clock           clock.Clock
+	httpClient      *http.Client
+	keys            map[string]crypto.PublicKey
+	staticKey       crypto.PublicKey
+	lastFetchedAt   time.Time
+	jwksEndpoint    string
+	jwksRefreshWait time.Duration
+	leeway          time.Duration
+	mu              sync.Mutex
+}
+
+func NewJWSVerifier(config *VerifierConfig, c clock.Clock) (*JWSVerifier, error) {
+	v := &JWSVerifier{
+		clock:           c,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+		keys:            map[string]crypto.PublicKey{},
+		jwksEndpoint:    config.JWKSEndpoint,
+		jwksRefreshWait: config.JWKSRefreshWait,
+		leeway:          config.Leeway,
+	}
+	if config.PublicKey != "" {
+		key, err := parsePublicKeyPEM(config.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		v.staticKey = key
+		return v, nil
+	}
+	if config.JWKSEndpoint == "" {
+		return nil, ErrNoVerificationKey
+	}
+	return v, nil
+}
+
+func (v *JWSVerifier) Verify(jws string) (*Claims, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return nil, nil
+	}
+	var header jwsHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, nil
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil
+	}
+	key, err := v.publicKey(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !verifySignature(header.Algorithm, key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, nil
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, nil
+	}
+	now := v.clock.Now()
+	if claims.ExpiredAt == 0 || now.After(time.Unix(claims.ExpiredAt, 0).Add(v.leeway)) {
+		return nil, nil
+	}
+	if claims.NotBefore != 0 && now.Add(v.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, nil
+	}
+	if claims.UserID == 0 {
+		return nil, nil
+	}
+	return &claims, nil
+}
+
+// publicKey returns the key with the ID, which is fetched from the JWKS if it's unknown. It returns nil if the key isn't found.
+// The fetch is made without holding the lock and its time is recorded even if it fails, so the unreachable endpoint is tried
+// only once per refresh wait instead of stalling every request signed by an unknown key
+func (v *JWSVerifier) publicKey(keyID string) (crypto.PublicKey, error) {
+	if v.staticKey != nil {
+		return v.staticKey, nil
+	}
+	v.mu.Lock()
+	if key, ok := v.keys[keyID]; ok {
+		v.mu.Unlock()
+		return key, nil
+	}
+	now := v.clock.Now()
+	if !v.lastFetchedAt.IsZero() && now.Before(v.lastFetchedAt.Add(v.jwksRefreshWait)) {
+		v.mu.Unlock()
+		return nil, nil
+	}
+	v.lastFetchedAt = now
+	v.mu.Unlock()
+
+	keys, err := v.fetchJWKS()
+	if err != nil {
+		return nil, err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+	return v.keys[keyID], nil
+}
+
+func (v *JWSVerifier) fetchJWKS() (map[string]crypto.PublicKey, error) {
+	res, err := v.httpClient.Get(v.jwksEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: unexpected status %d", res.StatusCode)
+	}
+	var set jsonWebKeySet
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		// The key of the unsupported type is skipped, so the others are still usable
+		if key, err := k.publicKey(); err == nil {
+			keys[k.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.KeyType)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signingInput, signature []byte) bool {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		k, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(k, signingInput, signature)
+	default:
+		return false
+	}
+	h := hash.New()
+	h.Write(signingInput)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(k, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		// The ECDSA signature of JWS is the concatenation of R and S, each padded to the size of the curve
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, digest, r, s)
+	}
+	return false
+}
+
+func parsePublicKeyPEM(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("invalid PEM encoded public key")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
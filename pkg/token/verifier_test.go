@@ -0,0 +1,220 @@
+package token_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/synthia-telemed/backend-api/pkg/token"
+	"github.com/synthia-telemed/backend-api/test/mock_clock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+var _ = Describe("JWS Verifier", func() {
+	var (
+		mockCtrl  *gomock.Controller
+		mockClock *mock_clock.MockClock
+		config    *token.VerifierConfig
+		now       time.Time
+		claims    *token.Claims
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClock = mock_clock.NewMockClock(mockCtrl)
+		now = time.Now()
+		mockClock.EXPECT().Now().Return(now).AnyTimes()
+		config = &token.VerifierConfig{JWKSRefreshWait: time.Minute, Leeway: 30 * time.Second}
+		claims = &token.Claims{
+			Role:      "Patient",
+			SessionID: "session-id",
+			UserID:    99,
+			ExpiredAt: now.Add(time.Hour).Unix(),
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("should require either public key or JWKS endpoint", func() {
+		_, err := token.NewJWSVerifier(config, mockClock)
+		Expect(err).To(Equal(token.ErrNoVerificationKey))
+	})
+
+	Context("Public key", func() {
+		var (
+			privateKey *rsa.PrivateKey
+			verifier   *token.JWSVerifier
+		)
+
+		BeforeEach(func() {
+			var err error
+			privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).To(BeNil())
+			der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+			Expect(err).To(BeNil())
+			config.PublicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+			verifier, err = token.NewJWSVerifier(config, mockClock)
+			Expect(err).To(BeNil())
+		})
+
+		It("should return claims of the valid JWS", func() {
+			c, err := verifier.Verify(signRS256(privateKey, "", claims))
+			Expect(err).To(BeNil())
+			Expect(c).To(Equal(claims))
+		})
+
+		It("should return nil if the JWS is expired", func() {
+			claims.ExpiredAt = now.Add(-time.Minute).Unix()
+			c, err := verifier.Verify(signRS256(privateKey, "", claims))
+			Expect(err).To(BeNil())
+			Expect(c).To(BeNil())
+		})
+
+		It("should accept the JWS expired within the leeway", func() {
+			claims.ExpiredAt = now.Add(-10 * time.Second).Unix()
+			c, err := verifier.Verify(signRS256(privateKey, "", claims))
+			Expect(err).To(BeNil())
+			Expect(c).ToNot(BeNil())
+		})
+
+		It("should return nil if the JWS is signed by the other key", func() {
+			otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).To(BeNil())
+			c, err := verifier.Verify(signRS256(otherKey, "", claims))
+			Expect(err).To(BeNil())
+			Expect(c).To(BeNil())
+		})
+
+		It("should return nil if the payload is tampered", func() {
+			jws := signRS256(privateKey, "", claims)
+			claims.UserID = 1
+			tampered := signRS256(privateKey, "", claims)
+			c, err := verifier.Verify(replaceSegment(jws, 1, tampered))
+			Expect(err).To(BeNil())
+			Expect(c).To(BeNil())
+		})
+
+		It("should return nil if the JWS is malformed", func() {
+			c, err := verifier.Verify("malformed")
+			Expect(err).To(BeNil())
+			Expect(c).To(BeNil())
+		})
+
+		It("should return nil if the algorithm is none", func() {
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+			payload, _ := json.Marshal(claims)
+			c, err := verifier.Verify(header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".")
+			Expect(err).To(BeNil())
+			Expect(c).To(BeNil())
+		})
+	})
+
+	Context("JWKS", func() {
+		var (
+			privateKey *ecdsa.PrivateKey
+			jwksServer *httptest.Server
+			fetchCount int
+			verifier   *token.JWSVerifier
+		)
+
+		BeforeEach(func() {
+			var err error
+			privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).To(BeNil())
+			fetchCount = 0
+			jwksServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fetchCount++
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"keys": []map[string]string{
+						{"kty": "RSA", "kid": "unsupported", "n": "!", "e": "AQAB"},
+						{
+							"kty": "EC",
+							"kid": "key-1",
+							"crv": "P-256",
+							"x":   base64.RawURLEncoding.EncodeToString(privateKey.X.FillBytes(make([]byte, 32))),
+							"y":   base64.RawURLEncoding.EncodeToString(privateKey.Y.FillBytes(make([]byte, 32))),
+						},
+					},
+				})
+			}))
+			config.JWKSEndpoint = jwksServer.URL
+			verifier, err = token.NewJWSVerifier(config, mockClock)
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			jwksServer.Close()
+		})
+
+		It("should verify the JWS with the key in JWKS and cache the keys", func() {
+			for i := 0; i < 2; i++ {
+				c, err := verifier.Verify(signES256(privateKey, "key-1", claims))
+				Expect(err).To(BeNil())
+				Expect(c).To(Equal(claims))
+			}
+			Expect(fetchCount).To(Equal(1))
+		})
+
+		It("should not fetch JWKS again within the refresh wait when the key is unknown", func() {
+			for i := 0; i < 2; i++ {
+				c, err := verifier.Verify(signES256(privateKey, "unknown", claims))
+				Expect(err).To(BeNil())
+				Expect(c).To(BeNil())
+			}
+			Expect(fetchCount).To(Equal(1))
+		})
+
+		It("should return error if JWKS can't be fetched and not fetch again within the refresh wait", func() {
+			jwksServer.Close()
+			_, err := verifier.Verify(signES256(privateKey, "key-1", claims))
+			Expect(err).ToNot(BeNil())
+			c, err := verifier.Verify(signES256(privateKey, "key-1", claims))
+			Expect(err).To(BeNil())
+			Expect(c).To(BeNil())
+		})
+	})
+})
+
+func encodeSigningInput(alg, keyID string, claims *token.Claims) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": keyID, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func signRS256(key *rsa.PrivateKey, keyID string, claims *token.Claims) string {
+	input := encodeSigningInput("RS256", keyID, claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	Expect(err).To(BeNil())
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func signES256(key *ecdsa.PrivateKey, keyID string, claims *token.Claims) string {
+	input := encodeSigningInput("ES256", keyID, claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	Expect(err).To(BeNil())
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// replaceSegment replaces the segment at the index of the JWS with the one of the other JWS
+func replaceSegment(jws string, index int, other string) string {
+	parts := strings.Split(jws, ".")
+	parts[index] = strings.Split(other, ".")[index]
+	return strings.Join(parts, ".")
+}
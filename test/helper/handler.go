@@ -49,6 +49,8 @@ func GenerateCreditCard() *datastore.CreditCard {
 		Brand:       "Visa",
 		PatientID:   uint(rand.Uint32()),
 		CardID:      uuid.New().String(),
+		ExpiryMonth: 12,
+		ExpiryYear:  time.Now().Year() + 1,
 	}
 }
 
@@ -61,12 +63,12 @@ func GenerateCreditCards(n int) []datastore.CreditCard {
 }
 
 func GeneratePaymentAndDataStoreCard(patientID uint, name string, isDefault bool) (*payment.Card, *datastore.CreditCard) {
-	expiry := fmt.Sprintf("12/%d", time.Now().Year())
 	pCard := &payment.Card{
 		ID:          uuid.New().String(),
 		Last4Digits: fmt.Sprintf("%d", rand.Intn(10000)),
 		Brand:       "MasterCard",
-		Expiry:      expiry,
+		ExpiryMonth: 12,
+		ExpiryYear:  time.Now().Year() + 1,
 	}
 	dCard := &datastore.CreditCard{
 		Last4Digits: pCard.Last4Digits,
@@ -75,7 +77,8 @@ func GeneratePaymentAndDataStoreCard(patientID uint, name string, isDefault bool
 		CardID:      pCard.ID,
 		Name:        name,
 		IsDefault:   isDefault,
-		Expiry:      expiry,
+		ExpiryMonth: pCard.ExpiryMonth,
+		ExpiryYear:  pCard.ExpiryYear,
 	}
 	return pCard, dCard
 }
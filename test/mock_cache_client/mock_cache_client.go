@@ -54,6 +54,40 @@ func (mr *MockClientMockRecorder) Delete(ctx interface{}, keys ...interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClient)(nil).Delete), varargs...)
 }
 
+// DeleteIfEquals mocks base method.
+func (m *MockClient) DeleteIfEquals(ctx context.Context, key, value string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIfEquals", ctx, key, value)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIfEquals indicates an expected call of DeleteIfEquals.
+func (mr *MockClientMockRecorder) DeleteIfEquals(ctx, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIfEquals", reflect.TypeOf((*MockClient)(nil).DeleteIfEquals), ctx, key, value)
+}
+
+// Expire mocks base method.
+func (m *MockClient) Expire(ctx context.Context, expiredIn time.Duration, keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, expiredIn}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Expire", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockClientMockRecorder) Expire(ctx, expiredIn interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, expiredIn}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockClient)(nil).Expire), varargs...)
+}
+
 // Get mocks base method.
 func (m *MockClient) Get(ctx context.Context, key string, getAndDelete bool) (string, error) {
 	m.ctrl.T.Helper()
@@ -98,6 +132,22 @@ func (mr *MockClientMockRecorder) HashSet(ctx, key, kv interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashSet", reflect.TypeOf((*MockClient)(nil).HashSet), ctx, key, kv)
 }
 
+// Increment mocks base method.
+func (m *MockClient) Increment(ctx context.Context, key string, expiredIn time.Duration) (int, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Increment", ctx, key, expiredIn)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Increment indicates an expected call of Increment.
+func (mr *MockClientMockRecorder) Increment(ctx, key, expiredIn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockClient)(nil).Increment), ctx, key, expiredIn)
+}
+
 // MultipleGet mocks base method.
 func (m *MockClient) MultipleGet(ctx context.Context, keys ...string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -132,6 +182,20 @@ func (mr *MockClientMockRecorder) MultipleSet(ctx, kv interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MultipleSet", reflect.TypeOf((*MockClient)(nil).MultipleSet), ctx, kv)
 }
 
+// Publish mocks base method.
+func (m *MockClient) Publish(ctx context.Context, channel, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, channel, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockClientMockRecorder) Publish(ctx, channel, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockClient)(nil).Publish), ctx, channel, message)
+}
+
 // Set mocks base method.
 func (m *MockClient) Set(ctx context.Context, key, value string, expiredIn time.Duration) error {
 	m.ctrl.T.Helper()
@@ -145,3 +209,98 @@ func (mr *MockClientMockRecorder) Set(ctx, key, value, expiredIn interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockClient)(nil).Set), ctx, key, value, expiredIn)
 }
+
+// SetIfNotExists mocks base method.
+func (m *MockClient) SetIfNotExists(ctx context.Context, key, value string, expiredIn time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetIfNotExists", ctx, key, value, expiredIn)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetIfNotExists indicates an expected call of SetIfNotExists.
+func (mr *MockClientMockRecorder) SetIfNotExists(ctx, key, value, expiredIn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIfNotExists", reflect.TypeOf((*MockClient)(nil).SetIfNotExists), ctx, key, value, expiredIn)
+}
+
+// SortedSetAdd mocks base method.
+func (m *MockClient) SortedSetAdd(ctx context.Context, key, member string, score float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SortedSetAdd", ctx, key, member, score)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SortedSetAdd indicates an expected call of SortedSetAdd.
+func (mr *MockClientMockRecorder) SortedSetAdd(ctx, key, member, score interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SortedSetAdd", reflect.TypeOf((*MockClient)(nil).SortedSetAdd), ctx, key, member, score)
+}
+
+// SortedSetRangeByMaxScore mocks base method.
+func (m *MockClient) SortedSetRangeByMaxScore(ctx context.Context, key string, max float64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SortedSetRangeByMaxScore", ctx, key, max)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SortedSetRangeByMaxScore indicates an expected call of SortedSetRangeByMaxScore.
+func (mr *MockClientMockRecorder) SortedSetRangeByMaxScore(ctx, key, max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SortedSetRangeByMaxScore", reflect.TypeOf((*MockClient)(nil).SortedSetRangeByMaxScore), ctx, key, max)
+}
+
+// SortedSetRemove mocks base method.
+func (m *MockClient) SortedSetRemove(ctx context.Context, key string, members ...string) (int, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key}
+	for _, a := range members {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SortedSetRemove", varargs...)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SortedSetRemove indicates an expected call of SortedSetRemove.
+func (mr *MockClientMockRecorder) SortedSetRemove(ctx, key interface{}, members ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key}, members...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SortedSetRemove", reflect.TypeOf((*MockClient)(nil).SortedSetRemove), varargs...)
+}
+
+// SortedSetScore mocks base method.
+func (m *MockClient) SortedSetScore(ctx context.Context, key, member string) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SortedSetScore", ctx, key, member)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SortedSetScore indicates an expected call of SortedSetScore.
+func (mr *MockClientMockRecorder) SortedSetScore(ctx, key, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SortedSetScore", reflect.TypeOf((*MockClient)(nil).SortedSetScore), ctx, key, member)
+}
+
+// Subscribe mocks base method.
+func (m *MockClient) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, channel)
+	ret0, _ := ret[0].(<-chan string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockClientMockRecorder) Subscribe(ctx, channel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockClient)(nil).Subscribe), ctx, channel)
+}
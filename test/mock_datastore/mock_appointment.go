@@ -62,3 +62,18 @@ func (mr *MockAppointmentDataStoreMockRecorder) FindByRefID(refID interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByRefID", reflect.TypeOf((*MockAppointmentDataStore)(nil).FindByRefID), refID)
 }
+
+// ListRefIDsByOutcome mocks base method.
+func (m *MockAppointmentDataStore) ListRefIDsByOutcome(refIDs []string, outcome datastore.AppointmentOutcome) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRefIDsByOutcome", refIDs, outcome)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRefIDsByOutcome indicates an expected call of ListRefIDsByOutcome.
+func (mr *MockAppointmentDataStoreMockRecorder) ListRefIDsByOutcome(refIDs, outcome interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRefIDsByOutcome", reflect.TypeOf((*MockAppointmentDataStore)(nil).ListRefIDsByOutcome), refIDs, outcome)
+}
@@ -6,6 +6,7 @@ package mock_datastore
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	datastore "github.com/synthia-telemed/backend-api/pkg/datastore"
@@ -34,6 +35,21 @@ func (m *MockCreditCardDataStore) EXPECT() *MockCreditCardDataStoreMockRecorder
 	return m.recorder
 }
 
+// ClaimExpiryNotification mocks base method.
+func (m *MockCreditCardDataStore) ClaimExpiryNotification(cardID uint, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExpiryNotification", cardID, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExpiryNotification indicates an expected call of ClaimExpiryNotification.
+func (mr *MockCreditCardDataStoreMockRecorder) ClaimExpiryNotification(cardID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExpiryNotification", reflect.TypeOf((*MockCreditCardDataStore)(nil).ClaimExpiryNotification), cardID, now)
+}
+
 // Count mocks base method.
 func (m *MockCreditCardDataStore) Count(patientID uint) (int, error) {
 	m.ctrl.T.Helper()
@@ -122,6 +138,21 @@ func (mr *MockCreditCardDataStoreMockRecorder) IsOwnCreditCard(patientID, cardID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOwnCreditCard", reflect.TypeOf((*MockCreditCardDataStore)(nil).IsOwnCreditCard), patientID, cardID)
 }
 
+// ListExpiringDefault mocks base method.
+func (m *MockCreditCardDataStore) ListExpiringDefault(from, to time.Time) ([]datastore.CreditCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiringDefault", from, to)
+	ret0, _ := ret[0].([]datastore.CreditCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiringDefault indicates an expected call of ListExpiringDefault.
+func (mr *MockCreditCardDataStoreMockRecorder) ListExpiringDefault(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiringDefault", reflect.TypeOf((*MockCreditCardDataStore)(nil).ListExpiringDefault), from, to)
+}
+
 // SetAllToNonDefault mocks base method.
 func (m *MockCreditCardDataStore) SetAllToNonDefault(patientID uint) error {
 	m.ctrl.T.Helper()
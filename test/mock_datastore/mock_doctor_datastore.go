@@ -49,6 +49,21 @@ func (mr *MockDoctorDataStoreMockRecorder) FindByID(id interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockDoctorDataStore)(nil).FindByID), id)
 }
 
+// FindByRefID mocks base method.
+func (m *MockDoctorDataStore) FindByRefID(refID string) (*datastore.Doctor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByRefID", refID)
+	ret0, _ := ret[0].(*datastore.Doctor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByRefID indicates an expected call of FindByRefID.
+func (mr *MockDoctorDataStoreMockRecorder) FindByRefID(refID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByRefID", reflect.TypeOf((*MockDoctorDataStore)(nil).FindByRefID), refID)
+}
+
 // FindOrCreate mocks base method.
 func (m *MockDoctorDataStore) FindOrCreate(doctor *datastore.Doctor) error {
 	m.ctrl.T.Helper()
@@ -62,3 +77,17 @@ func (mr *MockDoctorDataStoreMockRecorder) FindOrCreate(doctor interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrCreate", reflect.TypeOf((*MockDoctorDataStore)(nil).FindOrCreate), doctor)
 }
+
+// SetNotificationToken mocks base method.
+func (m *MockDoctorDataStore) SetNotificationToken(id uint, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationToken", id, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationToken indicates an expected call of SetNotificationToken.
+func (mr *MockDoctorDataStoreMockRecorder) SetNotificationToken(id, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationToken", reflect.TypeOf((*MockDoctorDataStore)(nil).SetNotificationToken), id, token)
+}
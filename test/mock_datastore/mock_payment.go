@@ -6,6 +6,7 @@ package mock_datastore
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	datastore "github.com/synthia-telemed/backend-api/pkg/datastore"
@@ -34,6 +35,21 @@ func (m *MockPaymentDataStore) EXPECT() *MockPaymentDataStoreMockRecorder {
 	return m.recorder
 }
 
+// CountByPatientID mocks base method.
+func (m *MockPaymentDataStore) CountByPatientID(patientID uint, filters *datastore.PaymentFilters) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByPatientID", patientID, filters)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByPatientID indicates an expected call of CountByPatientID.
+func (mr *MockPaymentDataStoreMockRecorder) CountByPatientID(patientID, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByPatientID", reflect.TypeOf((*MockPaymentDataStore)(nil).CountByPatientID), patientID, filters)
+}
+
 // Create mocks base method.
 func (m *MockPaymentDataStore) Create(payment *datastore.Payment) error {
 	m.ctrl.T.Helper()
@@ -48,6 +64,65 @@ func (mr *MockPaymentDataStoreMockRecorder) Create(payment interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPaymentDataStore)(nil).Create), payment)
 }
 
+// CreateLastSplitPart mocks base method.
+func (m *MockPaymentDataStore) CreateLastSplitPart(payment *datastore.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLastSplitPart", payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLastSplitPart indicates an expected call of CreateLastSplitPart.
+func (mr *MockPaymentDataStoreMockRecorder) CreateLastSplitPart(payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLastSplitPart", reflect.TypeOf((*MockPaymentDataStore)(nil).CreateLastSplitPart), payment)
+}
+
+// FindByChargeID mocks base method.
+func (m *MockPaymentDataStore) FindByChargeID(chargeID string) (*datastore.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByChargeID", chargeID)
+	ret0, _ := ret[0].(*datastore.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByChargeID indicates an expected call of FindByChargeID.
+func (mr *MockPaymentDataStoreMockRecorder) FindByChargeID(chargeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByChargeID", reflect.TypeOf((*MockPaymentDataStore)(nil).FindByChargeID), chargeID)
+}
+
+// FindByID mocks base method.
+func (m *MockPaymentDataStore) FindByID(id uint) (*datastore.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", id)
+	ret0, _ := ret[0].(*datastore.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockPaymentDataStoreMockRecorder) FindByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockPaymentDataStore)(nil).FindByID), id)
+}
+
+// FindLatestByInvoiceID mocks base method.
+func (m *MockPaymentDataStore) FindLatestByInvoiceID(invoiceID int) (*datastore.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatestByInvoiceID", invoiceID)
+	ret0, _ := ret[0].(*datastore.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatestByInvoiceID indicates an expected call of FindLatestByInvoiceID.
+func (mr *MockPaymentDataStoreMockRecorder) FindLatestByInvoiceID(invoiceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatestByInvoiceID", reflect.TypeOf((*MockPaymentDataStore)(nil).FindLatestByInvoiceID), invoiceID)
+}
+
 // FindLatestByInvoiceIDAndStatus mocks base method.
 func (m *MockPaymentDataStore) FindLatestByInvoiceIDAndStatus(invoiceID int, status datastore.PaymentStatus) (*datastore.Payment, error) {
 	m.ctrl.T.Helper()
@@ -62,3 +137,91 @@ func (mr *MockPaymentDataStoreMockRecorder) FindLatestByInvoiceIDAndStatus(invoi
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatestByInvoiceIDAndStatus", reflect.TypeOf((*MockPaymentDataStore)(nil).FindLatestByInvoiceIDAndStatus), invoiceID, status)
 }
+
+// ListByPatientID mocks base method.
+func (m *MockPaymentDataStore) ListByPatientID(patientID uint, filters *datastore.PaymentFilters, take, skip int) ([]datastore.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPatientID", patientID, filters, take, skip)
+	ret0, _ := ret[0].([]datastore.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPatientID indicates an expected call of ListByPatientID.
+func (mr *MockPaymentDataStoreMockRecorder) ListByPatientID(patientID, filters, take, skip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPatientID", reflect.TypeOf((*MockPaymentDataStore)(nil).ListByPatientID), patientID, filters, take, skip)
+}
+
+// ListCreatedBetween mocks base method.
+func (m *MockPaymentDataStore) ListCreatedBetween(from, to time.Time) ([]datastore.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCreatedBetween", from, to)
+	ret0, _ := ret[0].([]datastore.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCreatedBetween indicates an expected call of ListCreatedBetween.
+func (mr *MockPaymentDataStoreMockRecorder) ListCreatedBetween(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCreatedBetween", reflect.TypeOf((*MockPaymentDataStore)(nil).ListCreatedBetween), from, to)
+}
+
+// MarkRefunded mocks base method.
+func (m *MockPaymentDataStore) MarkRefunded(id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefunded", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRefunded indicates an expected call of MarkRefunded.
+func (mr *MockPaymentDataStoreMockRecorder) MarkRefunded(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefunded", reflect.TypeOf((*MockPaymentDataStore)(nil).MarkRefunded), id)
+}
+
+// ReleaseRefund mocks base method.
+func (m *MockPaymentDataStore) ReleaseRefund(refund *datastore.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRefund", refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRefund indicates an expected call of ReleaseRefund.
+func (mr *MockPaymentDataStoreMockRecorder) ReleaseRefund(refund interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRefund", reflect.TypeOf((*MockPaymentDataStore)(nil).ReleaseRefund), refund)
+}
+
+// ReserveRefund mocks base method.
+func (m *MockPaymentDataStore) ReserveRefund(refund *datastore.Refund) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveRefund", refund)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveRefund indicates an expected call of ReserveRefund.
+func (mr *MockPaymentDataStoreMockRecorder) ReserveRefund(refund interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveRefund", reflect.TypeOf((*MockPaymentDataStore)(nil).ReserveRefund), refund)
+}
+
+// SettlePending mocks base method.
+func (m *MockPaymentDataStore) SettlePending(id uint, status datastore.PaymentStatus, paidAt *time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SettlePending", id, status, paidAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SettlePending indicates an expected call of SettlePending.
+func (mr *MockPaymentDataStoreMockRecorder) SettlePending(id, status, paidAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettlePending", reflect.TypeOf((*MockPaymentDataStore)(nil).SettlePending), id, status, paidAt)
+}
@@ -80,6 +80,21 @@ func (mr *MockSystemClientMockRecorder) CountAppointmentsWithFilters(ctx, filter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAppointmentsWithFilters", reflect.TypeOf((*MockSystemClient)(nil).CountAppointmentsWithFilters), ctx, filters)
 }
 
+// CreateAppointment mocks base method.
+func (m *MockSystemClient) CreateAppointment(ctx context.Context, params *hospital.CreateAppointmentParams) (*hospital.Appointment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAppointment", ctx, params)
+	ret0, _ := ret[0].(*hospital.Appointment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAppointment indicates an expected call of CreateAppointment.
+func (mr *MockSystemClientMockRecorder) CreateAppointment(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAppointment", reflect.TypeOf((*MockSystemClient)(nil).CreateAppointment), ctx, params)
+}
+
 // FindAppointmentByID mocks base method.
 func (m *MockSystemClient) FindAppointmentByID(ctx context.Context, appointmentID int) (*hospital.Appointment, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +125,21 @@ func (mr *MockSystemClientMockRecorder) FindDoctorAppointmentByID(ctx, appointme
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDoctorAppointmentByID", reflect.TypeOf((*MockSystemClient)(nil).FindDoctorAppointmentByID), ctx, appointmentID)
 }
 
+// FindDoctorByID mocks base method.
+func (m *MockSystemClient) FindDoctorByID(ctx context.Context, id string) (*hospital.Doctor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDoctorByID", ctx, id)
+	ret0, _ := ret[0].(*hospital.Doctor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDoctorByID indicates an expected call of FindDoctorByID.
+func (mr *MockSystemClientMockRecorder) FindDoctorByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDoctorByID", reflect.TypeOf((*MockSystemClient)(nil).FindDoctorByID), ctx, id)
+}
+
 // FindDoctorByUsername mocks base method.
 func (m *MockSystemClient) FindDoctorByUsername(ctx context.Context, username string) (*hospital.Doctor, error) {
 	m.ctrl.T.Helper()
@@ -170,6 +200,46 @@ func (mr *MockSystemClientMockRecorder) FindPatientByID(ctx, id interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPatientByID", reflect.TypeOf((*MockSystemClient)(nil).FindPatientByID), ctx, id)
 }
 
+// IsDoctorAvailable mocks base method.
+func (m *MockSystemClient) IsDoctorAvailable(ctx context.Context, doctorID string, start, end time.Time, ignoredAppointmentIDs ...int) (bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, doctorID, start, end}
+	for _, a := range ignoredAppointmentIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IsDoctorAvailable", varargs...)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDoctorAvailable indicates an expected call of IsDoctorAvailable.
+func (mr *MockSystemClientMockRecorder) IsDoctorAvailable(ctx, doctorID, start, end interface{}, ignoredAppointmentIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, doctorID, start, end}, ignoredAppointmentIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDoctorAvailable", reflect.TypeOf((*MockSystemClient)(nil).IsDoctorAvailable), varargs...)
+}
+
+// IsPatientAvailable mocks base method.
+func (m *MockSystemClient) IsPatientAvailable(ctx context.Context, patientID string, start, end time.Time, ignoredAppointmentIDs ...int) (bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, patientID, start, end}
+	for _, a := range ignoredAppointmentIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IsPatientAvailable", varargs...)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsPatientAvailable indicates an expected call of IsPatientAvailable.
+func (mr *MockSystemClientMockRecorder) IsPatientAvailable(ctx, patientID, start, end interface{}, ignoredAppointmentIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, patientID, start, end}, ignoredAppointmentIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPatientAvailable", reflect.TypeOf((*MockSystemClient)(nil).IsPatientAvailable), varargs...)
+}
+
 // ListAppointmentsByDoctorID mocks base method.
 func (m *MockSystemClient) ListAppointmentsByDoctorID(ctx context.Context, doctorID string, date time.Time) ([]*hospital.AppointmentOverview, error) {
 	m.ctrl.T.Helper()
@@ -185,6 +255,36 @@ func (mr *MockSystemClientMockRecorder) ListAppointmentsByDoctorID(ctx, doctorID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppointmentsByDoctorID", reflect.TypeOf((*MockSystemClient)(nil).ListAppointmentsByDoctorID), ctx, doctorID, date)
 }
 
+// ListAppointmentsByDoctorIDInRange mocks base method.
+func (m *MockSystemClient) ListAppointmentsByDoctorIDInRange(ctx context.Context, doctorID string, start, end time.Time) ([]*hospital.AppointmentOverview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppointmentsByDoctorIDInRange", ctx, doctorID, start, end)
+	ret0, _ := ret[0].([]*hospital.AppointmentOverview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppointmentsByDoctorIDInRange indicates an expected call of ListAppointmentsByDoctorIDInRange.
+func (mr *MockSystemClientMockRecorder) ListAppointmentsByDoctorIDInRange(ctx, doctorID, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppointmentsByDoctorIDInRange", reflect.TypeOf((*MockSystemClient)(nil).ListAppointmentsByDoctorIDInRange), ctx, doctorID, start, end)
+}
+
+// ListAppointmentsByInvoiceIDs mocks base method.
+func (m *MockSystemClient) ListAppointmentsByInvoiceIDs(ctx context.Context, invoiceIDs []int) (map[int]*hospital.AppointmentOverview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppointmentsByInvoiceIDs", ctx, invoiceIDs)
+	ret0, _ := ret[0].(map[int]*hospital.AppointmentOverview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppointmentsByInvoiceIDs indicates an expected call of ListAppointmentsByInvoiceIDs.
+func (mr *MockSystemClientMockRecorder) ListAppointmentsByInvoiceIDs(ctx, invoiceIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppointmentsByInvoiceIDs", reflect.TypeOf((*MockSystemClient)(nil).ListAppointmentsByInvoiceIDs), ctx, invoiceIDs)
+}
+
 // ListAppointmentsByPatientID mocks base method.
 func (m *MockSystemClient) ListAppointmentsByPatientID(ctx context.Context, patientID string, since time.Time) ([]*hospital.AppointmentOverview, error) {
 	m.ctrl.T.Helper()
@@ -215,6 +315,36 @@ func (mr *MockSystemClientMockRecorder) ListAppointmentsWithFilters(ctx, filters
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppointmentsWithFilters", reflect.TypeOf((*MockSystemClient)(nil).ListAppointmentsWithFilters), ctx, filters, take, skip)
 }
 
+// ListAvailableDoctors mocks base method.
+func (m *MockSystemClient) ListAvailableDoctors(ctx context.Context, start, end time.Time) ([]*hospital.DoctorOverview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAvailableDoctors", ctx, start, end)
+	ret0, _ := ret[0].([]*hospital.DoctorOverview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAvailableDoctors indicates an expected call of ListAvailableDoctors.
+func (mr *MockSystemClientMockRecorder) ListAvailableDoctors(ctx, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAvailableDoctors", reflect.TypeOf((*MockSystemClient)(nil).ListAvailableDoctors), ctx, start, end)
+}
+
+// ListInvoicesByPatientID mocks base method.
+func (m *MockSystemClient) ListInvoicesByPatientID(ctx context.Context, patientID string) ([]*hospital.PatientInvoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvoicesByPatientID", ctx, patientID)
+	ret0, _ := ret[0].([]*hospital.PatientInvoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvoicesByPatientID indicates an expected call of ListInvoicesByPatientID.
+func (mr *MockSystemClientMockRecorder) ListInvoicesByPatientID(ctx, patientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvoicesByPatientID", reflect.TypeOf((*MockSystemClient)(nil).ListInvoicesByPatientID), ctx, patientID)
+}
+
 // PaidInvoice mocks base method.
 func (m *MockSystemClient) PaidInvoice(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
@@ -229,6 +359,21 @@ func (mr *MockSystemClientMockRecorder) PaidInvoice(ctx, id interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaidInvoice", reflect.TypeOf((*MockSystemClient)(nil).PaidInvoice), ctx, id)
 }
 
+// RescheduleAppointment mocks base method.
+func (m *MockSystemClient) RescheduleAppointment(ctx context.Context, appointment *hospital.Appointment, start, end time.Time) (*hospital.Appointment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleAppointment", ctx, appointment, start, end)
+	ret0, _ := ret[0].(*hospital.Appointment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RescheduleAppointment indicates an expected call of RescheduleAppointment.
+func (mr *MockSystemClientMockRecorder) RescheduleAppointment(ctx, appointment, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleAppointment", reflect.TypeOf((*MockSystemClient)(nil).RescheduleAppointment), ctx, appointment, start, end)
+}
+
 // SetAppointmentStatus mocks base method.
 func (m *MockSystemClient) SetAppointmentStatus(ctx context.Context, appointmentID int, status hospital.SettableAppointmentStatus) error {
 	m.ctrl.T.Helper()
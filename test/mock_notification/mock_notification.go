@@ -48,3 +48,17 @@ func (mr *MockClientMockRecorder) Send(ctx, params, data interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockClient)(nil).Send), ctx, params, data)
 }
+
+// SendToDoctor mocks base method.
+func (m *MockClient) SendToDoctor(ctx context.Context, token string, params notification.SendParams, data map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendToDoctor", ctx, token, params, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendToDoctor indicates an expected call of SendToDoctor.
+func (mr *MockClientMockRecorder) SendToDoctor(ctx, token, params, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToDoctor", reflect.TypeOf((*MockClient)(nil).SendToDoctor), ctx, token, params, data)
+}
@@ -6,6 +6,7 @@ package mock_payment
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	payment "github.com/synthia-telemed/backend-api/pkg/payment"
@@ -64,6 +65,51 @@ func (mr *MockClientMockRecorder) CreateCustomer(patientID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCustomer", reflect.TypeOf((*MockClient)(nil).CreateCustomer), patientID)
 }
 
+// GetCharge mocks base method.
+func (m *MockClient) GetCharge(chargeID string) (*payment.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCharge", chargeID)
+	ret0, _ := ret[0].(*payment.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCharge indicates an expected call of GetCharge.
+func (mr *MockClientMockRecorder) GetCharge(chargeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCharge", reflect.TypeOf((*MockClient)(nil).GetCharge), chargeID)
+}
+
+// ListCharges mocks base method.
+func (m *MockClient) ListCharges(from, to time.Time) ([]*payment.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCharges", from, to)
+	ret0, _ := ret[0].([]*payment.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCharges indicates an expected call of ListCharges.
+func (mr *MockClientMockRecorder) ListCharges(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCharges", reflect.TypeOf((*MockClient)(nil).ListCharges), from, to)
+}
+
+// ParseWebhookEvent mocks base method.
+func (m *MockClient) ParseWebhookEvent(payload []byte, signature, timestamp string) (*payment.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseWebhookEvent", payload, signature, timestamp)
+	ret0, _ := ret[0].(*payment.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseWebhookEvent indicates an expected call of ParseWebhookEvent.
+func (mr *MockClientMockRecorder) ParseWebhookEvent(payload, signature, timestamp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseWebhookEvent", reflect.TypeOf((*MockClient)(nil).ParseWebhookEvent), payload, signature, timestamp)
+}
+
 // PayWithCreditCard mocks base method.
 func (m *MockClient) PayWithCreditCard(customerID, cardID, refID string, amount int) (*payment.Payment, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +125,36 @@ func (mr *MockClientMockRecorder) PayWithCreditCard(customerID, cardID, refID, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayWithCreditCard", reflect.TypeOf((*MockClient)(nil).PayWithCreditCard), customerID, cardID, refID, amount)
 }
 
+// PayWithPromptPay mocks base method.
+func (m *MockClient) PayWithPromptPay(refID string, amount int) (*payment.PromptPay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayWithPromptPay", refID, amount)
+	ret0, _ := ret[0].(*payment.PromptPay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayWithPromptPay indicates an expected call of PayWithPromptPay.
+func (mr *MockClientMockRecorder) PayWithPromptPay(refID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayWithPromptPay", reflect.TypeOf((*MockClient)(nil).PayWithPromptPay), refID, amount)
+}
+
+// Refund mocks base method.
+func (m *MockClient) Refund(chargeID string, amount int) (*payment.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", chargeID, amount)
+	ret0, _ := ret[0].(*payment.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockClientMockRecorder) Refund(chargeID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockClient)(nil).Refund), chargeID, amount)
+}
+
 // RemoveCreditCard mocks base method.
 func (m *MockClient) RemoveCreditCard(customerID, cardID string) error {
 	m.ctrl.T.Helper()
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	token "github.com/synthia-telemed/backend-api/pkg/token"
 )
 
 // MockService is a mock of Service interface.
@@ -33,17 +34,61 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// GenerateRefreshToken mocks base method.
+func (m *MockService) GenerateRefreshToken(userID uint64, role, sessionID string) (*token.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRefreshToken", userID, role, sessionID)
+	ret0, _ := ret[0].(*token.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRefreshToken indicates an expected call of GenerateRefreshToken.
+func (mr *MockServiceMockRecorder) GenerateRefreshToken(userID, role, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRefreshToken", reflect.TypeOf((*MockService)(nil).GenerateRefreshToken), userID, role, sessionID)
+}
+
 // GenerateToken mocks base method.
-func (m *MockService) GenerateToken(userID uint64, role string) (string, error) {
+func (m *MockService) GenerateToken(userID uint64, role, sessionID string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", userID, role)
+	ret := m.ctrl.Call(m, "GenerateToken", userID, role, sessionID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockServiceMockRecorder) GenerateToken(userID, role interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GenerateToken(userID, role, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockService)(nil).GenerateToken), userID, role, sessionID)
+}
+
+// RevokeRefreshToken mocks base method.
+func (m *MockService) RevokeRefreshToken(sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockServiceMockRecorder) RevokeRefreshToken(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockService)(nil).RevokeRefreshToken), sessionID)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockService) RotateRefreshToken(refreshToken string) (*token.RotatedRefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", refreshToken)
+	ret0, _ := ret[0].(*token.RotatedRefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockServiceMockRecorder) RotateRefreshToken(refreshToken interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockService)(nil).GenerateToken), userID, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockService)(nil).RotateRefreshToken), refreshToken)
 }
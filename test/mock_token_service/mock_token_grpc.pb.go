@@ -36,6 +36,26 @@ func (m *MockTokenClient) EXPECT() *MockTokenClientMockRecorder {
 	return m.recorder
 }
 
+// GenerateRefreshToken mocks base method.
+func (m *MockTokenClient) GenerateRefreshToken(ctx context.Context, in *proto.GenerateRefreshTokenRequest, opts ...grpc.CallOption) (*proto.RefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GenerateRefreshToken", varargs...)
+	ret0, _ := ret[0].(*proto.RefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRefreshToken indicates an expected call of GenerateRefreshToken.
+func (mr *MockTokenClientMockRecorder) GenerateRefreshToken(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRefreshToken", reflect.TypeOf((*MockTokenClient)(nil).GenerateRefreshToken), varargs...)
+}
+
 // GenerateToken mocks base method.
 func (m *MockTokenClient) GenerateToken(ctx context.Context, in *proto.GenerateTokenRequest, opts ...grpc.CallOption) (*proto.TokenResponse, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +76,46 @@ func (mr *MockTokenClientMockRecorder) GenerateToken(ctx, in interface{}, opts .
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockTokenClient)(nil).GenerateToken), varargs...)
 }
 
+// RevokeRefreshToken mocks base method.
+func (m *MockTokenClient) RevokeRefreshToken(ctx context.Context, in *proto.RevokeRefreshTokenRequest, opts ...grpc.CallOption) (*proto.RevokeRefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", varargs...)
+	ret0, _ := ret[0].(*proto.RevokeRefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockTokenClientMockRecorder) RevokeRefreshToken(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockTokenClient)(nil).RevokeRefreshToken), varargs...)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockTokenClient) RotateRefreshToken(ctx context.Context, in *proto.RotateRefreshTokenRequest, opts ...grpc.CallOption) (*proto.RotateRefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RotateRefreshToken", varargs...)
+	ret0, _ := ret[0].(*proto.RotateRefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockTokenClientMockRecorder) RotateRefreshToken(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockTokenClient)(nil).RotateRefreshToken), varargs...)
+}
+
 // MockTokenServer is a mock of TokenServer interface.
 type MockTokenServer struct {
 	ctrl     *gomock.Controller
@@ -79,6 +139,21 @@ func (m *MockTokenServer) EXPECT() *MockTokenServerMockRecorder {
 	return m.recorder
 }
 
+// GenerateRefreshToken mocks base method.
+func (m *MockTokenServer) GenerateRefreshToken(arg0 context.Context, arg1 *proto.GenerateRefreshTokenRequest) (*proto.RefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(*proto.RefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRefreshToken indicates an expected call of GenerateRefreshToken.
+func (mr *MockTokenServerMockRecorder) GenerateRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRefreshToken", reflect.TypeOf((*MockTokenServer)(nil).GenerateRefreshToken), arg0, arg1)
+}
+
 // GenerateToken mocks base method.
 func (m *MockTokenServer) GenerateToken(arg0 context.Context, arg1 *proto.GenerateTokenRequest) (*proto.TokenResponse, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +169,36 @@ func (mr *MockTokenServerMockRecorder) GenerateToken(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockTokenServer)(nil).GenerateToken), arg0, arg1)
 }
 
+// RevokeRefreshToken mocks base method.
+func (m *MockTokenServer) RevokeRefreshToken(arg0 context.Context, arg1 *proto.RevokeRefreshTokenRequest) (*proto.RevokeRefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(*proto.RevokeRefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockTokenServerMockRecorder) RevokeRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockTokenServer)(nil).RevokeRefreshToken), arg0, arg1)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockTokenServer) RotateRefreshToken(arg0 context.Context, arg1 *proto.RotateRefreshTokenRequest) (*proto.RotateRefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(*proto.RotateRefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockTokenServerMockRecorder) RotateRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockTokenServer)(nil).RotateRefreshToken), arg0, arg1)
+}
+
 // mustEmbedUnimplementedTokenServer mocks base method.
 func (m *MockTokenServer) mustEmbedUnimplementedTokenServer() {
 	m.ctrl.T.Helper()